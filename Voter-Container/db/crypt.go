@@ -0,0 +1,113 @@
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// EncryptedPrefix starts every field the redis store has encrypted, so a
+// field written before ENCRYPTION_KEY was set can still be read as it is
+// and is encrypted the next time its voter is written
+const EncryptedPrefix = "enc:v1:"
+
+// ErrNoEncryptionKey is returned when a voter with encrypted fields is
+// read but ENCRYPTION_KEY is not set
+var ErrNoEncryptionKey = errors.New("voter is encrypted but ENCRYPTION_KEY is not set")
+
+// piiCipher encrypts the personal fields of voters with AES-256-GCM before
+// they go into redis and decrypts them when they come back out.  A nil
+// piiCipher leaves them as they are
+type piiCipher struct {
+	aead cipher.AEAD
+}
+
+// piiFields returns the fields of voter that are encrypted at rest, by
+// name.  A new name or contact field on Voter has to be added here
+func piiFields(voter *Voter) map[string]*string {
+	return map[string]*string{
+		"FirstName": &voter.FirstName,
+		"LastName":  &voter.LastName,
+	}
+}
+
+// piiCipherFromEnv returns a piiCipher keyed with ENCRYPTION_KEY, 32 bytes
+// as base64 such as from openssl rand -base64 32, or nil if it is not set.
+// Every replica has to have the same key, and voters written with a key
+// can't be read without it
+func piiCipherFromEnv() (*piiCipher, error) {
+	encoded := os.Getenv("ENCRYPTION_KEY")
+	if encoded == "" {
+		log.Println("ENCRYPTION_KEY is not set, voter names will be kept in plaintext")
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_KEY is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("ENCRYPTION_KEY must be 32 bytes, it is %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &piiCipher{aead: aead}, nil
+}
+
+// additionalData ties the ciphertext of a field to the voter and field it
+// is for, so it can't be copied onto another voter or field in redis
+func additionalData(voterId uint, field string) []byte {
+	return []byte(fmt.Sprintf("%s:%s", redisKeyFromId(voterId), field))
+}
+
+// encryptVoter encrypts the personal fields of voter in place, each with
+// a random nonce that is kept in front of its ciphertext
+func (c *piiCipher) encryptVoter(voter *Voter) error {
+	if c == nil {
+		return nil
+	}
+	for name, field := range piiFields(voter) {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		sealed := c.aead.Seal(nonce, nonce, []byte(*field), additionalData(voter.VoterID, name))
+		*field = EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+	}
+	return nil
+}
+
+// decryptVoter decrypts the personal fields of voter in place.  A field
+// without EncryptedPrefix was written in plaintext and is left alone
+func (c *piiCipher) decryptVoter(voter *Voter) error {
+	for name, field := range piiFields(voter) {
+		if !strings.HasPrefix(*field, EncryptedPrefix) {
+			continue
+		}
+		if c == nil {
+			return ErrNoEncryptionKey
+		}
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(*field, EncryptedPrefix))
+		if err != nil || len(sealed) < c.aead.NonceSize() {
+			return fmt.Errorf("voter %d has a corrupt %s", voter.VoterID, name)
+		}
+		nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+		plain, err := c.aead.Open(nil, nonce, ciphertext, additionalData(voter.VoterID, name))
+		if err != nil {
+			return fmt.Errorf("decrypting the %s of voter %d: %w", name, voter.VoterID, err)
+		}
+		*field = string(plain)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"sort"
+	"sync"
+)
+
+// memoryStore keeps voters in a map.  Nothing is saved when the process
+// exits, it is meant for tests and trying the API out without redis
+type memoryStore struct {
+	mu     sync.Mutex
+	voters map[uint]Voter
+}
+
+// NewMemoryStore returns an empty VoterStore that keeps voters in memory
+func NewMemoryStore() VoterStore {
+	return &memoryStore{voters: make(map[uint]Voter)}
+}
+
+func (m *memoryStore) Get(id uint) (Voter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	voter, ok := m.voters[id]
+	if !ok {
+		return Voter{}, ErrNotFound
+	}
+	return copyVoter(voter), nil
+}
+
+func (m *memoryStore) Add(voter Voter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.voters[voter.VoterID] = copyVoter(voter)
+	return nil
+}
+
+func (m *memoryStore) Update(voter Voter) error {
+	return m.Add(voter)
+}
+
+func (m *memoryStore) Delete(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.voters[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.voters, id)
+	return nil
+}
+
+func (m *memoryStore) List() ([]Voter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var voterList []Voter
+	for _, voter := range m.voters {
+		voterList = append(voterList, copyVoter(voter))
+	}
+	sort.Slice(voterList, func(i, j int) bool {
+		return voterList[i].VoterID < voterList[j].VoterID
+	})
+	return voterList, nil
+}
+
+func (m *memoryStore) Page(offset int, limit int) ([]Voter, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]uint, 0, len(m.voters))
+	for id := range m.voters {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	start, end := pageBounds(len(ids), offset, limit)
+	var voterList []Voter
+	for _, id := range ids[start:end] {
+		voterList = append(voterList, copyVoter(m.voters[id]))
+	}
+	return voterList, len(ids), nil
+}
+
+// copyVoter copies a voter so that callers can't change a stored voter's
+// history through the slice
+func copyVoter(voter Voter) Voter {
+	voter.VoteHistory = append([]voterPoll(nil), voter.VoteHistory...)
+	return voter
+}
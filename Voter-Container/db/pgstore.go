@@ -0,0 +1,151 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresDefaultLocation is the database used when DB_DRIVER=postgres
+// and DATABASE_URL is not set
+const PostgresDefaultLocation = "postgres://postgres@localhost:5432/voters?sslmode=disable"
+
+// postgresSchema creates the voters table if it is not there yet.  Each
+// voter is kept whole as a JSONB document, the same shape it has in
+// redis, keyed by its id
+const postgresSchema = `CREATE TABLE IF NOT EXISTS voters (
+	voter_id BIGINT PRIMARY KEY,
+	doc      JSONB  NOT NULL
+)`
+
+// postgresStore keeps voters in a postgres table, for deployments that
+// need the voters to be durable
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore connects to the postgres database at url, creates the
+// voters table if needed and returns a VoterStore backed by it
+func NewPostgresStore(url string) (VoterStore, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &postgresStore{db: db}, nil
+}
+
+func (p *postgresStore) Get(id uint) (Voter, error) {
+	var doc []byte
+	err := p.db.QueryRow(`SELECT doc FROM voters WHERE voter_id = $1`, id).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Voter{}, ErrNotFound
+	}
+	if err != nil {
+		return Voter{}, err
+	}
+
+	var voter Voter
+	if err := json.Unmarshal(doc, &voter); err != nil {
+		return Voter{}, err
+	}
+	return voter, nil
+}
+
+func (p *postgresStore) Add(voter Voter) error {
+	return p.put(voter)
+}
+
+func (p *postgresStore) Update(voter Voter) error {
+	return p.put(voter)
+}
+
+// put writes the whole voter, replacing it if it is already there
+func (p *postgresStore) put(voter Voter) error {
+	doc, err := json.Marshal(voter)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.Exec(`INSERT INTO voters (voter_id, doc) VALUES ($1, $2)
+		ON CONFLICT (voter_id) DO UPDATE SET doc = EXCLUDED.doc`, voter.VoterID, doc)
+	return err
+}
+
+func (p *postgresStore) Delete(id uint) error {
+	result, err := p.db.Exec(`DELETE FROM voters WHERE voter_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *postgresStore) List() ([]Voter, error) {
+	rows, err := p.db.Query(`SELECT doc FROM voters ORDER BY voter_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var voterList []Voter
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var voter Voter
+		if err := json.Unmarshal(doc, &voter); err != nil {
+			return nil, err
+		}
+		voterList = append(voterList, voter)
+	}
+	return voterList, rows.Err()
+}
+
+func (p *postgresStore) Page(offset int, limit int) ([]Voter, int, error) {
+	var total int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM voters`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	//LIMIT NULL is no limit at all
+	var limitArg any
+	if limit > 0 {
+		limitArg = limit
+	}
+	rows, err := p.db.Query(`SELECT doc FROM voters ORDER BY voter_id LIMIT $1 OFFSET $2`, limitArg, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var voterList []Voter
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, 0, err
+		}
+		var voter Voter
+		if err := json.Unmarshal(doc, &voter); err != nil {
+			return nil, 0, err
+		}
+		voterList = append(voterList, voter)
+	}
+	return voterList, total, rows.Err()
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nitishm/go-rejson/v4"
+)
+
+// ErrNotFound is returned by a VoterStore when the voter asked for is not
+// in the store
+var ErrNotFound = errors.New("voter not found")
+
+// VoterStore is where the voters are kept.  VoterList does all of its
+// reads and writes through a VoterStore, so the backend can be swapped
+// without touching the rules in VoterList.  Add and Update both write
+// the whole voter, VoterList checks whether the voter exists first.
+// Page returns limit voters, 0 for all of them, from offset on in order
+// of id, along with how many voters there are in all
+type VoterStore interface {
+	Get(id uint) (Voter, error)
+	Add(voter Voter) error
+	Update(voter Voter) error
+	Delete(id uint) error
+	List() ([]Voter, error)
+	Page(offset int, limit int) ([]Voter, int, error)
+}
+
+type cache struct {
+	cacheClient *redis.Client
+	jsonHelper  *rejson.Handler
+	context     context.Context
+}
+
+// redisStore keeps voters in redis as ReJSON documents under
+// voters:<id>, with their names encrypted by pii if ENCRYPTION_KEY is set,
+// see crypt.go
+type redisStore struct {
+	cache
+	pii *piiCipher
+}
+
+//------------------------------------------------------------
+// REDIS HELPERS
+//------------------------------------------------------------
+
+// In redis, our keys will be strings, they will look like
+// voter:<number>.  This function will take an integer and
+// return a string that can be used as a key in redis
+func redisKeyFromId(id uint) string {
+	return fmt.Sprintf("%s%d", RedisKeyPrefix, id)
+}
+
+// Helper to return a VoterList from redis provided a key
+func (r *redisStore) getItemFromRedis(key string, voter *Voter) error {
+
+	//Lets query redis for the voter, note we can return parts of the
+	//json structure, the second parameter "." means return the entire
+	//json structure
+	voterObject, err := r.jsonHelper.JSONGet(key, ".")
+	if err != nil {
+		if err.Error() == RedisNilError {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	//JSONGet returns an "any" object, or empty interface,
+	//we need to convert it to a byte array, which is the
+	//underlying type of the object, then we can unmarshal
+	//it into our voter struct
+	err = json.Unmarshal(voterObject.([]byte), voter)
+	if err != nil {
+		return err
+	}
+
+	return r.pii.decryptVoter(voter)
+}
+
+func (r *redisStore) Get(id uint) (Voter, error) {
+	var voter Voter
+	if err := r.getItemFromRedis(redisKeyFromId(id), &voter); err != nil {
+		return Voter{}, err
+	}
+	return voter, nil
+}
+
+func (r *redisStore) Add(voter Voter) error {
+	//voter is a copy, so encrypting it leaves the caller's alone
+	if err := r.pii.encryptVoter(&voter); err != nil {
+		return err
+	}
+	_, err := r.jsonHelper.JSONSet(redisKeyFromId(voter.VoterID), ".", voter)
+	return err
+}
+
+func (r *redisStore) Update(voter Voter) error {
+	//There is no update in ReJSON, so we just overwrite the voter
+	return r.Add(voter)
+}
+
+func (r *redisStore) Delete(id uint) error {
+	numDeleted, err := r.cacheClient.Del(r.context, redisKeyFromId(id)).Result()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *redisStore) List() ([]Voter, error) {
+	var voterList []Voter
+
+	pattern := RedisKeyPrefix + "*"
+	ks, err := r.cacheClient.Keys(r.context, pattern).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range ks {
+		var voter Voter
+		err := r.getItemFromRedis(key, &voter)
+		if errors.Is(err, ErrNotFound) {
+			//Deleted since we listed the keys
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		voterList = append(voterList, voter)
+	}
+
+	return voterList, nil
+}
+
+// Page only reads the voters on the page from redis, the ids of the
+// others are all it needs to sort and count them
+func (r *redisStore) Page(offset int, limit int) ([]Voter, int, error) {
+	pattern := RedisKeyPrefix + "*"
+	ks, err := r.cacheClient.Keys(r.context, pattern).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, 0, len(ks))
+	for _, key := range ks {
+		var id uint
+		if _, err := fmt.Sscanf(key, RedisKeyPrefix+"%d", &id); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	start, end := pageBounds(len(ids), offset, limit)
+	var voterList []Voter
+	for _, id := range ids[start:end] {
+		voter, err := r.Get(id)
+		if errors.Is(err, ErrNotFound) {
+			//Deleted since we listed the keys
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		voterList = append(voterList, voter)
+	}
+
+	return voterList, len(ids), nil
+}
+
+// pageBounds returns where the page of limit items, 0 for all of them,
+// from offset on starts and ends in a list of total items
+func pageBounds(total int, offset int, limit int) (start int, end int) {
+	start = offset
+	if start > total {
+		start = total
+	}
+	end = total
+	//Compared this way round so a huge limit can't overflow
+	if limit > 0 && limit < total-start {
+		end = start + limit
+	}
+	return start, end
+}
@@ -0,0 +1,262 @@
+// Package client is the HTTP plumbing shared by the typed clients of the
+// voting application services in votersclient, pollsclient and
+// votesclient.  Go code that needs a voter, poll or vote should go
+// through one of those rather than reading another service's redis keys
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Defaults for a new Client
+const (
+	DefaultTimeout   = 10 * time.Second
+	DefaultRetries   = 2
+	DefaultRetryWait = 100 * time.Millisecond
+)
+
+// AdminTokenHeader is the header administrative requests carry the admin
+// token in
+const AdminTokenHeader = "X-Admin-Token"
+
+// NextCursorHeader carries the cursor of the next page of a list paged
+// with ?cursor, 0 after the last page
+const NextCursorHeader = "X-Next-Cursor"
+
+// RequestIDHeader carries the id a service gave a request, it is sent
+// back on every response
+const RequestIDHeader = "X-Request-ID"
+
+// ActorHeader carries who is making a request, for the services' audit
+// log
+const ActorHeader = "X-Actor"
+
+// ErrNotFound is matched by the StatusError of a 404, so callers can use
+// errors.Is(err, client.ErrNotFound)
+var ErrNotFound = errors.New("not found")
+
+// StatusError is returned when a service answers with anything but a 2xx.
+// Message is the detail of the problem the service sent, if it gave one,
+// and RequestID the id the service logged the request under
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+	RequestID  string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Client sends requests to one service.  The fields can be changed after
+// New, but not while requests are being made
+type Client struct {
+	//BaseURL is where the service is, e.g. http://localhost:1080
+	BaseURL string
+	//HTTPClient sends the requests, its Timeout covers each attempt
+	HTTPClient *http.Client
+	//Retries is how many more times a GET, PUT or DELETE is tried after
+	//a network error, 429 or 5xx.  POSTs are never retried, they may
+	//have been carried out even though the answer was lost
+	Retries int
+	//RetryWait is how long to wait before the first retry, it doubles
+	//with each retry after that
+	RetryWait time.Duration
+	//AdminToken is sent in X-Admin-Token if it is set
+	AdminToken string
+	//Actor is sent in X-Actor if it is set, the audit log records the
+	//changes made with the Client as made by it
+	Actor string
+}
+
+// New returns a Client for the service at baseURL with the defaults
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		Retries:    DefaultRetries,
+		RetryWait:  DefaultRetryWait,
+	}
+}
+
+// Do sends a request to path, with query if it isn't nil and body as
+// JSON if it isn't nil, and decodes the JSON response into out if it
+// isn't nil.  The response headers are returned so that callers can read
+// paging headers
+func (c *Client) Do(ctx context.Context, method string, path string, query url.Values, body any, out any) (http.Header, error) {
+	return c.DoWithHeader(ctx, method, path, query, nil, body, out)
+}
+
+// DoWithHeader is Do with header added to the request, for requests that
+// need headers of their own such as a poll's passcode
+func (c *Client) DoWithHeader(ctx context.Context, method string, path string, query url.Values, header http.Header, body any, out any) (http.Header, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	retries := c.Retries
+	if method == http.MethodPost {
+		retries = 0
+	}
+
+	wait := c.RetryWait
+	for attempt := 0; ; attempt++ {
+		respHeader, retry, err := c.try(ctx, method, u, header, payload, out)
+		if err == nil || !retry || attempt >= retries {
+			return respHeader, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// try makes one attempt at a request, and says whether it is worth
+// trying again if it fails
+func (c *Client) try(ctx context.Context, method string, u string, reqHeader http.Header, payload []byte, out any) (header http.Header, retry bool, err error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, false, err
+	}
+	for name, values := range reqHeader {
+		req.Header[name] = values
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.AdminToken != "" {
+		req.Header.Set(AdminTokenHeader, c.AdminToken)
+	}
+	if c.Actor != "" {
+		req.Header.Set(ActorHeader, c.Actor)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		//Nothing to retry once the caller has given up
+		return nil, ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		statusErr := &StatusError{Method: method, URL: u, StatusCode: resp.StatusCode, RequestID: resp.Header.Get(RequestIDHeader)}
+		var msg struct {
+			Detail string `json:"detail"`
+			Error  string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&msg) == nil {
+			statusErr.Message = msg.Detail
+			if statusErr.Message == "" {
+				statusErr.Message = msg.Error
+			}
+		}
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return resp.Header, retry, statusErr
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, false, fmt.Errorf("%s %s: decoding response: %w", method, u, err)
+		}
+	}
+	return resp.Header, false, nil
+}
+
+// AuditEntry is a change made to a voter, poll or vote, from the audit
+// log.  Before and After are the entity as it was and as it became, as
+// JSON, Before is empty for a created entity and After for a deleted one
+type AuditEntry struct {
+	ID        string
+	Entity    string
+	EntityID  uint
+	Action    string
+	Actor     string
+	RequestID string
+	At        time.Time
+	Before    json.RawMessage
+	After     json.RawMessage
+}
+
+// GetAudit returns the entries in the audit log about entity, voter, poll
+// or vote, only those about the one with id unless id is 0, oldest first.
+// The services share the audit log, so any of them can be asked
+func (c *Client) GetAudit(ctx context.Context, entity string, id uint) ([]AuditEntry, error) {
+	q := url.Values{"entity": {entity}}
+	if id != 0 {
+		q.Set("id", fmt.Sprint(id))
+	}
+	entries := []AuditEntry{}
+	_, err := c.Do(ctx, http.MethodGet, "/audit", q, nil, &entries)
+	return entries, err
+}
+
+// ListAll reads every item of the list at path a page at a time, walking
+// the ?cursor pages until the service says there are no more.  The pages
+// follow a redis SCAN, which can return an item twice, so items with the
+// same id are only kept once
+func ListAll[T any](ctx context.Context, c *Client, path string, query url.Values, id func(T) uint) ([]T, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+
+	items := []T{}
+	seen := make(map[uint]bool)
+	cursor := "0"
+	for {
+		q.Set("cursor", cursor)
+		var page []T
+		header, err := c.Do(ctx, http.MethodGet, path, q, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page {
+			if !seen[id(item)] {
+				seen[id(item)] = true
+				items = append(items, item)
+			}
+		}
+
+		cursor = header.Get(NextCursorHeader)
+		if cursor == "" || cursor == "0" {
+			return items, nil
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Package httpclient is the HTTP client the services use to call each
+// other, such as the votes API looking voters and polls up, or a
+// cascading poll delete removing votes.  Every attempt has its own
+// timeout, attempts that fail in a way worth trying again are retried with
+// exponential backoff, and a circuit breaker stops calling a service that
+// keeps failing for a while, so a service that is down turns requests
+// away at once rather than holding every one of them up
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults for a new Client
+const (
+	DefaultAttemptTimeout   = 500 * time.Millisecond
+	DefaultRetries          = 2
+	DefaultBackoff          = 50 * time.Millisecond
+	DefaultMaxBackoff       = 1 * time.Second
+	DefaultFailureThreshold = 5
+	DefaultOpenFor          = 10 * time.Second
+	DefaultMaxBody          = 8 << 20
+)
+
+// ErrCircuitOpen is returned without calling the service while its circuit
+// breaker is open, it has failed too many times in a row
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// ErrUnexpectedStatus is wrapped by the error for a response that is not
+// a 2xx or 404, so a caller can tell it from the service not answering
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+// Config is how a Client retries and when its circuit breaker opens.
+// Zero fields are the defaults, except Retries, where 0 is no retries
+type Config struct {
+	//AttemptTimeout is how long each attempt has, the context passed to
+	//Get can end it sooner
+	AttemptTimeout time.Duration
+	//Retries is how many more times a request is tried after a network
+	//error, a timeout, a 429 or a 5xx
+	Retries int
+	//Backoff is the most to wait before the first retry, it doubles with
+	//each retry after that up to MaxBackoff.  The wait is picked at
+	//random up to it, so clients that failed together don't retry
+	//together
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	//FailureThreshold is how many requests in a row can fail, after
+	//their retries, before the breaker opens
+	FailureThreshold int
+	//OpenFor is how long the breaker stays open before it lets one
+	//request through to see if the service is back
+	OpenFor time.Duration
+	//MaxBody is the most of a response body that is read
+	MaxBody int64
+}
+
+// DefaultConfig returns a Config with the defaults
+func DefaultConfig() Config {
+	return Config{
+		AttemptTimeout:   DefaultAttemptTimeout,
+		Retries:          DefaultRetries,
+		Backoff:          DefaultBackoff,
+		MaxBackoff:       DefaultMaxBackoff,
+		FailureThreshold: DefaultFailureThreshold,
+		OpenFor:          DefaultOpenFor,
+		MaxBody:          DefaultMaxBody,
+	}
+}
+
+// Response is the status, headers and body of a response
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// breaker states
+const (
+	closed = iota
+	open
+	halfOpen
+)
+
+// Client sends requests to one service.  It is safe to use from many
+// goroutines
+type Client struct {
+	//name is the service, for logging
+	name   string
+	config Config
+	http   *http.Client
+
+	mu sync.Mutex
+	//state is closed, open or halfOpen
+	state int
+	//failures is how many requests in a row have failed
+	failures int
+	//openedAt is when the breaker last opened
+	openedAt time.Time
+	//trying is true while the one request let through a half open
+	//breaker is being made
+	trying bool
+}
+
+// New returns a Client for the service named name, with config filled in
+// with the defaults where it is zero
+func New(name string, config Config) *Client {
+	defaults := DefaultConfig()
+	if config.AttemptTimeout <= 0 {
+		config.AttemptTimeout = defaults.AttemptTimeout
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaults.Backoff
+	}
+	if config.MaxBackoff < config.Backoff {
+		config.MaxBackoff = defaults.MaxBackoff
+		if config.MaxBackoff < config.Backoff {
+			config.MaxBackoff = config.Backoff
+		}
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaults.FailureThreshold
+	}
+	if config.OpenFor <= 0 {
+		config.OpenFor = defaults.OpenFor
+	}
+	if config.MaxBody <= 0 {
+		config.MaxBody = defaults.MaxBody
+	}
+	return &Client{name: name, config: config, http: &http.Client{}}
+}
+
+// Open is true while the breaker is turning requests away
+func (c *Client) Open() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state == open && time.Since(c.openedAt) < c.config.OpenFor
+}
+
+// Get sends a GET for url, see Do
+func (c *Client) Get(ctx context.Context, url string) (*Response, error) {
+	return c.Do(ctx, http.MethodGet, url)
+}
+
+// Do sends a request with method and no body for url and returns the
+// response of the first attempt that is a 2xx or a 404, or that failed in
+// a way not worth retrying.  Failed attempts are sent again, so method
+// must be one it is safe to repeat, such as GET or DELETE.  An error is
+// returned if every attempt failed, if ctx ended, or with ErrCircuitOpen
+// if the breaker is open.  Only the service failing, a network error, a
+// timeout, a 429 or a 5xx, counts towards opening the breaker.  Any other
+// status is the service answering, and ctx being canceled says nothing
+// about it, so neither does
+func (c *Client) Do(ctx context.Context, method string, url string) (*Response, error) {
+	return c.DoWithHeader(ctx, method, url, nil)
+}
+
+// DoWithHeader is Do with header added to every attempt, for requests
+// that need more than the Accept header Do sends
+func (c *Client) DoWithHeader(ctx context.Context, method string, url string, header http.Header) (*Response, error) {
+	return c.do(ctx, method, url, header, c.config.Retries)
+}
+
+// DoOnceWithHeader is DoWithHeader with a single attempt, for requests
+// it is not safe to repeat, such as one that uses something up.  An
+// attempt that timed out may still have gone through, so it is never sent
+// again
+func (c *Client) DoOnceWithHeader(ctx context.Context, method string, url string, header http.Header) (*Response, error) {
+	return c.do(ctx, method, url, header, 0)
+}
+
+// do sends a request, retrying it up to retries times
+func (c *Client) do(ctx context.Context, method string, url string, header http.Header, retries int) (*Response, error) {
+	if !c.allow() {
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, c.name)
+	}
+
+	wait := c.config.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, retry, err := c.try(ctx, method, url, header)
+		if err == nil {
+			c.record(true)
+			return resp, nil
+		}
+		if !retry {
+			//Trying again won't change the answer, and it is not the
+			//service failing, so it is not held against it
+			c.record(true)
+			return nil, err
+		}
+		if attempt >= retries || ctx.Err() != nil {
+			c.failed(ctx)
+			return nil, err
+		}
+
+		log.Println("Retrying "+c.name+" after: ", err)
+		select {
+		case <-ctx.Done():
+			c.failed(ctx)
+			return nil, err
+		case <-time.After(time.Duration(rand.Int63n(int64(wait)) + 1)):
+		}
+		wait *= 2
+		if wait > c.config.MaxBackoff {
+			wait = c.config.MaxBackoff
+		}
+	}
+}
+
+// try makes one attempt at a request, and says whether it is worth trying
+// again if it fails, which is also whether the service failed
+func (c *Client) try(ctx context.Context, method string, url string, header http.Header) (*Response, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.AttemptTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		//Drain what is left so the connection can be reused
+		io.Copy(io.Discard, io.LimitReader(resp.Body, c.config.MaxBody))
+		return nil, true, fmt.Errorf("%w from %s: %d", ErrUnexpectedStatus, c.name, resp.StatusCode)
+	}
+	if (resp.StatusCode < 200 || resp.StatusCode > 299) && resp.StatusCode != http.StatusNotFound {
+		return nil, false, fmt.Errorf("%w from %s: %d", ErrUnexpectedStatus, c.name, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.config.MaxBody))
+	if err != nil {
+		return nil, true, err
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, false, nil
+}
+
+// failed records a request that failed, unless ctx was canceled, the
+// caller going away says nothing about the service
+func (c *Client) failed(ctx context.Context) {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.trying = false
+		return
+	}
+	c.record(false)
+}
+
+// allow says whether a request can be made.  Once an open breaker has
+// been open for OpenFor it is half open and lets one request through, the
+// rest are turned away until it is known whether that one worked
+func (c *Client) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.state {
+	case open:
+		if time.Since(c.openedAt) < c.config.OpenFor {
+			return false
+		}
+		c.state = halfOpen
+		c.trying = true
+		log.Println("Circuit breaker half open, trying " + c.name)
+		return true
+	case halfOpen:
+		if c.trying {
+			return false
+		}
+		c.trying = true
+		return true
+	}
+	return true
+}
+
+// record counts a request that worked or failed, opening the breaker when
+// FailureThreshold have failed in a row or the request let through a half
+// open breaker failed, and closing it when a request works
+func (c *Client) record(ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == halfOpen {
+		c.trying = false
+	}
+	if ok {
+		if c.state != closed {
+			log.Println("Circuit breaker closed, " + c.name + " is back")
+		}
+		c.state = closed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == halfOpen || (c.state == closed && c.failures >= c.config.FailureThreshold) {
+		log.Println("Circuit breaker open, "+c.name+" failed in a row: ", c.failures)
+		c.state = open
+		c.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,316 @@
+// Package pollsclient is a typed client for the Polls API
+package pollsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"drexel.edu/voting-application/client"
+)
+
+// PollOption is one of the choices of a poll
+type PollOption struct {
+	PollOptionID   uint
+	PollOptionText string
+}
+
+// PollSettings is the optional behaviour of a poll
+type PollSettings struct {
+	Status           string
+	VoteRateLimit    uint
+	OpensAt          *time.Time
+	ClosesAt         *time.Time
+	ResultsPublished bool
+	Archived         bool
+	VotingMethod     string `json:",omitempty"`
+	MaxSelections    uint   `json:",omitempty"`
+	AllowWriteIns    bool   `json:",omitempty"`
+	HasPasscode      bool   `json:",omitempty"`
+	InviteOnly       bool   `json:",omitempty"`
+	Anonymous        bool   `json:",omitempty"`
+}
+
+// Invitation is the single use token that lets the voter with VoterID
+// vote in an invite only poll, see votesclient.AddVoteWithInvitation
+type Invitation struct {
+	VoterID uint
+	Token   string
+}
+
+// BallotLink is a signed link the voter with VoterID can open their
+// ballot for a poll with, see GetBallot.  URL is a path, to be put after
+// the address of the Polls API
+type BallotLink struct {
+	VoterID   uint
+	URL       string
+	ExpiresAt time.Time
+}
+
+// Ballot is what a ballot link opens.  Token is sent with the voter's
+// vote the same as an invitation token
+type Ballot struct {
+	VoterID   uint
+	ExpiresAt time.Time
+	Token     string
+	Poll      Poll
+}
+
+// Poll is a poll as the Polls API sends it
+type Poll struct {
+	PollID       uint
+	PollTitle    string
+	PollQuestion string
+	PollOptions  []PollOption
+	Settings     PollSettings
+	Tags         []string `json:",omitempty"`
+	//Passcode is only sent, to give the poll a passcode, it is never
+	//sent back
+	Passcode string   `json:",omitempty"`
+	Status   string   `json:",omitempty"`
+	Version  uint     `json:",omitempty"`
+	Links    []string `json:",omitempty"`
+}
+
+// OptionResult is the votes one option of a poll has
+type OptionResult struct {
+	ID      uint    `json:"id"`
+	Text    string  `json:"text"`
+	Count   uint    `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// PollWinner is the winning options of a poll
+type PollWinner struct {
+	Winners     []uint   `json:"winners"`
+	WinningText []string `json:"winningText"`
+	Count       uint     `json:"count"`
+	IsTie       bool     `json:"isTie"`
+}
+
+// PollReport is a poll together with its results
+type PollReport struct {
+	Poll        Poll            `json:"poll"`
+	IsOpen      bool            `json:"isOpen"`
+	TotalVotes  uint            `json:"totalVotes"`
+	Options     []OptionResult  `json:"options"`
+	Winner      PollWinner      `json:"winner"`
+	BySource    map[string]uint `json:"bySource"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+}
+
+// Client talks to the Polls API, see client.Client for the settings
+type Client struct {
+	*client.Client
+}
+
+// New returns a client for the Polls API at baseURL, for example
+// http://localhost:1090
+func New(baseURL string) *Client {
+	return &Client{client.New(baseURL)}
+}
+
+// ListPolls returns every poll, the drafts too if includeDrafts is set
+func (c *Client) ListPolls(ctx context.Context, includeDrafts bool) ([]Poll, error) {
+	var query url.Values
+	if includeDrafts {
+		query = url.Values{"includeDrafts": {"true"}}
+	}
+	return client.ListAll(ctx, c.Client, "/polls", query, func(p Poll) uint {
+		return p.PollID
+	})
+}
+
+// ListPollsWithTag returns every published poll with tag, the drafts too
+// if includeDrafts is set
+func (c *Client) ListPollsWithTag(ctx context.Context, tag string, includeDrafts bool) ([]Poll, error) {
+	query := url.Values{"tag": {tag}}
+	if includeDrafts {
+		query.Set("includeDrafts", "true")
+	}
+	return client.ListAll(ctx, c.Client, "/polls", query, func(p Poll) uint {
+		return p.PollID
+	})
+}
+
+// GetPoll returns a poll, an error matching client.ErrNotFound if there
+// is no such poll
+func (c *Client) GetPoll(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/polls/%d", id), nil, nil, &poll)
+	return poll, err
+}
+
+// AddPoll adds a poll, as a draft, and returns it as it was stored.  A
+// poll with no PollID is given the next free one, which is in the
+// returned poll
+func (c *Client) AddPoll(ctx context.Context, poll Poll) (Poll, error) {
+	var added Poll
+	_, err := c.Do(ctx, http.MethodPost, "/polls", nil, poll, &added)
+	return added, err
+}
+
+// UpdatePoll replaces a poll and returns it as it was stored.  If
+// poll.Version is set and the poll has changed since, the error is a
+// StatusError with a 409
+func (c *Client) UpdatePoll(ctx context.Context, poll Poll) (Poll, error) {
+	var updated Poll
+	_, err := c.Do(ctx, http.MethodPut, "/polls", nil, poll, &updated)
+	return updated, err
+}
+
+// DeletePoll deletes a poll
+func (c *Client) DeletePoll(ctx context.Context, id uint) error {
+	_, err := c.Do(ctx, http.MethodDelete, fmt.Sprintf("/polls/%d", id), nil, nil, nil)
+	return err
+}
+
+// RemovePasscode takes the passcode off a poll and returns the poll
+func (c *Client) RemovePasscode(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodDelete, fmt.Sprintf("/polls/%d/passcode", id), nil, nil, &poll)
+	return poll, err
+}
+
+// CreateInvitations invites the voters with voterIds to a poll, making it
+// invite only, and returns their invitations.  The tokens can't be read
+// again, inviting a voter again gives them a new one.  It needs the admin
+// token when the Polls API has auth turned on
+func (c *Client) CreateInvitations(ctx context.Context, id uint, voterIds []uint) ([]Invitation, error) {
+	var created struct {
+		Invitations []Invitation
+	}
+	body := struct{ VoterIDs []uint }{voterIds}
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/invitations", id), nil, body, &created)
+	return created.Invitations, err
+}
+
+// GetInvitedVoters returns the ids of the voters whose invitations to a
+// poll have not been used
+func (c *Client) GetInvitedVoters(ctx context.Context, id uint) ([]uint, error) {
+	var invited struct {
+		VoterIDs []uint
+	}
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/polls/%d/invitations", id), nil, nil, &invited)
+	return invited.VoterIDs, err
+}
+
+// RemoveInvitations deletes every invitation to a poll, so it is no
+// longer invite only, and returns the poll
+func (c *Client) RemoveInvitations(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodDelete, fmt.Sprintf("/polls/%d/invitations", id), nil, nil, &poll)
+	return poll, err
+}
+
+// CreateBallotLinks returns a signed ballot link to a poll for each of the
+// voters with voterIds, making it invite only.  ttl is how long they work
+// for, 0 is the Polls API default.  It needs the admin token when the
+// Polls API has auth turned on
+func (c *Client) CreateBallotLinks(ctx context.Context, id uint, voterIds []uint, ttl time.Duration) ([]BallotLink, error) {
+	var created struct {
+		BallotLinks []BallotLink
+	}
+	body := struct {
+		VoterIDs []uint
+		TTL      string `json:",omitempty"`
+	}{VoterIDs: voterIds}
+	if ttl > 0 {
+		body.TTL = ttl.String()
+	}
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/ballot-links", id), nil, body, &created)
+	return created.BallotLinks, err
+}
+
+// GetBallot opens the ballot of a ballot link.  A 403 means the link was
+// changed, has expired or has been used
+func (c *Client) GetBallot(ctx context.Context, link BallotLink) (Ballot, error) {
+	var ballot Ballot
+	path, rawQuery, _ := strings.Cut(link.URL, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ballot, err
+	}
+	_, err = c.Do(ctx, http.MethodGet, path, query, nil, &ballot)
+	return ballot, err
+}
+
+// ClonePoll adds a copy of a poll's title, question and options as a new
+// draft and returns it, with its new PollID
+func (c *Client) ClonePoll(ctx context.Context, id uint) (Poll, error) {
+	var clone Poll
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/clone", id), nil, nil, &clone)
+	return clone, err
+}
+
+// AddPollOption adds an option to a poll and returns the poll.  Options
+// can't change once the poll has votes, the error is then a StatusError
+// with a 409
+func (c *Client) AddPollOption(ctx context.Context, id uint, option PollOption) (Poll, error) {
+	var poll Poll
+	body := map[string]string{"PollOptionText": option.PollOptionText}
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/options/%d", id, option.PollOptionID), nil, body, &poll)
+	return poll, err
+}
+
+// UpdatePollOption changes the text of an option of a poll and returns
+// the poll
+func (c *Client) UpdatePollOption(ctx context.Context, id uint, option PollOption) (Poll, error) {
+	var poll Poll
+	body := map[string]string{"PollOptionText": option.PollOptionText}
+	_, err := c.Do(ctx, http.MethodPut, fmt.Sprintf("/polls/%d/options/%d", id, option.PollOptionID), nil, body, &poll)
+	return poll, err
+}
+
+// DeletePollOption removes an option from a poll and returns the poll
+func (c *Client) DeletePollOption(ctx context.Context, id uint, optionId uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodDelete, fmt.Sprintf("/polls/%d/options/%d", id, optionId), nil, nil, &poll)
+	return poll, err
+}
+
+// PublishPoll moves a poll out of draft and returns it
+func (c *Client) PublishPoll(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/publish", id), nil, nil, &poll)
+	return poll, err
+}
+
+// OpenPoll opens a draft poll to votes and returns it
+func (c *Client) OpenPoll(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/open", id), nil, nil, &poll)
+	return poll, err
+}
+
+// ClosePoll stops an open poll taking votes and returns it
+func (c *Client) ClosePoll(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/close", id), nil, nil, &poll)
+	return poll, err
+}
+
+// ArchivePoll takes a poll that is no longer open out of the poll list
+// and returns it
+func (c *Client) ArchivePoll(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/archive", id), nil, nil, &poll)
+	return poll, err
+}
+
+// UnarchivePoll puts an archived poll back in the poll list and returns it
+func (c *Client) UnarchivePoll(ctx context.Context, id uint) (Poll, error) {
+	var poll Poll
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/polls/%d/unarchive", id), nil, nil, &poll)
+	return poll, err
+}
+
+// GetPollReport returns a poll together with its results
+func (c *Client) GetPollReport(ctx context.Context, id uint) (PollReport, error) {
+	var report PollReport
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/polls/%d/report", id), nil, nil, &report)
+	return report, err
+}
@@ -0,0 +1,136 @@
+// Package votersclient is a typed client for the Voters API
+package votersclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"drexel.edu/voting-application/client"
+)
+
+// VoterPoll is a poll a voter has voted in
+type VoterPoll struct {
+	PollID   uint
+	VoteDate time.Time
+}
+
+// Voter is a voter as the Voters API sends it
+type Voter struct {
+	VoterID     uint
+	FirstName   string
+	LastName    string
+	VoteHistory []VoterPoll
+	Version     uint     `json:",omitempty"`
+	Links       []string `json:",omitempty"`
+}
+
+// VoterPollResult is the status of one entry of AddVoterPolls
+type VoterPollResult struct {
+	PollID uint   `json:"pollId"`
+	Status string `json:"status"`
+}
+
+// VoterExport is everything the Voters API keeps about a voter, see
+// ExportVoter.  Votes are as the Votes API sends them
+type VoterExport struct {
+	ExportedAt  time.Time
+	Voter       Voter
+	VoteHistory []VoterPoll
+	Votes       []json.RawMessage
+}
+
+// Client talks to the Voters API, see client.Client for the settings
+type Client struct {
+	*client.Client
+}
+
+// New returns a client for the Voters API at baseURL, for example
+// http://localhost:1080
+func New(baseURL string) *Client {
+	return &Client{client.New(baseURL)}
+}
+
+// ListVoters returns every voter, sorted by id within each page
+func (c *Client) ListVoters(ctx context.Context) ([]Voter, error) {
+	return client.ListAll(ctx, c.Client, "/voters", nil, func(v Voter) uint {
+		return v.VoterID
+	})
+}
+
+// GetVoter returns a voter, an error matching client.ErrNotFound if
+// there is no such voter
+func (c *Client) GetVoter(ctx context.Context, id uint) (Voter, error) {
+	var voter Voter
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/voters/%d", id), nil, nil, &voter)
+	return voter, err
+}
+
+// AddVoter adds a voter and returns it as it was stored.  A voter with
+// no VoterID is given the next free one, which is in the returned voter
+func (c *Client) AddVoter(ctx context.Context, voter Voter) (Voter, error) {
+	var added Voter
+	_, err := c.Do(ctx, http.MethodPost, "/voters", nil, voter, &added)
+	return added, err
+}
+
+// UpdateVoter replaces the voter with voter.VoterID and returns it as it
+// was stored.  If voter.Version is set and the voter has changed since,
+// the error is a StatusError with a 409
+func (c *Client) UpdateVoter(ctx context.Context, voter Voter) (Voter, error) {
+	var updated Voter
+	_, err := c.Do(ctx, http.MethodPut, fmt.Sprintf("/voters/%d", voter.VoterID), nil, voter, &updated)
+	return updated, err
+}
+
+// DeleteVoter deletes a voter
+func (c *Client) DeleteVoter(ctx context.Context, id uint) error {
+	_, err := c.Do(ctx, http.MethodDelete, fmt.Sprintf("/voters/%d", id), nil, nil, nil)
+	return err
+}
+
+// GetVoterPolls returns the polls a voter has voted in
+func (c *Client) GetVoterPolls(ctx context.Context, id uint) ([]VoterPoll, error) {
+	var polls []VoterPoll
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/voters/%d/polls", id), nil, nil, &polls)
+	return polls, err
+}
+
+// ExportVoter returns everything kept about a voter, for a subject access
+// request.  If the Voters API can't reach the Votes API the error is a
+// StatusError with a 503
+func (c *Client) ExportVoter(ctx context.Context, id uint) (VoterExport, error) {
+	var export VoterExport
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/voters/%d/export", id), nil, nil, &export)
+	return export, err
+}
+
+// VoterErasure is what EraseVoter did
+type VoterErasure struct {
+	VoterID         uint
+	VotesAnonymized int
+	ErasedAt        time.Time
+}
+
+// EraseVoter scrubs and deletes a voter and anonymizes their votes, for a
+// voter who asked to be forgotten.  If it fails the error is a
+// StatusError, and it is safe to send again, which finishes an erasure
+// that got part way
+func (c *Client) EraseVoter(ctx context.Context, id uint) (VoterErasure, error) {
+	var erasure VoterErasure
+	_, err := c.Do(ctx, http.MethodDelete, fmt.Sprintf("/voters/%d/erase", id), nil, nil, &erasure)
+	return erasure, err
+}
+
+// AddVoterPolls adds polls to a voter, all of them or none, and returns
+// the status of each.  If any was already in the voter none are added and
+// the error is a StatusError with a 409
+func (c *Client) AddVoterPolls(ctx context.Context, id uint, polls []VoterPoll) ([]VoterPollResult, error) {
+	var resp struct {
+		Results []VoterPollResult `json:"results"`
+	}
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/voters/%d/polls/batch", id), nil, polls, &resp)
+	return resp.Results, err
+}
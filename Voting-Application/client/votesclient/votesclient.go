@@ -0,0 +1,231 @@
+// Package votesclient is a typed client for the Votes API
+package votesclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"drexel.edu/voting-application/client"
+)
+
+// The channels a vote can come in through
+const (
+	SourceWeb     = "web"
+	SourceSMS     = "sms"
+	SourceKiosk   = "kiosk"
+	SourceUnknown = "unknown"
+)
+
+// PasscodeHeader carries the passcode of a poll that has one with a vote
+// for it
+const PasscodeHeader = "X-Poll-Passcode"
+
+// InvitationHeader carries the invitation token of the voter with a vote
+// for an invite only poll
+const InvitationHeader = "X-Poll-Invitation"
+
+// Vote is a vote as the Votes API sends it.  A vote in an anonymous poll
+// has a VoterHash in place of its VoterID, which is 0
+type Vote struct {
+	VoteID      uint
+	VoterID     uint
+	PollID      uint
+	VoteValue   uint
+	VoteValues  []uint   `json:",omitempty"`
+	Rankings    []uint   `json:",omitempty"`
+	WriteInText string   `json:",omitempty"`
+	VoterHash   string   `json:",omitempty"`
+	Source      string   `json:",omitempty"`
+	Version     uint     `json:",omitempty"`
+	Links       []string `json:",omitempty"`
+}
+
+// Client talks to the Votes API, see client.Client for the settings
+type Client struct {
+	*client.Client
+}
+
+// New returns a client for the Votes API at baseURL, for example
+// http://localhost:1100
+func New(baseURL string) *Client {
+	return &Client{client.New(baseURL)}
+}
+
+// ListVotes returns every vote
+func (c *Client) ListVotes(ctx context.Context) ([]Vote, error) {
+	return client.ListAll(ctx, c.Client, "/votes", nil, func(v Vote) uint {
+		return v.VoteID
+	})
+}
+
+// GetVote returns a vote, an error matching client.ErrNotFound if there
+// is no such vote
+func (c *Client) GetVote(ctx context.Context, id uint) (Vote, error) {
+	var vote Vote
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/votes/%d", id), nil, nil, &vote)
+	return vote, err
+}
+
+// AddVote casts a vote and returns it as it was stored, with the VoteID
+// it was given if it had none.  A 400 means the vote is invalid or its
+// voter or poll does not exist, a 429 that the poll is taking too many
+// votes, and a 503 that voting is paused
+func (c *Client) AddVote(ctx context.Context, vote Vote) (Vote, error) {
+	var added Vote
+	_, err := c.Do(ctx, http.MethodPost, "/votes", nil, vote, &added)
+	return added, err
+}
+
+// AddVoteWithPasscode casts a vote in a poll that has a passcode, the
+// same as AddVote.  A 403 means passcode is not the poll's
+func (c *Client) AddVoteWithPasscode(ctx context.Context, vote Vote, passcode string) (Vote, error) {
+	var added Vote
+	header := http.Header{PasscodeHeader: {passcode}}
+	_, err := c.DoWithHeader(ctx, http.MethodPost, "/votes", nil, header, vote, &added)
+	return added, err
+}
+
+// AddVoteWithInvitation casts a vote in an invite only poll with the
+// token of vote.VoterID's invitation, which it uses up, the same as
+// AddVote.  A 403 means the voter was not invited, the invitation was
+// used or token is not its token
+func (c *Client) AddVoteWithInvitation(ctx context.Context, vote Vote, token string) (Vote, error) {
+	var added Vote
+	header := http.Header{InvitationHeader: {token}}
+	_, err := c.DoWithHeader(ctx, http.MethodPost, "/votes", nil, header, vote, &added)
+	return added, err
+}
+
+// The Status of a ReceiptCheck
+const (
+	ReceiptRecorded = "recorded"
+	ReceiptModified = "modified"
+	ReceiptDeleted  = "deleted"
+	ReceiptMissing  = "missing"
+)
+
+// VoteReceipt is the signed receipt the Votes API sends back with a vote
+// when VOTE_RECEIPT_SECRET is set, for the voter to keep
+type VoteReceipt struct {
+	VoteID    uint
+	PollID    uint
+	IssuedAt  time.Time
+	Signature string
+	Seal      string
+}
+
+// ReceiptCheck is what VerifyReceipt found out about the vote of a receipt
+type ReceiptCheck struct {
+	VoteID   uint
+	PollID   uint
+	IssuedAt time.Time
+	Status   string
+}
+
+// AddVoteWithReceipt casts a vote the same as AddVote, and returns the
+// receipt for it as well.  The receipt is nil if the Votes API does not
+// issue them
+func (c *Client) AddVoteWithReceipt(ctx context.Context, vote Vote) (Vote, *VoteReceipt, error) {
+	var added struct {
+		Vote
+		Receipt *VoteReceipt
+	}
+	_, err := c.Do(ctx, http.MethodPost, "/votes", nil, vote, &added)
+	return added.Vote, added.Receipt, err
+}
+
+// VerifyReceipt checks a receipt from AddVoteWithReceipt.  A receipt that
+// is not genuine is a StatusError with a 422, and a 503 means the Votes
+// API does not issue receipts
+func (c *Client) VerifyReceipt(ctx context.Context, receipt VoteReceipt) (ReceiptCheck, error) {
+	var check ReceiptCheck
+	_, err := c.Do(ctx, http.MethodPost, "/votes/verify-receipt", nil, receipt, &check)
+	return check, err
+}
+
+// UpdateVote replaces a vote and returns it as it was stored.  If
+// vote.Version is set and the vote has changed since, the error is a
+// StatusError with a 409
+func (c *Client) UpdateVote(ctx context.Context, vote Vote) (Vote, error) {
+	var updated Vote
+	_, err := c.Do(ctx, http.MethodPut, "/votes", nil, vote, &updated)
+	return updated, err
+}
+
+// DeleteVote deletes a vote, it can be restored for a while after
+func (c *Client) DeleteVote(ctx context.Context, id uint) error {
+	_, err := c.Do(ctx, http.MethodDelete, fmt.Sprintf("/votes/%d", id), nil, nil, nil)
+	return err
+}
+
+// RecastVote changes the VoteValue of the vote vote.VoterID cast in
+// vote.PollID while the poll is still open and returns it
+func (c *Client) RecastVote(ctx context.Context, vote Vote) (Vote, error) {
+	var recast Vote
+	_, err := c.Do(ctx, http.MethodPut, "/votes/byballot", nil, vote, &recast)
+	return recast, err
+}
+
+// RecastVoteWithPasscode changes a vote in a poll that has a passcode,
+// the same as RecastVote
+func (c *Client) RecastVoteWithPasscode(ctx context.Context, vote Vote, passcode string) (Vote, error) {
+	var recast Vote
+	header := http.Header{PasscodeHeader: {passcode}}
+	_, err := c.DoWithHeader(ctx, http.MethodPut, "/votes/byballot", nil, header, vote, &recast)
+	return recast, err
+}
+
+// RetractVote withdraws a voter's vote in a poll that is still open and
+// returns the votes withdrawn
+func (c *Client) RetractVote(ctx context.Context, voterId uint, pollId uint) ([]Vote, error) {
+	var votes []Vote
+	query := url.Values{
+		"voterId": {strconv.FormatUint(uint64(voterId), 10)},
+		"pollId":  {strconv.FormatUint(uint64(pollId), 10)},
+	}
+	_, err := c.Do(ctx, http.MethodDelete, "/votes/byballot", query, nil, &votes)
+	return votes, err
+}
+
+// RestoreVote brings a deleted vote back and returns it
+func (c *Client) RestoreVote(ctx context.Context, id uint) (Vote, error) {
+	var vote Vote
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/votes/%d/restore", id), nil, nil, &vote)
+	return vote, err
+}
+
+// GetPollVotes returns the votes cast in a poll, an error matching
+// client.ErrNotFound if the poll does not exist and has no votes
+func (c *Client) GetPollVotes(ctx context.Context, pollId uint) ([]Vote, error) {
+	var votes []Vote
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/polls/%d/votes", pollId), nil, nil, &votes)
+	return votes, err
+}
+
+// GetVotesByVoter returns the votes a voter has cast
+func (c *Client) GetVotesByVoter(ctx context.Context, voterId uint) ([]Vote, error) {
+	var votes []Vote
+	_, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/votes/byvoter/%d", voterId), nil, nil, &votes)
+	return votes, err
+}
+
+// VoterErasure is how many votes AnonymizeVoterVotes took a voter off,
+// Trashed of them deleted votes in the trash
+type VoterErasure struct {
+	VoterID    uint
+	Anonymized int
+	Trashed    int
+}
+
+// AnonymizeVoterVotes takes a voter off every vote they cast, for a voter
+// being erased, and needs the admin token.  Calling it again does nothing
+// more
+func (c *Client) AnonymizeVoterVotes(ctx context.Context, voterId uint) (VoterErasure, error) {
+	var erasure VoterErasure
+	_, err := c.Do(ctx, http.MethodPost, fmt.Sprintf("/votes/byvoter/%d/anonymize", voterId), nil, nil, &erasure)
+	return erasure, err
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// healthcheck hits the /health endpoint of each of the voting application
+// services and prints a table of the results.  It exits non-zero if any of
+// the services is unhealthy, which makes it handy for CI smoke tests.
+//
+// Usage: healthcheck [--json] [--timeout 5s] [service url ...]
+//
+// A service url is the base of the resource, for example
+// http://localhost:1080/voters, and /health is added to it.  With no urls
+// the three services are checked on localhost
+
+// The default services, on the ports the docker compose files publish them
+var defaultServices = []string{
+	"http://localhost:1080/voters",
+	"http://localhost:1090/polls",
+	"http://localhost:1100/votes",
+}
+
+// Global variables to hold the command line flags
+var (
+	jsonFlag    bool
+	timeoutFlag time.Duration
+)
+
+func processCmdLineFlags() {
+	flag.BoolVar(&jsonFlag, "json", false, "Print the results as JSON")
+	flag.DurationVar(&timeoutFlag, "timeout", 5*time.Second, "Timeout for each health request")
+
+	flag.Parse()
+}
+
+// healthData matches the health record returned by each service
+type healthData struct {
+	Uptime       time.Duration
+	APIcalls     uint
+	RedisLatency time.Duration
+}
+
+// result is what we found out about one service
+type result struct {
+	URL          string
+	Healthy      bool
+	StatusCode   int
+	Uptime       time.Duration
+	APIcalls     uint
+	RedisLatency time.Duration
+	Error        string `json:",omitempty"`
+}
+
+// checkService requests the health record of the service at url
+func checkService(client *http.Client, url string) result {
+	res := result{URL: url}
+
+	resp, err := client.Get(strings.TrimSuffix(url, "/") + "/health")
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+
+	res.StatusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		res.Error = resp.Status
+		return res
+	}
+
+	var health healthData
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		res.Error = "invalid health record: " + err.Error()
+		return res
+	}
+
+	res.Healthy = true
+	res.Uptime = health.Uptime
+	res.APIcalls = health.APIcalls
+	res.RedisLatency = health.RedisLatency
+	return res
+}
+
+// printTable prints the results as a table, one service per row
+func printTable(results []result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tSTATUS\tUPTIME\tCALLS\tREDIS LATENCY\tERROR")
+	for _, r := range results {
+		status := "UNHEALTHY"
+		if r.Healthy {
+			status = "OK"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", r.URL, status,
+			r.Uptime.Round(time.Second), r.APIcalls, r.RedisLatency, r.Error)
+	}
+	w.Flush()
+}
+
+// main is the entry point for the healthcheck tool
+func main() {
+	processCmdLineFlags()
+
+	services := flag.Args()
+	if len(services) == 0 {
+		services = defaultServices
+	}
+
+	client := &http.Client{Timeout: timeoutFlag}
+	results := make([]result, 0, len(services))
+	healthy := true
+	for _, url := range services {
+		r := checkService(client, url)
+		if !r.Healthy {
+			healthy = false
+		}
+		results = append(results, r)
+	}
+
+	if jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(results)
+	} else {
+		printTable(results)
+	}
+
+	if !healthy {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"drexel.edu/polls/db"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader is the header administrative requests carry the
+// admin token in when FEATURE_AUTH is on, as it is by default
+const AdminTokenHeader = "X-Admin-Token"
+
+// RequireAdmin is middleware for the administrative endpoints.  When the
+// Auth feature is on, as it is unless FEATURE_AUTH=false, requests must
+// send the token from the ADMIN_TOKEN environment variable in the
+// X-Admin-Token header.  If ADMIN_TOKEN is not set every request is turned
+// away, rather than letting anyone in.  With the feature off every request
+// is let in, which is logged at startup
+func (pa *PollsAPI) RequireAdmin(c *gin.Context) {
+	if !pa.isAdmin(c) {
+		log.Println("Rejected admin request to ", c.Request.URL.Path)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	c.Next()
+}
+
+// isAdmin reports whether the request may use administrative features,
+// for endpoints where only some requests are administrative.  Like
+// RequireAdmin, every request is an admin's with the Auth feature off
+func (pa *PollsAPI) isAdmin(c *gin.Context) bool {
+	if !pa.features.Auth {
+		return true
+	}
+
+	token := os.Getenv("ADMIN_TOKEN")
+	sent := c.GetHeader(AdminTokenHeader)
+	return token != "" && subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1
+}
+
+// forceRequested reads the ?force query parameter, which only admins may
+// set.  If it is invalid, or set by someone who isn't an admin, the
+// request is aborted and ok is false
+func (pa *PollsAPI) forceRequested(c *gin.Context) (force bool, ok bool) {
+	forceS := c.Query("force")
+	if forceS == "" {
+		return false, true
+	}
+	force, err := strconv.ParseBool(forceS)
+	if err != nil {
+		log.Println("Error converting force to bool: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return false, false
+	}
+	if force && !pa.isAdmin(c) {
+		log.Println("Rejected force from non admin request to ", c.Request.URL.Path)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return false, false
+	}
+	return force, true
+}
+
+// implementation for POST /polls/:id/reopen
+// opens a closed poll to votes again, until ?closesAt (RFC 3339) or
+// indefinitely.  Polls whose results were published need ?force=true
+func (pa *PollsAPI) ReopenPoll(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	force, ok := pa.forceRequested(c)
+	if !ok {
+		return
+	}
+
+	var closesAt *time.Time
+	if closesS := c.Query("closesAt"); closesS != "" {
+		t, err := time.Parse(time.RFC3339, closesS)
+		if err != nil {
+			log.Println("Error parsing closesAt: ", err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		closesAt = &t
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	poll, err := pa.db.ReopenPoll(numAsUint, closesAt, force, now)
+	if err != nil {
+		log.Println("Error reopening poll: ", err)
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			c.AbortWithStatus(http.StatusNotFound)
+		case errors.Is(err, db.ErrPollNotClosed), errors.Is(err, db.ErrResultsPublished), errors.Is(err, db.ErrPollArchived), errors.Is(err, db.ErrVersionMismatch):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, db.ErrInvalidCloseTime):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	//Reopening changes results people may already have seen, so keep
+	//a record of who did it
+	log.Println("Audit: poll ", numAsUint, " reopened by ", c.ClientIP(), ", closesAt: ", closesAt, ", force: ", force)
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, poll)
+}
@@ -1,222 +1,1469 @@
-package api
-
-import (
-	"log"
-	"net/http"
-	"strconv"
-	"time"
-
-	"drexel.edu/polls/db"
-	"github.com/gin-gonic/gin"
-)
-
-// The api package creates and maintains a reference to the data handler
-// this is a good design practice
-type PollsAPI struct {
-	db *db.PollList
-}
-
-var bootTime time.Time
-var calls uint
-
-func New() (*PollsAPI, error) {
-	dbHandler, err := db.NewPollList()
-	if err != nil {
-		return nil, err
-	}
-
-	bootTime = time.Now()
-
-	return &PollsAPI{db: dbHandler}, nil
-}
-
-type PollRequest struct {
-	PollID			uint	`json:"PollID"`
-	PollTitle		string	`json:"Polltitle"`
-	PollQuestion	string	`json:"PollQuestion"`
-	PollOptions		string	`json:"PollOptions"`
-}
-
-// implementation for GET /polls
-// returns all polls
-func (pa *PollsAPI) ListAllPolls(c *gin.Context) {
-
-	pollList, err := pa.db.GetAllPolls()
-	if err != nil {
-		log.Println("Error Getting All Polls: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-	//Note that the database returns a nil slice if there are no items
-	//in the database.  We need to convert this to an empty slice
-	//so that the JSON marshalling works correctly.  We want to return
-	//an empty slice, not a nil slice. This will result in the json being []
-	if pollList == nil {
-		pollList = make([]db.Poll, 0)
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, pollList)
-}
-
-// implementation for GET /polls/:id
-// returns a single poll
-func (pa *PollsAPI) GetPoll(c *gin.Context) {
-
-	//Note go is minimalistic, so we have to get the
-	//id parameter using the Param() function, and then
-	//convert it to an int64 using the strconv package
-	idS := c.Param("id")
-	id64, err := strconv.ParseInt(idS, 10, 32)
-	if err != nil {
-		log.Println("Error converting id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	//Note that ParseInt always returns an int64, so we have to
-	//convert it to an int before we can use it.
-	num := int(id64)
-	var numAsUint uint
-	if num >= 0 {
-		numAsUint = uint(num)
-	} else {
-		log.Println("PollID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	poll, err := pa.db.GetPoll(numAsUint)
-	if err != nil {
-		log.Println("Poll not found: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-
-	calls = calls + 1
-	//Git will automatically convert the struct to JSON
-	//and set the content-type header to application/json
-	c.JSON(http.StatusOK, poll)
-}
-
-// implementation for GET /crash
-// This simulates a crash to show some of the benefits of the
-// gin framework
-func (pa *PollsAPI) CrashSim(c *gin.Context) {
-	//panic() is go's version of throwing an exception
-	panic("Simulating an unexpected crash")
-}
-
-// implementation for POST /polls
-// adds a new poll
-func (pa *PollsAPI) AddPoll(c *gin.Context) {
-	var poll db.Poll
-
-	//With HTTP based APIs, a POST request will usually
-	//have a body that contains the data to be added
-	//to the database.  The body is usually JSON, so
-	//we need to bind the JSON to a struct that we
-	//can use in our code.
-	//This framework exposes the raw body via c.Request.Body
-	//but it also provides a helper function ShouldBindJSON()
-	//that will extract the body, convert it to JSON and
-	//bind it to a struct for us.  It will also report an error
-	//if the body is not JSON or if the JSON does not match
-	//the struct we are binding to.
-	if err := c.ShouldBindJSON(&poll); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := pa.db.AddPoll(poll); err != nil {
-		log.Println("Error adding poll: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, poll)
-}
-
-// implementation for PUT /polls
-// Web api standards use PUT for Updates
-func (pa *PollsAPI) UpdatePoll(c *gin.Context) {
-	var poll db.Poll
-	if err := c.ShouldBindJSON(&poll); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := pa.db.UpdatePoll(poll); err != nil {
-		log.Println("Error updating poll: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, poll)
-}
-
-// implementation for DELETE /polls/:id
-// deletes a poll
-func (pa *PollsAPI) DeletePoll(c *gin.Context) {
-	idS := c.Param("id")
-	id64, err := strconv.ParseInt(idS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	num := int(id64)
-	var numAsUint uint
-	if num >= 0 {
-		numAsUint = uint(num)
-	} else {
-		log.Println("PollID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := pa.db.DeletePoll(numAsUint); err != nil {
-		log.Println("Error deleting poll: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-}
-
-// implementation for DELETE /polls
-// deletes all polls
-func (pa *PollsAPI) DeleteAllPolls(c *gin.Context) {
-
-	if err := pa.db.DeleteAllPolls(); err != nil {
-		log.Println("Error deleting all polls: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-}
-
-// implementation for GET /polls/health
-// returns a "health" record indicating that the polls API is functioning properly
-
-func (pa *PollsAPI) GetHealthData(c *gin.Context){
-
-	healthData, err := pa.db.GetHealthData(bootTime, calls+1)
-	if err != nil {
-		log.Println("Error Getting health data: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-	
-	calls = calls + 1
-	c.JSON(http.StatusOK, healthData)
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"drexel.edu/polls/db"
+	"github.com/gin-gonic/gin"
+)
+
+// The api package creates and maintains a reference to the data handler
+// this is a good design practice
+type PollsAPI struct {
+	db       PollStore
+	features Features
+	clock    Clock
+	graphql  http.Handler
+	gateway  http.Handler
+}
+
+// Features holds the feature flags for the optional capabilities of the
+// service.  The flags are read once at startup in main.go and passed in
+// here so that route registration and middleware wiring can be made
+// conditional without changing any code
+type Features struct {
+	SSE        bool
+	Auth       bool
+	Metrics    bool
+	Crash      bool
+	StrictIDs  bool
+	StrictJSON bool
+	AutoIDs    bool
+	TestClock  bool
+	GraphQL    bool
+	Gateway    bool
+}
+
+var bootTime time.Time
+var calls uint
+
+// New returns an api backed by redis, configured from the environment
+func New(features Features) (*PollsAPI, error) {
+	dbHandler, err := db.NewPollList()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithStore(dbHandler, features), nil
+}
+
+// Features returns the feature flags the api was created with
+func (pa *PollsAPI) Features() Features {
+	return pa.features
+}
+
+type PollRequest struct {
+	PollID			uint	`json:"PollID"`
+	PollTitle		string	`json:"Polltitle"`
+	PollQuestion	string	`json:"PollQuestion"`
+	PollOptions		string	`json:"PollOptions"`
+}
+
+// implementation for GET /polls
+// returns all polls, drafts are left out unless ?includeDrafts=true and
+// archived polls unless ?includeArchived=true.
+// ?title, ?hasOption, ?status and ?tag pick out the polls that match, see
+// db.PollFilter.  ?sort=PollTitle&order=desc sorts the whole list before
+// it is paged.  Pages are read with ?limit and ?offset, or with ?cursor
+// for lists too long to read in one go
+func (pa *PollsAPI) ListAllPolls(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+	cursor, cursored, ok := parseCursor(c, page)
+	if !ok {
+		return
+	}
+
+	includeDrafts := false
+	if includeS := c.Query("includeDrafts"); includeS != "" {
+		include, err := strconv.ParseBool(includeS)
+		if err != nil {
+			log.Println("Error converting includeDrafts to bool: ", err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		includeDrafts = include
+	}
+
+	includeArchived := false
+	if includeS := c.Query("includeArchived"); includeS != "" {
+		include, err := strconv.ParseBool(includeS)
+		if err != nil {
+			log.Println("Error converting includeArchived to bool: ", err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		includeArchived = include
+	}
+
+	filter := db.PollFilter{
+		IncludeDrafts:   includeDrafts,
+		IncludeArchived: includeArchived,
+		Title:           c.Query("title"),
+		HasOption:       c.Query("hasOption"),
+		Status:          c.Query("status"),
+		Tag:             c.Query("tag"),
+	}
+	if err := filter.Validate(); err != nil {
+		log.Println("Error filtering polls: ", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field, desc, ok := parseSort(c, cursored)
+	if !ok {
+		return
+	}
+	order := db.PollSort{Field: field, Desc: desc}
+	if err := order.Validate(); err != nil {
+		log.Println("Error sorting polls: ", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if cursored {
+		pollList, next, err := pa.db.GetPollsPage(cursor, page.Limit, filter)
+		if err != nil {
+			log.Println("Error Getting Polls Page: ", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		calls = calls + 1
+		writeCursorPage(c, pollList, cursorPage{Limit: page.Limit, Cursor: cursor, NextCursor: next}, envelope)
+		return
+	}
+
+	pollList, err := pa.db.GetAllPolls(filter, order)
+	if err != nil {
+		log.Println("Error Getting All Polls: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	//Note that the database returns a nil slice if there are no items
+	//in the database.  We need to convert this to an empty slice
+	//so that the JSON marshalling works correctly.  We want to return
+	//an empty slice, not a nil slice. This will result in the json being []
+	if pollList == nil {
+		pollList = make([]db.Poll, 0)
+	}
+
+	calls = calls + 1
+	writeList(c, pollList, page, envelope)
+}
+
+// implementation for GET /polls/search
+// returns the polls whose title or question has every word of ?q, the
+// best matches first, using RediSearch.  Drafts are left out unless
+// ?includeDrafts=true, and pages are read with ?limit and ?offset
+func (pa *PollsAPI) SearchPolls(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	includeDrafts := false
+	if includeS := c.Query("includeDrafts"); includeS != "" {
+		include, err := strconv.ParseBool(includeS)
+		if err != nil {
+			log.Println("Error converting includeDrafts to bool: ", err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		includeDrafts = include
+	}
+
+	pollList, err := pa.db.SearchPolls(c.Query("q"), includeDrafts)
+	if err != nil {
+		log.Println("Error Searching Polls: ", err)
+		switch {
+		case errors.Is(err, db.ErrEmptySearch):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, db.ErrSearchUnavailable):
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	calls = calls + 1
+	writeList(c, pollList, page, envelope)
+}
+
+// implementation for GET /polls/ids
+// returns just the id and title of every poll, drafts are left out
+// unless ?includeDrafts=true
+func (pa *PollsAPI) ListAllPollIds(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	includeDrafts := false
+	if includeS := c.Query("includeDrafts"); includeS != "" {
+		include, err := strconv.ParseBool(includeS)
+		if err != nil {
+			log.Println("Error converting includeDrafts to bool: ", err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		includeDrafts = include
+	}
+
+	summaries, err := pa.db.GetAllPollSummaries(includeDrafts)
+	if err != nil {
+		log.Println("Error Getting All Poll Ids: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	writeList(c, summaries, page, envelope)
+}
+
+// implementation for GET /polls/:id
+// returns a single poll
+func (pa *PollsAPI) GetPoll(c *gin.Context) {
+
+	//Note go is minimalistic, so we have to get the
+	//id parameter using the Param() function, and then
+	//convert it to an int64 using the strconv package
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	//Note that ParseInt always returns an int64, so we have to
+	//convert it to an int before we can use it.
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	poll, err := pa.db.GetPoll(numAsUint)
+	if err != nil {
+		log.Println("Error getting poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	setETag(c, poll.Version)
+	//Git will automatically convert the struct to JSON
+	//and set the content-type header to application/json
+	c.JSON(http.StatusOK, poll)
+}
+
+// implementation for GET /crash
+// This simulates a crash to show some of the benefits of the
+// gin framework
+func (pa *PollsAPI) CrashSim(c *gin.Context) {
+	//panic() is go's version of throwing an exception
+	panic("Simulating an unexpected crash")
+}
+
+// implementation for POST /polls
+// adds a new poll
+func (pa *PollsAPI) AddPoll(c *gin.Context) {
+	var poll db.Poll
+
+	//With HTTP based APIs, a POST request will usually
+	//have a body that contains the data to be added
+	//to the database.  The body is usually JSON, so
+	//we need to bind the JSON to a struct that we
+	//can use in our code.
+	//This framework exposes the raw body via c.Request.Body
+	//but it also provides a helper function ShouldBindJSON()
+	//that will extract the body, convert it to JSON and
+	//bind it to a struct for us.  It will also report an error
+	//if the body is not JSON or if the JSON does not match
+	//the struct we are binding to.
+	if !pa.bindJSON(c, &poll) {
+		return
+	}
+	//A poll sent without a PollID is given the next free one
+	newID := poll.PollID == 0 && pa.features.AutoIDs
+	if !newID && !pa.validBodyID(c, "PollID", poll.PollID) {
+		return
+	}
+
+	add := func() error { return pa.db.AddPoll(poll) }
+	if newID {
+		add = func() error {
+			id, err := pa.db.AddPollNewID(poll)
+			poll.PollID = id
+			return err
+		}
+	}
+	if err := add(); err != nil {
+		log.Println("Error adding poll: ", err)
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidSchedule) || errors.Is(err, db.ErrInvalidVotingMethod) ||
+			errors.Is(err, db.ErrInvalidTags) || errors.Is(err, db.ErrInvalidPasscode) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrAlreadyExists) || errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	//Send back the poll as it was stored, with the fields the db layer
+	//fills in, rather than what was sent.  The poll was created either way
+	stored, err := pa.db.GetPoll(poll.PollID)
+	if err != nil {
+		log.Println("Error reading back poll: ", err)
+		stored = poll
+	}
+
+	calls = calls + 1
+	c.Header("Location", "/polls/"+strconv.FormatUint(uint64(stored.PollID), 10))
+	c.JSON(http.StatusCreated, stored)
+}
+
+// implementation for PUT /polls
+// Web api standards use PUT for Updates
+func (pa *PollsAPI) UpdatePoll(c *gin.Context) {
+	var poll db.Poll
+	if !pa.bindPoll(c, &poll) {
+		return
+	}
+	if !pa.validBodyID(c, "PollID", poll.PollID) {
+		return
+	}
+	force, ok := pa.forceRequested(c)
+	if !ok {
+		return
+	}
+
+	//With If-Match the write is also turned away if the poll changes
+	//after this check, unless the body sends a Version of its own
+	version, ok := pa.ifMatchPoll(c, poll.PollID)
+	if !ok {
+		return
+	}
+	if poll.Version == 0 {
+		poll.Version = version
+	}
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	if err := pa.db.UpdatePoll(poll, force, now); err != nil {
+		log.Println("Error updating poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidSchedule) || errors.Is(err, db.ErrInvalidVotingMethod) ||
+			errors.Is(err, db.ErrInvalidTags) || errors.Is(err, db.ErrInvalidPasscode) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(staleStatus(c), gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrOptionsLocked) || errors.Is(err, db.ErrAnonymousLocked) || errors.Is(err, db.ErrPollClosed) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	//Send back the Version the poll has now, for the next update
+	if updated, err := pa.db.GetPoll(poll.PollID); err == nil {
+		poll = updated
+	}
+
+	calls = calls + 1
+	setETag(c, poll.Version)
+	c.JSON(http.StatusOK, poll)
+}
+
+// implementation for PATCH /polls/:id
+// applies the body, a JSON merge patch (RFC 7386), so only the fields
+// that are in it are updated and a field set to null is cleared.  Returns
+// just the fields that changed, as
+// {"changed": {"Field": {"from": ..., "to": ...}}}
+func (pa *PollsAPI) PatchPoll(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	//We need the record as it is before the write so that we can
+	//tell what the patch changed
+	before, err := pa.db.GetPoll(numAsUint)
+	if err != nil {
+		log.Println("Error getting poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	//The patch is merged into the current record, so a client can
+	//change one field without sending back, and maybe clobbering, the
+	//rest
+	patch, err := c.GetRawData()
+	if err != nil {
+		bodyError(c, err)
+		return
+	}
+	var after db.Poll
+	if err := mergePatch(before, patch, &after, pa.features.StrictJSON); err != nil {
+		log.Println("Error applying merge patch: ", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validBody(c, &after) {
+		return
+	}
+	after.PollID = before.PollID
+	force, ok := pa.forceRequested(c)
+	if !ok {
+		return
+	}
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	if err := pa.db.UpdatePoll(after, force, now); err != nil {
+		log.Println("Error patching poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidSchedule) || errors.Is(err, db.ErrInvalidVotingMethod) ||
+			errors.Is(err, db.ErrInvalidTags) || errors.Is(err, db.ErrInvalidPasscode) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrOptionsLocked) || errors.Is(err, db.ErrAnonymousLocked) ||
+			errors.Is(err, db.ErrPollClosed) || errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"changed": changedFields(before, after)})
+}
+
+// implementation for DELETE /polls/:id
+// deletes a poll
+func (pa *PollsAPI) DeletePoll(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := pa.ifMatchPoll(c, numAsUint); !ok {
+		return
+	}
+
+	if err := pa.db.DeletePoll(numAsUint); err != nil {
+		log.Println("Error deleting poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+}
+
+// implementation for POST /polls/:id/publish
+// publishes a draft poll
+func (pa *PollsAPI) PublishPoll(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	poll, err := pa.db.PublishPoll(numAsUint)
+	if err != nil {
+		log.Println("Error publishing poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, poll)
+}
+
+// implementation for POST /polls/:id/open
+// opens a draft poll to votes
+func (pa *PollsAPI) OpenPoll(c *gin.Context) {
+	pa.changePoll(c, "opening", pa.db.OpenPoll)
+}
+
+// implementation for POST /polls/:id/close
+// stops an open poll taking votes
+func (pa *PollsAPI) ClosePoll(c *gin.Context) {
+	pa.changePoll(c, "closing", pa.db.ClosePoll)
+}
+
+// implementation for POST /polls/:id/archive
+// archives a poll that is no longer open, taking it out of the poll list
+func (pa *PollsAPI) ArchivePoll(c *gin.Context) {
+	pa.changePoll(c, "archiving", pa.db.ArchivePoll)
+}
+
+// implementation for POST /polls/:id/unarchive
+// puts an archived poll back in the poll list
+func (pa *PollsAPI) UnarchivePoll(c *gin.Context) {
+	pa.changePoll(c, "unarchiving", pa.db.UnarchivePoll)
+}
+
+// implementation for DELETE /polls/:id/passcode
+// takes the passcode off a poll, so votes for it no longer need one
+func (pa *PollsAPI) RemovePasscode(c *gin.Context) {
+	pa.changePoll(c, "removing the passcode of", func(id uint, now time.Time) (db.Poll, error) {
+		return pa.db.RemovePasscode(id)
+	})
+}
+
+// implementation for GET /polls/:id/passcode
+// checks the passcode in the X-Poll-Passcode header against the poll's,
+// for the votes API.  Returns {"valid": bool}, always true for a poll
+// without a passcode, rather than an error status for a wrong one
+func (pa *PollsAPI) CheckPasscode(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	valid, err := pa.db.CheckPasscode(numAsUint, c.GetHeader(db.PasscodeHeader))
+	if err != nil {
+		log.Println("Error checking passcode: ", err)
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"valid": valid})
+}
+
+// invitationsBody is the body of POST /polls/:id/invitations
+type invitationsBody struct {
+	VoterIDs []uint `binding:"required"`
+}
+
+// implementation for POST /polls/:id/invitations
+// invites the voters in the body to the poll, making it invite only, and
+// returns their tokens.  This is the only time the tokens are sent
+func (pa *PollsAPI) CreateInvitations(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	var body invitationsBody
+	if !pa.bindJSON(c, &body) {
+		return
+	}
+
+	invitations, err := pa.db.CreateInvitations(numAsUint, body.VoterIDs)
+	if err != nil {
+		log.Println("Error creating invitations: ", err)
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidInvitations) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusCreated, gin.H{"PollID": numAsUint, "Invitations": invitations})
+}
+
+// implementation for GET /polls/:id/invitations
+// returns the ids of the voters whose invitations have not been used
+func (pa *PollsAPI) GetInvitedVoters(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterIds, err := pa.db.GetInvitedVoters(numAsUint)
+	if err != nil {
+		log.Println("Error getting invited voters: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"PollID": numAsUint, "VoterIDs": voterIds})
+}
+
+// implementation for DELETE /polls/:id/invitations
+// deletes every invitation to a poll, so it is no longer invite only
+func (pa *PollsAPI) RemoveInvitations(c *gin.Context) {
+	pa.changePoll(c, "removing the invitations of", func(id uint, now time.Time) (db.Poll, error) {
+		return pa.db.RemoveInvitations(id)
+	})
+}
+
+// implementation for DELETE /polls/:id/invitations/:voterId
+// uses up the voter's invitation if the token in the X-Poll-Invitation
+// header is theirs, for the votes API.  Returns {"valid": bool}, always
+// true for a poll that is not invite only, rather than an error status
+// for a token that is not valid
+func (pa *PollsAPI) RedeemInvitation(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterS := c.Param("voterId")
+	voter64, err := strconv.ParseUint(voterS, 10, 32)
+	if err != nil {
+		log.Println("Error converting voterId to uint64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	valid, err := pa.db.RedeemInvitation(numAsUint, uint(voter64), c.GetHeader(db.InvitationHeader), now)
+	if err != nil {
+		log.Println("Error redeeming invitation: ", err)
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"valid": valid})
+}
+
+// ballotLinksBody is the body of POST /polls/:id/ballot-links.  TTL is a
+// go duration string such as "48h", empty is db.DefaultBallotLinkTTL
+type ballotLinksBody struct {
+	VoterIDs []uint `binding:"required"`
+	TTL      string
+}
+
+// implementation for POST /polls/:id/ballot-links
+// returns a signed link to the poll's ballot for each voter in the body,
+// to be sent to them, making the poll invite only
+func (pa *PollsAPI) CreateBallotLinks(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	var body ballotLinksBody
+	if !pa.bindJSON(c, &body) {
+		return
+	}
+	var ttl time.Duration
+	if body.TTL != "" {
+		ttl, err = time.ParseDuration(body.TTL)
+		if err != nil {
+			log.Println("Error parsing ballot link TTL: ", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": db.ErrInvalidBallotLinkTTL.Error()})
+			return
+		}
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	links, err := pa.db.CreateBallotLinks(numAsUint, body.VoterIDs, ttl, now)
+	if err != nil {
+		log.Println("Error creating ballot links: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidInvitations) || errors.Is(err, db.ErrInvalidBallotLinkTTL) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrBallotLinksOff) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusCreated, gin.H{"PollID": numAsUint, "BallotLinks": links})
+}
+
+// implementation for GET /polls/:id/ballot
+// opens the ballot of a signed link from POST /polls/:id/ballot-links,
+// with the token the voter's vote is sent with
+func (pa *PollsAPI) GetBallot(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voter64, err := strconv.ParseUint(c.Query("voterId"), 10, 32)
+	if err != nil {
+		log.Println("Error converting voterId to uint64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		log.Println("Error converting expires to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	ballot, err := pa.db.GetBallot(numAsUint, uint(voter64), expires, c.Query("sig"), now)
+	if err != nil {
+		log.Println("Error getting ballot: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidBallotLink) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrBallotLinksOff) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, ballot)
+}
+
+// changePoll moves the poll in the path from one state to another with
+// change, at the time of the request, and returns the changed poll.
+// doing is what change does, for the log
+func (pa *PollsAPI) changePoll(c *gin.Context, doing string, change func(id uint, now time.Time) (db.Poll, error)) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	poll, err := change(numAsUint, now)
+	if err != nil {
+		log.Println("Error "+doing+" poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, poll)
+}
+
+// implementation for GET /polls/:id/winner
+// returns the winning option(s) of a poll once it has closed, or the
+// current leader(s) of an open poll with ?draft=true
+func (pa *PollsAPI) GetPollWinner(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	provisional := false
+	if draftS := c.Query("draft"); draftS != "" {
+		draft, err := strconv.ParseBool(draftS)
+		if err != nil {
+			log.Println("Error converting draft to bool: ", err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		provisional = draft
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	winner, err := pa.db.GetPollWinner(numAsUint, provisional, now)
+	if err != nil {
+		log.Println("Error getting poll winner: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrPollOpen) {
+			c.AbortWithStatus(http.StatusConflict)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, winner)
+}
+
+// implementation for GET /polls/:id/report
+// returns the poll along with its vote counts, percentages and winner
+// in a single report
+func (pa *PollsAPI) GetPollReport(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	report, err := pa.db.GetPollReport(numAsUint, now)
+	if err != nil {
+		log.Println("Error getting poll report: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, report)
+}
+
+// implementation for GET /polls/:id/distribution
+// returns the mean and standard deviation of the option voted for, and
+// the entropy of the votes, to show how split a poll is
+func (pa *PollsAPI) GetPollDistribution(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	distribution, err := pa.db.GetPollDistribution(numAsUint)
+	if err != nil {
+		log.Println("Error getting poll distribution: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, distribution)
+}
+
+// implementation for GET /polls/:id/settings
+// returns just the settings of a poll
+func (pa *PollsAPI) GetPollSettings(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	settings, err := pa.db.GetPollSettings(numAsUint)
+	if err != nil {
+		log.Println("Error getting poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, settings)
+}
+
+// implementation for PUT /polls/:id/settings
+// replaces just the settings of a poll, those left out of the body keep
+// their current values.  Reopening a closed poll or taking back its
+// results this way needs ?force=true, as with POST /polls/:id/reopen
+func (pa *PollsAPI) UpdatePollSettings(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	force, ok := pa.forceRequested(c)
+	if !ok {
+		return
+	}
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	//The body is read over the current settings, so that only the ones
+	//it has change
+	settings, err := pa.db.GetPollSettings(numAsUint)
+	if err != nil {
+		log.Println("Error getting poll settings: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !pa.bindJSON(c, &settings) {
+		return
+	}
+
+	settings, err = pa.db.UpdatePollSettings(numAsUint, settings, force, now)
+	if err != nil {
+		log.Println("Error updating poll settings: ", err)
+		if errors.Is(err, db.ErrInvalidSchedule) || errors.Is(err, db.ErrInvalidVotingMethod) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, settings)
+}
+
+// implementation for POST /polls/:id/clone
+// adds a copy of a poll, with its title, question and options, as a new
+// draft with an id of its own, for polls that are run again and again
+func (pa *PollsAPI) ClonePoll(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	clone, err := pa.db.ClonePoll(numAsUint)
+	if err != nil {
+		log.Println("Error cloning poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Header("Location", "/polls/"+strconv.FormatUint(uint64(clone.PollID), 10))
+	c.JSON(http.StatusCreated, clone)
+}
+
+// pollOptionBody is the body of a request that adds or changes one
+// option of a poll, its id is in the path
+type pollOptionBody struct {
+	PollOptionText string `binding:"required"`
+}
+
+// implementation for POST /polls/:id/options/:optionId
+// adds an option to a poll, without sending the whole poll.  Options are
+// locked once the poll has votes, unless ?force=true
+func (pa *PollsAPI) AddPollOption(c *gin.Context) {
+	var body pollOptionBody
+	pa.changeOption(c, "adding", http.StatusCreated, &body, func(id uint, optionId uint, force bool) (db.Poll, error) {
+		return pa.db.AddPollOption(id, optionId, body.PollOptionText, force)
+	})
+}
+
+// implementation for PUT /polls/:id/options/:optionId
+// changes the text of one option of a poll.  Options are locked once the
+// poll has votes, unless ?force=true
+func (pa *PollsAPI) UpdatePollOption(c *gin.Context) {
+	var body pollOptionBody
+	pa.changeOption(c, "updating", http.StatusOK, &body, func(id uint, optionId uint, force bool) (db.Poll, error) {
+		return pa.db.UpdatePollOption(id, optionId, body.PollOptionText, force)
+	})
+}
+
+// implementation for DELETE /polls/:id/options/:optionId
+// removes one option from a poll, a poll keeps at least two.  Options are
+// locked once the poll has votes, unless ?force=true
+func (pa *PollsAPI) DeletePollOption(c *gin.Context) {
+	pa.changeOption(c, "deleting", http.StatusOK, nil, pa.db.DeletePollOption)
+}
+
+// changeOption reads the poll and option ids in the path, and the body
+// into body unless it is nil, then makes the change and returns the poll
+// with status.  doing is what change does, for the log
+func (pa *PollsAPI) changeOption(c *gin.Context, doing string, status int, body any, change func(id uint, optionId uint, force bool) (db.Poll, error)) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	optionS := c.Param("optionId")
+	option64, err := strconv.ParseUint(optionS, 10, 32)
+	if err != nil {
+		log.Println("Error converting optionId to uint64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if body != nil && !pa.bindJSON(c, body) {
+		return
+	}
+	force, ok := pa.forceRequested(c)
+	if !ok {
+		return
+	}
+	if _, ok := pa.ifMatchPoll(c, numAsUint); !ok {
+		return
+	}
+
+	poll, err := change(numAsUint, uint(option64), force)
+	if err != nil {
+		log.Println("Error "+doing+" poll option: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrConflict) || errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(status, poll)
+}
+
+// implementation for DELETE /polls
+// deletes all polls
+func (pa *PollsAPI) DeleteAllPolls(c *gin.Context) {
+
+	if err := pa.db.DeleteAllPolls(); err != nil {
+		log.Println("Error deleting all polls: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+}
+
+// implementation for GET /schema
+// returns the field list and model version of the Poll model so that
+// clients can detect when the model has changed
+func (pa *PollsAPI) GetSchema(c *gin.Context) {
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, db.PollSchema())
+}
+
+// implementation for GET /polls/health
+// returns a "health" record indicating that the polls API is functioning properly
+
+func (pa *PollsAPI) GetHealthData(c *gin.Context){
+
+	healthData, err := pa.db.GetHealthData(bootTime, calls+1)
+	if err != nil {
+		log.Println("Error Getting health data: ", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+	
+	calls = calls + 1
+	c.JSON(http.StatusOK, healthData)
 }
\ No newline at end of file
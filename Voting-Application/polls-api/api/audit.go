@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"drexel.edu/polls/db"
+	"github.com/gin-gonic/gin"
+)
+
+// ActorHeader is the header a client says who is making a request in,
+// for the audit log.  Nothing checks it, it is only as good as the
+// client.  Without it the client's address is recorded instead
+const ActorHeader = "X-Actor"
+
+// actor returns who is making the request c, for the audit log
+func actor(c *gin.Context) string {
+	if a := c.GetHeader(ActorHeader); a != "" {
+		return a
+	}
+	return c.ClientIP()
+}
+
+// bodyID reads the id in field of the JSON body of c, ok is false if
+// there isn't one.  The body is put back for the handler to read.  If it
+// can't be read the request is aborted
+func bodyID(c *gin.Context, field string) (id uint, ok bool) {
+	body, err := c.GetRawData()
+	if err != nil {
+		bodyError(c, err)
+		return 0, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return 0, false
+	}
+	if err := json.Unmarshal(fields[field], &id); err != nil || id == 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// auditID reads the id of an entity from s, ok is false if it is not a
+// positive integer
+func auditID(s string) (id uint, ok bool) {
+	id64, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || id64 == 0 {
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+// audit records entry, a change made by the request c, in the audit log.
+// The change has already been made by the time this is called, so a
+// failure is only logged
+func (pa *PollsAPI) audit(c *gin.Context, entry db.AuditEntry) {
+	entry.Actor = actor(c)
+	entry.RequestID = c.Writer.Header().Get(RequestIDHeader)
+	entry.At = time.Now()
+	if err := pa.db.RecordAudit(entry); err != nil {
+		log.Println("Error recording audit entry for ", entry.Entity, " ", entry.EntityID, ": ", err)
+	}
+}
+
+// auditPoll records the change the request c made to the poll with id,
+// from before to after, either of which is nil if the poll did not exist
+// then
+func (pa *PollsAPI) auditPoll(c *gin.Context, id uint, before *db.Poll, after *db.Poll) {
+	if before == nil && after == nil {
+		return
+	}
+	entry, err := db.NewAuditEntry(db.AuditPoll, id, before, after)
+	if err != nil {
+		log.Println("Error making audit entry for poll ", id, ": ", err)
+		return
+	}
+	pa.audit(c, entry)
+}
+
+// pollSnapshot returns the poll with id as it is now, or nil if there is
+// no such poll
+func (pa *PollsAPI) pollSnapshot(id uint) (*db.Poll, error) {
+	poll, err := pa.db.GetPoll(id)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+// Audited is middleware for the routes that change a poll.  It reads the
+// poll in the route's :id, or the PollID in the body of a route without
+// one, before the handler runs, and again after it if the handler
+// succeeds, and records the change in the audit log.  A poll the handler
+// adds, or clones, is found from the Location it sends.  If a poll can't
+// be read the change is logged but not recorded
+func (pa *PollsAPI) Audited(c *gin.Context) {
+	id, hasID := auditID(c.Param("id"))
+	if !hasID && c.Request.Method == http.MethodPut {
+		if id, hasID = bodyID(c, "PollID"); c.IsAborted() {
+			return
+		}
+	}
+	var before *db.Poll
+	if hasID {
+		var err error
+		if before, err = pa.pollSnapshot(id); err != nil {
+			log.Println("Error reading poll ", id, " to audit: ", err)
+			c.Next()
+			return
+		}
+	}
+
+	c.Next()
+	if c.Writer.Status() >= http.StatusMultipleChoices {
+		return
+	}
+
+	if created, ok := auditID(path.Base(c.Writer.Header().Get("Location"))); ok && created != id {
+		id, hasID, before = created, true, nil
+	}
+	if !hasID {
+		return
+	}
+	after, err := pa.pollSnapshot(id)
+	if err != nil {
+		log.Println("Error reading poll ", id, " to audit: ", err)
+		return
+	}
+	pa.auditPoll(c, id, before, after)
+}
+
+// AuditedAll is middleware for the routes that delete every poll.  It
+// reads them all before the handler runs, and records each one that is
+// gone after it, even if the handler failed part way through
+func (pa *PollsAPI) AuditedAll(c *gin.Context) {
+	before, err := pa.db.GetAllPolls(db.PollFilter{IncludeDrafts: true, IncludeArchived: true}, db.PollSort{})
+	if err != nil {
+		log.Println("Error reading polls to audit: ", err)
+	}
+
+	c.Next()
+
+	for i := range before {
+		id := before[i].PollID
+		if after, err := pa.pollSnapshot(id); err != nil || after != nil {
+			continue
+		}
+		pa.auditPoll(c, id, &before[i], nil)
+	}
+}
+
+// implementation for GET /audit
+// returns the entries in the audit log about ?entity, voter, poll or
+// vote, only those about the one with ?id if it is given, oldest first.
+// Pages are read with ?limit and ?offset
+func (pa *PollsAPI) GetAudit(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	var id uint
+	if idS := c.Query("id"); idS != "" {
+		if id, ok = auditID(idS); !ok {
+			log.Println("Invalid id: ", idS)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "id must be a positive integer"})
+			return
+		}
+	}
+
+	entries, err := pa.db.GetAudit(c.Query("entity"), id)
+	if err != nil {
+		log.Println("Error getting audit log: ", err)
+		if errors.Is(err, db.ErrInvalidAuditEntity) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrAuditUnavailable) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = make([]db.AuditEntry, 0)
+	}
+
+	calls = calls + 1
+	//The snapshots of voters can have their names in them
+	c.Header("Cache-Control", "no-store")
+	writeList(c, entries, page, envelope)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// errPatchNotObject is returned for a merge patch that isn't a JSON
+// object, which would replace the whole record rather than patch it
+var errPatchNotObject = errors.New("a merge patch must be a JSON object")
+
+// fieldChange describes how one field changed in an update
+type fieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// changedFields compares the exported top level fields of before and
+// after, which must be the same struct type, and returns the ones that
+// differ keyed by field name.  Links are generated by the db layer on
+// every write so they are never reported
+func changedFields(before, after any) map[string]fieldChange {
+	changed := make(map[string]fieldChange)
+
+	b := reflect.ValueOf(before)
+	a := reflect.ValueOf(after)
+	for i := 0; i < b.NumField(); i++ {
+		field := b.Type().Field(i)
+		if !field.IsExported() || field.Name == "Links" {
+			continue
+		}
+		from := b.Field(i).Interface()
+		to := a.Field(i).Interface()
+		if !reflect.DeepEqual(from, to) {
+			changed[field.Name] = fieldChange{From: from, To: to}
+		}
+	}
+
+	return changed
+}
+
+// mergePatch applies patch, an RFC 7386 JSON merge patch, to a copy of
+// src and decodes the result into dst.  Fields missing from the patch are
+// kept, fields set to null are removed, which leaves them at their zero
+// value in dst, objects are merged field by field and everything else,
+// arrays included, is replaced whole.  If strict is true a field of the
+// patch that dst does not have, see exactFields, is an error
+func mergePatch(src any, patch []byte, dst any, strict bool) error {
+	if strict {
+		if err := exactFields(patch, reflect.TypeOf(dst)); err != nil {
+			return err
+		}
+	}
+	var patchDoc any
+	if err := decodeJSON(patch, &patchDoc); err != nil {
+		return err
+	}
+	if _, ok := patchDoc.(map[string]any); !ok {
+		return errPatchNotObject
+	}
+
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := decodeJSON(b, &doc); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergeValue(doc, patchDoc))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(dst)
+}
+
+// decodeJSON decodes data keeping numbers as they were written, so that
+// large ids survive the round trip through mergePatch
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// mergeValue is the MergePatch function of RFC 7386
+func mergeValue(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergeValue(targetObj[k], v)
+	}
+	return targetObj
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"os"
+
+	"drexel.edu/polls/db"
+	"drexel.edu/polls/graph"
+	"github.com/gin-gonic/gin"
+)
+
+// graphQLFromEnv returns the GraphQL endpoint over store, reading votes
+// from the votes API at VOTES_API_URL and voters from the voters API at
+// VOTERS_API_URL, the same services a cascading delete uses
+func graphQLFromEnv(store PollStore) http.Handler {
+	votesURL := db.DefaultVotesAPIURL
+	if u := os.Getenv("VOTES_API_URL"); u != "" {
+		votesURL = u
+	}
+	votersURL := db.DefaultVotersAPIURL
+	if u := os.Getenv("VOTERS_API_URL"); u != "" {
+		votersURL = u
+	}
+	return graph.NewHandler(store, votesURL, votersURL)
+}
+
+// implementation for GET and POST /graphql
+// answers a GraphQL query for polls, their options, their votes and the
+// voters who cast them, see graph/schema.graphqls
+func (pa *PollsAPI) GraphQL(c *gin.Context) {
+	calls = calls + 1
+	pa.graphql.ServeHTTP(c.Writer, c.Request)
+}
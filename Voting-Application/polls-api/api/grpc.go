@@ -0,0 +1,418 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"drexel.edu/polls/db"
+	"drexel.edu/polls/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ActorMetadata and AdminTokenMetadata are the metadata keys a gRPC client
+// sends the X-Actor and X-Admin-Token headers in
+const (
+	ActorMetadata      = "x-actor"
+	AdminTokenMetadata = "x-admin-token"
+)
+
+// pollsServer is the PollsAPI gRPC service.  It reads and writes the same
+// store as the REST routes, so a poll changed over one is changed over
+// the other
+type pollsServer struct {
+	pb.UnimplementedPollsAPIServer
+	pa *PollsAPI
+}
+
+// GRPCServer returns a gRPC server with the PollsAPI service registered,
+// backed by the same store as pa.  main.go serves it on a second port
+func (pa *PollsAPI) GRPCServer() *grpc.Server {
+	server := grpc.NewServer()
+	pb.RegisterPollsAPIServer(server, &pollsServer{pa: pa})
+	return server
+}
+
+// grpcError turns an error from the store into a gRPC status, the same way
+// the REST routes turn it into an HTTP status
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrNotStorable), errors.Is(err, db.ErrInvalidSchedule),
+		errors.Is(err, db.ErrInvalidCloseTime), errors.Is(err, db.ErrInvalidVotingMethod),
+		errors.Is(err, db.ErrInvalidTags), errors.Is(err, db.ErrInvalidPasscode):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrNeedsRedis):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, db.ErrVersionMismatch), errors.Is(err, db.ErrOptionsLocked),
+		errors.Is(err, db.ErrAnonymousLocked):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, db.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, db.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, db.ErrDependencyUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// validID checks an id sent in a call the same way validBodyID checks one
+// sent in a body
+func (pa *PollsAPI) validID(field string, id uint32) error {
+	if !pa.features.StrictIDs {
+		return nil
+	}
+	if id == 0 || id > maxID {
+		return status.Errorf(codes.InvalidArgument, "%s must be between 1 and %d", field, maxID)
+	}
+	return nil
+}
+
+// metadataValue returns the first value of key in the metadata of the
+// call ctx, "" if there is none
+func metadataValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// isAdminCall is isAdmin for a gRPC call, the admin token is sent in the
+// x-admin-token metadata
+func (pa *PollsAPI) isAdminCall(ctx context.Context) bool {
+	if !pa.features.Auth {
+		return true
+	}
+
+	token := os.Getenv("ADMIN_TOKEN")
+	sent := metadataValue(ctx, AdminTokenMetadata)
+	return token != "" && subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1
+}
+
+// grpcActor returns who is making the call ctx, for the audit log.  A
+// call through the gateway has the client's address in x-forwarded-for
+func grpcActor(ctx context.Context) string {
+	if a := metadataValue(ctx, ActorMetadata); a != "" {
+		return a
+	}
+	if a := metadataValue(ctx, "x-forwarded-for"); a != "" {
+		return a
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// auditCall records the change the call ctx made to the poll with id in
+// the audit log, as Audited does for the REST routes
+func (pa *PollsAPI) auditCall(ctx context.Context, id uint, before *db.Poll, after *db.Poll) {
+	if before == nil && after == nil {
+		return
+	}
+	entry, err := db.NewAuditEntry(db.AuditPoll, id, before, after)
+	if err != nil {
+		log.Println("Error making audit entry for poll ", id, ": ", err)
+		return
+	}
+	entry.Actor = grpcActor(ctx)
+	entry.At = time.Now()
+	if err := pa.db.RecordAudit(entry); err != nil {
+		log.Println("Error recording audit entry for poll ", id, ": ", err)
+	}
+}
+
+// formatTime is t as RFC 3339, "" if there is no time
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseTime reads the RFC 3339 time in field, nil if it is ""
+func parseTime(field string, s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s must be an RFC 3339 time", field)
+	}
+	return &t, nil
+}
+
+// toPBPoll is poll as the gRPC message, its status worked out for now
+func toPBPoll(poll db.Poll, now time.Time) *pb.Poll {
+	options := make([]*pb.PollOption, 0, len(poll.PollOptions))
+	for _, option := range poll.PollOptions {
+		options = append(options, &pb.PollOption{PollOptionId: uint32(option.PollOptionID), PollOptionText: option.PollOptionText})
+	}
+	settings := poll.Settings
+	return &pb.Poll{
+		PollId:       uint32(poll.PollID),
+		PollTitle:    poll.PollTitle,
+		PollQuestion: poll.PollQuestion,
+		PollOptions:  options,
+		Settings: &pb.PollSettings{
+			Status:           settings.Status,
+			VoteRateLimit:    uint32(settings.VoteRateLimit),
+			ClosesAt:         formatTime(settings.ClosesAt),
+			ResultsPublished: settings.ResultsPublished,
+			Archived:         settings.Archived,
+			OpensAt:          formatTime(settings.OpensAt),
+			VotingMethod:     settings.VotingMethod,
+			MaxSelections:    uint32(settings.MaxSelections),
+			AllowWriteIns:    settings.AllowWriteIns,
+			HasPasscode:      settings.HasPasscode,
+			InviteOnly:       settings.InviteOnly,
+			Anonymous:        settings.Anonymous,
+		},
+		Version: uint32(poll.Version),
+		Status:  settings.State(now),
+		Tags:    poll.Tags,
+	}
+}
+
+// fromPBPoll is the gRPC message poll as a db.Poll, checked the way a poll
+// sent to the REST routes is
+func fromPBPoll(poll *pb.Poll) (db.Poll, error) {
+	if poll == nil {
+		return db.Poll{}, status.Error(codes.InvalidArgument, "poll is required")
+	}
+	if poll.PollTitle == "" {
+		return db.Poll{}, status.Error(codes.InvalidArgument, "poll_title is required")
+	}
+	if len(poll.PollOptions) < 2 {
+		return db.Poll{}, status.Error(codes.InvalidArgument, "poll_options must have at least 2 options")
+	}
+
+	dbPoll := db.Poll{
+		PollID:       uint(poll.PollId),
+		PollTitle:    poll.PollTitle,
+		PollQuestion: poll.PollQuestion,
+		Tags:         poll.Tags,
+		Passcode:     poll.Passcode,
+		Version:      uint(poll.Version),
+	}
+	for i, option := range poll.PollOptions {
+		if option.PollOptionText == "" {
+			return db.Poll{}, status.Errorf(codes.InvalidArgument, "poll_options[%d].poll_option_text is required", i)
+		}
+		dbPoll.AddOption(uint(option.PollOptionId), option.PollOptionText)
+	}
+
+	//HasPasscode and InviteOnly are copied across, but the store sets them
+	//itself as it does for a poll sent over REST
+	if settings := poll.Settings; settings != nil {
+		opensAt, err := parseTime("settings.opens_at", settings.OpensAt)
+		if err != nil {
+			return db.Poll{}, err
+		}
+		closesAt, err := parseTime("settings.closes_at", settings.ClosesAt)
+		if err != nil {
+			return db.Poll{}, err
+		}
+		dbPoll.Settings = db.PollSettings{
+			Status:           settings.Status,
+			VoteRateLimit:    uint(settings.VoteRateLimit),
+			OpensAt:          opensAt,
+			ClosesAt:         closesAt,
+			ResultsPublished: settings.ResultsPublished,
+			Archived:         settings.Archived,
+			VotingMethod:     settings.VotingMethod,
+			MaxSelections:    uint(settings.MaxSelections),
+			AllowWriteIns:    settings.AllowWriteIns,
+			HasPasscode:      settings.HasPasscode,
+			InviteOnly:       settings.InviteOnly,
+			Anonymous:        settings.Anonymous,
+		}
+	}
+	return dbPoll, nil
+}
+
+func (s *pollsServer) ListPolls(ctx context.Context, req *pb.ListPollsRequest) (*pb.ListPollsResponse, error) {
+	filter := db.PollFilter{IncludeDrafts: req.IncludeDrafts, Tag: req.Tag}
+	if err := filter.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	pollList, next, err := s.pa.db.GetPollsPage(req.Cursor, int(req.Limit), filter)
+	if err != nil {
+		log.Println("Error Getting Polls Page: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	now := s.pa.clock.Now()
+	resp := &pb.ListPollsResponse{Polls: make([]*pb.Poll, 0, len(pollList)), NextCursor: next}
+	for _, poll := range pollList {
+		resp.Polls = append(resp.Polls, toPBPoll(poll, now))
+	}
+	return resp, nil
+}
+
+func (s *pollsServer) GetPoll(ctx context.Context, req *pb.GetPollRequest) (*pb.Poll, error) {
+	poll, err := s.pa.db.GetPoll(uint(req.PollId))
+	if err != nil {
+		log.Println("Error getting poll: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	return toPBPoll(poll, s.pa.clock.Now()), nil
+}
+
+// AddPoll adds poll, a poll sent without a poll_id is given the next free
+// one as with POST /polls
+func (s *pollsServer) AddPoll(ctx context.Context, req *pb.Poll) (*pb.Poll, error) {
+	poll, err := fromPBPoll(req)
+	if err != nil {
+		return nil, err
+	}
+	newID := poll.PollID == 0 && s.pa.features.AutoIDs
+	if !newID {
+		if err := s.pa.validID("poll_id", req.PollId); err != nil {
+			return nil, err
+		}
+		err = s.pa.db.AddPoll(poll)
+	} else {
+		poll.PollID, err = s.pa.db.AddPollNewID(poll)
+	}
+	if err != nil {
+		log.Println("Error adding poll: ", err)
+		return nil, grpcError(err)
+	}
+
+	stored, err := s.pa.db.GetPoll(poll.PollID)
+	if err != nil {
+		log.Println("Error reading back poll: ", err)
+		stored = poll
+	}
+
+	calls = calls + 1
+	s.pa.auditCall(ctx, stored.PollID, nil, &stored)
+	return toPBPoll(stored, s.pa.clock.Now()), nil
+}
+
+// UpdatePoll replaces the poll with poll_id, as PUT /polls, though settings
+// are kept only when they are left out entirely.  With a version
+// the update is turned away if the poll has changed since, as with
+// If-Match, and force needs the admin token as ?force=true does
+func (s *pollsServer) UpdatePoll(ctx context.Context, req *pb.UpdatePollRequest) (*pb.Poll, error) {
+	poll, err := fromPBPoll(req.Poll)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.pa.validID("poll.poll_id", req.Poll.PollId); err != nil {
+		return nil, err
+	}
+	if req.Force && !s.pa.isAdminCall(ctx) {
+		log.Println("Rejected force from non admin call to UpdatePoll")
+		return nil, status.Error(codes.Unauthenticated, "force needs the admin token")
+	}
+
+	//A poll sent without settings keeps the ones it has
+	if req.Poll.Settings == nil {
+		if settings, err := s.pa.db.GetPollSettings(poll.PollID); err == nil {
+			poll.Settings = settings
+		}
+	}
+
+	before, _ := s.pa.pollSnapshot(poll.PollID)
+	if err := s.pa.db.UpdatePoll(poll, req.Force, s.pa.clock.Now()); err != nil {
+		log.Println("Error updating poll: ", err)
+		return nil, grpcError(err)
+	}
+	updated, err := s.pa.db.GetPoll(poll.PollID)
+	if err != nil {
+		log.Println("Error reading back poll: ", err)
+		updated = poll
+	}
+
+	calls = calls + 1
+	s.pa.auditCall(ctx, poll.PollID, before, &updated)
+	return toPBPoll(updated, s.pa.clock.Now()), nil
+}
+
+func (s *pollsServer) DeletePoll(ctx context.Context, req *pb.DeletePollRequest) (*pb.DeletePollResponse, error) {
+	id := uint(req.PollId)
+	before, _ := s.pa.pollSnapshot(id)
+	if err := s.pa.db.DeletePoll(id); err != nil {
+		log.Println("Error deleting poll: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	s.pa.auditCall(ctx, id, before, nil)
+	return &pb.DeletePollResponse{}, nil
+}
+
+func (s *pollsServer) PublishPoll(ctx context.Context, req *pb.GetPollRequest) (*pb.Poll, error) {
+	id := uint(req.PollId)
+	before, _ := s.pa.pollSnapshot(id)
+	poll, err := s.pa.db.PublishPoll(id)
+	if err != nil {
+		log.Println("Error publishing poll: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	s.pa.auditCall(ctx, id, before, &poll)
+	return toPBPoll(poll, s.pa.clock.Now()), nil
+}
+
+func (s *pollsServer) GetPollReport(ctx context.Context, req *pb.GetPollRequest) (*pb.PollReport, error) {
+	now := s.pa.clock.Now()
+	report, err := s.pa.db.GetPollReport(uint(req.PollId), now)
+	if err != nil {
+		log.Println("Error getting poll report: ", err)
+		return nil, grpcError(err)
+	}
+
+	options := make([]*pb.OptionResult, 0, len(report.Options))
+	for _, option := range report.Options {
+		options = append(options, &pb.OptionResult{
+			Id:      uint32(option.ID),
+			Text:    option.Text,
+			Count:   uint32(option.Count),
+			Percent: option.Percent,
+		})
+	}
+	winners := make([]uint32, 0, len(report.Winner.Winners))
+	for _, winner := range report.Winner.Winners {
+		winners = append(winners, uint32(winner))
+	}
+	bySource := make(map[string]uint32, len(report.BySource))
+	for source, count := range report.BySource {
+		bySource[source] = uint32(count)
+	}
+
+	calls = calls + 1
+	return &pb.PollReport{
+		Poll:       toPBPoll(report.Poll, now),
+		IsOpen:     report.IsOpen,
+		TotalVotes: uint32(report.TotalVotes),
+		Options:    options,
+		Winner: &pb.PollWinner{
+			Winners:     winners,
+			WinningText: report.Winner.WinningText,
+			Count:       uint32(report.Winner.Count),
+			IsTie:       report.Winner.IsTie,
+		},
+		BySource:    bySource,
+		GeneratedAt: report.GeneratedAt.Format(time.RFC3339Nano),
+	}, nil
+}
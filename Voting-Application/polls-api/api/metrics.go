@@ -0,0 +1,38 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// implementation for GET /metrics
+// returns the cache hit and miss counters, labeled by cache name, in the
+// Prometheus text exposition format so they can be scraped
+func (pa *PollsAPI) GetMetrics(c *gin.Context) {
+
+	stats := pa.db.CacheStats()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out bytes.Buffer
+	fmt.Fprintln(&out, "# HELP polls_cache_hits_total Lookups that were answered from the cache.")
+	fmt.Fprintln(&out, "# TYPE polls_cache_hits_total counter")
+	for _, name := range names {
+		fmt.Fprintf(&out, "polls_cache_hits_total{cache=%q} %d\n", name, stats[name].Hits)
+	}
+	fmt.Fprintln(&out, "# HELP polls_cache_misses_total Lookups that the cache could not answer.")
+	fmt.Fprintln(&out, "# TYPE polls_cache_misses_total counter")
+	for _, name := range names {
+		fmt.Fprintf(&out, "polls_cache_misses_total{cache=%q} %d\n", name, stats[name].Misses)
+	}
+
+	calls = calls + 1
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", out.Bytes())
+}
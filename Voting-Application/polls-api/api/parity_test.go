@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The REST routes and the /v1 routes generated from the protos are handled
+// by different code, the handlers in api-handler.go and the gRPC methods in
+// grpc.go.  These tests send the same requests to both and check that they
+// store the same poll and fail the same way, so the two can't drift apart
+// unnoticed
+
+// parityPollID is the first of the ids the tests use, far past any id the
+// seed data or AddPollNewID hands out
+const parityPollID = 900101
+
+// newParityRouter connects to the redis at REDIS_URL, or the default
+// location, and skips the test if there isn't one.  It returns a router
+// with the REST routes the tests use, registered as in main.go, and /v1
+func newParityRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	pa, err := New(Features{StrictIDs: true, AutoIDs: true, Gateway: true})
+	if err != nil {
+		t.Skip("redis is not available: ", err)
+	}
+
+	r := gin.New()
+	r.Use(Problems())
+	r.POST("/polls", pa.AddPoll)
+	r.PUT("/polls", pa.UpdatePoll)
+	r.GET("/polls/:id", pa.GetPoll)
+	r.DELETE("/polls/:id", pa.DeletePoll)
+	r.POST("/polls/:id/publish", pa.PublishPoll)
+	r.Any("/v1/*path", pa.Gateway)
+	return r
+}
+
+// serve sends r the request and returns the response
+func serve(r http.Handler, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// parityPoll is the part of a poll both kinds of route send, whichever
+// names they give the fields
+type parityPoll struct {
+	ID       uint
+	Title    string
+	Question string
+	Options  []string
+	Tags     []string
+	Status   string
+	Version  uint
+}
+
+// readPoll reads the poll with id over REST, or over /v1 if v1 is set
+func readPoll(t *testing.T, r http.Handler, id uint, v1 bool) parityPoll {
+	t.Helper()
+
+	path := "/polls/" + strconv.FormatUint(uint64(id), 10)
+	if v1 {
+		path = "/v1" + path
+	}
+	w := serve(r, http.MethodGet, path, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body.String())
+	}
+
+	var poll parityPoll
+	if v1 {
+		var sent struct {
+			PollID       uint   `json:"poll_id"`
+			PollTitle    string `json:"poll_title"`
+			PollQuestion string `json:"poll_question"`
+			PollOptions  []struct {
+				PollOptionText string `json:"poll_option_text"`
+			} `json:"poll_options"`
+			Tags    []string `json:"tags"`
+			Status  string   `json:"status"`
+			Version uint     `json:"version"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &sent); err != nil {
+			t.Fatal("Error reading poll: ", err)
+		}
+		poll = parityPoll{ID: sent.PollID, Title: sent.PollTitle, Question: sent.PollQuestion, Tags: sent.Tags, Status: sent.Status, Version: sent.Version}
+		for _, option := range sent.PollOptions {
+			poll.Options = append(poll.Options, option.PollOptionText)
+		}
+	} else {
+		var sent struct {
+			PollID       uint
+			PollTitle    string
+			PollQuestion string
+			PollOptions  []struct {
+				PollOptionText string
+			}
+			Tags    []string
+			Status  string
+			Version uint
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &sent); err != nil {
+			t.Fatal("Error reading poll: ", err)
+		}
+		poll = parityPoll{ID: sent.PollID, Title: sent.PollTitle, Question: sent.PollQuestion, Tags: sent.Tags, Status: sent.Status, Version: sent.Version}
+		for _, option := range sent.PollOptions {
+			poll.Options = append(poll.Options, option.PollOptionText)
+		}
+	}
+	//REST leaves out an empty list of tags, /v1 sends []
+	if len(poll.Tags) == 0 {
+		poll.Tags = nil
+	}
+	return poll
+}
+
+func TestParityWrittenPollsReadTheSame(t *testing.T) {
+	r := newParityRouter(t)
+	restID, v1ID := uint(parityPollID), uint(parityPollID+1)
+	for _, id := range []uint{restID, v1ID} {
+		serve(r, http.MethodDelete, "/polls/"+strconv.FormatUint(uint64(id), 10), "")
+		defer serve(r, http.MethodDelete, "/polls/"+strconv.FormatUint(uint64(id), 10), "")
+	}
+
+	restPoll := `"PollTitle": "Lunch", "PollQuestion": "Where?", "PollOptions": [{"PollOptionID": 1, "PollOptionText": "Pizza"}, {"PollOptionID": 2, "PollOptionText": "Tacos"}], "Tags": ["Food"]`
+	v1Poll := `"poll_title": "Lunch", "poll_question": "Where?", "poll_options": [{"poll_option_id": 1, "poll_option_text": "Pizza"}, {"poll_option_id": 2, "poll_option_text": "Tacos"}], "tags": ["Food"]`
+	if w := serve(r, http.MethodPost, "/polls", `{"PollID": 900101, `+restPoll+`}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /polls: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPost, "/v1/polls", `{"poll_id": 900102, `+v1Poll+`}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /v1/polls: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPost, "/polls/900101/publish", ""); w.Code != http.StatusOK {
+		t.Fatalf("POST /polls/900101/publish: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPost, "/v1/polls/900102/publish", ""); w.Code != http.StatusOK {
+		t.Fatalf("POST /v1/polls/900102/publish: status %d: %s", w.Code, w.Body.String())
+	}
+	restPoll = strings.Replace(restPoll, "Lunch", "Dinner", 1)
+	v1Poll = strings.Replace(v1Poll, "Lunch", "Dinner", 1)
+	if w := serve(r, http.MethodPut, "/polls", `{"PollID": 900101, "Version": 2, `+restPoll+`}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT /polls: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPut, "/v1/polls/900102", `{"version": 2, `+v1Poll+`}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT /v1/polls/900102: status %d: %s", w.Code, w.Body.String())
+	}
+
+	//Each poll reads the same over both kinds of route, and the two polls,
+	//written the same way over different routes, match
+	for _, id := range []uint{restID, v1ID} {
+		rest, v1 := readPoll(t, r, id, false), readPoll(t, r, id, true)
+		if !reflect.DeepEqual(rest, v1) {
+			t.Errorf("poll %d reads %+v over REST but %+v over /v1", id, rest, v1)
+		}
+	}
+	rest, v1 := readPoll(t, r, restID, false), readPoll(t, r, v1ID, false)
+	v1.ID = rest.ID
+	if !reflect.DeepEqual(rest, v1) {
+		t.Errorf("poll written over REST is %+v but over /v1 is %+v", rest, v1)
+	}
+}
+
+func TestParityErrorsMatch(t *testing.T) {
+	r := newParityRouter(t)
+	serve(r, http.MethodDelete, "/polls/900103", "")
+	defer serve(r, http.MethodDelete, "/polls/900103", "")
+	restPoll := `"PollTitle": "Lunch", "PollOptions": [{"PollOptionID": 1, "PollOptionText": "Pizza"}, {"PollOptionID": 2, "PollOptionText": "Tacos"}]`
+	v1Poll := `"poll_title": "Lunch", "poll_options": [{"poll_option_id": 1, "poll_option_text": "Pizza"}, {"poll_option_id": 2, "poll_option_text": "Tacos"}]`
+	if w := serve(r, http.MethodPost, "/polls", `{"PollID": 900103, `+restPoll+`}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /polls: status %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		restPath, restBody string
+		v1Path, v1Body     string
+	}{
+		{"missing poll", http.MethodGet, "/polls/900199", "", "/v1/polls/900199", ""},
+		{"update missing poll", http.MethodPut, "/polls", `{"PollID": 900199, ` + restPoll + `}`,
+			"/v1/polls/900199", `{` + v1Poll + `}`},
+		{"stale version", http.MethodPut, "/polls", `{"PollID": 900103, "Version": 7, ` + restPoll + `}`,
+			"/v1/polls/900103", `{"version": 7, ` + v1Poll + `}`},
+		{"poll already exists", http.MethodPost, "/polls", `{"PollID": 900103, ` + restPoll + `}`,
+			"/v1/polls", `{"poll_id": 900103, ` + v1Poll + `}`},
+		{"bad tag", http.MethodPost, "/polls", `{"PollID": 900104, "Tags": ["no spaces"], ` + restPoll + `}`,
+			"/v1/polls", `{"poll_id": 900104, "tags": ["no spaces"], ` + v1Poll + `}`},
+		{"publish missing poll", http.MethodPost, "/polls/900199/publish", "", "/v1/polls/900199/publish", ""},
+	}
+	for _, test := range tests {
+		rest := serve(r, test.method, test.restPath, test.restBody)
+		v1 := serve(r, test.method, test.v1Path, test.v1Body)
+		if rest.Code != v1.Code {
+			t.Errorf("%s: REST sent %d but /v1 sent %d", test.name, rest.Code, v1.Code)
+		}
+		if ct := v1.Header().Get("Content-Type"); ct != ProblemContentType {
+			t.Errorf("%s: /v1 sent %q rather than a problem", test.name, ct)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"drexel.edu/polls/db"
+	"github.com/gin-gonic/gin"
+)
+
+// previewTemplate renders a poll as a small HTML page for sharing.  The
+// OpenGraph tags let chat apps and social sites unfurl a link to it.
+// html/template escapes everything it inserts based on where it goes, so
+// the poll text can't inject markup or break out of the attributes
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.PollTitle}}</title>
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.PollTitle}}">
+<meta property="og:description" content="{{.PollQuestion}}">
+</head>
+<body>
+<div class="poll-preview">
+<h1>{{.PollTitle}}</h1>
+<p>{{.PollQuestion}}</p>
+<ul>
+{{- range .PollOptions}}
+<li>{{.PollOptionText}}</li>
+{{- end}}
+</ul>
+</div>
+</body>
+</html>
+`))
+
+// implementation for GET /polls/:id/preview
+// returns an HTML preview of a poll for embedding or link unfurling
+func (pa *PollsAPI) PreviewPoll(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	poll, err := pa.db.GetPoll(numAsUint)
+	if err != nil {
+		log.Println("Error getting poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	//Render into a buffer first so that a template error can still be
+	//reported as a 500 instead of a half written page
+	var page bytes.Buffer
+	if err := previewTemplate.Execute(&page, poll); err != nil {
+		log.Println("Error rendering poll preview: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the id of a request.  A client can send its own
+// to follow a request across services, otherwise one is made up.  Either
+// way it is sent back on every response and is in every problem
+const RequestIDHeader = "X-Request-ID"
+
+// ProblemContentType is the content type of every error response, see
+// RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// problemWriter holds back the status and body of an error response so
+// that Problems can send them as a problem instead.  Anything below 400
+// goes straight through, so streams and websockets are untouched
+type problemWriter struct {
+	gin.ResponseWriter
+	//status is the error status being held back, 0 if there is none
+	status int
+	body   bytes.Buffer
+}
+
+func (w *problemWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest && !w.ResponseWriter.Written() {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *problemWriter) WriteHeaderNow() {
+	if w.status != 0 {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *problemWriter) Write(data []byte) (int, error) {
+	if w.status != 0 {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *problemWriter) WriteString(s string) (int, error) {
+	if w.status != 0 {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *problemWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+// Unwrap lets http.ResponseController reach the connection, see Limits
+func (w *problemWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *problemWriter) Written() bool {
+	return w.status != 0 || w.ResponseWriter.Written()
+}
+
+// newRequestID returns 16 random bytes as hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Println("Error making request id: ", err)
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// Problems is middleware that gives every request an id and turns every
+// error response, from c.AbortWithStatus, c.AbortWithStatusJSON or gin's
+// own 404 and 405, into an application/problem+json body: {"type":
+// "about:blank", "title": the status text, "status", "detail": the
+// "error" the handler gave if it gave one, "instance": the path,
+// "requestId"}.  The fields of a JSON object the handler sent are kept
+// alongside those, so clients reading {"error": ...} or a batch's
+// "results" still find them
+func Problems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		orig := c.Writer
+		w := &problemWriter{ResponseWriter: orig}
+		c.Writer = w
+		//Put the real writer back even if a handler panics, so that
+		//gin's recovery can still send its 500
+		defer func() { c.Writer = orig }()
+		c.Next()
+
+		status := w.status
+		//gin sets the status of a request with no route before running
+		//the middleware, and only writes its plain text body after it
+		if status == 0 && !orig.Written() && orig.Status() >= http.StatusBadRequest {
+			status = orig.Status()
+		}
+		if status == 0 {
+			return
+		}
+
+		fields := make(map[string]any)
+		if w.body.Len() > 0 {
+			//Anything that isn't a JSON object is dropped
+			if err := json.Unmarshal(w.body.Bytes(), &fields); err != nil || fields == nil {
+				fields = make(map[string]any)
+			}
+		}
+		if msg, ok := fields["error"].(string); ok && msg != "" {
+			fields["detail"] = msg
+		}
+		fields["type"] = "about:blank"
+		fields["title"] = http.StatusText(status)
+		fields["status"] = status
+		fields["instance"] = c.Request.URL.Path
+		fields["requestId"] = requestID
+
+		body, err := json.Marshal(fields)
+		if err != nil {
+			log.Println("Error making problem: ", err)
+			orig.WriteHeader(status)
+			orig.WriteHeaderNow()
+			return
+		}
+		orig.Header().Set("Content-Type", ProblemContentType)
+		orig.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		orig.WriteHeader(status)
+		orig.Write(body)
+	}
+}
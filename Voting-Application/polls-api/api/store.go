@@ -0,0 +1,62 @@
+package api
+
+import (
+	"time"
+
+	"drexel.edu/polls/db"
+)
+
+// PollStore is everything the api needs from the data layer.  *db.PollList
+// is the real implementation, anything else that satisfies it, such as a
+// fake in a test or a PollList wired to a custom redis client, can be
+// passed to NewWithStore
+type PollStore interface {
+	GetAllPolls(filter db.PollFilter, order db.PollSort) ([]db.Poll, error)
+	GetPollsPage(cursor uint64, count int, filter db.PollFilter) ([]db.Poll, uint64, error)
+	SearchPolls(q string, includeDrafts bool) ([]db.Poll, error)
+	GetAllPollSummaries(includeDrafts bool) ([]db.PollSummary, error)
+	GetPoll(id uint) (db.Poll, error)
+	AddPoll(poll db.Poll) error
+	AddPollNewID(poll db.Poll) (uint, error)
+	UpdatePoll(poll db.Poll, force bool, now time.Time) error
+	AddPollOption(id uint, optionId uint, text string, force bool) (db.Poll, error)
+	UpdatePollOption(id uint, optionId uint, text string, force bool) (db.Poll, error)
+	DeletePollOption(id uint, optionId uint, force bool) (db.Poll, error)
+	ClonePoll(id uint) (db.Poll, error)
+	DeletePoll(id uint) error
+	DeleteAllPolls() error
+	PublishPoll(id uint) (db.Poll, error)
+	OpenPoll(id uint, now time.Time) (db.Poll, error)
+	ClosePoll(id uint, now time.Time) (db.Poll, error)
+	ArchivePoll(id uint, now time.Time) (db.Poll, error)
+	UnarchivePoll(id uint, now time.Time) (db.Poll, error)
+	ReopenPoll(id uint, closesAt *time.Time, force bool, now time.Time) (db.Poll, error)
+	CheckPasscode(id uint, passcode string) (bool, error)
+	RemovePasscode(id uint) (db.Poll, error)
+	CreateInvitations(id uint, voterIds []uint) ([]db.Invitation, error)
+	GetInvitedVoters(id uint) ([]uint, error)
+	RedeemInvitation(id uint, voterId uint, token string, now time.Time) (bool, error)
+	RemoveInvitations(id uint) (db.Poll, error)
+	CreateBallotLinks(id uint, voterIds []uint, ttl time.Duration, now time.Time) ([]db.BallotLink, error)
+	GetBallot(id uint, voterId uint, expires int64, sig string, now time.Time) (db.Ballot, error)
+	GetPollSettings(id uint) (db.PollSettings, error)
+	UpdatePollSettings(id uint, settings db.PollSettings, force bool, now time.Time) (db.PollSettings, error)
+	GetPollWinner(id uint, provisional bool, now time.Time) (db.PollWinner, error)
+	GetPollReport(id uint, now time.Time) (db.PollReport, error)
+	GetPollDistribution(id uint) (db.PollDistribution, error)
+	WatchPollVotes(id uint) (<-chan struct{}, func())
+	CacheStats() map[string]db.CacheStats
+	RecordAudit(entry db.AuditEntry) error
+	GetAudit(entity string, id uint) ([]db.AuditEntry, error)
+	GetHealthData(bootTime time.Time, calls uint) (db.HealthData, error)
+}
+
+// NewWithStore returns an api backed by store instead of the redis backed
+// store New creates
+func NewWithStore(store PollStore, features Features) *PollsAPI {
+	bootTime = time.Now()
+
+	pa := &PollsAPI{db: store, features: features, clock: systemClock{}, graphql: graphQLFromEnv(store)}
+	pa.gateway = pa.newGateway()
+	return pa
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"drexel.edu/polls/db"
+	"github.com/gin-gonic/gin"
+)
+
+// ResultsEvent is the name of the server-sent event each results
+// snapshot is sent as
+const ResultsEvent = "results"
+
+// implementation for GET /polls/:id/results/stream
+// streams the poll report as server-sent events, one now and another
+// every time a vote in the poll is added, changed or removed, until the
+// client goes away
+func (pa *PollsAPI) StreamPollResults(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	now, ok := pa.now(c)
+	if !ok {
+		return
+	}
+
+	//Start watching before the first report so that a vote that lands
+	//in between is not missed
+	changes, stop := pa.db.WatchPollVotes(numAsUint)
+	defer stop()
+
+	report, err := pa.db.GetPollReport(numAsUint, now)
+	if err != nil {
+		log.Println("Error getting poll report: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Header("Cache-Control", "no-cache")
+	c.SSEvent(ResultsEvent, report)
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-changes:
+		}
+
+		//The time header, if any, was checked above
+		now, _ := pa.now(c)
+		report, err := pa.db.GetPollReport(numAsUint, now)
+		if err != nil {
+			//The poll may have been deleted, either way there is
+			//nothing more to send
+			log.Println("Error getting poll report: ", err)
+			return false
+		}
+		c.SSEvent(ResultsEvent, report)
+		return true
+	})
+}
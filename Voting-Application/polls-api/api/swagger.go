@@ -0,0 +1,48 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the OpenAPI 3 description of every route in main.go.
+// It is written by hand, so change it along with the routes
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// SwaggerUIVersion is the swagger-ui-dist release the /swagger/ page
+// loads from the CDN
+const SwaggerUIVersion = "5.9.0"
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%[1]s</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@%[2]s/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@%[2]s/swagger-ui-bundle.js"></script>
+<script>SwaggerUIBundle({url: "openapi.yaml", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// implementation for GET /swagger/*any
+// serves the OpenAPI spec at /swagger/openapi.yaml and a Swagger UI
+// page for it at /swagger/
+func (pa *PollsAPI) Swagger(c *gin.Context) {
+	switch c.Param("any") {
+	case "/openapi.yaml":
+		c.Data(http.StatusOK, "application/yaml", openAPISpec)
+	case "/", "/index.html":
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.String(http.StatusOK, swaggerUIPage, "Polls API", SwaggerUIVersion)
+	default:
+		c.AbortWithStatus(http.StatusNotFound)
+	}
+}
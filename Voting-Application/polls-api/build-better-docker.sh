@@ -1,2 +1,2 @@
-#!/bin/bash
-docker build --tag polls-api-better:v1  -f ./dockerfile.better .
+#!/bin/bash
+docker build --tag polls-api-better:v1  -f ./dockerfile.better ..
@@ -1,3 +1,3 @@
 #!/bin/bash
 docker buildx create --use 
-docker buildx build --platform linux/amd64,linux/arm64  -f ./dockerfile.hub . -t mattgott1231/polls-api:v1 --push
\ No newline at end of file
+docker buildx build --platform linux/amd64,linux/arm64  -f ./dockerfile.hub .. -t mattgott1231/polls-api:v1 --push
\ No newline at end of file
@@ -0,0 +1,26 @@
+package db
+
+// ErrAnonymousLocked is returned when changing whether a poll that has
+// already been voted on is anonymous
+var ErrAnonymousLocked error = conflict("poll has votes, Anonymous can't change")
+
+// checkAnonymousChange makes sure the Anonymous setting of the poll with
+// id only changes from was to anonymous while nobody has voted in it.
+// The votes API hashes the voter of each vote in an anonymous poll as it
+// is cast, so a change after that would leave the poll with some votes
+// that can be tied to their voters and some that can't.  Unlike the
+// options it can't be forced, the voters of the votes already cast can't
+// be hashed or brought back afterwards
+func (p *PollList) checkAnonymousChange(id uint, was bool, anonymous bool) error {
+	if was == anonymous {
+		return nil
+	}
+	voted, err := p.hasVotes(id)
+	if err != nil {
+		return err
+	}
+	if voted {
+		return ErrAnonymousLocked
+	}
+	return nil
+}
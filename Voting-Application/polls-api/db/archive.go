@@ -0,0 +1,76 @@
+package db
+
+import "time"
+
+// ErrPollArchived is returned when reopening a poll that is archived, it
+// has to be unarchived first
+var ErrPollArchived error = conflict("poll is archived, unarchive it first")
+
+// ErrArchiveOpen is returned when archiving a poll that is still
+// accepting votes
+var ErrArchiveOpen error = conflict("poll is still open, close it before archiving")
+
+// ArchivePoll accepts a poll id and archives the poll, so that it is left
+// out of the poll list by default and takes no more votes, while it and
+// its votes and results are all kept.  now is the time to decide whether
+// the poll is still open at.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+//					(3) The poll must not be open, drafts and closed
+//						polls can be archived
+//
+// Postconditions:
+//
+//	    (1) The poll will be archived, archiving a poll that is
+//			already archived is not an error
+//		(2) The archived poll will be returned
+//		(3) If the poll is open, ErrArchiveOpen is returned
+//		(4) If there is an error, it will be returned
+func (p *PollList) ArchivePoll(id uint, now time.Time) (Poll, error) {
+	return p.setArchived(id, true, now)
+}
+
+// UnarchivePoll accepts a poll id and puts an archived poll back in the
+// poll list.  The poll stays closed, use ReopenPoll to take votes again.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The poll will no longer be archived, unarchiving a poll
+//			that is not archived is not an error
+//		(2) The unarchived poll will be returned
+//		(3) If there is an error, it will be returned
+func (p *PollList) UnarchivePoll(id uint, now time.Time) (Poll, error) {
+	return p.setArchived(id, false, now)
+}
+
+// setArchived archives or unarchives the poll with id, changing just its
+// settings
+func (p *PollList) setArchived(id uint, archived bool, now time.Time) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	poll.Settings = poll.Settings.withDefaults(PollSettings{})
+	if poll.Settings.Archived == archived {
+		return poll, nil
+	}
+	if archived && poll.Settings.IsOpen(now) {
+		return Poll{}, ErrArchiveOpen
+	}
+
+	poll.Settings.Archived = archived
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+	poll.Version++
+	p.invalidate(id)
+
+	return poll, nil
+}
@@ -0,0 +1,223 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// AuditStreamKey is the redis stream every service appends an AuditEntry
+// to for each change made to a voter, poll or vote through it.  The
+// services share it, so the audit log read from any of them has the
+// changes made through all of them
+const AuditStreamKey = "audit"
+
+// The kinds of entity an AuditEntry can be about
+const (
+	AuditVoter = "voter"
+	AuditPoll  = "poll"
+	AuditVote  = "vote"
+)
+
+// The actions an AuditEntry can record.  AuditErased is a voter erased by
+// the voters API, whose earlier entries have been taken out of the stream
+// along with their names
+const (
+	AuditCreated = "created"
+	AuditUpdated = "updated"
+	AuditDeleted = "deleted"
+	AuditErased  = "erased"
+)
+
+// ErrInvalidAuditEntity is returned when the audit log is read for an
+// entity that is not a voter, poll or vote
+var ErrInvalidAuditEntity = errors.New("invalid audit entity")
+
+// ErrAuditUnavailable is returned when the audit log is read from a
+// store that does not keep one, the audit log is kept in redis
+var ErrAuditUnavailable = errors.New("audit log unavailable")
+
+// ValidateAuditEntity makes sure that entity is one of the kinds of
+// entity the audit log records
+func ValidateAuditEntity(entity string) error {
+	switch entity {
+	case AuditVoter, AuditPoll, AuditVote:
+		return nil
+	}
+	return fmt.Errorf("%w %q, must be one of %s, %s or %s", ErrInvalidAuditEntity, entity,
+		AuditVoter, AuditPoll, AuditVote)
+}
+
+// AuditEntry is a change made to a voter, poll or vote.  Before and After
+// are the entity as it was and as it is now, as JSON.  Before is left out
+// of a created entry and After out of a deleted one.  ID is the entry's id
+// in the stream, the entries are in the order of their ids
+type AuditEntry struct {
+	ID       string
+	Entity   string
+	EntityID uint
+	Action   string
+	//Actor is who made the change, as they said in X-Actor, or their
+	//address if they didn't.  RequestID is the X-Request-ID of the
+	//request that made it, to find it in the logs
+	Actor     string
+	RequestID string
+	At        time.Time
+	Before    json.RawMessage `json:",omitempty"`
+	After     json.RawMessage `json:",omitempty"`
+}
+
+// NewAuditEntry returns the entry for a change to the entity with id from
+// before to after, either of which is nil if the entity did not exist at
+// that time.  The action is worked out from which of them are nil
+func NewAuditEntry(entity string, id uint, before any, after any) (AuditEntry, error) {
+	entry := AuditEntry{Entity: entity, EntityID: id}
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return AuditEntry{}, err
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		return AuditEntry{}, err
+	}
+	switch {
+	case entry.Before == nil:
+		entry.Action = AuditCreated
+	case entry.After == nil:
+		entry.Action = AuditDeleted
+	default:
+		entry.Action = AuditUpdated
+	}
+	return entry, nil
+}
+
+// auditSnapshot returns v as JSON, or nil if v is nil, a nil pointer
+// included
+func auditSnapshot(v any) (json.RawMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(b) == "null" {
+		return nil, nil
+	}
+	return b, nil
+}
+
+// appendAudit adds entry to the end of the audit stream
+func (c *cache) appendAudit(entry AuditEntry) error {
+	values := map[string]interface{}{
+		"entity":    entry.Entity,
+		"entityId":  entry.EntityID,
+		"action":    entry.Action,
+		"actor":     entry.Actor,
+		"requestId": entry.RequestID,
+		"at":        entry.At.UTC().Format(time.RFC3339Nano),
+		"before":    string(entry.Before),
+		"after":     string(entry.After),
+	}
+	return c.cacheClient.XAdd(c.context, &redis.XAddArgs{Stream: AuditStreamKey, Values: values}).Err()
+}
+
+// auditEntryFromMessage reads the entry in msg
+func auditEntryFromMessage(msg redis.XMessage) (AuditEntry, error) {
+	field := func(name string) string {
+		s, _ := msg.Values[name].(string)
+		return s
+	}
+	entry := AuditEntry{
+		ID:        msg.ID,
+		Entity:    field("entity"),
+		Action:    field("action"),
+		Actor:     field("actor"),
+		RequestID: field("requestId"),
+	}
+	id, err := strconv.ParseUint(field("entityId"), 10, 32)
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("audit entry %s has an invalid entity id: %w", msg.ID, err)
+	}
+	entry.EntityID = uint(id)
+	if entry.At, err = time.Parse(time.RFC3339Nano, field("at")); err != nil {
+		return AuditEntry{}, fmt.Errorf("audit entry %s has an invalid time: %w", msg.ID, err)
+	}
+	if before := field("before"); before != "" {
+		entry.Before = json.RawMessage(before)
+	}
+	if after := field("after"); after != "" {
+		entry.After = json.RawMessage(after)
+	}
+	return entry, nil
+}
+
+// auditEntries returns the entries about entity, only those about the one with
+// id unless id is 0, oldest first.  The stream is read a batch at a time
+// from the start, entries that can't be read are skipped
+func (c *cache) auditEntries(entity string, id uint) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	start := "-"
+	for {
+		msgs, err := c.cacheClient.XRangeN(c.context, AuditStreamKey, start, "+", c.scanBatch).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			entry, err := auditEntryFromMessage(msg)
+			if err != nil {
+				log.Println("Skipping audit entry: ", err)
+				continue
+			}
+			if entry.Entity == entity && (id == 0 || entry.EntityID == id) {
+				entries = append(entries, entry)
+			}
+		}
+		if int64(len(msgs)) < c.scanBatch {
+			return entries, nil
+		}
+		//Carry on from just after the last entry read
+		start = "(" + msgs[len(msgs)-1].ID
+	}
+}
+
+// RecordAudit accepts an audit entry and appends it to the audit log, at
+// entry.At.  A store with no audit log records nothing.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The entry will be at the end of the audit log
+//		(2) If there is an error, it will be returned
+func (p *PollList) RecordAudit(entry AuditEntry) error {
+	if p.needsRedis() != nil {
+		return nil
+	}
+	return p.appendAudit(entry)
+}
+
+// GetAudit accepts an entity and an id and returns the entries in the
+// audit log about it, or about every one of the entity if id is 0.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) entity must be voter, poll or vote
+//
+// Postconditions:
+//
+//	    (1) The entries will be returned, oldest first
+//		(2) If entity is not valid an error wrapping
+//			ErrInvalidAuditEntity will be returned
+//		(3) If the store has no audit log ErrAuditUnavailable will be
+//			returned
+//		(4) If there is an error, it will be returned
+func (p *PollList) GetAudit(entity string, id uint) ([]AuditEntry, error) {
+	if err := ValidateAuditEntity(entity); err != nil {
+		return nil, err
+	}
+	if p.needsRedis() != nil {
+		return nil, ErrAuditUnavailable
+	}
+	return p.auditEntries(entity, id)
+}
@@ -0,0 +1,242 @@
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBallotLinkTTL is how long a ballot link works for when it is
+// created without a TTL, and MaxBallotLinkTTL the longest it can
+const (
+	DefaultBallotLinkTTL = 72 * time.Hour
+	MaxBallotLinkTTL     = 30 * 24 * time.Hour
+)
+
+// BallotLinkUsedKeyPrefix is the prefix of the keys that mark a ballot
+// link as used, ballot-link-used:<poll id>:<signature>.  They expire with
+// the link, after which it is turned away for being out of date instead
+const BallotLinkUsedKeyPrefix = "ballot-link-used:"
+
+// ErrBallotLinksOff is returned when ballot links are asked for but
+// BALLOT_LINK_SECRET is not set, so they can't be signed
+var ErrBallotLinksOff = errors.New("ballot links are not turned on, BALLOT_LINK_SECRET is not set")
+
+// ErrInvalidBallotLink is returned for a ballot link whose signature is
+// not right, that has expired or that has been used
+var ErrInvalidBallotLink = errors.New("invalid ballot link")
+
+// ErrInvalidBallotLinkTTL is returned when ballot links are asked for with
+// a TTL that is negative or longer than MaxBallotLinkTTL
+var ErrInvalidBallotLinkTTL = fmt.Errorf("ballot link TTL must be up to %s", MaxBallotLinkTTL)
+
+// BallotLink is a link the voter with VoterID can open their ballot for a
+// poll with and cast their vote, once, until ExpiresAt.  URL is the path
+// and query of GET /polls/:id/ballot, signed so that it can't be changed
+// to another voter or poll, or to expire later
+type BallotLink struct {
+	VoterID   uint
+	URL       string
+	ExpiresAt time.Time
+}
+
+// Ballot is what GET /polls/:id/ballot sends the voter a ballot link is
+// for.  Token is sent with their vote in X-Poll-Invitation, in place of an
+// invitation token
+type Ballot struct {
+	VoterID   uint
+	ExpiresAt time.Time
+	Token     string
+	Poll      Poll
+}
+
+// ballotLinkSecretFromEnv returns BALLOT_LINK_SECRET, the key ballot links
+// are signed with, or nil if it is not set, which turns them off.  Every
+// replica has to have the same secret
+func ballotLinkSecretFromEnv() []byte {
+	secret := os.Getenv("BALLOT_LINK_SECRET")
+	if secret == "" {
+		return nil
+	}
+	if len(secret) < 32 {
+		log.Println("BALLOT_LINK_SECRET is shorter than 32 characters, use a longer one")
+	}
+	return []byte(secret)
+}
+
+// signBallotLink returns the HMAC-SHA256 of a ballot link for the voter
+// with voterId to the poll with id that expires at expires, as hex
+func (p *PollList) signBallotLink(id uint, voterId uint, expires int64) string {
+	mac := hmac.New(sha256.New, p.ballotLinkSecret)
+	fmt.Fprintf(mac, "%d:%d:%d", id, voterId, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkBallotLink makes sure sig is the signature of a ballot link for the
+// voter with voterId to the poll with id that expires at expires, and
+// that it has not expired at now
+func (p *PollList) checkBallotLink(id uint, voterId uint, expires int64, sig string, now time.Time) error {
+	if p.ballotLinkSecret == nil {
+		return ErrBallotLinksOff
+	}
+	if !hmac.Equal([]byte(sig), []byte(p.signBallotLink(id, voterId, expires))) {
+		return fmt.Errorf("%w: bad signature", ErrInvalidBallotLink)
+	}
+	if !now.Before(time.Unix(expires, 0)) {
+		return fmt.Errorf("%w: the link has expired", ErrInvalidBallotLink)
+	}
+	return nil
+}
+
+// ballotLinkUsedKey is the key that marks the ballot link with sig to the
+// poll with id as used
+func ballotLinkUsedKey(id uint, sig string) string {
+	return fmt.Sprintf("%s%d:%s", BallotLinkUsedKeyPrefix, id, sig)
+}
+
+// isBallotToken reports whether token is the Token of a Ballot, which is
+// <expires>.<signature>, rather than an invitation token, which never has
+// a dot in it
+func isBallotToken(token string) bool {
+	return strings.Contains(token, ".")
+}
+
+// redeemBallotToken uses up the ballot link a Ballot's token is from, see
+// RedeemInvitation.  The link is marked used with SETNX, so of two votes
+// with it at once only one is taken
+func (p *PollList) redeemBallotToken(id uint, voterId uint, token string, now time.Time) (bool, error) {
+	expiresS, sig, _ := strings.Cut(token, ".")
+	expires, err := strconv.ParseInt(expiresS, 10, 64)
+	if err != nil {
+		return false, nil
+	}
+	if err := p.checkBallotLink(id, voterId, expires, sig, now); err != nil {
+		if errors.Is(err, ErrInvalidBallotLink) || errors.Is(err, ErrBallotLinksOff) {
+			return false, nil
+		}
+		return false, err
+	}
+	return p.cacheClient.SetNX(p.context, ballotLinkUsedKey(id, sig), now.Unix(), time.Unix(expires, 0).Sub(now)).Result()
+}
+
+// CreateBallotLinks accepts a poll id, the ids of the voters to send
+// ballot links to and how long the links should work for, and returns a
+// signed link for each voter, making the poll invite only if it was not
+// already.  Nothing is stored for a link, so they can't be listed, and
+// they all stop working if the poll's invitations are removed.  ttl 0 is
+// DefaultBallotLinkTTL, now is the time it is from.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+//					(3) BALLOT_LINK_SECRET must be set, if it is not
+//						ErrBallotLinksOff is returned
+//
+//					(4) There must be 1 to MaxInvitationsPerRequest
+//						voter ids, none of them 0
+//
+// Postconditions:
+//
+//	    (1) The links will be returned in the order of the voter ids,
+//			a voter id given twice gets one link
+//		(2) The poll's Settings.InviteOnly will be set
+//		(3) If the voter ids are not valid an error wrapping
+//			ErrInvalidInvitations will be returned, if the ttl is not
+//			ErrInvalidBallotLinkTTL
+//		(4) If there is an error, it will be returned
+func (p *PollList) CreateBallotLinks(id uint, voterIds []uint, ttl time.Duration, now time.Time) ([]BallotLink, error) {
+
+	if p.ballotLinkSecret == nil {
+		return nil, ErrBallotLinksOff
+	}
+	if err := p.needsRedis(); err != nil {
+		return nil, err
+	}
+	if err := validateInvitedVoters(voterIds); err != nil {
+		return nil, err
+	}
+	if ttl == 0 {
+		ttl = DefaultBallotLinkTTL
+	}
+	if ttl < 0 || ttl > MaxBallotLinkTTL {
+		return nil, ErrInvalidBallotLinkTTL
+	}
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return nil, notFoundOr(err, "poll does not exist")
+	}
+
+	expiresAt := now.Add(ttl).Truncate(time.Second).UTC()
+	links := make([]BallotLink, 0, len(voterIds))
+	seen := make(map[uint]bool, len(voterIds))
+	for _, voterId := range voterIds {
+		if seen[voterId] {
+			continue
+		}
+		seen[voterId] = true
+		sig := p.signBallotLink(id, voterId, expiresAt.Unix())
+		url := fmt.Sprintf("/polls/%d/ballot?voterId=%d&expires=%d&sig=%s", id, voterId, expiresAt.Unix(), sig)
+		links = append(links, BallotLink{VoterID: voterId, URL: url, ExpiresAt: expiresAt})
+	}
+	if err := p.makeInviteOnly(poll); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// GetBallot accepts the poll id, voter id, expiry and signature of a
+// ballot link and returns the ballot it is for, if the link is one
+// CreateBallotLinks made and is still good at now.  Opening the link does
+// not use it up, only voting with the ballot's Token does, so a link that
+// is opened twice, or by a mail scanner first, still works.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+//					(3) BALLOT_LINK_SECRET must be set, if it is not
+//						ErrBallotLinksOff is returned
+//
+// Postconditions:
+//
+//	    (1) The ballot will be returned
+//		(2) If the signature is not right, or the link has expired
+//			or been used, an error wrapping ErrInvalidBallotLink will
+//			be returned
+//		(3) If there is an error, it will be returned
+//		(4) The database file will not be modified
+func (p *PollList) GetBallot(id uint, voterId uint, expires int64, sig string, now time.Time) (Ballot, error) {
+
+	if err := p.checkBallotLink(id, voterId, expires, sig, now); err != nil {
+		return Ballot{}, err
+	}
+	if err := p.needsRedis(); err != nil {
+		return Ballot{}, err
+	}
+	used, err := p.cacheClient.Exists(p.context, ballotLinkUsedKey(id, sig)).Result()
+	if err != nil {
+		return Ballot{}, err
+	}
+	if used > 0 {
+		return Ballot{}, fmt.Errorf("%w: the link has been used", ErrInvalidBallotLink)
+	}
+
+	poll, err := p.GetPoll(id)
+	if err != nil {
+		return Ballot{}, err
+	}
+	return Ballot{
+		VoterID:   voterId,
+		ExpiresAt: time.Unix(expires, 0).UTC(),
+		Token:     fmt.Sprintf("%d.%s", expires, sig),
+		Poll:      poll,
+	}, nil
+}
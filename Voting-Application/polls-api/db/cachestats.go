@@ -0,0 +1,50 @@
+package db
+
+import (
+	"sync/atomic"
+)
+
+// PollCacheName is the name the local poll cache's hits and misses are
+// reported under
+const PollCacheName = "poll"
+
+// cacheCounter counts how often a cache had what was asked for.  It is
+// safe for concurrent use
+type cacheCounter struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *cacheCounter) hit() {
+	c.hits.Add(1)
+}
+
+func (c *cacheCounter) miss() {
+	c.misses.Add(1)
+}
+
+// CacheStats is how effective a cache has been since the service started.
+// HitRatio is 0 until the cache has been asked for anything
+type CacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	HitRatio float64
+}
+
+func (c *cacheCounter) stats() CacheStats {
+	stats := CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// CacheStats returns the hits, misses and hit ratio of each cache, keyed
+// by the cache's name
+func (p *PollList) CacheStats() map[string]CacheStats {
+	stats := make(map[string]CacheStats, len(p.cacheCounters))
+	for name, counter := range p.cacheCounters {
+		stats[name] = counter.stats()
+	}
+	return stats
+}
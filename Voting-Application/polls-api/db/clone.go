@@ -0,0 +1,47 @@
+package db
+
+// ClonePoll accepts a poll id and adds a copy of the poll as a new draft
+// under an id the DB picks, the same way as AddPollNewID.  The title,
+// question, options and tags are copied, and so are the settings that say
+// how votes are taken and counted, the voting method, MaxSelections,
+// AllowWriteIns, Anonymous and VoteRateLimit.  The schedule is not, the clone has no
+// OpensAt or ClosesAt and its results are not published, and none of the
+// votes of the poll are copied.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The copy will be added to the DB as a draft and returned
+//		(2) The poll that was cloned will not be modified
+//		(3) If there is an error, it will be returned
+func (p *PollList) ClonePoll(id uint) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	settings := poll.Settings.withDefaults(PollSettings{})
+	clone := Poll{
+		PollTitle:    poll.PollTitle,
+		PollQuestion: poll.PollQuestion,
+		PollOptions:  append([]pollOption{}, poll.PollOptions...),
+		Tags:         append([]string(nil), poll.Tags...),
+		Settings: PollSettings{
+			Status:        PollStatusDraft,
+			VoteRateLimit: settings.VoteRateLimit,
+			VotingMethod:  settings.VotingMethod,
+			MaxSelections: settings.MaxSelections,
+			AllowWriteIns: settings.AllowWriteIns,
+			Anonymous:     settings.Anonymous,
+		},
+	}
+
+	newId, err := p.AddPollNewID(clone)
+	if err != nil {
+		return Poll{}, err
+	}
+	return p.GetPoll(newId)
+}
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// setNew writes value as the document at key with JSON.SET's NX option,
+// so it is only written if there is no document there yet.  Checking
+// first and then writing would let two requests adding the same poll at
+// once both see it missing, this is one command so one of them gets
+// ErrAlreadyExists instead
+func (c *cache) setNew(key string, value any) error {
+	doc, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	err = c.cacheClient.Do(c.context, "JSON.SET", key, ".", string(doc), "NX").Err()
+	if errors.Is(err, redis.Nil) {
+		return ErrAlreadyExists
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidStatusFilter is returned when polls are filtered by a status
+// that polls can't have
+var ErrInvalidStatusFilter = errors.New("status must be draft or published")
+
+// PollFilter picks which polls GetAllPolls and GetPollsPage return.  The
+// zero value is every published poll that is not archived.  Empty fields
+// match any poll
+type PollFilter struct {
+	//IncludeDrafts returns draft polls as well as published ones
+	IncludeDrafts bool
+	//IncludeArchived returns archived polls as well
+	IncludeArchived bool
+	//Title matches polls whose title contains it, ignoring case
+	Title string
+	//HasOption matches polls with an option whose text is it, ignoring
+	//case
+	HasOption string
+	//Status matches polls with that Settings.Status.  Asking for drafts
+	//returns them whatever IncludeDrafts says
+	Status string
+	//Tag matches polls with the tag, ignoring case
+	Tag string
+}
+
+// Validate returns ErrInvalidStatusFilter if Status isn't one a poll can
+// have, or ErrInvalidTags if Tag isn't one a poll can have
+func (f PollFilter) Validate() error {
+	if f.Tag != "" {
+		if err := validateTag(normalizeTag(f.Tag)); err != nil {
+			return err
+		}
+	}
+	switch f.Status {
+	case "", PollStatusDraft, PollStatusPublished:
+		return nil
+	}
+	return ErrInvalidStatusFilter
+}
+
+// isZero is true when the filter only leaves out the drafts, as the list
+// did before polls could be filtered
+func (f PollFilter) isZero() bool {
+	return f.Title == "" && f.HasOption == "" && f.Status == "" && f.Tag == ""
+}
+
+func (f PollFilter) matches(poll Poll) bool {
+	if f.Status != "" {
+		if poll.Settings.Status != f.Status {
+			return false
+		}
+	} else if poll.Settings.Status == PollStatusDraft && !f.IncludeDrafts {
+		return false
+	}
+	if poll.Settings.Archived && !f.IncludeArchived {
+		return false
+	}
+
+	if f.Title != "" && !strings.Contains(strings.ToLower(poll.PollTitle), strings.ToLower(f.Title)) {
+		return false
+	}
+
+	if f.Tag != "" && !poll.hasTag(normalizeTag(f.Tag)) {
+		return false
+	}
+
+	if f.HasOption != "" {
+		found := false
+		for _, option := range poll.PollOptions {
+			if strings.EqualFold(option.PollOptionText, f.HasOption) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,296 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MaxInvitationsPerRequest is the most voters CreateInvitations takes at
+// once
+const MaxInvitationsPerRequest = 1000
+
+// InvitationHeader is the header a vote for an invite only poll has to
+// send its voter's invitation token in, and that
+// DELETE /polls/:id/invitations/:voterId redeems
+const InvitationHeader = "X-Poll-Invitation"
+
+// InvitationKeyPrefix is the prefix of the keys the invitations are kept
+// under.  The invitations of a poll are a hash under
+// poll-invitations:<number> from each voter id to the sha256 of its
+// token, so a token can't be read back out of redis, and they are kept
+// out of the poll so they are never sent with it
+const InvitationKeyPrefix = "poll-invitations:"
+
+// ErrInvalidInvitations is returned when invitations are asked for with
+// no voters, too many of them or a voter id of 0
+var ErrInvalidInvitations = errors.New("invalid invitations")
+
+// Invitation is a single use token that lets the voter with VoterID cast
+// a vote in an invite only poll.  The token is only ever sent back when
+// the invitation is created
+type Invitation struct {
+	VoterID uint
+	Token   string
+}
+
+func invitationKeyFromId(id uint) string {
+	return fmt.Sprintf("%s%d", InvitationKeyPrefix, id)
+}
+
+// newInvitationToken returns a random token, 128 bits as url safe base64
+func newInvitationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashInvitationToken returns the sha256 of a token as hex.  The tokens
+// are random, so unlike a passcode they don't need a slow hash
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateInvitations accepts a poll id and the ids of the voters to invite
+// and gives each voter a new invitation to the poll, making the poll
+// invite only if it was not already.  A voter that was already invited
+// gets a new token, and the one they had no longer works.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+//					(3) There must be 1 to MaxInvitationsPerRequest
+//						voter ids, none of them 0
+//
+// Postconditions:
+//
+//	    (1) The invitations will be returned in the order of the voter
+//			ids, a voter id given twice gets one invitation
+//		(2) The poll's Settings.InviteOnly will be set
+//		(3) If the voter ids are not valid an error wrapping
+//			ErrInvalidInvitations will be returned
+//		(4) If there is an error, it will be returned
+func (p *PollList) CreateInvitations(id uint, voterIds []uint) ([]Invitation, error) {
+
+	if err := validateInvitedVoters(voterIds); err != nil {
+		return nil, err
+	}
+	if err := p.needsRedis(); err != nil {
+		return nil, err
+	}
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return nil, notFoundOr(err, "poll does not exist")
+	}
+
+	invitations := make([]Invitation, 0, len(voterIds))
+	hashes := make(map[string]any, len(voterIds))
+	for _, voterId := range voterIds {
+		field := strconv.FormatUint(uint64(voterId), 10)
+		if _, ok := hashes[field]; ok {
+			continue
+		}
+		token, err := newInvitationToken()
+		if err != nil {
+			return nil, err
+		}
+		hashes[field] = hashInvitationToken(token)
+		invitations = append(invitations, Invitation{VoterID: voterId, Token: token})
+	}
+	//The tokens are written before the poll is made invite only, so an
+	//invite only poll never turns away a voter whose token is on its way
+	if err := p.cacheClient.HSet(p.context, invitationKeyFromId(id), hashes).Err(); err != nil {
+		return nil, err
+	}
+	if err := p.makeInviteOnly(poll); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// validateInvitedVoters makes sure there are 1 to MaxInvitationsPerRequest
+// voter ids to invite, none of them 0
+func validateInvitedVoters(voterIds []uint) error {
+	if len(voterIds) == 0 || len(voterIds) > MaxInvitationsPerRequest {
+		return fmt.Errorf("%w: invite 1 to %d voters at a time", ErrInvalidInvitations, MaxInvitationsPerRequest)
+	}
+	for _, voterId := range voterIds {
+		if voterId == 0 {
+			return fmt.Errorf("%w: voter ids must be positive", ErrInvalidInvitations)
+		}
+	}
+	return nil
+}
+
+// makeInviteOnly sets the Settings.InviteOnly of poll, as it was read,
+// if it is not already set
+func (p *PollList) makeInviteOnly(poll Poll) error {
+	if poll.Settings.InviteOnly {
+		return nil
+	}
+	poll.Settings.InviteOnly = true
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return notFoundOr(err, "poll does not exist")
+	}
+	p.invalidate(poll.PollID)
+	return nil
+}
+
+// GetInvitedVoters accepts a poll id and returns the ids of the voters
+// whose invitations to the poll have not been used yet.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The voter ids will be returned sorted, an empty slice if
+//			there are none
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) GetInvitedVoters(id uint) ([]uint, error) {
+
+	if _, err := p.store.get(id); err != nil {
+		return nil, notFoundOr(err, "poll does not exist")
+	}
+	//Without redis no voter can have been invited
+	if p.needsRedis() != nil {
+		return []uint{}, nil
+	}
+
+	fields, err := p.cacheClient.HKeys(p.context, invitationKeyFromId(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	voterIds := make([]uint, 0, len(fields))
+	for _, field := range fields {
+		voterId, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			continue
+		}
+		voterIds = append(voterIds, uint(voterId))
+	}
+	sort.Slice(voterIds, func(i, j int) bool { return voterIds[i] < voterIds[j] })
+	return voterIds, nil
+}
+
+// RedeemInvitation accepts a poll id, a voter id and a token and uses up
+// the voter's invitation to the poll if token is its token, for the votes
+// API to call before it takes the voter's vote.  The token can also be
+// the token of a signed ballot link of the voter, see ballotlinks.go.  A
+// poll that is not invite only takes any token, or none, and nothing is
+// used up.  Two redemptions of one token at once can't both succeed, only
+// one of them gets to delete it.  now is the time a ballot link's expiry
+// is checked against.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) true will be returned if the poll is not invite only, or
+//			token was the voter's and their invitation has been
+//			deleted so it can't be used again
+//		(2) false will be returned, and nothing changed, if the voter
+//			was not invited, their invitation was used or token is not
+//			its token
+//		(3) If there is an error, it will be returned
+func (p *PollList) RedeemInvitation(id uint, voterId uint, token string, now time.Time) (bool, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return false, notFoundOr(err, "poll does not exist")
+	}
+	if !poll.Settings.InviteOnly {
+		return true, nil
+	}
+	if token == "" {
+		return false, nil
+	}
+	if err := p.needsRedis(); err != nil {
+		return false, err
+	}
+	if isBallotToken(token) {
+		return p.redeemBallotToken(id, voterId, token, now)
+	}
+
+	key := invitationKeyFromId(id)
+	field := strconv.FormatUint(uint64(voterId), 10)
+	hash, err := p.cacheClient.HGet(p.context, key, field).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(hashInvitationToken(token))) != 1 {
+		return false, nil
+	}
+
+	//Only delete the invitation if it is still the one that was checked,
+	//a new one given to the voter since is left alone
+	deleted, err := redeemInvitationScript.Run(p.context, p.cacheClient, []string{key}, field, hash).Int()
+	if err != nil {
+		return false, err
+	}
+	return deleted == 1, nil
+}
+
+// redeemInvitationScript deletes the field ARGV[1] of the hash KEYS[1] if
+// it is still ARGV[2], and returns how many fields it deleted
+var redeemInvitationScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+	return redis.call("HDEL", KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// RemoveInvitations accepts a poll id and deletes every invitation to
+// the poll, so it is no longer invite only and votes for it no longer
+// need one.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The poll will not be invite only and be returned, removing
+//			the invitations of a poll that has none is not an error
+//		(2) If there is an error, it will be returned
+func (p *PollList) RemoveInvitations(id uint) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	if poll.Settings.InviteOnly {
+		poll.Settings.InviteOnly = false
+		if err := p.putVersion(poll, poll.Version); err != nil {
+			return Poll{}, notFoundOr(err, "poll does not exist")
+		}
+		poll.Version++
+		p.invalidate(id)
+	}
+	if p.needsRedis() == nil {
+		if err := p.cacheClient.Del(p.context, invitationKeyFromId(id)).Err(); err != nil {
+			return Poll{}, err
+		}
+	}
+
+	return poll, nil
+}
@@ -0,0 +1,135 @@
+package db
+
+import "time"
+
+// The states of a poll's lifecycle, as Poll.Status.  A poll is a draft
+// until it is opened, scheduled if it has a Settings.OpensAt still to
+// come, open while it takes votes and closed once it has reached
+// Settings.ClosesAt or been archived
+const (
+	PollStateDraft     = "draft"
+	PollStateScheduled = "scheduled"
+	PollStateOpen      = "open"
+	PollStateClosed    = "closed"
+)
+
+// ErrPollClosed is returned when opening a poll that has closed, closed
+// polls are reopened with ReopenPoll
+var ErrPollClosed error = conflict("poll is closed, reopen it instead")
+
+// ErrDraftExpired is returned when opening a draft whose closing time
+// has already passed
+var ErrDraftExpired error = conflict("poll's closing time has passed, change it before opening")
+
+// ErrPollDraft is returned when closing a poll that has not opened yet
+var ErrPollDraft error = conflict("poll is a draft and was never open")
+
+// State returns where a poll with these settings is in its lifecycle at
+// now
+func (s PollSettings) State(now time.Time) string {
+	switch {
+	case s.IsOpen(now):
+		return PollStateOpen
+	case s.Archived:
+		return PollStateClosed
+	case s.OpensAt != nil && now.Before(*s.OpensAt):
+		return PollStateScheduled
+	case s.OpensAt == nil && s.withDefaults(PollSettings{}).Status == PollStatusDraft:
+		return PollStateDraft
+	}
+	return PollStateClosed
+}
+
+// OpenPoll accepts a poll id and opens a draft poll to votes, the same as
+// PublishPoll.  A scheduled poll is opened straight away, its OpensAt is
+// cleared.  now is the time to decide whether the poll is open at.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+//					(3) The poll must not be closed or archived
+//
+// Postconditions:
+//
+//	    (1) The poll will be open, opening a poll that is already
+//			open is not an error
+//		(2) The open poll will be returned
+//		(3) If the poll is archived ErrPollArchived is returned, if
+//			it has closed ErrPollClosed, and if it is a draft whose
+//			closing time has passed ErrDraftExpired
+//		(4) If there is an error, it will be returned
+func (p *PollList) OpenPoll(id uint, now time.Time) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	switch {
+	case poll.Settings.Archived:
+		return Poll{}, ErrPollArchived
+	case poll.Settings.State(now) == PollStateOpen:
+		return poll, nil
+	case poll.Settings.State(now) == PollStateClosed:
+		return Poll{}, ErrPollClosed
+	case poll.Settings.ClosesAt != nil && !now.Before(*poll.Settings.ClosesAt):
+		//A draft whose closing time has passed would be closed as soon
+		//as it was published
+		return Poll{}, ErrDraftExpired
+	case poll.Settings.OpensAt == nil:
+		return p.PublishPoll(id)
+	}
+
+	poll.Settings = poll.Settings.withDefaults(PollSettings{})
+	poll.Settings.Status = PollStatusPublished
+	poll.Settings.OpensAt = nil
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+	poll.Version++
+	p.invalidate(id)
+
+	return poll, nil
+}
+
+// ClosePoll accepts a poll id and stops an open poll taking votes at
+// now, by setting its closing time to now.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+//					(3) The poll must not be a draft or scheduled
+//
+// Postconditions:
+//
+//	    (1) The poll will be closed, closing a poll that is already
+//			closed is not an error
+//		(2) The closed poll will be returned
+//		(3) If the poll is a draft or scheduled, ErrPollDraft is
+//			returned
+//		(4) If there is an error, it will be returned
+func (p *PollList) ClosePoll(id uint, now time.Time) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	poll.Settings = poll.Settings.withDefaults(PollSettings{})
+	switch poll.Settings.State(now) {
+	case PollStateDraft, PollStateScheduled:
+		return Poll{}, ErrPollDraft
+	case PollStateClosed:
+		return poll, nil
+	}
+
+	closesAt := now
+	poll.Settings.ClosesAt = &closesAt
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+	poll.Version++
+	p.invalidate(id)
+
+	return poll, nil
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// VotesChangedChannel is the redis pub/sub channel the votes API
+// publishes the id of a poll to whenever its votes change, or "*" when
+// every vote was deleted
+const VotesChangedChannel = "votes:changed"
+
+// voteWatchers hands the vote changes this replica hears about to the
+// callers of WatchPollVotes.  There is one redis subscription however
+// many are watching
+type voteWatchers struct {
+	lock     sync.Mutex
+	watchers map[uint]map[chan struct{}]struct{}
+}
+
+func newVoteWatchers() *voteWatchers {
+	return &voteWatchers{watchers: make(map[uint]map[chan struct{}]struct{})}
+}
+
+// add returns a channel that is signalled when the votes of poll id
+// change
+func (w *voteWatchers) add(id uint) chan struct{} {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	//A buffer of one lets a burst of votes be sent as a single signal
+	//to a watcher that hasn't caught up yet
+	ch := make(chan struct{}, 1)
+	if w.watchers[id] == nil {
+		w.watchers[id] = make(map[chan struct{}]struct{})
+	}
+	w.watchers[id][ch] = struct{}{}
+	return ch
+}
+
+func (w *voteWatchers) remove(id uint, ch chan struct{}) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	delete(w.watchers[id], ch)
+	if len(w.watchers[id]) == 0 {
+		delete(w.watchers, id)
+	}
+}
+
+// notify signals the watchers of poll id, never blocking on one that is
+// still busy with the last change
+func (w *voteWatchers) notify(id uint) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for ch := range w.watchers[id] {
+		signal(ch)
+	}
+}
+
+func (w *voteWatchers) notifyAll() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, chs := range w.watchers {
+		for ch := range chs {
+			signal(ch)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// WatchPollVotes returns a channel that receives a value whenever the
+// votes of poll id change, and a function that stops the watching.  Several
+// changes close together may arrive as one
+func (p *PollList) WatchPollVotes(id uint) (<-chan struct{}, func()) {
+	ch := p.voteWatchers.add(id)
+	return ch, func() {
+		p.voteWatchers.remove(id, ch)
+	}
+}
+
+// subscribeVoteChanges listens for the votes API announcing changes to
+// the votes of a poll and passes them on to the watchers of that poll.
+// It returns once the subscription is in place, the listening carries on
+// in the background
+func (p *PollList) subscribeVoteChanges() error {
+	pubsub := p.cacheClient.Subscribe(p.context, VotesChangedChannel)
+	if _, err := pubsub.Receive(p.context); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	go func(ch <-chan *redis.Message) {
+		for msg := range ch {
+			if msg.Payload == "*" {
+				p.voteWatchers.notifyAll()
+				continue
+			}
+			id, err := strconv.ParseUint(msg.Payload, 10, 64)
+			if err != nil {
+				log.Println("Ignoring invalid votes changed message: ", msg.Payload)
+				continue
+			}
+			p.voteWatchers.notify(uint(id))
+		}
+	}(pubsub.Channel())
+
+	return nil
+}
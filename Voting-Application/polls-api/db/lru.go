@@ -0,0 +1,186 @@
+package db
+
+import (
+	"container/list"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// InvalidationChannel is the redis pub/sub channel that replicas of the
+// polls API use to tell each other to drop a poll from their local cache.
+// The message is the poll id, or "*" to drop everything
+const InvalidationChannel = "invalidate:polls"
+
+// DefaultPollCacheSize is how many polls each replica keeps in its local
+// cache unless POLL_CACHE_SIZE says otherwise
+const DefaultPollCacheSize = 128
+
+// pollLRU is a small least recently used cache of polls that sits in
+// front of redis for GetPoll.  A size of 0 turns the cache off.  It is safe
+// for concurrent use
+type pollLRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[uint]*list.Element
+}
+
+func newPollLRU(size int) *pollLRU {
+	return &pollLRU{
+		size:  size,
+		order: list.New(),
+		items: make(map[uint]*list.Element),
+	}
+}
+
+// get returns a copy of the cached poll with id, if there is one
+func (l *pollLRU) get(id uint) (Poll, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[id]
+	if !ok {
+		return Poll{}, false
+	}
+	l.order.MoveToFront(elem)
+	return clonePoll(elem.Value.(Poll)), true
+}
+
+// resize changes how many polls the cache holds, evicting the least
+// recently used polls if it now holds too many
+func (l *pollLRU) resize(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.size = size
+	l.trim()
+}
+
+// put caches a copy of poll, evicting the least recently used poll if
+// the cache is full
+func (l *pollLRU) put(poll Poll) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size <= 0 {
+		return
+	}
+
+	if elem, ok := l.items[poll.PollID]; ok {
+		elem.Value = clonePoll(poll)
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.items[poll.PollID] = l.order.PushFront(clonePoll(poll))
+	l.trim()
+}
+
+// trim evicts the least recently used polls until the cache fits its
+// size, the caller must hold the lock
+func (l *pollLRU) trim() {
+	for l.order.Len() > 0 && l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(Poll).PollID)
+	}
+}
+
+// evict drops the poll with id from the cache
+func (l *pollLRU) evict(id uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[id]; ok {
+		l.order.Remove(elem)
+		delete(l.items, id)
+	}
+}
+
+// purge drops every poll from the cache
+func (l *pollLRU) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	l.items = make(map[uint]*list.Element)
+}
+
+// clonePoll copies a poll so that the cached copy does not share slices
+// or times with one handed out to a caller
+func clonePoll(poll Poll) Poll {
+	poll.PollOptions = append([]pollOption(nil), poll.PollOptions...)
+	poll.Tags = append([]string(nil), poll.Tags...)
+	poll.Links = append([]string(nil), poll.Links...)
+	poll.Settings.OpensAt = cloneTime(poll.Settings.OpensAt)
+	poll.Settings.ClosesAt = cloneTime(poll.Settings.ClosesAt)
+	return poll
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// invalidate drops the poll with id from the local cache and tells every
+// other replica to do the same
+func (p *PollList) invalidate(id uint) {
+	p.localCache.evict(id)
+	p.publishInvalidation(strconv.FormatUint(uint64(id), 10))
+}
+
+// invalidateAll drops every poll from the local cache and tells every
+// other replica to do the same
+func (p *PollList) invalidateAll() {
+	p.localCache.purge()
+	p.publishInvalidation("*")
+}
+
+// publishInvalidation tells the other replicas to evict msg, a poll id
+// or "*".  Without redis there is nothing to tell them with, so a store
+// that more than one replica shares should be run with POLL_CACHE_SIZE=0
+func (p *PollList) publishInvalidation(msg string) {
+	if p.needsRedis() != nil {
+		return
+	}
+	if err := p.cacheClient.Publish(p.context, InvalidationChannel, msg).Err(); err != nil {
+		//The write has already happened, so all we can do is log that
+		//the other replicas may serve the old poll for a while
+		log.Println("Error publishing poll cache invalidation: ", err)
+	}
+}
+
+// subscribeInvalidations listens for invalidation messages from the
+// other replicas and evicts the polls they name from the local cache.
+// It returns once the subscription is in place, the listening carries on
+// in the background
+func (p *PollList) subscribeInvalidations() error {
+	pubsub := p.cacheClient.Subscribe(p.context, InvalidationChannel)
+	if _, err := pubsub.Receive(p.context); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	go func(ch <-chan *redis.Message) {
+		for msg := range ch {
+			if msg.Payload == "*" {
+				p.localCache.purge()
+				continue
+			}
+			id, err := strconv.ParseUint(msg.Payload, 10, 64)
+			if err != nil {
+				log.Println("Ignoring invalid poll cache invalidation: ", msg.Payload)
+				continue
+			}
+			p.localCache.evict(uint(id))
+		}
+	}(pubsub.Channel())
+
+	return nil
+}
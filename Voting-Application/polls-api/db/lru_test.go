@@ -0,0 +1,110 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// testPollID is far past any id the seed data or AddPollNewID hands out,
+// so the tests don't trip over polls that are already there
+const testPollID = 900001
+
+// newTestPollList connects to the redis at REDIS_URL, or the default
+// location, and skips the test if there isn't one.  It has to be
+// redis-stack, like the one docker-compose.yaml runs
+func newTestPollList(tb testing.TB) *PollList {
+	tb.Helper()
+
+	location := os.Getenv("REDIS_URL")
+	if location == "" {
+		location = RedisDefaultLocation
+	}
+	pollList, err := NewWithCacheInstance(location)
+	if err != nil {
+		tb.Skip("redis is not available at ", location, ": ", err)
+	}
+	return pollList
+}
+
+func newTestPoll(id uint, title string) Poll {
+	return Poll{
+		PollID:       id,
+		PollTitle:    title,
+		PollQuestion: "Which one?",
+		PollOptions: []pollOption{
+			{PollOptionID: 1, PollOptionText: "This one"},
+			{PollOptionID: 2, PollOptionText: "That one"},
+		},
+	}
+}
+
+// waitForEviction waits for the poll with id to drop out of the local
+// cache of pollList, the invalidation arrives asynchronously
+func waitForEviction(t *testing.T, pollList *PollList, id uint) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := pollList.localCache.get(id); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("poll ", id, " was not evicted from the other replica's cache")
+}
+
+// TestInvalidationAcrossReplicas runs two poll lists against the same
+// redis, like two replicas of the polls API, and checks that a change made
+// through one evicts the poll from the local cache of the other
+func TestInvalidationAcrossReplicas(t *testing.T) {
+	replicaA := newTestPollList(t)
+	replicaB := newTestPollList(t)
+
+	replicaA.DeletePoll(testPollID)
+	if err := replicaA.AddPoll(newTestPoll(testPollID, "Before")); err != nil {
+		t.Fatal("Error adding poll: ", err)
+	}
+	t.Cleanup(func() { replicaA.DeletePoll(testPollID) })
+
+	//Reading the poll on B caches it there
+	if _, err := replicaB.GetPoll(testPollID); err != nil {
+		t.Fatal("Error getting poll: ", err)
+	}
+	if _, ok := replicaB.localCache.get(testPollID); !ok {
+		t.Fatal("poll was not cached by GetPoll")
+	}
+
+	if err := replicaA.UpdatePoll(newTestPoll(testPollID, "After"), false, time.Now()); err != nil {
+		t.Fatal("Error updating poll: ", err)
+	}
+	waitForEviction(t, replicaB, testPollID)
+
+	poll, err := replicaB.GetPoll(testPollID)
+	if err != nil {
+		t.Fatal("Error getting poll: ", err)
+	}
+	if poll.PollTitle != "After" {
+		t.Errorf("replica B got title %q after the update, want %q", poll.PollTitle, "After")
+	}
+
+	if err := replicaA.DeletePoll(testPollID); err != nil {
+		t.Fatal("Error deleting poll: ", err)
+	}
+	waitForEviction(t, replicaB, testPollID)
+
+	if _, err := replicaB.GetPoll(testPollID); err == nil {
+		t.Error("replica B still got the poll after it was deleted")
+	}
+}
+
+// TestInvalidateAllAcrossReplicas checks that "*" empties the local cache
+// of every replica
+func TestInvalidateAllAcrossReplicas(t *testing.T) {
+	replicaA := newTestPollList(t)
+	replicaB := newTestPollList(t)
+
+	replicaB.localCache.put(newTestPoll(testPollID, "Cached"))
+	replicaA.invalidateAll()
+	waitForEviction(t, replicaB, testPollID)
+}
@@ -0,0 +1,182 @@
+//go:build mongo
+
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDefaultLocation is the server used with -db mongo when MONGO_URL
+// is not set
+const MongoDefaultLocation = "mongodb://localhost:27017"
+
+// Polls are kept in the polls collection of the voting database, the
+// same database the voters API keeps its voters in
+const (
+	MongoDatabase   = "voting"
+	MongoCollection = "polls"
+)
+
+// mongoTimeout bounds every call to mongo, so that an unreachable server
+// fails the request instead of hanging it
+const mongoTimeout = 5 * time.Second
+
+// mongoStore keeps polls as documents in a MongoDB collection.  The
+// driver maps the Poll fields to lower case names, so PollID is pollid
+type mongoStore struct {
+	client *mongo.Client
+	polls  *mongo.Collection
+}
+
+// NewMongoPollList connects to the mongo server in MONGO_URL, creates
+// the indexes it needs and returns a PollList that keeps its polls there
+// instead of redis.  Passcodes, invitations, ballot links and the audit
+// log need redis, so they are not available.  It is only available in
+// builds made with -tags mongo
+func NewMongoPollList() (*PollList, error) {
+	mongoUrl := os.Getenv("MONGO_URL")
+	if mongoUrl == "" {
+		mongoUrl = MongoDefaultLocation
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoUrl))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	//PollID is unique, like the redis key it replaces, which is also what
+	//stops two polls being created with the same id
+	polls := client.Database(MongoDatabase).Collection(MongoCollection)
+	_, err = polls.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "pollid", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	pollList := newPollList(&mongoStore{client: client, polls: polls}, withoutRedis())
+	pollList.startFromEnv()
+
+	return pollList, nil
+}
+
+func (m *mongoStore) get(id uint) (Poll, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	var poll Poll
+	err := m.polls.FindOne(ctx, bson.M{"pollid": id}).Decode(&poll)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Poll{}, ErrNotFound
+	}
+	if err != nil {
+		return Poll{}, err
+	}
+	return poll, nil
+}
+
+// create relies on the unique index on pollid, so of two polls added
+// with the same id at once only one goes in
+func (m *mongoStore) create(poll Poll) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := m.polls.InsertOne(ctx, poll)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// putVersion checks the version in the filter of the same ReplaceOne
+// that writes the poll
+func (m *mongoStore) putVersion(poll Poll, version uint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	poll.Version = version + 1
+	result, err := m.polls.ReplaceOne(ctx, bson.M{"pollid": poll.PollID, "version": version}, poll)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return missedVersion(m, poll.PollID, version)
+	}
+	return nil
+}
+
+func (m *mongoStore) delete(id uint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	result, err := m.polls.DeleteOne(ctx, bson.M{"pollid": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *mongoStore) deleteAll() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := m.polls.DeleteMany(ctx, bson.M{})
+	return err
+}
+
+// findPolls returns the polls matching filter in PollID order
+func (m *mongoStore) findPolls(filter bson.M, opts *options.FindOptions) ([]Poll, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	cursor, err := m.polls.Find(ctx, filter, opts.SetSort(bson.D{{Key: "pollid", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var polls []Poll
+	if err := cursor.All(ctx, &polls); err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+func (m *mongoStore) list() ([]Poll, error) {
+	return m.findPolls(bson.M{}, options.Find())
+}
+
+// listPage uses the last poll id of the previous page as the cursor,
+// poll ids start at 1 so 0 is the start
+func (m *mongoStore) listPage(cursor uint64, count int) ([]Poll, uint64, error) {
+	count = pageCount(count)
+	polls, err := m.findPolls(bson.M{"pollid": bson.M{"$gt": cursor}}, options.Find().SetLimit(int64(count)))
+	if err != nil {
+		return nil, 0, err
+	}
+	return polls, lastPollID(polls, count), nil
+}
+
+func (m *mongoStore) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	return m.client.Ping(ctx, nil)
+}
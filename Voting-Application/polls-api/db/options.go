@@ -0,0 +1,153 @@
+package db
+
+import (
+	"fmt"
+	"log"
+)
+
+// MinPollOptions is the fewest options a poll can have, the same as the
+// binding on Poll.PollOptions
+const MinPollOptions = 2
+
+// AddPollOption accepts a poll id, an option id and its text and adds the
+// option to the end of the poll's options, leaving the rest of the poll as
+// it is.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB and not already
+//						have an option with optionId
+//
+//					(3) The options must not change if the poll has
+//						votes, unless force is true
+//
+// Postconditions:
+//
+//	    (1) The poll will have the new option and be returned
+//		(2) If the poll already has the option ErrConflict is
+//			returned, if it has votes and force is false
+//			ErrOptionsLocked
+//		(3) If there is an error, it will be returned
+func (p *PollList) AddPollOption(id uint, optionId uint, text string, force bool) (Poll, error) {
+	return p.changeOptions(id, force, func(options []pollOption) ([]pollOption, error) {
+		if optionIndex(options, optionId) >= 0 {
+			return nil, conflict("poll already has the option")
+		}
+		return append(options, pollOption{PollOptionID: optionId, PollOptionText: text}), nil
+	})
+}
+
+// UpdatePollOption accepts a poll id, an option id and new text for the
+// option and replaces the option's text, leaving the rest of the poll as
+// it is.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB and have an
+//						option with optionId
+//
+//					(3) The options must not change if the poll has
+//						votes, unless force is true
+//
+// Postconditions:
+//
+//	    (1) The option will have the new text and the poll will be
+//			returned
+//		(2) If the poll or the option does not exist ErrNotFound is
+//			returned, if the poll has votes and force is false
+//			ErrOptionsLocked
+//		(3) If there is an error, it will be returned
+func (p *PollList) UpdatePollOption(id uint, optionId uint, text string, force bool) (Poll, error) {
+	return p.changeOptions(id, force, func(options []pollOption) ([]pollOption, error) {
+		i := optionIndex(options, optionId)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: poll option does not exist", ErrNotFound)
+		}
+		options[i].PollOptionText = text
+		return options, nil
+	})
+}
+
+// DeletePollOption accepts a poll id and an option id and removes the
+// option from the poll, leaving the rest of the poll as it is.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB and have an
+//						option with optionId
+//
+//					(3) The poll must have more than MinPollOptions
+//						options
+//
+//					(4) The options must not change if the poll has
+//						votes, unless force is true
+//
+// Postconditions:
+//
+//	    (1) The option will be removed and the poll returned
+//		(2) If the poll or the option does not exist ErrNotFound is
+//			returned, if the poll would be left with too few options
+//			ErrConflict, if it has votes and force is false
+//			ErrOptionsLocked
+//		(3) If there is an error, it will be returned
+func (p *PollList) DeletePollOption(id uint, optionId uint, force bool) (Poll, error) {
+	return p.changeOptions(id, force, func(options []pollOption) ([]pollOption, error) {
+		i := optionIndex(options, optionId)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: poll option does not exist", ErrNotFound)
+		}
+		if len(options) <= MinPollOptions {
+			return nil, conflict(fmt.Sprintf("poll must keep at least %d options", MinPollOptions))
+		}
+		return append(options[:i], options[i+1:]...), nil
+	})
+}
+
+// changeOptions reads the poll with id, hands a copy of its options to
+// change and writes what comes back as the poll's options.  The poll is
+// written with its Version checked, so a change to the rest of the poll
+// in between is turned away rather than lost.  Like
+// UpdatePoll, the options of a poll with votes only change with force
+func (p *PollList) changeOptions(id uint, force bool, change func([]pollOption) ([]pollOption, error)) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	options, err := change(append([]pollOption{}, poll.PollOptions...))
+	if err != nil {
+		return Poll{}, err
+	}
+
+	voted, err := p.hasVotes(id)
+	if err != nil {
+		return Poll{}, err
+	}
+	if voted && !force {
+		return Poll{}, ErrOptionsLocked
+	}
+	if voted {
+		log.Println("Options of poll ", id, " changed with force, votes for removed options are no longer counted")
+	}
+
+	poll.PollOptions = options
+	if err := validatePollStorable(poll); err != nil {
+		return Poll{}, err
+	}
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+	poll.Version++
+	p.invalidate(id)
+
+	return poll, nil
+}
+
+// optionIndex returns where the option with optionId is in options, or -1
+// if it is not there
+func optionIndex(options []pollOption, optionId uint) int {
+	for i, option := range options {
+		if option.PollOptionID == optionId {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// marshalOrdered marshals v, which must encode as a JSON object, with the
+// first field at the start and the last field at the end.  The fields in
+// between keep the order encoding/json gives them, which is the order
+// they are declared in.  v must not have a MarshalJSON method itself or
+// this would never return, callers pass a plain copy of their type
+func marshalOrdered(v any, first string, last string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	//Split the object into its fields without decoding the values
+	type field struct {
+		key string
+		val json.RawMessage
+	}
+	var fields []field
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field{key: tok.(string), val: val})
+	}
+
+	rank := func(key string) int {
+		switch key {
+		case first:
+			return 0
+		case last:
+			return 2
+		}
+		return 1
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return rank(fields[i].key) < rank(fields[j].key)
+	})
+
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(key)
+		out.WriteByte(':')
+		out.Write(f.val)
+	}
+	out.WriteByte('}')
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasscodeLength and MaxPasscodeLength bound how long a poll's passcode
+// can be, bcrypt only uses the first 72 bytes
+const (
+	MinPasscodeLength = 4
+	MaxPasscodeLength = 72
+)
+
+// PasscodeHeader is the header a vote for a poll with a passcode has to
+// send it in, and that GET /polls/:id/passcode checks
+const PasscodeHeader = "X-Poll-Passcode"
+
+// PasscodeKeyPrefix is the prefix of the keys the passcode hashes are
+// kept under, poll-passcode:<number>.  They are kept out of the poll so
+// that the hash is never sent with it
+const PasscodeKeyPrefix = "poll-passcode:"
+
+// ErrInvalidPasscode is returned when a poll is given a passcode that is
+// too short or too long
+var ErrInvalidPasscode = fmt.Errorf("passcode must be %d to %d characters", MinPasscodeLength, MaxPasscodeLength)
+
+func passcodeKeyFromId(id uint) string {
+	return fmt.Sprintf("%s%d", PasscodeKeyPrefix, id)
+}
+
+// hashPasscode returns the bcrypt hash of passcode, or "" if there is no
+// passcode
+func hashPasscode(passcode string) (string, error) {
+	if passcode == "" {
+		return "", nil
+	}
+	if len(passcode) < MinPasscodeLength || len(passcode) > MaxPasscodeLength {
+		return "", ErrInvalidPasscode
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(passcode), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// setPasscodeHash keeps the hash of the passcode of the poll with id.  It
+// is written after the poll, a poll whose Settings.HasPasscode is set
+// before its hash is takes no passcode at all until it is, so it never
+// takes votes it should not
+func (p *PollList) setPasscodeHash(id uint, hash string) error {
+	return p.cacheClient.Set(p.context, passcodeKeyFromId(id), hash, 0).Err()
+}
+
+// CheckPasscode accepts a poll id and a passcode and reports whether a
+// vote for the poll with that passcode may be taken.  Any passcode, or
+// none, is right for a poll without one.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) true will be returned if the poll has no passcode or
+//			passcode is its passcode
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) CheckPasscode(id uint, passcode string) (bool, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return false, notFoundOr(err, "poll does not exist")
+	}
+	if !poll.Settings.HasPasscode {
+		return true, nil
+	}
+	if passcode == "" {
+		return false, nil
+	}
+	if err := p.needsRedis(); err != nil {
+		return false, err
+	}
+
+	hash, err := p.cacheClient.Get(p.context, passcodeKeyFromId(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		//The hash has not been written yet, see setPasscodeHash
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(passcode)) == nil, nil
+}
+
+// RemovePasscode accepts a poll id and takes the passcode off the poll,
+// so votes for it no longer need one.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The poll will have no passcode and be returned, removing
+//			the passcode of a poll that has none is not an error
+//		(2) If there is an error, it will be returned
+func (p *PollList) RemovePasscode(id uint) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	if poll.Settings.HasPasscode {
+		poll.Settings.HasPasscode = false
+		if err := p.putVersion(poll, poll.Version); err != nil {
+			return Poll{}, notFoundOr(err, "poll does not exist")
+		}
+		poll.Version++
+		p.invalidate(id)
+	}
+	//Without redis no poll can have been given a passcode
+	if p.needsRedis() == nil {
+		if err := p.cacheClient.Del(p.context, passcodeKeyFromId(id)).Err(); err != nil {
+			return Poll{}, err
+		}
+	}
+
+	return poll, nil
+}
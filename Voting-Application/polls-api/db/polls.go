@@ -1,366 +1,1226 @@
-package db
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"time"
-	"log"
-	"os"
-
-	"github.com/go-redis/redis/v8"
-	"github.com/nitishm/go-rejson/v4"
-)
-
-type pollOption struct {
-	PollOptionID    uint
-	PollOptionText string
-}
-  
-type Poll struct {
-	PollID			uint
-	PollTitle		string
-	PollQuestion	string
-	PollOptions		[]pollOption
-	Links 			[]string
-}
-
-const (
-	RedisNilError        = "redis: nil"
-	RedisDefaultLocation = "0.0.0.0:6379"
-	RedisKeyPrefix       = "polls:"
-)
-
-type cache struct {
-	cacheClient *redis.Client
-	jsonHelper  *rejson.Handler
-	context     context.Context
-}
-
-type healthData struct{
-	Uptime time.Duration
-	APIcalls uint
-}
-
-type PollList struct {
-	healthInfo healthData
-	cache
-}
-
-//constructor for PollList struct
-func NewPollList() (*PollList, error) {
-	//We will use an override if the REDIS_URL is provided as an environment
-	//variable, which is the preferred way to wire up a docker container
-	redisUrl := os.Getenv("REDIS_URL")
-	//This handles the default condition
-	if redisUrl == "" {
-		redisUrl = RedisDefaultLocation
-	}
-	return NewWithCacheInstance(redisUrl)
-}
-
-// NewWithCacheInstance is a constructor function that returns a pointer to a new
-// Poll struct.  It accepts a string that represents the location of the redis
-// cache.
-func NewWithCacheInstance(location string) (*PollList, error) {
-
-	//Connect to redis.  Other options can be provided, but the
-	//defaults are OK
-	client := redis.NewClient(&redis.Options{
-		Addr: location,
-	})
-
-	//We use this context to coordinate betwen our go code and
-	//the redis operaitons
-	ctx := context.Background()
-
-	//This is the reccomended way to ensure that our redis connection
-	//is working
-	err := client.Ping(ctx).Err()
-	if err != nil {
-		log.Println("Error connecting to redis" + err.Error())
-		return nil, err
-	}
-
-	//By default, redis manages keys and values, where the values
-	//are either strings, sets, maps, etc.  Redis has an extension
-	//module called ReJSON that allows us to store JSON objects
-	//however, we need a companion library in order to work with it
-	//Below we create an instance of the JSON helper and associate
-	//it with our redis connnection
-	jsonHelper := rejson.NewReJSONHandler()
-	jsonHelper.SetGoRedisClientWithContext(ctx, client)
-
-	//Return a pointer to a new voterList struct
-	pollList := &PollList{
-		healthInfo: healthData{},
-		cache: cache{
-			cacheClient: client,
-			jsonHelper:  jsonHelper,
-			context:     ctx,
-		},
-	}
-	return pollList, nil
-}
-
-//------------------------------------------------------------
-// REDIS HELPERS
-//------------------------------------------------------------
-
-// In redis, our keys will be strings, they will look like
-// polls:<number>.  This function will take an integer and
-// return a string that can be used as a key in redis
-func redisKeyFromId(id uint) string {
-	return fmt.Sprintf("%s%d", RedisKeyPrefix, id)
-}
-
-// Helper to return a VoterList from redis provided a key
-func (p *PollList) getItemFromRedis(key string, poll *Poll) error {
-
-	//Lets query redis for the poll, note we can return parts of the
-	//json structure, the second parameter "." means return the entire
-	//json structure
-	pollObject, err := p.jsonHelper.JSONGet(key, ".")
-	if err != nil {
-		return err
-	}
-
-	//JSONGet returns an "any" object, or empty interface,
-	//we need to convert it to a byte array, which is the
-	//underlying type of the object, then we can unmarshal
-	//it into our voter struct
-	err = json.Unmarshal(pollObject.([]byte), poll)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-//------------------------------------------------------------
-// THESE ARE THE PUBLIC FUNCTIONS THAT SUPPORT OUR POLL APP
-//------------------------------------------------------------
-
-// AddPoll accepts a Poll and adds it to the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The poll must not already exist in the DB
-//	    				because we use the poll.PollID as the key, this
-//						function must check if the poll already
-//	    				exists in the DB, if so, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be added to the DB
-//		(2) The DB file will be saved with the poll added
-//		(3) If there is an error, it will be returned
-func (p *PollList) AddPoll(poll Poll) error {
-
-	//Before we add an poll to the DB, lets make sure
-	//it does not exist, if it does, return an error
-	redisKey := redisKeyFromId(poll.PollID)
-	var existingPoll Poll
-	if err := p.getItemFromRedis(redisKey, &existingPoll); err == nil {
-		return errors.New("poll already exists")
-	}
-
-	//Add poll to database with JSON Set
-	poll.Links = []string{"GET All Polls: 1090/polls/", "POST Poll: 1090/polls/:id", "DELETE All Polls: 1090/polls", "DELETE Poll: 1090/polls/:id","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id"}
-	if _, err := p.jsonHelper.JSONSet(redisKey, ".", poll); err != nil {
-		return err
-	}
-
-	//If everything is ok, return nil for the error
-	return nil
-}
-
-// DeletePoll accepts a poll id and removes it from the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The poll must exist in the DB
-//	    				because we use the poll.PollID as the key, this
-//						function must check if the poll already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be removed from the DB
-//		(2) The DB file will be saved with the poll removed
-//		(3) If there is an error, it will be returned
-func (p *PollList) DeletePoll(id uint) error {
-
-	pattern := redisKeyFromId(id)
-	numDeleted, err := p.cacheClient.Del(p.context, pattern).Result()
-	if err != nil {
-		return err
-	}
-	if numDeleted == 0 {
-		return errors.New("poll does not exist")
-	}
-
-	return nil
-}
-
-// DeleteAllPolls removes all polls from the DB.
-// It will be exposed via a DELETE /polls endpoint
-func (p *PollList) DeleteAllPolls() error {
-
-	pattern := RedisKeyPrefix + "*"
-	ks, _ := p.cacheClient.Keys(p.context, pattern).Result()
-	//Note delete can take a collection of keys.  In go we can
-	//expand a slice into individual arguments by using the ...
-	//operator
-	numDeleted, err := p.cacheClient.Del(p.context, ks...).Result()
-	if err != nil {
-		return err
-	}
-
-	if numDeleted != int64(len(ks)) {
-		return errors.New("one or more polls could not be deleted")
-	}
-
-	return nil
-}
-
-// UpdatePoll accepts a Poll and updates it in the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The poll must exist in the DB
-//	    				because we use the poll.PollID as the key, this
-//						function must check if the poll already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be updated in the DB
-//		(2) The DB file will be saved with the poll updated
-//		(3) If there is an error, it will be returned
-func (p *PollList) UpdatePoll(poll Poll) error {
-
-	// Check if poll exists before trying to update it
-	// this is a good practice, return an error if the
-	// poll does not exist
-	redisKey := redisKeyFromId(poll.PollID)
-	var existingPoll Poll
-	if err := p.getItemFromRedis(redisKey, &existingPoll); err != nil {
-		return errors.New("poll does not exist")
-	}
-
-	//Add poll to database with JSON Set.  Note there is no update
-	//functionality, so we just overwrite the existing poll
-	poll.Links = []string{"GET All Polls: 1090/polls/", "POST Poll: 1090/polls/:id", "DELETE All Polls: 1090/polls", "DELETE Poll: 1090/polls/:id","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id"}
-	if _, err := p.jsonHelper.JSONSet(redisKey, ".", poll); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// GetPoll accepts a poll id and returns the poll from the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The poll must exist in the DB
-//	    				because we use the poll.PollID as the key, this
-//						function must check if the poll already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be returned, if it exists
-//		(2) If there is an error, it will be returned
-//			along with an empty Poll
-//		(3) The database file will not be modified
-func (p *PollList) GetPoll(id uint) (Poll, error) {
-
-	// Check if poll exists before trying to get it
-	// this is a good practice, return an error if the
-	// poll does not exist
-	var poll Poll
-	pattern := redisKeyFromId(id)
-	err := p.getItemFromRedis(pattern, &poll)
-	if err != nil {
-		return Poll{}, errors.New("poll does not exist")
-	}
-
-	return poll, nil
-}
-
-// GetAllPolls returns all polls from the DB.  If successful it
-// returns a slice of all of the polls to the caller
-// Preconditions:   (1) The database file must exist and be a valid
-//
-// Postconditions:
-//
-//	    (1) All polls will be returned, if any exist
-//		(2) If there is an error, it will be returned
-//			along with an empty slice
-//		(3) The database file will not be modified
-func (p *PollList) GetAllPolls() ([]Poll, error) {
-
-	//Now that we have the DB loaded, lets crate a slice
-	var pollList []Poll
-	var poll Poll
-
-	//Lets query redis for all of the items
-	pattern := RedisKeyPrefix + "*"
-	ks, _ := p.cacheClient.Keys(p.context, pattern).Result()
-	for _, key := range ks {
-		err := p.getItemFromRedis(key, &poll)
-		if err != nil {
-			return nil, err
-		}
-		pollList = append(pollList, poll)
-	}
-
-	if len(pollList) < 1 {
-		pollList = append(pollList, Poll{
-			PollID: 0,
-			PollTitle: "",
-			PollQuestion: "",
-			PollOptions: []pollOption{},
-			Links: []string{"GET All Polls: 1090/polls/", "POST Poll: 1090/polls/:id", "DELETE All Polls: 1090/polls", "DELETE Poll: 1090/polls/:id","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id"},
-		})
-	}
-
-	//Now that we have all of our polls in a slice, return it
-	return pollList, nil
-}
-
-// PrintPoll accepts a Poll and prints it to the console
-// in a JSON pretty format. As some help, look at the
-// json.MarshalIndent() function from our in class go tutorial.
-func (p *PollList) PrintPoll(poll Poll) {
-	jsonBytes, _ := json.MarshalIndent(poll, "", "  ")
-	fmt.Println(string(jsonBytes))
-}
-
-// PrintAllPolls accepts a slice of Polls and prints them to the console
-// in a JSON pretty format.  It should call PrintPoll() to print each poll
-// versus repeating the code.
-func (p *PollList) PrintAllPolls(pollList []Poll) {
-	for _, poll := range pollList {
-		p.PrintPoll(poll)
-	}
-}
-
-// JsonToPoll accepts a json string and returns a Poll
-// This is helpful because the CLI accepts polls for insertion
-// and updates in JSON format.  We need to convert it to a Poll
-// struct to perform any operations on it.
-func (p *PollList) JsonToPoll(jsonString string) (Poll, error) {
-	var poll Poll
-	err := json.Unmarshal([]byte(jsonString), &poll)
-	if err != nil {
-		return Poll{}, err
-	}
-
-	return poll, nil
-}
-
-func (p *PollList) GetHealthData(bootTime time.Time, calls uint) (healthData, error){
-
-	p.healthInfo = healthData{Uptime: time.Now().Sub(bootTime), APIcalls: calls}
-
-	return p.healthInfo, nil
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nitishm/go-rejson/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+type pollOption struct {
+	PollOptionID    uint
+	PollOptionText string `binding:"required"`
+}
+
+// AddOption adds an option to the end of the poll's options, for callers
+// outside this package building a poll that did not come from JSON
+func (p *Poll) AddOption(optionId uint, text string) {
+	p.PollOptions = append(p.PollOptions, pollOption{PollOptionID: optionId, PollOptionText: text})
+}
+  
+type Poll struct {
+	PollID			uint
+	PollTitle		string `binding:"required"`
+	PollQuestion	string
+	PollOptions		[]pollOption `binding:"min=2,dive"`
+	Settings		PollSettings
+	//Tags group related polls, such as the polls of one election, see
+	//tags.go
+	Tags			[]string `json:",omitempty"`
+	//Passcode is only ever sent to the API, to give the poll a passcode
+	//votes must have or change it.  It is never stored or sent back, the
+	//hash is kept apart from the poll, see passcode.go
+	Passcode		string `json:",omitempty"`
+	//Status is where the poll is in its lifecycle, draft, open or
+	//closed.  It is worked out from Settings whenever the poll is
+	//written, see lifecycle.go, so whatever it is set to is ignored
+	Status			string
+	//Version goes up by one with every change, see versions.go
+	Version			uint
+	Links 			[]string
+}
+
+// MarshalJSON writes a poll with PollID first and Links last, whatever
+// order the fields end up declared in, so that clients can rely on that
+// order.  Status is filled in for the time it is written at.
+// Unmarshalling is the default and takes the fields in any order
+func (p Poll) MarshalJSON() ([]byte, error) {
+	type plainPoll Poll
+	p.Status = p.Settings.State(time.Now())
+	return marshalOrdered(plainPoll(p), "PollID", "Links")
+}
+
+// PollSettings groups the optional behaviour of a poll so that the top
+// level of Poll only describes the poll itself.  Any setting left out
+// gets a default, see withDefaults
+type PollSettings struct {
+	//Status is draft or published, new polls are drafts
+	Status string
+	//VoteRateLimit caps how many votes the poll accepts per rate
+	//window in the votes API, 0 uses the votes API default
+	VoteRateLimit uint
+	//OpensAt is when the poll starts accepting votes, null means as
+	//soon as it is published.  A draft with an OpensAt opens by itself
+	//then, without being published, see schedule.go
+	OpensAt *time.Time
+	//ClosesAt is when the poll stops accepting votes, null means the
+	//poll stays open
+	ClosesAt *time.Time
+	//ResultsPublished is set once the results of a closed poll have
+	//been announced, the poll can then only be reopened with force
+	ResultsPublished bool
+	//Archived polls are left out of the poll list by default and take
+	//no votes, it is only changed by ArchivePoll and UnarchivePoll
+	Archived bool
+	//VotingMethod is how the votes are counted, plurality or
+	//instant-runoff, see runoff.go
+	VotingMethod string
+	//MaxSelections is how many options a vote can select, 0 and 1 both
+	//mean a single choice
+	MaxSelections uint
+	//AllowWriteIns lets a vote give an answer of its own instead of
+	//one of the options, see writeins.go
+	AllowWriteIns bool
+	//HasPasscode is set while the poll has a passcode, so the votes API
+	//knows to check it.  It is only changed by giving the poll a
+	//Passcode and by RemovePasscode
+	HasPasscode bool
+	//InviteOnly is set while the poll only takes votes from voters it
+	//has invited, see invitations.go.  It is only changed by
+	//CreateInvitations and RemoveInvitations
+	InviteOnly bool
+	//Anonymous polls don't keep who cast each vote, the votes API
+	//keeps a salted hash of the voter instead, see anonymous.go.  It
+	//can't change once the poll has votes
+	Anonymous bool
+}
+
+// PollSummary is just the id and title of a poll, for clients that
+// don't need the whole poll such as a dropdown
+type PollSummary struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+const (
+	RedisNilError        = "redis: nil"
+	RedisDefaultLocation = "0.0.0.0:6379"
+	RedisKeyPrefix       = "polls:"
+)
+
+// ErrNotFound is returned when a poll is not in redis.  Any other error
+// means redis could not be reached or sent back something we can't read,
+// so callers should not report those as a missing poll
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyExists is returned when a poll is added but is already there
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrConflict is returned when a change to a poll can't be made in the
+// state the poll is in.  The more specific errors below are all
+// ErrConflict as well, so callers can check for either
+var ErrConflict = errors.New("conflict")
+
+// conflict is an error that is also ErrConflict, without "conflict" in
+// its message, which clients are shown
+type conflict string
+
+func (e conflict) Error() string { return string(e) }
+
+func (e conflict) Is(target error) bool { return target == ErrConflict }
+
+// ErrOptionsLocked is returned when changing the options of a poll that
+// has already been voted on
+var ErrOptionsLocked error = conflict("poll has votes, options are locked")
+
+// ErrPollNotClosed is returned when reopening a poll that is not closed
+var ErrPollNotClosed error = conflict("poll is not closed")
+
+// ErrResultsPublished is returned when reopening a poll whose results
+// have already been published, without forcing it
+var ErrResultsPublished error = conflict("poll results have already been published")
+
+// ErrInvalidCloseTime is returned when a poll is given a closing time
+// that has already passed
+var ErrInvalidCloseTime = errors.New("closing time must be in the future")
+
+// ErrInvalidSchedule is returned when a poll is given an opening time that
+// is not before its closing time
+var ErrInvalidSchedule = errors.New("opening time must be before the closing time")
+
+// A poll starts out as a draft, drafts are hidden from the poll list by
+// default and cannot be voted on until the poll is published
+const (
+	PollStatusDraft     = "draft"
+	PollStatusPublished = "published"
+)
+
+type cache struct {
+	cacheClient *redis.Client
+	jsonHelper  *rejson.Handler
+	context     context.Context
+	//scanBatch is the COUNT hint each SCAN sends, see scan.go
+	scanBatch int64
+}
+
+// HealthData is the health record returned by the health endpoint
+type HealthData struct{
+	Uptime time.Duration
+	APIcalls uint
+	//RedisLatency is how long a PING to the store took, the name is kept
+	//from when redis was the only store
+	RedisLatency time.Duration
+	//Caches has the hits, misses and hit ratio of each cache
+	Caches map[string]CacheStats
+}
+
+type PollList struct {
+	healthInfo HealthData
+	//store is where the polls are kept, see store.go.  Everything else
+	//is kept in the redis of cache, which has no client when the polls
+	//are kept somewhere else
+	store pollStore
+	//localCache holds recently read polls so GetPoll does not always
+	//have to go to redis, see lru.go
+	localCache *pollLRU
+	//pollReads lets concurrent GetPoll calls for the same poll share one
+	//redis lookup, it is nil when that is turned off
+	pollReads *singleflight.Group
+	//cacheCounters has the hits and misses of each cache, by name
+	cacheCounters map[string]*cacheCounter
+	//voteWatchers are told when the votes of a poll change, see live.go
+	voteWatchers *voteWatchers
+	//idScheme is how AddPollNewID picks ids, see ids.go
+	idScheme string
+	//deletePolicy is what DeletePoll does with a poll that has votes,
+	//and refs the services a cascading delete goes to, see refs.go
+	deletePolicy string
+	refs         refServices
+	//ballotLinkSecret signs ballot links, they are off when it is nil,
+	//see ballotlinks.go
+	ballotLinkSecret []byte
+	//retention is how long closed polls are kept, 0 for forever, see
+	//retention.go
+	retention time.Duration
+	cache
+}
+
+//constructor for PollList struct
+func NewPollList() (*PollList, error) {
+	//We will use an override if the REDIS_URL is provided as an environment
+	//variable, which is the preferred way to wire up a docker container
+	redisUrl := os.Getenv("REDIS_URL")
+	//This handles the default condition
+	if redisUrl == "" {
+		redisUrl = RedisDefaultLocation
+	}
+	pollList, err := NewWithCacheInstance(redisUrl)
+	if err != nil {
+		return nil, err
+	}
+	pollList.startFromEnv()
+
+	return pollList, nil
+}
+
+// startFromEnv applies the settings in the environment to a new
+// PollList, starts its background work and then seeds it, whichever
+// store it keeps its polls in
+func (pollList *PollList) startFromEnv() {
+
+	//The size of the local poll cache can also be overridden with an
+	//environment variable, 0 turns the cache off
+	if size := os.Getenv("POLL_CACHE_SIZE"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 0 {
+			log.Println("Invalid POLL_CACHE_SIZE, using default: ", size)
+		} else {
+			pollList.localCache.resize(n)
+		}
+	}
+
+	//Sharing concurrent reads of the same poll is on by default, it
+	//can be turned off if it ever gets in the way
+	if dedupe := os.Getenv("POLL_DEDUPE_READS"); dedupe != "" {
+		b, err := strconv.ParseBool(dedupe)
+		if err != nil {
+			log.Println("Invalid POLL_DEDUPE_READS, using default: ", dedupe)
+		} else if !b {
+			pollList.pollReads = nil
+		}
+	}
+
+	//Drafts with an opening time are published once it passes, every
+	//DefaultScheduleInterval unless POLL_SCHEDULE_INTERVAL is set to
+	//another go duration string, "0" turns it off
+	schedule := DefaultScheduleInterval
+	if interval := os.Getenv("POLL_SCHEDULE_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < 0 {
+			log.Println("Invalid POLL_SCHEDULE_INTERVAL, using default: ", interval)
+		} else {
+			schedule = d
+		}
+	}
+	if schedule > 0 {
+		pollList.watchSchedule(schedule)
+	}
+
+	//Closed polls are kept forever unless POLL_RETENTION is set, see
+	//retention.go
+	if retention, ok := retentionFromEnv(); ok {
+		pollList.retention = retention
+		pollList.watchRetention(retentionIntervalFromEnv())
+	}
+
+	//Seed last, so that the settings above are in place
+	pollList.seedFromEnv()
+}
+
+// newPollList returns a PollList that keeps its polls in store and
+// everything else in the redis of c, if it has a client
+func newPollList(store pollStore, c cache) *PollList {
+	return &PollList{
+		healthInfo: HealthData{},
+		store:      store,
+		cache:      c,
+		localCache: newPollLRU(DefaultPollCacheSize),
+		pollReads:  &singleflight.Group{},
+		cacheCounters: map[string]*cacheCounter{
+			PollCacheName: {},
+		},
+		voteWatchers: newVoteWatchers(),
+		idScheme:     idSchemeFromEnv(),
+		deletePolicy: deletePolicyFromEnv(),
+		refs:         refServicesFromEnv(),
+
+		ballotLinkSecret: ballotLinkSecretFromEnv(),
+	}
+}
+
+// NewWithCacheInstance is a constructor function that returns a pointer to a new
+// Poll struct.  It accepts a string that represents the location of the redis
+// cache.
+func NewWithCacheInstance(location string) (*PollList, error) {
+
+	//Connect to redis.  Other options can be provided, but the
+	//defaults are OK
+	client := redis.NewClient(&redis.Options{
+		Addr: location,
+	})
+
+	//We use this context to coordinate betwen our go code and
+	//the redis operaitons
+	ctx := context.Background()
+
+	//This is the reccomended way to ensure that our redis connection
+	//is working
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		log.Println("Error connecting to redis" + err.Error())
+		return nil, err
+	}
+
+	//By default, redis manages keys and values, where the values
+	//are either strings, sets, maps, etc.  Redis has an extension
+	//module called ReJSON that allows us to store JSON objects
+	//however, we need a companion library in order to work with it
+	//Below we create an instance of the JSON helper and associate
+	//it with our redis connnection
+	jsonHelper := rejson.NewReJSONHandler()
+	jsonHelper.SetGoRedisClientWithContext(ctx, client)
+
+	//Return a pointer to a new pollList struct, keeping the polls in
+	//the same redis as everything else
+	c := cache{
+		cacheClient: client,
+		jsonHelper:  jsonHelper,
+		context:     ctx,
+		scanBatch:   scanBatchSizeFromEnv(),
+	}
+	pollList := newPollList(&redisStore{cache: c}, c)
+
+	//Other replicas publish the ids of polls they change, listen for
+	//them so that we don't keep serving the old poll from our cache
+	if err := pollList.subscribeInvalidations(); err != nil {
+		log.Println("Error subscribing to poll cache invalidations" + err.Error())
+		return nil, err
+	}
+
+	//The votes API publishes the ids of polls whose votes change, so
+	//that streamed results can be refreshed
+	if err := pollList.subscribeVoteChanges(); err != nil {
+		log.Println("Error subscribing to vote changes" + err.Error())
+		return nil, err
+	}
+
+	//Without RediSearch everything but GET /polls/search still works
+	if err := c.createSearchIndex(); err != nil {
+		log.Println("Error creating the poll search index: ", err)
+	}
+	return pollList, nil
+}
+
+//------------------------------------------------------------
+// REDIS HELPERS
+//------------------------------------------------------------
+
+// notFoundOr returns a not found error saying msg if err is ErrNotFound,
+// otherwise err is a problem with redis and is passed back as it is
+func notFoundOr(err error, msg string) error {
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, msg)
+	}
+	return err
+}
+
+// In redis, our keys will be strings, they will look like
+// polls:<number>.  This function will take an integer and
+// return a string that can be used as a key in redis
+func redisKeyFromId(id uint) string {
+	return fmt.Sprintf("%s%d", RedisKeyPrefix, id)
+}
+
+// putVersion writes poll over the stored poll it was read as, at
+// version, giving it the next version.  If the poll has changed since it
+// was read ErrVersionMismatch is returned and nothing is written
+func (p *PollList) putVersion(poll Poll, version uint) error {
+	poll.Version = version + 1
+	return p.store.putVersion(poll, version)
+}
+
+//------------------------------------------------------------
+// THESE ARE THE PUBLIC FUNCTIONS THAT SUPPORT OUR POLL APP
+//------------------------------------------------------------
+
+// AddPoll accepts a Poll and adds it to the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must not already exist in the DB
+//	    				because we use the poll.PollID as the key, this
+//						function must check if the poll already
+//	    				exists in the DB, if so, return an error
+//
+// Postconditions:
+//
+//	    (1) The poll will be added to the DB
+//		(2) The DB file will be saved with the poll added
+//		(3) If there is an error, it will be returned
+func (p *PollList) AddPoll(poll Poll) error {
+
+	//Before we add an poll to the DB, lets make sure
+	//it does not exist, if it does, return an error
+	if _, err := p.store.get(poll.PollID); err == nil {
+		return fmt.Errorf("%w: poll already exists", ErrAlreadyExists)
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	//New polls are drafts unless told otherwise
+	poll.Settings = poll.Settings.withDefaults(PollSettings{Status: PollStatusDraft})
+	poll.Settings.Archived = false
+	poll.Settings.InviteOnly = false
+	if err := poll.Settings.validate(); err != nil {
+		return err
+	}
+	tags, err := normalizeTags(poll.Tags)
+	if err != nil {
+		return err
+	}
+	poll.Tags = tags
+	hash, err := hashPasscode(poll.Passcode)
+	if err != nil {
+		return err
+	}
+	if hash != "" {
+		if err := p.needsRedis(); err != nil {
+			return err
+		}
+	}
+	poll.Settings.HasPasscode = hash != ""
+	poll.Passcode = ""
+
+	//Add poll to database with JSON Set
+	poll.Links = []string{"GET All Polls: 1090/polls/", "POST Poll: 1090/polls/:id", "DELETE All Polls: 1090/polls", "DELETE Poll: 1090/polls/:id","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id"}
+	poll.Version = 1
+	if err := validatePollStorable(poll); err != nil {
+		return err
+	}
+	//The check above turns most duplicates away early, create catches
+	//one added since
+	if err := p.store.create(poll); err != nil {
+		if errors.Is(err, ErrAlreadyExists) {
+			return fmt.Errorf("%w: poll already exists", err)
+		}
+		return err
+	}
+	if hash != "" {
+		if err := p.setPasscodeHash(poll.PollID, hash); err != nil {
+			return err
+		}
+	}
+
+	//If everything is ok, return nil for the error
+	return nil
+}
+
+// DeletePoll accepts a poll id and removes it from the DB.  What
+// happens to the votes of a poll that has been voted on depends on
+// POLL_DELETE_POLICY, see refs.go
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//	    				because we use the poll.PollID as the key, this
+//						function must check if the poll already
+//	    				exists in the DB, if not, return an error
+//
+//					(3) With the block policy, nobody may have voted
+//						in the poll
+//
+// Postconditions:
+//
+//	    (1) The poll will be removed from the DB, with the cascade
+//			policy after its votes and VoteHistory entries were
+//			removed from the votes and voters APIs
+//		(2) The DB file will be saved with the poll removed
+//		(3) With the block policy, if the poll has votes
+//			ErrPollHasVotes is returned and nothing is deleted
+//		(4) With the cascade policy, if the votes or voters API
+//			can't be reached ErrDependencyUnavailable is returned and
+//			the poll is not deleted, though some of its votes may be
+//		(5) If there is an error, it will be returned
+func (p *PollList) DeletePoll(id uint) error {
+
+	//The poll is read rather than just checked for, its tags have to
+	//come out of the tag index with it
+	if _, err := p.store.get(id); err != nil {
+		return notFoundOr(err, "poll does not exist")
+	}
+	//The check and the delete are not one step, a vote cast in between
+	//is left behind
+	if err := p.clearRefs(id); err != nil {
+		return err
+	}
+
+	if err := p.store.delete(id); err != nil {
+		return notFoundOr(err, "poll does not exist")
+	}
+	if p.needsRedis() == nil {
+		if err := p.cacheClient.Del(p.context, passcodeKeyFromId(id)).Err(); err != nil {
+			log.Println("Error deleting passcode of poll ", id, ": ", err)
+		}
+		if err := p.cacheClient.Del(p.context, invitationKeyFromId(id)).Err(); err != nil {
+			log.Println("Error deleting invitations of poll ", id, ": ", err)
+		}
+	}
+	p.invalidate(id)
+
+	return nil
+}
+
+// DeleteAllPolls removes all polls from the DB.
+// It will be exposed via a DELETE /polls endpoint
+func (p *PollList) DeleteAllPolls() error {
+
+	//Some polls may have been deleted even if not all of them were,
+	//so drop every cached poll either way
+	err := p.store.deleteAll()
+	p.invalidateAll()
+	if err != nil {
+		return err
+	}
+	if p.needsRedis() != nil {
+		return nil
+	}
+
+	//With every poll gone so is every passcode, invitation and used
+	//ballot link
+	for _, prefix := range []string{PasscodeKeyPrefix, InvitationKeyPrefix, BallotLinkUsedKeyPrefix} {
+		extraKeys, err := p.scanKeys(prefix + "*")
+		if err != nil {
+			return err
+		}
+		if len(extraKeys) > 0 {
+			if err := p.cacheClient.Del(p.context, extraKeys...).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// UpdatePoll accepts a Poll and updates it in the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//	    				because we use the poll.PollID as the key, this
+//						function must check if the poll already
+//	    				exists in the DB, if not, return an error
+//
+//					(3) The options must not change if the poll has
+//						votes, unless force is true
+//
+//					(4) The closing time and ResultsPublished must not
+//						change if the poll has closed at now, unless
+//						force is true
+//
+// Postconditions:
+//
+//	    (1) The poll will be updated in the DB
+//		(2) The DB file will be saved with the poll updated
+//		(3) If the options change on a poll with votes and force is
+//			false, ErrOptionsLocked is returned, if Settings.Anonymous
+//			changes on a poll with votes ErrAnonymousLocked, and if a
+//			closed poll would be reopened or its results unpublished
+//			ErrPollClosed
+//		(4) If there is an error, it will be returned
+func (p *PollList) UpdatePoll(poll Poll, force bool, now time.Time) error {
+
+	// Check if poll exists before trying to update it
+	// this is a good practice, return an error if the
+	// poll does not exist
+	existingPoll, err := p.store.get(poll.PollID)
+	if err != nil {
+		return notFoundOr(err, "poll does not exist")
+	}
+	//A poll sent with no Version overwrites whatever is stored
+	if poll.Version != 0 && poll.Version != existingPoll.Version {
+		return versionMismatch(poll.Version, existingPoll.Version)
+	}
+
+	//An update that leaves out the status keeps the current one, use
+	//PublishPoll to move a poll out of draft
+	poll.Settings = poll.Settings.withDefaults(existingPoll.Settings)
+	poll.Settings.Archived = existingPoll.Settings.Archived
+	if err := poll.Settings.validate(); err != nil {
+		return err
+	}
+	if err := checkClosedChange(existingPoll.Settings, poll.Settings, force, now); err != nil {
+		return err
+	}
+	tags, err := normalizeTags(poll.Tags)
+	if err != nil {
+		return err
+	}
+	poll.Tags = tags
+	//The passcode can't be read back, so a poll sent without one keeps
+	//the one it has
+	hash, err := hashPasscode(poll.Passcode)
+	if err != nil {
+		return err
+	}
+	if hash != "" {
+		if err := p.needsRedis(); err != nil {
+			return err
+		}
+	}
+	poll.Settings.HasPasscode = existingPoll.Settings.HasPasscode || hash != ""
+	poll.Settings.InviteOnly = existingPoll.Settings.InviteOnly
+	poll.Passcode = ""
+	if err := p.checkAnonymousChange(poll.PollID, existingPoll.Settings.Anonymous, poll.Settings.Anonymous); err != nil {
+		return err
+	}
+
+	//Once anyone has voted the options are what they voted for, so they
+	//can't change unless forced.  Results are tallied from the votes on
+	//every read, so a forced change is reflected in them straight away
+	if !force && !sameOptions(existingPoll.PollOptions, poll.PollOptions) {
+		voted, err := p.hasVotes(poll.PollID)
+		if err != nil {
+			return err
+		}
+		if voted {
+			return ErrOptionsLocked
+		}
+	}
+	if force && !sameOptions(existingPoll.PollOptions, poll.PollOptions) {
+		log.Println("Options of poll ", poll.PollID, " changed with force, votes for removed options are no longer counted")
+	}
+
+	//Overwrite the existing poll, as long as it has not changed since
+	//it was read above
+	poll.Links = []string{"GET All Polls: 1090/polls/", "POST Poll: 1090/polls/:id", "DELETE All Polls: 1090/polls", "DELETE Poll: 1090/polls/:id","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id"}
+	poll.Version = existingPoll.Version + 1
+	if err := validatePollStorable(poll); err != nil {
+		return err
+	}
+	if err := p.putVersion(poll, existingPoll.Version); err != nil {
+		return notFoundOr(err, "poll does not exist")
+	}
+	p.invalidate(poll.PollID)
+	if hash != "" {
+		if err := p.setPasscodeHash(poll.PollID, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetPoll accepts a poll id and returns the poll from the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//	    				because we use the poll.PollID as the key, this
+//						function must check if the poll already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The poll will be returned, if it exists
+//		(2) If there is an error, it will be returned
+//			along with an empty Poll
+//		(3) The database file will not be modified
+func (p *PollList) GetPoll(id uint) (Poll, error) {
+
+	//Serve the poll from the local cache if we have it, updates on any
+	//replica evict it so it is never older than the last change.  Redis
+	//purging a closed poll evicts nothing, so one whose time has come is
+	//read again, see retention.go
+	if poll, ok := p.localCache.get(id); ok {
+		now := time.Now()
+		if purgeAt := poll.Settings.PurgeAt(p.retention, now); purgeAt == nil || now.Before(*purgeAt) {
+			p.cacheCounters[PollCacheName].hit()
+			return poll, nil
+		}
+		p.localCache.evict(id)
+	}
+	p.cacheCounters[PollCacheName].miss()
+
+	//When a hot poll is not cached, many requests for it can arrive at
+	//once.  Only the first goes to redis, the rest wait for its answer
+	if p.pollReads == nil {
+		return p.loadPoll(id)
+	}
+	result, err, _ := p.pollReads.Do(strconv.FormatUint(uint64(id), 10), func() (any, error) {
+		return p.loadPoll(id)
+	})
+	if err != nil {
+		return Poll{}, err
+	}
+	//Every caller gets the same poll back, so hand each one a copy
+	return clonePoll(result.(Poll)), nil
+}
+
+// loadPoll reads a poll from the store and puts it in the local cache
+func (p *PollList) loadPoll(id uint) (Poll, error) {
+
+	// Check if poll exists before trying to get it
+	// this is a good practice, return an error if the
+	// poll does not exist
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+	p.localCache.put(poll)
+
+	return poll, nil
+}
+
+// GetAllPolls returns the polls in the DB that match filter, in the
+// order order asks for.  If successful it returns a slice of the polls to
+// the caller.  Draft polls are only included if the filter asks for them,
+// see PollFilter
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) filter must be valid, see PollFilter.Validate
+//
+//					(3) order must be valid, see PollSort.Validate
+//
+// Postconditions:
+//
+//	    (1) The matching polls will be returned, sorted, if any
+//			exist
+//		(2) If there is an error, it will be returned
+//			along with an empty slice
+//		(3) The database file will not be modified
+func (p *PollList) GetAllPolls(filter PollFilter, order PollSort) ([]Poll, error) {
+
+	//Now that we have the DB loaded, lets crate a slice
+	var pollList []Poll
+
+	//Lets query the store for all of the items.  With redis the polls
+	//with a tag are in its index, so only they need to be read
+	var polls []Poll
+	var err error
+	if tagged, ok := p.store.(pollTagIndex); ok && filter.Tag != "" {
+		polls, err = tagged.listTagged(normalizeTag(filter.Tag))
+	} else {
+		polls, err = p.store.list()
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, poll := range polls {
+		if !filter.matches(poll) {
+			continue
+		}
+		pollList = append(pollList, poll)
+	}
+	//Redis lists keys in no particular order, sort the whole list before
+	//it is paged so that pages of the list are stable
+	order.sortPolls(pollList)
+
+	//A search that matches nothing is just empty, the placeholder is
+	//only there to show the links of an empty DB
+	if len(pollList) < 1 && filter.isZero() {
+		pollList = append(pollList, Poll{
+			PollID: 0,
+			PollTitle: "",
+			PollQuestion: "",
+			PollOptions: []pollOption{},
+			Links: []string{"GET All Polls: 1090/polls/", "POST Poll: 1090/polls/:id", "DELETE All Polls: 1090/polls", "DELETE Poll: 1090/polls/:id","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id"},
+		})
+	}
+
+	//Now that we have all of our polls in a slice, return it
+	return pollList, nil
+}
+
+// GetPollsPage returns a page of the polls in the DB, starting at
+// cursor, 0 for the first page, for clients that can't wait for every
+// poll at once.  With redis the cursor is a SCAN cursor, other stores
+// page by id.  count is roughly how many polls to return, 0 for
+// SCAN_BATCH_SIZE.  Only the polls that match filter are returned,
+// so a page can hold fewer than the batch that was scanned.  Unlike
+// GetAllPolls the polls are only sorted within the page, and an empty DB
+// gives an empty page.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) cursor must be 0 or a cursor returned by an
+//						earlier call
+//
+// Postconditions:
+//
+//	    (1) The page of polls will be returned, along with the
+//			cursor of the next page, 0 if this was the last page.
+//			A page may be empty, or have more than count polls,
+//			before the last one
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) GetPollsPage(cursor uint64, count int, filter PollFilter) ([]Poll, uint64, error) {
+
+	polls, next, err := p.store.listPage(cursor, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	var pollList []Poll
+	for _, poll := range polls {
+		if !filter.matches(poll) {
+			continue
+		}
+		pollList = append(pollList, poll)
+	}
+	sort.Slice(pollList, func(i, j int) bool {
+		return pollList[i].PollID < pollList[j].PollID
+	})
+
+	return pollList, next, nil
+}
+
+// GetAllPollSummaries returns the id and title of every poll in the DB,
+// sorted by id.  Draft polls are only included if includeDrafts is true.
+// With redis, rather than reading whole polls it asks ReJSON for just the
+// fields it needs from every poll at once, so it is much cheaper than
+// GetAllPolls
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The summaries will be returned, an empty slice if
+//			there are no polls
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) GetAllPollSummaries(includeDrafts bool) ([]PollSummary, error) {
+
+	var summaries []PollSummary
+	if summarizer, ok := p.store.(pollSummarizer); ok {
+		found, err := summarizer.summaries(includeDrafts)
+		if err != nil {
+			return nil, err
+		}
+		summaries = found
+	} else {
+		polls, err := p.store.list()
+		if err != nil {
+			return nil, err
+		}
+		for _, poll := range polls {
+			//Polls stored before there were statuses have none, they
+			//count as published
+			if poll.Settings.Status == PollStatusDraft && !includeDrafts {
+				continue
+			}
+			summaries = append(summaries, PollSummary{ID: poll.PollID, Title: poll.PollTitle})
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ID < summaries[j].ID
+	})
+	if summaries == nil {
+		summaries = []PollSummary{}
+	}
+	return summaries, nil
+}
+
+// summaries reads the id, title and status of every poll with one
+// JSON.MGET each rather than reading the whole polls
+func (r *redisStore) summaries(includeDrafts bool) ([]PollSummary, error) {
+
+	var summaries []PollSummary
+
+	pattern := RedisKeyPrefix + "*"
+	ks, err := r.scanKeys(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(ks) == 0 {
+		return summaries, nil
+	}
+
+	ids, err := r.getPathFromRedis(".PollID", ks)
+	if err != nil {
+		return nil, err
+	}
+	titles, err := r.getPathFromRedis(".PollTitle", ks)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := r.getPathFromRedis(".Settings.Status", ks)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ks {
+		//A nil id means the poll was deleted since we listed the keys
+		if ids[i] == nil {
+			continue
+		}
+		var summary PollSummary
+		if err := json.Unmarshal(ids[i], &summary.ID); err != nil {
+			return nil, err
+		}
+		if titles[i] != nil {
+			if err := json.Unmarshal(titles[i], &summary.Title); err != nil {
+				return nil, err
+			}
+		}
+		//Polls stored before there were statuses have none, they count
+		//as published
+		var status string
+		if statuses[i] != nil {
+			if err := json.Unmarshal(statuses[i], &status); err != nil {
+				return nil, err
+			}
+		}
+		if status == PollStatusDraft && !includeDrafts {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// getPathFromRedis reads one path from each of keys with a single
+// JSON.MGET.  The results line up with keys, a key without the path
+// gives nil
+func (c *cache) getPathFromRedis(path string, keys []string) ([][]byte, error) {
+	res, err := c.jsonHelper.JSONMGet(path, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]byte, len(keys))
+	for i, value := range res.([]interface{}) {
+		if value != nil {
+			values[i] = value.([]byte)
+		}
+	}
+	return values, nil
+}
+
+// PublishPoll accepts a poll id and moves the poll from draft to
+// published so that it is listed and can be voted on.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The poll status will be published, publishing a poll
+//			that is already published is not an error
+//		(2) The published poll will be returned
+//		(3) If there is an error, it will be returned
+func (p *PollList) PublishPoll(id uint) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	//Polls stored before settings existed get the defaults filled in
+	poll.Settings = poll.Settings.withDefaults(PollSettings{})
+	poll.Settings.Status = PollStatusPublished
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+	poll.Version++
+	p.invalidate(id)
+
+	return poll, nil
+}
+
+// ReopenPoll accepts a poll id and opens a closed poll to votes again.
+// The poll stays open until closesAt, or indefinitely if closesAt is nil.
+// now is the time to decide whether the poll is closed at.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB and be closed
+//
+//					(3) The poll's results must not have been published,
+//						unless force is true
+//
+//					(4) closesAt, if given, must be in the future
+//
+// Postconditions:
+//
+//	    (1) The poll will accept votes again, a forced reopen also
+//			clears ResultsPublished as the results are no longer final,
+//			and it will no longer be expiring, see retention.go
+//		(2) The reopened poll will be returned
+//		(3) If the poll is open, scheduled or still a draft,
+//			ErrPollNotClosed is returned, and if its results were
+//			published without force, ErrResultsPublished is returned
+//		(4) If the poll is archived, ErrPollArchived is returned
+//		(5) If there is an error, it will be returned
+func (p *PollList) ReopenPoll(id uint, closesAt *time.Time, force bool, now time.Time) (Poll, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+
+	poll.Settings = poll.Settings.withDefaults(PollSettings{})
+	if poll.Settings.Archived {
+		return Poll{}, ErrPollArchived
+	}
+	if poll.Settings.State(now) != PollStateClosed {
+		return Poll{}, ErrPollNotClosed
+	}
+	if poll.Settings.ResultsPublished && !force {
+		return Poll{}, ErrResultsPublished
+	}
+	if closesAt != nil && !closesAt.After(now) {
+		return Poll{}, ErrInvalidCloseTime
+	}
+
+	poll.Settings.ClosesAt = closesAt
+	poll.Settings.ResultsPublished = false
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return Poll{}, notFoundOr(err, "poll does not exist")
+	}
+	poll.Version++
+	p.invalidate(id)
+	p.keepPoll(id)
+
+	return poll, nil
+}
+
+// GetPollSettings accepts a poll id and returns just the settings of
+// the poll, with defaults filled in for any that were never stored.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The poll settings will be returned, if the poll exists
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) GetPollSettings(id uint) (PollSettings, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return PollSettings{}, notFoundOr(err, "poll does not exist")
+	}
+
+	return poll.Settings.withDefaults(PollSettings{}), nil
+}
+
+// UpdatePollSettings accepts a poll id and new settings and replaces
+// just the settings block of the poll.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The poll settings will be updated, a status that is left
+//			out keeps the current status
+//		(2) The stored settings will be returned
+//		(3) If Anonymous changes and the poll has votes
+//			ErrAnonymousLocked will be returned, and if the poll has
+//			closed at now and the closing time or ResultsPublished
+//			change without force ErrPollClosed, see ReopenPoll
+//		(4) If there is an error, it will be returned
+func (p *PollList) UpdatePollSettings(id uint, settings PollSettings, force bool, now time.Time) (PollSettings, error) {
+
+	poll, err := p.store.get(id)
+	if err != nil {
+		return PollSettings{}, notFoundOr(err, "poll does not exist")
+	}
+
+	settings = settings.withDefaults(poll.Settings)
+	settings.Archived = poll.Settings.Archived
+	settings.HasPasscode = poll.Settings.HasPasscode
+	settings.InviteOnly = poll.Settings.InviteOnly
+	if err := settings.validate(); err != nil {
+		return PollSettings{}, err
+	}
+	if err := checkClosedChange(poll.Settings, settings, force, now); err != nil {
+		return PollSettings{}, err
+	}
+	if err := p.checkAnonymousChange(id, poll.Settings.Anonymous, settings.Anonymous); err != nil {
+		return PollSettings{}, err
+	}
+
+	poll.Settings = settings
+	if err := p.putVersion(poll, poll.Version); err != nil {
+		return PollSettings{}, notFoundOr(err, "poll does not exist")
+	}
+	p.invalidate(id)
+
+	return settings, nil
+}
+
+// withDefaults fills in any settings that were left out from current,
+// the settings already stored for the poll.  Polls stored before there
+// were statuses were always open to votes, so they count as published
+func (s PollSettings) withDefaults(current PollSettings) PollSettings {
+	if s.Status == "" {
+		s.Status = current.Status
+	}
+	if s.Status == "" {
+		s.Status = PollStatusPublished
+	}
+	if s.VotingMethod == "" {
+		s.VotingMethod = current.VotingMethod
+	}
+	if s.VotingMethod == "" {
+		s.VotingMethod = VotingMethodPlurality
+	}
+	return s
+}
+
+// checkClosedChange returns ErrPollClosed if settings would change the
+// closing time or ResultsPublished of a poll that had current and has
+// closed at now, which would reopen it or take back its results without
+// going through ReopenPoll.  force, which only admins can give, allows it
+func checkClosedChange(current PollSettings, settings PollSettings, force bool, now time.Time) error {
+	if force || current.State(now) != PollStateClosed {
+		return nil
+	}
+	sameClose := current.ClosesAt == nil && settings.ClosesAt == nil ||
+		current.ClosesAt != nil && settings.ClosesAt != nil && current.ClosesAt.Equal(*settings.ClosesAt)
+	if !sameClose || current.ResultsPublished != settings.ResultsPublished {
+		return ErrPollClosed
+	}
+	return nil
+}
+
+// IsOpen reports whether the poll is accepting votes at now, that is it
+// has been published or has an opening time, has reached its opening
+// time, is not archived and has not reached its closing time
+func (s PollSettings) IsOpen(now time.Time) bool {
+	if s.Archived {
+		return false
+	}
+	if s.OpensAt == nil {
+		if s.Status == PollStatusDraft {
+			return false
+		}
+	} else if now.Before(*s.OpensAt) {
+		return false
+	}
+	return s.ClosesAt == nil || now.Before(*s.ClosesAt)
+}
+
+// validate makes sure the settings are ones we can store
+func (s PollSettings) validate() error {
+	if s.OpensAt != nil && s.ClosesAt != nil && !s.OpensAt.Before(*s.ClosesAt) {
+		return ErrInvalidSchedule
+	}
+	if err := validateVotingMethod(s.VotingMethod); err != nil {
+		return err
+	}
+	if s.MaxSelections > 1 && s.VotingMethod == VotingMethodInstantRunoff {
+		return fmt.Errorf("%w: instant-runoff polls take a single choice, rank the options instead", ErrInvalidVotingMethod)
+	}
+	return validatePollStatus(s.Status)
+}
+
+// validatePollStatus makes sure that status is one we know about
+func validatePollStatus(status string) error {
+	switch status {
+	case PollStatusDraft, PollStatusPublished:
+		return nil
+	}
+	return fmt.Errorf("invalid poll status %q", status)
+}
+
+// PrintPoll accepts a Poll and prints it to the console
+// in a JSON pretty format. As some help, look at the
+// json.MarshalIndent() function from our in class go tutorial.
+func (p *PollList) PrintPoll(poll Poll) {
+	jsonBytes, _ := json.MarshalIndent(poll, "", "  ")
+	fmt.Println(string(jsonBytes))
+}
+
+// PrintAllPolls accepts a slice of Polls and prints them to the console
+// in a JSON pretty format.  It should call PrintPoll() to print each poll
+// versus repeating the code.
+func (p *PollList) PrintAllPolls(pollList []Poll) {
+	for _, poll := range pollList {
+		p.PrintPoll(poll)
+	}
+}
+
+// JsonToPoll accepts a json string and returns a Poll
+// This is helpful because the CLI accepts polls for insertion
+// and updates in JSON format.  We need to convert it to a Poll
+// struct to perform any operations on it.
+func (p *PollList) JsonToPoll(jsonString string) (Poll, error) {
+	var poll Poll
+	err := json.Unmarshal([]byte(jsonString), &poll)
+	if err != nil {
+		return Poll{}, err
+	}
+
+	return poll, nil
+}
+
+func (p *PollList) GetHealthData(bootTime time.Time, calls uint) (HealthData, error){
+
+	//Time a PING so that a slow or unreachable store shows up in the
+	//health record
+	start := time.Now()
+	if err := p.store.ping(); err != nil {
+		return HealthData{}, err
+	}
+	latency := time.Since(start)
+
+	p.healthInfo = HealthData{Uptime: time.Now().Sub(bootTime), APIcalls: calls, RedisLatency: latency, Caches: p.CacheStats()}
+
+	return p.healthInfo, nil
 }
\ No newline at end of file
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+)
+
+// commandCounter is a redis hook that counts the commands a client sends,
+// a pipeline counting as one round trip
+type commandCounter struct {
+	n int64
+}
+
+func (c *commandCounter) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	atomic.AddInt64(&c.n, 1)
+	return ctx, nil
+}
+
+func (c *commandCounter) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+func (c *commandCounter) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	atomic.AddInt64(&c.n, 1)
+	return ctx, nil
+}
+
+func (c *commandCounter) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+// BenchmarkGetPollConcurrent has many goroutines reading the same poll at
+// once with the local cache off, so every read misses it, and reports how
+// many redis commands each GetPoll cost with and without POLL_DEDUPE_READS
+func BenchmarkGetPollConcurrent(b *testing.B) {
+	pollList := newTestPollList(b)
+
+	pollList.DeletePoll(testPollID)
+	if err := pollList.AddPoll(newTestPoll(testPollID, "Hot")); err != nil {
+		b.Fatal("Error adding poll: ", err)
+	}
+	b.Cleanup(func() { pollList.DeletePoll(testPollID) })
+
+	pollList.localCache.resize(0)
+	counter := &commandCounter{}
+	pollList.cacheClient.AddHook(counter)
+
+	for _, bm := range []struct {
+		name      string
+		pollReads *singleflight.Group
+	}{
+		{"dedupe", &singleflight.Group{}},
+		{"no-dedupe", nil},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			pollList.pollReads = bm.pollReads
+			atomic.StoreInt64(&counter.n, 0)
+
+			b.SetParallelism(16)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := pollList.GetPoll(testPollID); err != nil {
+						b.Error("Error getting poll: ", err)
+						return
+					}
+				}
+			})
+			b.ReportMetric(float64(atomic.LoadInt64(&counter.n))/float64(b.N), "redis-cmds/op")
+		})
+	}
+}
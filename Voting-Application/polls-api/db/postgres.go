@@ -0,0 +1,163 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresDefaultLocation is the database used with -db postgres when
+// DATABASE_URL is not set, the same database the voters API defaults to
+const PostgresDefaultLocation = "postgres://postgres@localhost:5432/voting?sslmode=disable"
+
+// postgresSchema creates the polls table if it is not there yet.  Each
+// poll is kept whole as a JSONB document, the same shape it has in
+// redis, keyed by its id.  The version is kept beside it so a write can
+// check it
+const postgresSchema = `CREATE TABLE IF NOT EXISTS polls (
+	poll_id BIGINT PRIMARY KEY,
+	version BIGINT NOT NULL,
+	doc     JSONB  NOT NULL
+)`
+
+// postgresStore keeps polls in a postgres table, for deployments that
+// need the polls to be durable
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresPollList connects to the postgres database in DATABASE_URL,
+// creates the polls table if it is not there and returns a PollList that
+// keeps its polls there instead of redis.  Passcodes, invitations, ballot
+// links and the audit log need redis, so they are not available
+func NewPostgresPollList() (*PollList, error) {
+	databaseUrl := os.Getenv("DATABASE_URL")
+	if databaseUrl == "" {
+		databaseUrl = PostgresDefaultLocation
+	}
+
+	db, err := sql.Open("postgres", databaseUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	pollList := newPollList(&postgresStore{db: db}, withoutRedis())
+	pollList.startFromEnv()
+
+	return pollList, nil
+}
+
+func (p *postgresStore) get(id uint) (Poll, error) {
+	var doc []byte
+	err := p.db.QueryRow(`SELECT doc FROM polls WHERE poll_id = $1`, id).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Poll{}, ErrNotFound
+	}
+	if err != nil {
+		return Poll{}, err
+	}
+
+	var poll Poll
+	if err := json.Unmarshal(doc, &poll); err != nil {
+		return Poll{}, err
+	}
+	return poll, nil
+}
+
+// create leaves a poll that is already there alone, so of two polls
+// added with the same id at once only one goes in
+func (p *postgresStore) create(poll Poll) error {
+	doc, err := json.Marshal(poll)
+	if err != nil {
+		return err
+	}
+	result, err := p.db.Exec(`INSERT INTO polls (poll_id, version, doc) VALUES ($1, $2, $3)
+		ON CONFLICT (poll_id) DO NOTHING`, poll.PollID, poll.Version, string(doc))
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// putVersion checks the version in the same UPDATE that writes the poll
+func (p *postgresStore) putVersion(poll Poll, version uint) error {
+	poll.Version = version + 1
+	doc, err := json.Marshal(poll)
+	if err != nil {
+		return err
+	}
+	result, err := p.db.Exec(`UPDATE polls SET version = $1, doc = $2 WHERE poll_id = $3 AND version = $4`,
+		poll.Version, string(doc), poll.PollID, version)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return missedVersion(p, poll.PollID, version)
+	}
+	return nil
+}
+
+func (p *postgresStore) delete(id uint) error {
+	result, err := p.db.Exec(`DELETE FROM polls WHERE poll_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *postgresStore) deleteAll() error {
+	_, err := p.db.Exec(`DELETE FROM polls`)
+	return err
+}
+
+func (p *postgresStore) list() ([]Poll, error) {
+	rows, err := p.db.Query(`SELECT doc FROM polls ORDER BY poll_id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanPolls(rows)
+}
+
+// listPage uses the last poll id of the previous page as the cursor,
+// poll ids start at 1 so 0 is the start
+func (p *postgresStore) listPage(cursor uint64, count int) ([]Poll, uint64, error) {
+	count = pageCount(count)
+	rows, err := p.db.Query(`SELECT doc FROM polls WHERE poll_id > $1 ORDER BY poll_id LIMIT $2`, cursor, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	polls, err := scanPolls(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return polls, lastPollID(polls, count), nil
+}
+
+func (p *postgresStore) ping() error {
+	return p.db.Ping()
+}
@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"drexel.edu/voting-application/client/httpclient"
+)
+
+// DeletePolicyBlock and DeletePolicyCascade are what POLL_DELETE_POLICY
+// can have DeletePoll do with a poll that has been voted on.  Block turns
+// the delete away, cascade deletes the poll's votes in the votes API and
+// the poll from the VoteHistory of every voter in the voters API first
+const (
+	DeletePolicyBlock   = "block"
+	DeletePolicyCascade = "cascade"
+)
+
+// DefaultVotesAPIURL and DefaultVotersAPIURL are where a cascading delete
+// looks for the votes and voters APIs when VOTES_API_URL and
+// VOTERS_API_URL are not set
+const (
+	DefaultVotesAPIURL  = "http://localhost:1100"
+	DefaultVotersAPIURL = "http://localhost:1080"
+)
+
+// DefaultCascadeTimeout is how long each attempt at a request of a
+// cascading delete has when POLL_CASCADE_TIMEOUT is not set
+const DefaultCascadeTimeout = 5 * time.Second
+
+// maxRefsBody is the largest list of votes, or page of voters, that is
+// read from another service
+const maxRefsBody = 8 << 20
+
+// ErrPollHasVotes is returned when a poll that has votes is deleted with
+// the block policy
+var ErrPollHasVotes error = conflict("poll has votes, delete them first")
+
+// ErrDependencyUnavailable is returned when a cascading delete can't reach
+// the votes or voters API, or it answers with an error.  The poll is not
+// deleted, so the delete can be tried again
+var ErrDependencyUnavailable = errors.New("dependency unavailable")
+
+// deletePolicyFromEnv returns POLL_DELETE_POLICY, or DeletePolicyBlock if
+// it is not set or not a known policy
+func deletePolicyFromEnv() string {
+	policy := DeletePolicyBlock
+	if s := os.Getenv("POLL_DELETE_POLICY"); s != "" {
+		if s == DeletePolicyBlock || s == DeletePolicyCascade {
+			policy = s
+		} else {
+			log.Println("Invalid POLL_DELETE_POLICY, using default: ", s)
+		}
+	}
+	return policy
+}
+
+// refServices are the services that keep records referring to polls, that
+// a cascading delete removes
+type refServices struct {
+	votesURL  string
+	votersURL string
+	//Each service has its own client, so one that is down doesn't open
+	//the circuit breaker of the other
+	votes  *httpclient.Client
+	voters *httpclient.Client
+}
+
+// refServicesFromEnv returns the services at VOTES_API_URL and
+// VOTERS_API_URL, or the defaults, with POLL_CASCADE_TIMEOUT for each
+// attempt at a request.  The requests are GETs and DELETEs, so they are
+// retried, see httpclient
+func refServicesFromEnv() refServices {
+	refs := refServices{
+		votesURL:  DefaultVotesAPIURL,
+		votersURL: DefaultVotersAPIURL,
+	}
+	if u := os.Getenv("VOTES_API_URL"); u != "" {
+		refs.votesURL = u
+	}
+	if u := os.Getenv("VOTERS_API_URL"); u != "" {
+		refs.votersURL = u
+	}
+	refs.votesURL = strings.TrimSuffix(refs.votesURL, "/")
+	refs.votersURL = strings.TrimSuffix(refs.votersURL, "/")
+
+	timeout := DefaultCascadeTimeout
+	if t := os.Getenv("POLL_CASCADE_TIMEOUT"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil || d <= 0 {
+			log.Println("Invalid POLL_CASCADE_TIMEOUT, using default: ", t)
+		} else {
+			timeout = d
+		}
+	}
+	config := httpclient.DefaultConfig()
+	config.AttemptTimeout = timeout
+	config.MaxBody = maxRefsBody
+	refs.votes = httpclient.New("votes", config)
+	refs.voters = httpclient.New("voters", config)
+	return refs
+}
+
+// send makes a request to u with client, the service named name, and
+// returns the body and headers of a 2xx.  A 404 is ErrNotFound and
+// anything else is ErrDependencyUnavailable naming the service
+func (r refServices) send(ctx context.Context, client *httpclient.Client, name string, method string, u string) ([]byte, http.Header, error) {
+	resp, err := client.Do(ctx, method, u)
+	if err != nil {
+		log.Println("Error reaching "+name+": ", err)
+		return nil, nil, fmt.Errorf("%w: %s", ErrDependencyUnavailable, name)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, ErrNotFound
+	}
+	return resp.Body, resp.Header, nil
+}
+
+// deleteVotes deletes every vote of the poll with id through the votes
+// API, so its vote counts, indexes and events are kept right.  Votes that
+// are already gone are skipped
+func (r refServices) deleteVotes(ctx context.Context, id uint) error {
+	body, _, err := r.send(ctx, r.votes, "votes", http.MethodGet, fmt.Sprintf("%s/polls/%d/votes", r.votesURL, id))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var votes []struct {
+		VoteID uint
+	}
+	if err := json.Unmarshal(body, &votes); err != nil {
+		return err
+	}
+
+	for _, vote := range votes {
+		_, _, err := r.send(ctx, r.votes, "votes", http.MethodDelete, fmt.Sprintf("%s/votes/%d", r.votesURL, vote.VoteID))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// pollVotes reads the votes of the poll with id through the votes API, as
+// JSON.  A poll the votes API does not know has no votes
+func (r refServices) pollVotes(ctx context.Context, id uint) ([][]byte, error) {
+	body, _, err := r.send(ctx, r.votes, "votes", http.MethodGet, fmt.Sprintf("%s/polls/%d/votes", r.votesURL, id))
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var votes []json.RawMessage
+	if err := json.Unmarshal(body, &votes); err != nil {
+		return nil, err
+	}
+
+	docs := make([][]byte, 0, len(votes))
+	for _, vote := range votes {
+		docs = append(docs, vote)
+	}
+	return docs, nil
+}
+
+// deleteVoteHistory removes the poll with id from the VoteHistory of every
+// voter through the voters API.  The voters API keeps no index of the
+// polls voters have voted in, so every voter is read, a ?cursor page at a
+// time
+func (r refServices) deleteVoteHistory(ctx context.Context, id uint) error {
+	cursor := "0"
+	for {
+		body, header, err := r.send(ctx, r.voters, "voters", http.MethodGet, r.votersURL+"/voters?cursor="+cursor)
+		if err != nil {
+			return err
+		}
+		var voters []struct {
+			VoterID     uint
+			VoteHistory []struct {
+				PollID uint
+			}
+		}
+		if err := json.Unmarshal(body, &voters); err != nil {
+			return err
+		}
+
+		for _, voter := range voters {
+			for _, poll := range voter.VoteHistory {
+				if poll.PollID != id {
+					continue
+				}
+				_, _, err := r.send(ctx, r.voters, "voters", http.MethodDelete, fmt.Sprintf("%s/voters/%d/polls/%d", r.votersURL, voter.VoterID, id))
+				if err != nil && !errors.Is(err, ErrNotFound) {
+					return err
+				}
+				break
+			}
+		}
+
+		cursor = header.Get("X-Next-Cursor")
+		if cursor == "" || cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// clearRefs makes sure nothing refers to the poll with id before it is
+// deleted, the way the delete policy says.  With block it returns
+// ErrPollHasVotes if anyone has voted in the poll, with cascade it
+// deletes the poll's votes and VoteHistory entries
+func (p *PollList) clearRefs(id uint) error {
+	if p.deletePolicy != DeletePolicyCascade {
+		voted, err := p.hasVotes(id)
+		if err != nil {
+			return err
+		}
+		if voted {
+			return ErrPollHasVotes
+		}
+		return nil
+	}
+
+	if err := p.refs.deleteVotes(p.context, id); err != nil {
+		return err
+	}
+	return p.refs.deleteVoteHistory(p.context, id)
+}
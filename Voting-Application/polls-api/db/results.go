@@ -0,0 +1,477 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// VotesKeyPrefix is the prefix the votes API stores votes under, the
+// polls API reads them to work out results
+const VotesKeyPrefix = "votes:"
+
+// Each poll's vote count is kept by the votes API under
+// poll:<number>:count, the polls API only reads it
+func voteCountKeyFromPollId(id uint) string {
+	return fmt.Sprintf("poll:%d:count", id)
+}
+
+// The votes API also keeps the ids of each poll's votes in a set under
+// poll-votes:<number>, so the votes of one poll can be read without
+// scanning every vote
+func pollVotesKeyFromPollId(id uint) string {
+	return fmt.Sprintf("poll-votes:%d", id)
+}
+
+// voteKeysOfPoll returns the keys of the votes of the poll with id from
+// the index the votes API keeps.  Polls whose votes were cast before the
+// index existed have every vote key returned instead, so callers must
+// still check the PollID of each vote
+func (p *PollList) voteKeysOfPoll(id uint) ([]string, error) {
+	ids, err := p.cacheClient.SMembers(p.context, pollVotesKeyFromPollId(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		ks := make([]string, 0, len(ids))
+		for _, voteId := range ids {
+			ks = append(ks, VotesKeyPrefix+voteId)
+		}
+		return ks, nil
+	}
+
+	//An empty index is either a poll nobody has voted in, or one whose
+	//votes were never indexed, the vote count tells them apart
+	count, err := p.cacheClient.Get(p.context, voteCountKeyFromPollId(id)).Int64()
+	if err == nil && count <= 0 {
+		return nil, nil
+	}
+	if err != nil && err.Error() != RedisNilError {
+		return nil, err
+	}
+	return p.scanKeys(VotesKeyPrefix + "*")
+}
+
+// voteDocsOfPoll returns the votes of the poll with id as JSON.  With
+// redis they are read from the keys the votes API keeps them under, which
+// can include votes of other polls, see voteKeysOfPoll.  Otherwise the
+// polls API has no access to them, so they are asked for through the votes
+// API.  A vote deleted since it was listed is nil
+func (p *PollList) voteDocsOfPoll(id uint) ([][]byte, error) {
+	if p.needsRedis() != nil {
+		return p.refs.pollVotes(p.context, id)
+	}
+
+	ks, err := p.voteKeysOfPoll(id)
+	if err != nil {
+		return nil, err
+	}
+	return p.getDocsFromRedis(ks)
+}
+
+// ErrPollOpen is returned when final results are asked for while the poll
+// is still accepting votes
+var ErrPollOpen = errors.New("poll is still open")
+
+// voteRef holds the parts of a vote, owned by the votes API, that the
+// polls API needs to tally results.  VoteValue is the PollOptionID that
+// was voted for and Source is the channel the vote came in through.
+// Rankings, if the vote has them, are the PollOptionIDs in the order the
+// voter ranked them, VoteValue first, and VoteValues every option selected
+// in a poll that allows more than one, also VoteValue first.  A write-in
+// vote has WriteInText instead of a VoteValue
+type voteRef struct {
+	PollID      uint
+	VoteValue   uint
+	VoteValues  []uint
+	Source      string
+	Rankings    []uint
+	WriteInText string
+}
+
+// VoteSourceUnknown is the source of votes that were stored before the
+// votes API recorded where votes came from
+const VoteSourceUnknown = "unknown"
+
+// PollWinner is the outcome of a poll.  Winners has more than one option
+// in it when there is a tie, and is empty if nobody has voted
+type PollWinner struct {
+	Winners     []uint   `json:"winners"`
+	WinningText []string `json:"winningText"`
+	Count       uint     `json:"count"`
+	IsTie       bool     `json:"isTie"`
+}
+
+// PollReport is the complete results of a single poll, for
+// GET /polls/:id/report.  While the poll is open the winner is only the
+// current leader.  BySource has how many of the counted votes came in
+// through each channel.  TotalVotes is how many votes were counted, in a
+// poll that allows more than one selection the option counts add up to
+// more than that and their percents to more than 100.  Options always
+// count first choices, or every selection, the winner is picked by the
+// poll's VotingMethod, and for instant-runoff Rounds has how the count
+// went.  WriteIns counts the write-in answers, which can't win
+type PollReport struct {
+	Poll         Poll            `json:"poll"`
+	IsOpen       bool            `json:"isOpen"`
+	VotingMethod string          `json:"votingMethod"`
+	TotalVotes   uint            `json:"totalVotes"`
+	Options      []OptionResult  `json:"options"`
+	Winner       PollWinner      `json:"winner"`
+	Rounds       []RunoffRound   `json:"rounds,omitempty"`
+	WriteIns     []WriteInResult `json:"writeIns,omitempty"`
+	BySource     map[string]uint `json:"bySource"`
+	GeneratedAt  time.Time       `json:"generatedAt"`
+}
+
+// OptionResult is how one option of a poll did
+type OptionResult struct {
+	ID      uint    `json:"id"`
+	Text    string  `json:"text"`
+	Count   uint    `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// PollDistribution describes how the votes of a poll are spread across
+// its options.  Options are numbered by their position in PollOptions,
+// starting at 0, for Mean and StdDev.  Entropy is in bits, 0 when every
+// vote is for one option, and NormalizedEntropy divides it by the most
+// it could be for the number of options, so 1 means an even split.  All
+// of them are 0 when there are no votes
+type PollDistribution struct {
+	TotalVotes        uint    `json:"totalVotes"`
+	Mean              float64 `json:"mean"`
+	StdDev            float64 `json:"stdDev"`
+	Entropy           float64 `json:"entropy"`
+	NormalizedEntropy float64 `json:"normalizedEntropy"`
+}
+
+// TallyVotes accepts a poll id and returns how many votes each of the
+// poll's options has received, keyed by PollOptionID.  Every option is in
+// the tally, including ones with no votes.  Votes for values that are not
+// options of the poll are not counted
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The tally will be returned along with the poll
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) TallyVotes(id uint) (Poll, map[uint]uint, error) {
+	t, err := p.tallyPoll(id)
+	return t.poll, t.counts, err
+}
+
+// pollTally is everything counted from the votes of a poll: how many
+// votes were counted, how many each option has as a first choice or
+// selection, how many came in through each channel, the ballot of every
+// vote for a runoff, and the write-in answers
+type pollTally struct {
+	poll     Poll
+	votes    uint
+	counts   map[uint]uint
+	bySource map[string]uint
+	ballots  [][]uint
+	writeIns map[string]*writeInGroup
+}
+
+// tallyPoll reads the votes of the poll with id and counts them
+func (p *PollList) tallyPoll(id uint) (pollTally, error) {
+
+	poll, err := p.GetPoll(id)
+	if err != nil {
+		return pollTally{}, err
+	}
+
+	t := pollTally{
+		poll:     poll,
+		counts:   make(map[uint]uint, len(poll.PollOptions)),
+		bySource: make(map[string]uint),
+		ballots:  [][]uint{},
+		writeIns: make(map[string]*writeInGroup),
+	}
+	for _, option := range poll.PollOptions {
+		t.counts[option.PollOptionID] = 0
+	}
+
+	docs, err := p.voteDocsOfPoll(id)
+	if err != nil {
+		return pollTally{}, err
+	}
+	for _, doc := range docs {
+		if doc == nil {
+			//Deleted since we listed the keys
+			continue
+		}
+		var vote voteRef
+		if err := json.Unmarshal(doc, &vote); err != nil {
+			return pollTally{}, err
+		}
+		if vote.PollID != id {
+			continue
+		}
+		//A vote counts once for each option it selected, most votes
+		//select just their VoteValue
+		selections := vote.VoteValues
+		if len(selections) == 0 {
+			selections = []uint{vote.VoteValue}
+		}
+		counted := make(map[uint]bool, len(selections))
+		for _, value := range selections {
+			if _, ok := t.counts[value]; ok && !counted[value] {
+				t.counts[value]++
+				counted[value] = true
+			}
+		}
+		if len(counted) > 0 || addWriteIn(t.writeIns, vote.WriteInText) {
+			t.votes++
+			if vote.Source == "" {
+				vote.Source = VoteSourceUnknown
+			}
+			t.bySource[vote.Source]++
+		}
+		if ballot := ballotOf(vote, t.counts); len(ballot) > 0 {
+			t.ballots = append(t.ballots, ballot)
+		}
+	}
+
+	return t, nil
+}
+
+// winner picks the winner of the poll by its VotingMethod, with the
+// rounds of the count for instant-runoff
+func (t pollTally) winner() (PollWinner, []RunoffRound) {
+	if t.poll.Settings.VotingMethod == VotingMethodInstantRunoff {
+		return instantRunoff(t.poll, t.ballots)
+	}
+	return winnerFromTally(t.poll, t.counts), nil
+}
+
+// hasVotes reports whether anyone has voted in the poll with id.  The
+// vote count kept by the votes API answers this without a scan, only
+// polls that have no count yet, such as ones voted on before the count
+// existed, fall back to scanning the votes.  Without redis the votes API
+// is asked for the votes
+func (p *PollList) hasVotes(id uint) (bool, error) {
+	if p.needsRedis() != nil {
+		docs, err := p.refs.pollVotes(p.context, id)
+		return len(docs) > 0, err
+	}
+
+	count, err := p.cacheClient.Get(p.context, voteCountKeyFromPollId(id)).Int64()
+	if err == nil {
+		return count > 0, nil
+	}
+	if err.Error() != RedisNilError {
+		return false, err
+	}
+
+	ks, err := p.voteKeysOfPoll(id)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range ks {
+		voteObject, err := p.jsonHelper.JSONGet(key, ".")
+		if err != nil {
+			if err.Error() == RedisNilError {
+				//Deleted since we listed the keys
+				continue
+			}
+			return false, err
+		}
+		var vote voteRef
+		if err := json.Unmarshal(voteObject.([]byte), &vote); err != nil {
+			return false, err
+		}
+		if vote.PollID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sameOptions reports whether two sets of poll options are the same,
+// treating no options and an empty list as the same
+func sameOptions(a, b []pollOption) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetPollWinner accepts a poll id and returns the option, or options if
+// there is a tie, that won.  With the plurality voting method that is the
+// option with the most votes, with instant-runoff the one left with more
+// than half of the votes, see runoff.go.  now is the time to decide
+// whether the poll is still open at.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+//					(3) The poll must no longer be open, unless
+//						provisional is true
+//
+// Postconditions:
+//
+//	    (1) The winner will be returned
+//		(2) If the poll is still open and provisional is false,
+//			ErrPollOpen is returned
+//		(3) If there is an error, it will be returned
+//		(4) The database file will not be modified
+func (p *PollList) GetPollWinner(id uint, provisional bool, now time.Time) (PollWinner, error) {
+
+	t, err := p.tallyPoll(id)
+	if err != nil {
+		return PollWinner{}, err
+	}
+	if t.poll.Settings.IsOpen(now) && !provisional {
+		return PollWinner{}, ErrPollOpen
+	}
+
+	winner, _ := t.winner()
+	return winner, nil
+}
+
+// winnerFromTally picks the option, or options if there is a tie, with
+// the most votes in tally
+func winnerFromTally(poll Poll, tally map[uint]uint) PollWinner {
+	winner := PollWinner{Winners: []uint{}, WinningText: []string{}}
+	for _, option := range poll.PollOptions {
+		count := tally[option.PollOptionID]
+		if count == 0 || count < winner.Count {
+			continue
+		}
+		if count > winner.Count {
+			winner.Count = count
+			winner.Winners = winner.Winners[:0]
+			winner.WinningText = winner.WinningText[:0]
+		}
+		winner.Winners = append(winner.Winners, option.PollOptionID)
+		winner.WinningText = append(winner.WinningText, option.PollOptionText)
+	}
+	winner.IsTie = len(winner.Winners) > 1
+
+	return winner
+}
+
+// GetPollDistribution accepts a poll id and returns how its votes are
+// spread across its options.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The distribution will be returned, every value is a
+//			finite number, including for polls with no votes or a
+//			single option
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) GetPollDistribution(id uint) (PollDistribution, error) {
+
+	poll, tally, err := p.TallyVotes(id)
+	if err != nil {
+		return PollDistribution{}, err
+	}
+
+	return distributionFromTally(poll, tally), nil
+}
+
+// distributionFromTally works out the distribution of the votes in
+// tally over the options of poll
+func distributionFromTally(poll Poll, tally map[uint]uint) PollDistribution {
+	var dist PollDistribution
+	for _, option := range poll.PollOptions {
+		dist.TotalVotes += tally[option.PollOptionID]
+	}
+	//Nothing to divide by, report all zeros rather than NaN
+	if dist.TotalVotes == 0 {
+		return dist
+	}
+
+	total := float64(dist.TotalVotes)
+	for i, option := range poll.PollOptions {
+		dist.Mean += float64(i) * float64(tally[option.PollOptionID]) / total
+	}
+	var variance float64
+	for i, option := range poll.PollOptions {
+		count := tally[option.PollOptionID]
+		if count == 0 {
+			//0 log 0 is taken to be 0
+			continue
+		}
+		share := float64(count) / total
+		variance += share * (float64(i) - dist.Mean) * (float64(i) - dist.Mean)
+		dist.Entropy -= share * math.Log2(share)
+	}
+	dist.StdDev = math.Sqrt(variance)
+	//Rounding can leave a tiny negative entropy when one option has
+	//every vote
+	if dist.Entropy < 0 {
+		dist.Entropy = 0
+	}
+	//With a single option there is no spread to compare against
+	if len(poll.PollOptions) > 1 {
+		dist.NormalizedEntropy = dist.Entropy / math.Log2(float64(len(poll.PollOptions)))
+	}
+
+	return dist
+}
+
+// GetPollReport accepts a poll id and returns the poll together with its
+// results.  Open polls can be reported on, the report says whether the
+// poll was still open at now, the time it is generated at.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The report will be returned, a poll with no votes has
+//			every option at 0 percent and no winner
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (p *PollList) GetPollReport(id uint, now time.Time) (PollReport, error) {
+
+	t, err := p.tallyPoll(id)
+	if err != nil {
+		return PollReport{}, err
+	}
+	poll, tally := t.poll, t.counts
+
+	report := PollReport{
+		Poll:         poll,
+		IsOpen:       poll.Settings.IsOpen(now),
+		VotingMethod: poll.Settings.withDefaults(PollSettings{}).VotingMethod,
+		Options:      make([]OptionResult, 0, len(poll.PollOptions)),
+		BySource:     t.bySource,
+		GeneratedAt:  now,
+	}
+	report.Winner, report.Rounds = t.winner()
+	report.TotalVotes = t.votes
+	for _, option := range poll.PollOptions {
+		result := OptionResult{
+			ID:    option.PollOptionID,
+			Text:  option.PollOptionText,
+			Count: tally[option.PollOptionID],
+		}
+		if report.TotalVotes > 0 {
+			result.Percent = 100 * float64(result.Count) / float64(report.TotalVotes)
+		}
+		report.Options = append(report.Options, result)
+	}
+	if len(t.writeIns) > 0 {
+		report.WriteIns = writeInResults(t.writeIns, report.TotalVotes)
+	}
+
+	return report, nil
+}
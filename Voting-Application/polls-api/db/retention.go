@@ -0,0 +1,166 @@
+package db
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultRetentionInterval is how often expiries are set on closed polls
+// when POLL_RETENTION is set and POLL_RETENTION_INTERVAL is not
+const DefaultRetentionInterval = time.Hour
+
+// Closed polls are kept forever unless POLL_RETENTION is set, to a go
+// duration string such as "2160h" for 90 days.  Then every
+// POLL_RETENTION_INTERVAL the polls API gives each closed poll, and the
+// keys that go with it, an EXPIREAT of its Settings.ClosesAt plus the
+// retention, and redis purges them then whether or not the polls API is
+// running.  The votes API does the same for the poll's votes, it needs
+// the same POLL_RETENTION.  A poll that is no longer closed, because it
+// was reopened or its closing time moved, has its expiry taken off again
+
+// retentionFromEnv returns POLL_RETENTION, and whether it is set.  If it
+// is not closed polls are kept forever and nothing is run.  "0" keeps
+// them forever too, but takes off any expiries set while it was longer
+func retentionFromEnv() (time.Duration, bool) {
+	retention := os.Getenv("POLL_RETENTION")
+	if retention == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(retention)
+	if err != nil || d < 0 {
+		log.Println("Invalid POLL_RETENTION, keeping closed polls: ", retention)
+		return 0, false
+	}
+	return d, true
+}
+
+// retentionIntervalFromEnv returns POLL_RETENTION_INTERVAL, or the
+// default if it is not set or not a valid duration
+func retentionIntervalFromEnv() time.Duration {
+	interval := DefaultRetentionInterval
+	if s := os.Getenv("POLL_RETENTION_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			log.Println("Invalid POLL_RETENTION_INTERVAL, using default: ", s)
+		} else {
+			interval = d
+		}
+	}
+	return interval
+}
+
+// PurgeAt returns when a poll with these settings is purged with a
+// retention of retention, at now, or nil if it is kept.  Only closed
+// polls with a closing time are purged, a poll archived before it had
+// one is kept
+func (s PollSettings) PurgeAt(retention time.Duration, now time.Time) *time.Time {
+	if retention <= 0 || s.ClosesAt == nil || s.State(now) != PollStateClosed {
+		return nil
+	}
+	purgeAt := s.ClosesAt.Add(retention)
+	return &purgeAt
+}
+
+// retentionKeys are the keys of the poll with id that are purged with it
+func retentionKeys(id uint) []string {
+	return []string{redisKeyFromId(id), passcodeKeyFromId(id), invitationKeyFromId(id)}
+}
+
+// applyRetention gives every closed poll, at now, the expiry it is due
+// and takes it off every other poll, and returns how many polls are
+// expiring.  A poll whose time has already come is purged straight away.
+// Other stores have no expiries, so the polls whose time has come are
+// deleted by each run instead
+func (p *PollList) applyRetention(now time.Time) (int, error) {
+
+	polls, err := p.store.list()
+	if err != nil {
+		return 0, err
+	}
+	if p.needsRedis() != nil {
+		return p.purgeDue(polls, now)
+	}
+
+	expiring := 0
+	_, err = p.cacheClient.Pipelined(p.context, func(pipe redis.Pipeliner) error {
+		for _, poll := range polls {
+			purgeAt := poll.Settings.PurgeAt(p.retention, now)
+			for _, key := range retentionKeys(poll.PollID) {
+				if purgeAt != nil {
+					pipe.ExpireAt(p.context, key, *purgeAt)
+				} else {
+					pipe.Persist(p.context, key)
+				}
+			}
+			if purgeAt != nil {
+				expiring++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return expiring, nil
+}
+
+// purgeDue deletes the polls whose time has come at now, for the stores
+// that have no expiries, and returns how many of the rest are expiring
+func (p *PollList) purgeDue(polls []Poll, now time.Time) (int, error) {
+	expiring := 0
+	for _, poll := range polls {
+		purgeAt := poll.Settings.PurgeAt(p.retention, now)
+		if purgeAt == nil {
+			continue
+		}
+		if now.Before(*purgeAt) {
+			expiring++
+			continue
+		}
+		if err := p.store.delete(poll.PollID); err != nil && !errors.Is(err, ErrNotFound) {
+			return expiring, err
+		}
+		p.invalidate(poll.PollID)
+	}
+	return expiring, nil
+}
+
+// keepPoll takes the expiry off the poll with id, and the keys that go
+// with it, for a poll that has been reopened.  The background run would
+// too, but the poll could be purged before it comes round.  A failure is
+// only logged, the next run takes it off
+func (p *PollList) keepPoll(id uint) {
+	if p.retention <= 0 || p.needsRedis() != nil {
+		return
+	}
+	_, err := p.cacheClient.Pipelined(p.context, func(pipe redis.Pipeliner) error {
+		for _, key := range retentionKeys(id) {
+			pipe.Persist(p.context, key)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Error taking the expiry off poll ", id, ": ", err)
+	}
+}
+
+// watchRetention sets the expiries of the closed polls every interval in
+// the background
+func (p *PollList) watchRetention(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			expiring, err := p.applyRetention(now)
+			if err != nil {
+				log.Println("Error setting the expiries of closed polls: ", err)
+				continue
+			}
+			log.Println("Closed polls expiring: ", expiring)
+		}
+	}()
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The ways a poll's votes can be counted, as Settings.VotingMethod.  With
+// plurality, the default, the option with the most votes wins.  With
+// instant-runoff votes can rank the options in their Rankings, and the
+// option with the fewest first choices is knocked out, and its votes
+// passed on to their next choice, until one option has more than half
+// of the votes still in the count
+const (
+	VotingMethodPlurality     = "plurality"
+	VotingMethodInstantRunoff = "instant-runoff"
+)
+
+// ErrInvalidVotingMethod is returned when a poll is given a voting method
+// we don't know about
+var ErrInvalidVotingMethod = errors.New("invalid voting method")
+
+// validateVotingMethod makes sure that method is one we know about, or
+// empty for the default
+func validateVotingMethod(method string) error {
+	switch method {
+	case "", VotingMethodPlurality, VotingMethodInstantRunoff:
+		return nil
+	}
+	return fmt.Errorf("%w %q, use %s or %s", ErrInvalidVotingMethod, method, VotingMethodPlurality, VotingMethodInstantRunoff)
+}
+
+// RunoffRound is one round of an instant runoff count.  Counts has how
+// many votes each option still in the count has, Eliminated the options
+// knocked out at the end of the round, and Exhausted how many votes have
+// no choices left in the count
+type RunoffRound struct {
+	Round      uint          `json:"round"`
+	Counts     map[uint]uint `json:"counts"`
+	Eliminated []uint        `json:"eliminated"`
+	Exhausted  uint          `json:"exhausted"`
+}
+
+// ballotOf returns the choices of a vote, best first, that are options of
+// the poll, each once.  A vote without Rankings is a single choice for
+// its VoteValue
+func ballotOf(vote voteRef, options map[uint]uint) []uint {
+	choices := vote.Rankings
+	if len(choices) == 0 {
+		choices = []uint{vote.VoteValue}
+	}
+	ballot := make([]uint, 0, len(choices))
+	seen := make(map[uint]bool, len(choices))
+	for _, choice := range choices {
+		if _, ok := options[choice]; !ok || seen[choice] {
+			continue
+		}
+		seen[choice] = true
+		ballot = append(ballot, choice)
+	}
+	return ballot
+}
+
+// instantRunoff counts ballots by instant runoff and returns the winner
+// and every round.  Each round gives every ballot to its best choice still
+// in the count.  An option with more than half of those ballots wins, if
+// every option left has the same count they tie, otherwise every option
+// with the fewest ballots is knocked out together.  Options nobody chose
+// first are knocked out in the first round
+func instantRunoff(poll Poll, ballots [][]uint) (PollWinner, []RunoffRound) {
+	winner := PollWinner{Winners: []uint{}, WinningText: []string{}}
+	rounds := []RunoffRound{}
+	if len(ballots) == 0 {
+		return winner, rounds
+	}
+
+	text := make(map[uint]string, len(poll.PollOptions))
+	running := make(map[uint]bool, len(poll.PollOptions))
+	for _, option := range poll.PollOptions {
+		text[option.PollOptionID] = option.PollOptionText
+		running[option.PollOptionID] = true
+	}
+
+	for len(running) > 0 {
+		round := RunoffRound{
+			Round:      uint(len(rounds) + 1),
+			Counts:     make(map[uint]uint, len(running)),
+			Eliminated: []uint{},
+		}
+		for id := range running {
+			round.Counts[id] = 0
+		}
+		var active uint
+		for _, ballot := range ballots {
+			counted := false
+			for _, choice := range ballot {
+				if running[choice] {
+					round.Counts[choice]++
+					counted = true
+					break
+				}
+			}
+			if counted {
+				active++
+			} else {
+				round.Exhausted++
+			}
+		}
+
+		//Every ballot has run out of choices, nobody wins
+		if active == 0 {
+			rounds = append(rounds, round)
+			return winner, rounds
+		}
+
+		var most, fewest uint
+		first := true
+		for _, count := range round.Counts {
+			if first || count > most {
+				most = count
+			}
+			if first || count < fewest {
+				fewest = count
+			}
+			first = false
+		}
+
+		if most*2 > active || most == fewest {
+			rounds = append(rounds, round)
+			//Go through the options in poll order so that tied
+			//winners come out in a stable order
+			for _, option := range poll.PollOptions {
+				id := option.PollOptionID
+				if running[id] && round.Counts[id] == most {
+					winner.Winners = append(winner.Winners, id)
+					winner.WinningText = append(winner.WinningText, text[id])
+				}
+			}
+			winner.Count = most
+			winner.IsTie = len(winner.Winners) > 1
+			return winner, rounds
+		}
+
+		for _, option := range poll.PollOptions {
+			id := option.PollOptionID
+			if running[id] && round.Counts[id] == fewest {
+				round.Eliminated = append(round.Eliminated, id)
+				delete(running, id)
+			}
+		}
+		rounds = append(rounds, round)
+	}
+	return winner, rounds
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// DefaultScheduleInterval is how often drafts whose OpensAt has passed are
+// published when POLL_SCHEDULE_INTERVAL is not set
+const DefaultScheduleInterval = 30 * time.Second
+
+// A poll opens and closes at Settings.OpensAt and Settings.ClosesAt by
+// itself, without anyone publishing or closing it, because IsOpen and
+// State work it out at the time they are asked.  A draft that has reached
+// its OpensAt is still stored as a draft though, so the poll list, the
+// poll ids and search, which go by the stored status, would keep hiding
+// it.  publishDue fixes the stored status up, and the polls API runs it
+// every POLL_SCHEDULE_INTERVAL
+
+// publishDue publishes every draft whose OpensAt is at or before now, and
+// returns how many it published.  A poll changed by someone else in the
+// meantime is skipped, the next run picks it up
+func (p *PollList) publishDue(now time.Time) (int, error) {
+
+	polls, err := p.store.list()
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, poll := range polls {
+		opensAt := poll.Settings.OpensAt
+		if poll.Settings.Status != PollStatusDraft || opensAt == nil || now.Before(*opensAt) {
+			continue
+		}
+		poll.Settings.Status = PollStatusPublished
+		err := p.putVersion(poll, poll.Version)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrVersionMismatch) {
+			continue
+		}
+		if err != nil {
+			return published, err
+		}
+		p.invalidate(poll.PollID)
+		published++
+	}
+	return published, nil
+}
+
+// watchSchedule publishes the drafts that are due every interval in the
+// background
+func (p *PollList) watchSchedule(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			published, err := p.publishDue(now)
+			if err != nil {
+				log.Println("Error publishing scheduled polls: ", err)
+				continue
+			}
+			if published > 0 {
+				log.Println("Published scheduled polls: ", published)
+			}
+		}
+	}()
+}
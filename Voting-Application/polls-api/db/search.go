@@ -0,0 +1,205 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PollSearchIndex is the RediSearch index over the title and question of
+// every poll.  RediSearch keeps it up to date itself as the polls: keys
+// change, so nothing has to be done when a poll is added, updated or
+// deleted
+const PollSearchIndex = "polls-idx"
+
+// SearchMaxResults is the most polls a search returns, the best matches
+// first
+const SearchMaxResults = 1000
+
+// ErrEmptySearch is returned when polls are searched for nothing
+var ErrEmptySearch = errors.New("q must have a word to search for")
+
+// ErrSearchUnavailable is returned when the redis polls are kept in does
+// not have the RediSearch module, redis/redis-stack does
+var ErrSearchUnavailable = errors.New("search needs the RediSearch module")
+
+// createSearchIndex creates PollSearchIndex if it is not there yet.  A
+// title counts for twice as much as a question when the matches are
+// ranked
+func (c *cache) createSearchIndex() error {
+	err := c.cacheClient.Do(c.context, "FT.CREATE", PollSearchIndex,
+		"ON", "JSON", "PREFIX", "1", RedisKeyPrefix,
+		"SCHEMA",
+		"$.PollTitle", "AS", "PollTitle", "TEXT", "WEIGHT", "2",
+		"$.PollQuestion", "AS", "PollQuestion", "TEXT",
+		"$.Settings.Status", "AS", "Status", "TAG",
+	).Err()
+	if err != nil && strings.Contains(err.Error(), "Index already exists") {
+		return nil
+	}
+	return searchError(err)
+}
+
+// searchError turns redis not knowing the FT. commands into
+// ErrSearchUnavailable
+func searchError(err error) error {
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+		return ErrSearchUnavailable
+	}
+	return err
+}
+
+// missingIndex is true if err is RediSearch saying there is no such
+// index, older versions call it an unknown index
+func missingIndex(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "no such index") || strings.Contains(msg, "unknown index")
+}
+
+// searchWords returns the words of q, only its letters and digits are
+// kept so nothing in it is taken as query syntax
+func searchWords(q string) []string {
+	return strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchQuery returns the RediSearch query for polls whose title or
+// question has every word of q
+func searchQuery(q string, includeDrafts bool) (string, error) {
+	words := searchWords(q)
+	if len(words) == 0 {
+		return "", ErrEmptySearch
+	}
+
+	query := "@PollTitle|PollQuestion:(" + strings.Join(words, " ") + ")"
+	if !includeDrafts {
+		query += " -@Status:{" + PollStatusDraft + "}"
+	}
+	return query, nil
+}
+
+// SearchPolls returns the polls whose title or question has every word
+// of q, the best matches first, at most SearchMaxResults of them.  Draft
+// polls are only included if includeDrafts is true.  With redis the
+// RediSearch index is used, and if it has gone, as it does when redis is
+// flushed, it is created again.  Other stores have every poll read and
+// return the matches by id
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) q must have at least one letter or digit
+//
+// Postconditions:
+//
+//	    (1) The matching polls will be returned, an empty slice if
+//			there are none
+//		(2) If q has no words ErrEmptySearch will be returned
+//		(3) If redis has no RediSearch ErrSearchUnavailable will be
+//			returned
+//		(4) If there is an error, it will be returned
+//		(5) The database file will not be modified
+func (p *PollList) SearchPolls(q string, includeDrafts bool) ([]Poll, error) {
+
+	if searcher, ok := p.store.(pollSearcher); ok {
+		return searcher.search(q, includeDrafts)
+	}
+
+	words := searchWords(q)
+	if len(words) == 0 {
+		return nil, ErrEmptySearch
+	}
+	all, err := p.store.list()
+	if err != nil {
+		return nil, err
+	}
+	pollList := []Poll{}
+	for _, poll := range all {
+		if poll.Settings.Status == PollStatusDraft && !includeDrafts {
+			continue
+		}
+		if matchesSearch(poll, words) {
+			pollList = append(pollList, poll)
+		}
+	}
+	sort.Slice(pollList, func(i, j int) bool {
+		return pollList[i].PollID < pollList[j].PollID
+	})
+	if len(pollList) > SearchMaxResults {
+		pollList = pollList[:SearchMaxResults]
+	}
+	return pollList, nil
+}
+
+// matchesSearch reports whether every one of words is in the title or
+// question of poll, ignoring case
+func matchesSearch(poll Poll, words []string) bool {
+	text := strings.ToLower(poll.PollTitle + " " + poll.PollQuestion)
+	for _, word := range words {
+		if !strings.Contains(text, strings.ToLower(word)) {
+			return false
+		}
+	}
+	return true
+}
+
+// search runs q against the RediSearch index
+func (r *redisStore) search(q string, includeDrafts bool) ([]Poll, error) {
+
+	query, err := searchQuery(q, includeDrafts)
+	if err != nil {
+		return nil, err
+	}
+
+	search := func() ([]interface{}, error) {
+		//NOCONTENT returns just the keys, the polls are then read the
+		//same way as every other list
+		val, err := r.cacheClient.Do(r.context, "FT.SEARCH", PollSearchIndex, query,
+			"NOCONTENT", "LIMIT", "0", SearchMaxResults).Result()
+		if err != nil {
+			return nil, err
+		}
+		res, ok := val.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected FT.SEARCH reply %T", val)
+		}
+		return res, nil
+	}
+	res, err := search()
+	if err != nil && missingIndex(err) {
+		log.Println("Search index missing, creating it: ", err)
+		if err := r.createSearchIndex(); err != nil {
+			return nil, err
+		}
+		res, err = search()
+	}
+	if err != nil {
+		return nil, searchError(err)
+	}
+
+	//The reply is the number of matches followed by their keys, best
+	//match first
+	var keys []string
+	for i, r := range res {
+		if i == 0 {
+			continue
+		}
+		if key, ok := r.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return []Poll{}, nil
+	}
+
+	pollList, err := r.getItemsFromRedis(keys)
+	if err != nil {
+		return nil, err
+	}
+	if pollList == nil {
+		pollList = []Poll{}
+	}
+	return pollList, nil
+}
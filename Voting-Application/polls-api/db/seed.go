@@ -0,0 +1,101 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// seedFromEnv seeds the DB from the file named by SEED_FILE, if it is
+// set, so that demos and tests start from known data.  SEED_FORCE=true
+// seeds even if there are already polls.  Seeding problems are logged
+// rather than stopping the service from starting
+func (p *PollList) seedFromEnv() {
+	path := os.Getenv("SEED_FILE")
+	if path == "" {
+		return
+	}
+
+	force := false
+	if forceS := os.Getenv("SEED_FORCE"); forceS != "" {
+		b, err := strconv.ParseBool(forceS)
+		if err != nil {
+			log.Println("Invalid SEED_FORCE, using default: ", forceS)
+		} else {
+			force = b
+		}
+	}
+
+	seeded, err := p.SeedFromFile(path, force)
+	if err != nil {
+		log.Println("Error seeding polls from ", path, ": ", err)
+		return
+	}
+	log.Println("Seeded ", seeded, " polls from ", path)
+}
+
+// SeedFromFile accepts the path of a JSON file holding an array of
+// polls and adds them to the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The file must hold a JSON array of polls
+//
+// Postconditions:
+//
+//	    (1) If the DB has no polls, or force is true, the polls in
+//			the file will be added, with force any that already exist
+//			are overwritten
+//		(2) If the DB already has polls and force is false nothing
+//			is seeded and 0 is returned
+//		(3) Polls that can't be stored are logged and skipped, the
+//			number that were seeded is returned
+//		(4) If the file can't be read, or there is an error, it will
+//			be returned
+func (p *PollList) SeedFromFile(path string, force bool) (int, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return 0, err
+	}
+
+	if !force {
+		existing, err := p.store.list()
+		if err != nil {
+			return 0, err
+		}
+		if len(existing) > 0 {
+			log.Println("Not seeding, there are already polls, set SEED_FORCE=true to seed anyway")
+			return 0, nil
+		}
+	}
+
+	seeded := 0
+	for i, record := range records {
+		poll, err := p.JsonToPoll(string(record))
+		if err != nil {
+			log.Println("Skipping seed record ", i, ": ", err)
+			continue
+		}
+		_, err = p.GetPoll(poll.PollID)
+		switch {
+		case err == nil:
+			err = p.UpdatePoll(poll, true, time.Now())
+		case errors.Is(err, ErrNotFound):
+			err = p.AddPoll(poll)
+		}
+		if err != nil {
+			log.Println("Skipping seed record ", i, ": ", err)
+			continue
+		}
+		seeded++
+	}
+
+	return seeded, nil
+}
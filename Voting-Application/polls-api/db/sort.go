@@ -0,0 +1,49 @@
+package db
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrInvalidSort is returned when polls are sorted by a field they can't
+// be sorted by
+var ErrInvalidSort = errors.New("sort must be PollID or PollTitle")
+
+// PollSort picks the order GetAllPolls returns polls in.  The zero value
+// is by PollID, smallest first, as the list has always been
+type PollSort struct {
+	//Field is PollID or PollTitle, empty for PollID
+	Field string
+	//Desc reverses the order
+	Desc bool
+}
+
+// Validate returns ErrInvalidSort if Field isn't one polls can be sorted
+// by
+func (s PollSort) Validate() error {
+	switch s.Field {
+	case "", "PollID", "PollTitle":
+		return nil
+	}
+	return ErrInvalidSort
+}
+
+// sortPolls sorts pollList in place.  Polls with the same title stay in
+// PollID order, whichever way the list is sorted, so that pages of the
+// list are stable
+func (s PollSort) sortPolls(pollList []Poll) {
+	sort.Slice(pollList, func(i, j int) bool {
+		a, b := pollList[i], pollList[j]
+		if s.Field == "PollTitle" && a.PollTitle != b.PollTitle {
+			if s.Desc {
+				return a.PollTitle > b.PollTitle
+			}
+			return a.PollTitle < b.PollTitle
+		}
+		//Only a sort by id runs backwards on the id
+		if s.Desc && s.Field != "PollTitle" {
+			return a.PollID > b.PollID
+		}
+		return a.PollID < b.PollID
+	})
+}
@@ -0,0 +1,154 @@
+//go:build sqlite
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema creates the polls table if it is not there yet.  Each poll
+// is kept whole as a JSON document, the same shape it has in redis, keyed
+// by its id.  The version is kept beside it so a write can check it
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS polls (
+	poll_id INTEGER PRIMARY KEY,
+	version INTEGER NOT NULL,
+	doc     TEXT    NOT NULL
+)`
+
+// sqliteStore keeps polls in a SQLite file, so the polls API can be run
+// locally with nothing else installed and keep its data between runs
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLitePollList opens, or creates, the SQLite database file at path
+// and returns a PollList that keeps its polls there instead of redis.
+// Passcodes, invitations, ballot links and the audit log need redis, so
+// they are not available.  It is only available in builds made with
+// -tags sqlite
+func NewSQLitePollList(path string) (*PollList, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	//SQLite only allows one writer at a time, sharing one connection
+	//stops concurrent requests failing with "database is locked"
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	pollList := newPollList(&sqliteStore{db: db}, withoutRedis())
+	pollList.startFromEnv()
+
+	return pollList, nil
+}
+
+func (s *sqliteStore) get(id uint) (Poll, error) {
+	var doc []byte
+	err := s.db.QueryRow(`SELECT doc FROM polls WHERE poll_id = ?`, id).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Poll{}, ErrNotFound
+	}
+	if err != nil {
+		return Poll{}, err
+	}
+
+	var poll Poll
+	if err := json.Unmarshal(doc, &poll); err != nil {
+		return Poll{}, err
+	}
+	return poll, nil
+}
+
+// create leaves a poll that is already there alone, so of two polls
+// added with the same id at once only one goes in
+func (s *sqliteStore) create(poll Poll) error {
+	doc, err := json.Marshal(poll)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec(`INSERT INTO polls (poll_id, version, doc) VALUES (?, ?, ?)
+		ON CONFLICT (poll_id) DO NOTHING`, poll.PollID, poll.Version, string(doc))
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// putVersion checks the version in the same UPDATE that writes the poll
+func (s *sqliteStore) putVersion(poll Poll, version uint) error {
+	poll.Version = version + 1
+	doc, err := json.Marshal(poll)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec(`UPDATE polls SET version = ?, doc = ? WHERE poll_id = ? AND version = ?`,
+		poll.Version, string(doc), poll.PollID, version)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return missedVersion(s, poll.PollID, version)
+	}
+	return nil
+}
+
+func (s *sqliteStore) delete(id uint) error {
+	result, err := s.db.Exec(`DELETE FROM polls WHERE poll_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) deleteAll() error {
+	_, err := s.db.Exec(`DELETE FROM polls`)
+	return err
+}
+
+func (s *sqliteStore) list() ([]Poll, error) {
+	rows, err := s.db.Query(`SELECT doc FROM polls ORDER BY poll_id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanPolls(rows)
+}
+
+// listPage uses the last poll id of the previous page as the cursor,
+// poll ids start at 1 so 0 is the start
+func (s *sqliteStore) listPage(cursor uint64, count int) ([]Poll, uint64, error) {
+	count = pageCount(count)
+	rows, err := s.db.Query(`SELECT doc FROM polls WHERE poll_id > ? ORDER BY poll_id LIMIT ?`, cursor, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	polls, err := scanPolls(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return polls, lastPollID(polls, count), nil
+}
+
+func (s *sqliteStore) ping() error {
+	return s.db.Ping()
+}
@@ -0,0 +1,14 @@
+//go:build !sqlite
+
+package db
+
+import "errors"
+
+// ErrSQLiteNotBuilt is returned when the SQLite store is asked for by a
+// build made without -tags sqlite, which leaves out the SQLite driver
+var ErrSQLiteNotBuilt = errors.New("built without SQLite support, rebuild with -tags sqlite")
+
+// NewSQLitePollList is only available in builds made with -tags sqlite
+func NewSQLitePollList(path string) (*PollList, error) {
+	return nil, ErrSQLiteNotBuilt
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// ErrNotStorable is returned when an item cannot be written to ReJSON
+// without losing or changing some of its data
+var ErrNotStorable = errors.New("item cannot be stored")
+
+// validatePollStorable checks that the poll comes back unchanged after the
+// trip through JSON that JSONSet and JSONGet put it through.  JSONSet
+// stores whatever it is handed, so without this a lossy poll is only
+// noticed later when it is read back wrong.  The poll is checked without
+// its MarshalJSON, which fills in Status and so never round trips
+func validatePollStorable(poll Poll) error {
+	type plainPoll Poll
+	return validateStorable(plainPoll(poll))
+}
+
+// validateStorable marshals item, unmarshals it into a fresh value and
+// compares the two.  If they differ, some field did not survive the round
+// trip.  It also makes sure every number in the document is one ReJSON
+// can hold, ReJSON keeps integers as signed 64 bit values, so a uint
+// above math.MaxInt64 would be mangled
+func validateStorable[T any](item T) error {
+	doc, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotStorable, err)
+	}
+
+	var roundTrip T
+	if err := json.Unmarshal(doc, &roundTrip); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotStorable, err)
+	}
+	if !storableEqual(reflect.ValueOf(item), reflect.ValueOf(roundTrip)) {
+		return fmt.Errorf("%w: %T changes when read back as %s", ErrNotStorable, item, doc)
+	}
+
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotStorable, err)
+	}
+	return checkStorableNumbers(generic, "")
+}
+
+// storableEqual compares the exported data of two values the way JSON
+// sees it.  Times are compared with Equal because a time read back from
+// JSON has lost its monotonic clock reading and location pointer
+func storableEqual(a, b reflect.Value) bool {
+	if a.Type() == reflect.TypeOf(time.Time{}) {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !a.Type().Field(i).IsExported() {
+				continue
+			}
+			if !storableEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !storableEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			other := b.MapIndex(iter.Key())
+			if !other.IsValid() || !storableEqual(iter.Value(), other) {
+				return false
+			}
+		}
+		return true
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return storableEqual(a.Elem(), b.Elem())
+	}
+	return a.Interface() == b.Interface()
+}
+
+// checkStorableNumbers walks a decoded JSON document and returns an error
+// naming the first number that ReJSON cannot represent
+func checkStorableNumbers(value any, path string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if err := checkStorableNumbers(child, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if err := checkStorableNumbers(child, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil || math.IsInf(f, 0) {
+			return fmt.Errorf("%w: %s is out of range (%s)", ErrNotStorable, path, v)
+		}
+		//A whole number that did not fit in an int64 would be stored as a
+		//double and lose precision
+		if f == math.Trunc(f) && !bytes.ContainsAny([]byte(v.String()), ".eE") {
+			return fmt.Errorf("%w: %s is out of range (%s)", ErrNotStorable, path, v)
+		}
+	}
+	return nil
+}
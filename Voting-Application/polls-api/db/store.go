@@ -0,0 +1,302 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// pollStore is where the polls are kept.  PollList does all of its reads
+// and writes of polls through a pollStore, so the backend can be swapped
+// without touching the rules in PollList.  create only adds a poll whose
+// id is free, or returns ErrAlreadyExists, and putVersion only writes a
+// poll over the one stored if its Version is still version, giving it
+// version+1, or returns ErrVersionMismatch.  listPage returns about count
+// polls from cursor on, 0 for the start, and the cursor to carry on
+// from, 0 after the last page.  What a cursor means is up to each store
+type pollStore interface {
+	get(id uint) (Poll, error)
+	create(poll Poll) error
+	putVersion(poll Poll, version uint) error
+	delete(id uint) error
+	deleteAll() error
+	list() ([]Poll, error)
+	listPage(cursor uint64, count int) ([]Poll, uint64, error)
+	ping() error
+}
+
+// ErrNeedsRedis is returned by the features of the polls API that are
+// built on redis whatever store the polls are in, passcodes, invitations
+// and ballot links, when the polls are kept somewhere else
+var ErrNeedsRedis = errors.New("only available when the polls are kept in redis")
+
+// withoutRedis is the cache of a PollList that keeps its polls in
+// another store, it has no client, see needsRedis
+func withoutRedis() cache {
+	return cache{
+		context:   context.Background(),
+		scanBatch: scanBatchSizeFromEnv(),
+	}
+}
+
+// needsRedis returns ErrNeedsRedis if there is no redis, the PollList
+// keeps its polls in another store
+func (c *cache) needsRedis() error {
+	if c.cacheClient == nil {
+		return ErrNeedsRedis
+	}
+	return nil
+}
+
+// redisStore keeps polls in redis as ReJSON documents under polls:<id>,
+// with their tags in the tag index, see tags.go
+type redisStore struct {
+	cache
+}
+
+// Helper to return a poll from redis provided a key
+func (r *redisStore) getItemFromRedis(key string, poll *Poll) error {
+
+	//Lets query redis for the poll, note we can return parts of the
+	//json structure, the second parameter "." means return the entire
+	//json structure
+	//ReJSON reports a key that does not exist as a redis nil, anything
+	//else is a real error talking to redis
+	pollObject, err := r.jsonHelper.JSONGet(key, ".")
+	if err != nil {
+		if err.Error() == RedisNilError {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	//JSONGet returns an "any" object, or empty interface,
+	//we need to convert it to a byte array, which is the
+	//underlying type of the object, then we can unmarshal
+	//it into our poll struct
+	err = json.Unmarshal(pollObject.([]byte), poll)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Helper to return the polls at keys from redis, reading them in batches
+// rather than one at a time.  Keys that have been deleted since they were
+// listed are skipped
+func (r *redisStore) getItemsFromRedis(keys []string) ([]Poll, error) {
+	docs, err := r.getDocsFromRedis(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var polls []Poll
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		//A new poll each time, unmarshalling into the same struct would
+		//leave behind fields from the previous poll
+		var poll Poll
+		if err := json.Unmarshal(doc, &poll); err != nil {
+			return nil, err
+		}
+		polls = append(polls, poll)
+	}
+	return polls, nil
+}
+
+func (r *redisStore) get(id uint) (Poll, error) {
+	var poll Poll
+	if err := r.getItemFromRedis(redisKeyFromId(id), &poll); err != nil {
+		return Poll{}, err
+	}
+	return poll, nil
+}
+
+// create uses JSON.SET's NX option, so of two polls added with the same
+// id at once only one goes in
+func (r *redisStore) create(poll Poll) error {
+	if err := r.setNew(redisKeyFromId(poll.PollID), poll); err != nil {
+		return err
+	}
+	r.indexTags(poll.PollID, nil, poll.Tags)
+	return nil
+}
+
+func (r *redisStore) putVersion(poll Poll, version uint) error {
+	//The old tags have to come out of the tag index.  Versions only go
+	//up, so if the write goes through these are the tags it replaced
+	old, err := r.get(poll.PollID)
+	if err != nil {
+		return err
+	}
+	if old.Version != version {
+		return versionMismatch(version, old.Version)
+	}
+
+	if err := r.setVersioned(redisKeyFromId(poll.PollID), ".", poll, version); err != nil {
+		return err
+	}
+	r.indexTags(poll.PollID, old.Tags, poll.Tags)
+	return nil
+}
+
+func (r *redisStore) delete(id uint) error {
+	old, err := r.get(id)
+	if err != nil {
+		return err
+	}
+
+	numDeleted, err := r.cacheClient.Del(r.context, redisKeyFromId(id)).Result()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	r.indexTags(id, old.Tags, nil)
+	return nil
+}
+
+// deleteAll deletes every poll, and with them the tag index
+func (r *redisStore) deleteAll() error {
+	ks, err := r.scanKeys(RedisKeyPrefix + "*")
+	if err != nil {
+		return err
+	}
+	//Note delete can take a collection of keys.  In go we can
+	//expand a slice into individual arguments by using the ...
+	//operator
+	if len(ks) > 0 {
+		numDeleted, err := r.cacheClient.Del(r.context, ks...).Result()
+		if err != nil {
+			return err
+		}
+		if numDeleted != int64(len(ks)) {
+			return errors.New("one or more polls could not be deleted")
+		}
+	}
+
+	tagKeys, err := r.scanKeys(PollTagKeyPrefix + "*")
+	if err != nil {
+		return err
+	}
+	if len(tagKeys) > 0 {
+		return r.cacheClient.Del(r.context, tagKeys...).Err()
+	}
+	return nil
+}
+
+func (r *redisStore) list() ([]Poll, error) {
+	ks, err := r.scanKeys(RedisKeyPrefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	return r.getItemsFromRedis(ks)
+}
+
+// listPage uses the SCAN cursor, so a page can have more or fewer than
+// count polls, and it is count keys that are asked for, 0 for the batch
+// size
+func (r *redisStore) listPage(cursor uint64, count int) ([]Poll, uint64, error) {
+	ks, next, err := r.scanPage(RedisKeyPrefix+"*", cursor, int64(count))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	polls, err := r.getItemsFromRedis(ks)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return polls, next, nil
+}
+
+func (r *redisStore) ping() error {
+	return r.cacheClient.Ping(r.context).Err()
+}
+
+// pollTagIndex is a pollStore that keeps an index of the polls with each
+// tag, so they can be listed without reading every poll.  Other stores
+// are read whole and filtered
+type pollTagIndex interface {
+	listTagged(tag string) ([]Poll, error)
+}
+
+// pollSummarizer is a pollStore that can read just the ids, titles and
+// statuses of the polls rather than the whole polls.  Other stores are
+// read whole
+type pollSummarizer interface {
+	summaries(includeDrafts bool) ([]PollSummary, error)
+}
+
+// pollSearcher is a pollStore with a full text index of the titles and
+// questions of the polls, returning the best matches first.  Other
+// stores are read whole and searched word by word, see SearchPolls
+type pollSearcher interface {
+	search(q string, includeDrafts bool) ([]Poll, error)
+}
+
+// idAllocator is a pollStore that can give out new poll ids itself, so
+// that two replicas never hand out the same one.  Other stores are given
+// one more than the highest id they have, and a clash is caught by create
+type idAllocator interface {
+	newID() (uint, error)
+}
+
+func (r *redisStore) newID() (uint, error) {
+	return r.nextID(PollIDCounterKey, RedisKeyPrefix)
+}
+
+// lastPollID returns the PollID of the last poll of a full page, as the
+// cursor of the next page, or 0 if the page was short so it was the
+// last one.  It is the cursor of the stores that page by id
+func lastPollID(polls []Poll, count int) uint64 {
+	if len(polls) < count || len(polls) == 0 {
+		return 0
+	}
+	return uint64(polls[len(polls)-1].PollID)
+}
+
+// pageCount is the number of polls a store that pages by id puts on a
+// page, count or the scan batch size if it is not positive
+func pageCount(count int) int {
+	if count <= 0 {
+		return DefaultScanBatchSize
+	}
+	return count
+}
+
+// scanPolls reads the doc column of each of rows as a poll, for the
+// SQLite and postgres stores
+func scanPolls(rows *sql.Rows) ([]Poll, error) {
+	defer rows.Close()
+
+	var polls []Poll
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var poll Poll
+		if err := json.Unmarshal(doc, &poll); err != nil {
+			return nil, err
+		}
+		polls = append(polls, poll)
+	}
+	return polls, rows.Err()
+}
+
+// missedVersion is the error of a putVersion that wrote nothing, either
+// the poll has gone or its version has moved on, which it reads the poll
+// again to tell apart
+func missedVersion(s pollStore, id uint, version uint) error {
+	poll, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	return versionMismatch(version, poll.Version)
+}
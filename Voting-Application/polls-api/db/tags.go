@@ -0,0 +1,127 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// MaxPollTags is the most tags a poll can have, and MaxTagLength the
+// most characters a tag can have
+const (
+	MaxPollTags  = 10
+	MaxTagLength = 32
+)
+
+// PollTagKeyPrefix is the prefix of the tag index.  The ids of the polls
+// with each tag are kept in a set under poll-tags:<tag>, so the polls
+// with a tag can be listed without scanning every poll.  It does not
+// start with RedisKeyPrefix, so the sets are never read as polls
+const PollTagKeyPrefix = "poll-tags:"
+
+// ErrInvalidTags is returned when a poll is given a tag that is empty,
+// too long or has characters other than letters, digits, - and _, or
+// more than MaxPollTags tags
+var ErrInvalidTags = errors.New("invalid tags")
+
+func pollTagKey(tag string) string {
+	return PollTagKeyPrefix + tag
+}
+
+// normalizeTag trims a tag and lowercases it, so that "Budget" and
+// " budget" are the same tag
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// validateTag makes sure a normalized tag is one a poll can have
+func validateTag(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("%w: a tag can't be empty", ErrInvalidTags)
+	}
+	if len(tag) > MaxTagLength {
+		return fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidTags, tag, MaxTagLength)
+	}
+	for _, r := range tag {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return fmt.Errorf("%w: tag %q can only have letters, digits, - and _", ErrInvalidTags, tag)
+		}
+	}
+	return nil
+}
+
+// normalizeTags normalizes every tag of a poll and drops the repeats,
+// keeping the first of each in order.  No tags is nil, so polls without
+// tags are stored as they were before there were tags
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if err := validateTag(tag); err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxPollTags {
+		return nil, fmt.Errorf("%w: a poll can have at most %d tags", ErrInvalidTags, MaxPollTags)
+	}
+	return normalized, nil
+}
+
+// indexTags moves the poll with id from the index sets of the tags in old
+// it no longer has to those of the tags in tags.  Like the vote indexes of
+// the votes API a failure is only logged, the poll has already been
+// written, and the polls read through the index are checked against
+// their tags so a stale entry is never listed
+func (c *cache) indexTags(id uint, old []string, tags []string) {
+	keep := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		keep[tag] = true
+	}
+	for _, tag := range old {
+		if keep[tag] {
+			continue
+		}
+		if err := c.cacheClient.SRem(c.context, pollTagKey(tag), id).Err(); err != nil {
+			log.Println("Error unindexing tag ", tag, " of poll ", id, ": ", err)
+		}
+	}
+	for _, tag := range tags {
+		if err := c.cacheClient.SAdd(c.context, pollTagKey(tag), id).Err(); err != nil {
+			log.Println("Error indexing tag ", tag, " of poll ", id, ": ", err)
+		}
+	}
+}
+
+// listTagged returns the polls in the index set of tag.  The caller
+// still checks their tags, see indexTags
+func (r *redisStore) listTagged(tag string) ([]Poll, error) {
+	ids, err := r.cacheClient.SMembers(r.context, pollTagKey(tag)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ks := make([]string, 0, len(ids))
+	for _, id := range ids {
+		ks = append(ks, RedisKeyPrefix+id)
+	}
+	return r.getItemsFromRedis(ks)
+}
+
+// hasTag reports whether a poll has the normalized tag
+func (poll Poll) hasTag(tag string) bool {
+	for _, t := range poll.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"sort"
+	"strings"
+)
+
+// WriteInResult is how one write-in answer did.  Answers that differ only
+// in case or spacing are counted together, under the spelling most of
+// them used
+type WriteInResult struct {
+	Text    string  `json:"text"`
+	Count   uint    `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// writeInGroup counts the votes for one write-in answer, and how many of
+// them used each spelling of it
+type writeInGroup struct {
+	count     uint
+	spellings map[string]uint
+}
+
+// writeInKey is what write-in answers are grouped by, the answer in lower
+// case with its spaces collapsed
+func writeInKey(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// addWriteIn counts a vote for the write-in answer text in groups, it
+// returns false if the answer is empty
+func addWriteIn(groups map[string]*writeInGroup, text string) bool {
+	key := writeInKey(text)
+	if key == "" {
+		return false
+	}
+	group, ok := groups[key]
+	if !ok {
+		group = &writeInGroup{spellings: make(map[string]uint)}
+		groups[key] = group
+	}
+	group.count++
+	group.spellings[strings.Join(strings.Fields(text), " ")]++
+	return true
+}
+
+// writeInResults returns the write-in answers in groups with the most
+// votes first, and the share of total each has
+func writeInResults(groups map[string]*writeInGroup, total uint) []WriteInResult {
+	results := make([]WriteInResult, 0, len(groups))
+	for _, group := range groups {
+		result := WriteInResult{Count: group.count}
+		var most uint
+		for spelling, count := range group.spellings {
+			if count > most || (count == most && spelling < result.Text) {
+				result.Text = spelling
+				most = count
+			}
+		}
+		if total > 0 {
+			result.Percent = 100 * float64(result.Count) / float64(total)
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return results[i].Text < results[j].Text
+	})
+	return results
+}
@@ -0,0 +1,44 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+type Poll struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Question string `json:"question"`
+	// draft, scheduled, open or closed
+	Status  string        `json:"status"`
+	Tags    []string      `json:"tags"`
+	Version int           `json:"version"`
+	Options []*PollOption `json:"options"`
+	// The votes cast in the poll, read from the votes API
+	Votes []*Vote `json:"votes"`
+}
+
+type PollOption struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+}
+
+type Query struct {
+}
+
+type Vote struct {
+	ID int `json:"id"`
+	// The option voted for, 0 for a write-in
+	Value int `json:"value"`
+	// Every option selected, for polls that allow more than one
+	Values      []int   `json:"values"`
+	WriteInText *string `json:"writeInText,omitempty"`
+	Source      string  `json:"source"`
+	// Null for a vote in an anonymous poll
+	VoterID *int `json:"voterId,omitempty"`
+	// The voter who cast the vote, read from the voters API, null for a vote in an anonymous poll or by a voter that has since been deleted
+	Voter *Voter `json:"voter,omitempty"`
+}
+
+type Voter struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
@@ -0,0 +1,156 @@
+// Package graph is the GraphQL endpoint of the polls API.  generated.go
+// and model/models_gen.go are generated by gqlgen from schema.graphqls,
+// the resolvers in schema.resolvers.go fill the polls in from the polls
+// API itself and their votes and voters from the other two services
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"drexel.edu/polls/db"
+	"drexel.edu/polls/graph/model"
+	"drexel.edu/voting-application/client"
+	"drexel.edu/voting-application/client/votersclient"
+	"drexel.edu/voting-application/client/votesclient"
+	"github.com/99designs/gqlgen/graphql/handler"
+)
+
+// PollReader is the part of the polls API's store the resolvers read
+// polls through
+type PollReader interface {
+	GetAllPolls(filter db.PollFilter, order db.PollSort) ([]db.Poll, error)
+	GetPoll(id uint) (db.Poll, error)
+}
+
+// Resolver reads polls from polls, and their votes and voters from the
+// votes and voters APIs
+type Resolver struct {
+	polls  PollReader
+	votes  *votesclient.Client
+	voters *votersclient.Client
+}
+
+// NewHandler returns the GraphQL endpoint, serving queries over GET and
+// POST, reading polls from polls and their votes and voters from the
+// votes API at votesURL and the voters API at votersURL
+func NewHandler(polls PollReader, votesURL string, votersURL string) http.Handler {
+	resolver := &Resolver{
+		polls:  polls,
+		votes:  votesclient.New(votesURL),
+		voters: votersclient.New(votersURL),
+	}
+	srv := handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: resolver}))
+
+	//Each query gets its own voter cache, so a voter who voted more than
+	//once in the polls asked for is only read once
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), votersKey{}, &voterCache{voters: map[uint]*voterRead{}})
+		srv.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// votersKey is the context key of the voterCache of a query
+type votersKey struct{}
+
+// voterCache holds the voters read during one query
+type voterCache struct {
+	lock   sync.Mutex
+	voters map[uint]*voterRead
+}
+
+// voterRead is the read of one voter, the first resolver to ask for the
+// voter reads it and the others wait for that
+type voterRead struct {
+	once  sync.Once
+	voter *model.Voter
+	err   error
+}
+
+// voter returns the voter with id, nil if the voters API has no such
+// voter, reading it at most once per query
+func (r *Resolver) voter(ctx context.Context, id uint) (*model.Voter, error) {
+	cache, ok := ctx.Value(votersKey{}).(*voterCache)
+	if !ok {
+		return r.readVoter(ctx, id)
+	}
+
+	cache.lock.Lock()
+	read, ok := cache.voters[id]
+	if !ok {
+		read = &voterRead{}
+		cache.voters[id] = read
+	}
+	cache.lock.Unlock()
+
+	read.once.Do(func() {
+		read.voter, read.err = r.readVoter(ctx, id)
+	})
+	return read.voter, read.err
+}
+
+// readVoter reads the voter with id from the voters API
+func (r *Resolver) readVoter(ctx context.Context, id uint) (*model.Voter, error) {
+	voter, err := r.voters.GetVoter(ctx, id)
+	if errors.Is(err, client.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.Voter{ID: int(voter.VoterID), FirstName: voter.FirstName, LastName: voter.LastName}, nil
+}
+
+// toPoll is poll as the GraphQL model, its votes are resolved when they
+// are asked for
+func toPoll(poll db.Poll) *model.Poll {
+	options := make([]*model.PollOption, 0, len(poll.PollOptions))
+	for _, option := range poll.PollOptions {
+		options = append(options, &model.PollOption{ID: int(option.PollOptionID), Text: option.PollOptionText})
+	}
+	tags := poll.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &model.Poll{
+		ID:       int(poll.PollID),
+		Title:    poll.PollTitle,
+		Question: poll.PollQuestion,
+		Status:   poll.Settings.State(time.Now()),
+		Tags:     tags,
+		Version:  int(poll.Version),
+		Options:  options,
+	}
+}
+
+// toVote is vote as the GraphQL model, its voter is resolved when it is
+// asked for
+func toVote(vote votesclient.Vote) *model.Vote {
+	values := make([]int, 0, len(vote.VoteValues))
+	for _, value := range vote.VoteValues {
+		values = append(values, int(value))
+	}
+	if len(values) == 0 {
+		values = append(values, int(vote.VoteValue))
+	}
+
+	v := &model.Vote{
+		ID:     int(vote.VoteID),
+		Value:  int(vote.VoteValue),
+		Values: values,
+		Source: vote.Source,
+	}
+	if vote.WriteInText != "" {
+		v.WriteInText = &vote.WriteInText
+	}
+	if vote.VoterHash == "" {
+		voterId := int(vote.VoterID)
+		v.VoterID = &voterId
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.49
+
+import (
+	"context"
+	"errors"
+
+	"drexel.edu/polls/db"
+	"drexel.edu/polls/graph/model"
+	"drexel.edu/voting-application/client"
+)
+
+// Votes is the resolver for the votes field.
+func (r *pollResolver) Votes(ctx context.Context, obj *model.Poll) ([]*model.Vote, error) {
+	//The votes API answers 404 for a poll nobody has voted in yet
+	votes, err := r.votes.GetPollVotes(ctx, uint(obj.ID))
+	if errors.Is(err, client.ErrNotFound) {
+		return []*model.Vote{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	voteList := make([]*model.Vote, 0, len(votes))
+	for _, vote := range votes {
+		voteList = append(voteList, toVote(vote))
+	}
+	return voteList, nil
+}
+
+// Poll is the resolver for the poll field.
+func (r *queryResolver) Poll(ctx context.Context, id int) (*model.Poll, error) {
+	if id < 0 {
+		return nil, nil
+	}
+	poll, err := r.polls.GetPoll(uint(id))
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPoll(poll), nil
+}
+
+// Polls is the resolver for the polls field.
+func (r *queryResolver) Polls(ctx context.Context, includeDrafts *bool, includeArchived *bool, tag *string) ([]*model.Poll, error) {
+	filter := db.PollFilter{
+		IncludeDrafts:   includeDrafts != nil && *includeDrafts,
+		IncludeArchived: includeArchived != nil && *includeArchived,
+	}
+	if tag != nil {
+		filter.Tag = *tag
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	polls, err := r.polls.GetAllPolls(filter, db.PollSort{})
+	if err != nil {
+		return nil, err
+	}
+	pollList := make([]*model.Poll, 0, len(polls))
+	for _, poll := range polls {
+		pollList = append(pollList, toPoll(poll))
+	}
+	return pollList, nil
+}
+
+// Voter is the resolver for the voter field.
+func (r *voteResolver) Voter(ctx context.Context, obj *model.Vote) (*model.Voter, error) {
+	if obj.VoterID == nil {
+		return nil, nil
+	}
+	return r.voter(ctx, uint(*obj.VoterID))
+}
+
+// Poll returns PollResolver implementation.
+func (r *Resolver) Poll() PollResolver { return &pollResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Vote returns VoteResolver implementation.
+func (r *Resolver) Vote() VoteResolver { return &voteResolver{r} }
+
+type pollResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type voteResolver struct{ *Resolver }
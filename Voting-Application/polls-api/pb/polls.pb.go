@@ -0,0 +1,1283 @@
+// The Polls API over gRPC.  The messages mirror the JSON the REST API
+// sends, field for field apart from Links, so that a poll read over either
+// is the same poll.  Timestamps are RFC 3339 strings, as in the JSON
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: polls.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PollOption struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PollOptionId   uint32 `protobuf:"varint,1,opt,name=poll_option_id,json=pollOptionId,proto3" json:"poll_option_id,omitempty"`
+	PollOptionText string `protobuf:"bytes,2,opt,name=poll_option_text,json=pollOptionText,proto3" json:"poll_option_text,omitempty"`
+}
+
+func (x *PollOption) Reset() {
+	*x = PollOption{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PollOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollOption) ProtoMessage() {}
+
+func (x *PollOption) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollOption.ProtoReflect.Descriptor instead.
+func (*PollOption) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PollOption) GetPollOptionId() uint32 {
+	if x != nil {
+		return x.PollOptionId
+	}
+	return 0
+}
+
+func (x *PollOption) GetPollOptionText() string {
+	if x != nil {
+		return x.PollOptionText
+	}
+	return ""
+}
+
+type PollSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// draft or published
+	Status        string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	VoteRateLimit uint32 `protobuf:"varint,2,opt,name=vote_rate_limit,json=voteRateLimit,proto3" json:"vote_rate_limit,omitempty"`
+	// empty while the poll stays open
+	ClosesAt         string `protobuf:"bytes,3,opt,name=closes_at,json=closesAt,proto3" json:"closes_at,omitempty"`
+	ResultsPublished bool   `protobuf:"varint,4,opt,name=results_published,json=resultsPublished,proto3" json:"results_published,omitempty"`
+	// left out of the poll list by default and takes no votes
+	Archived bool `protobuf:"varint,5,opt,name=archived,proto3" json:"archived,omitempty"`
+	// a draft with an opening time opens by itself once it has passed
+	OpensAt string `protobuf:"bytes,6,opt,name=opens_at,json=opensAt,proto3" json:"opens_at,omitempty"`
+	// plurality or instant-runoff
+	VotingMethod string `protobuf:"bytes,7,opt,name=voting_method,json=votingMethod,proto3" json:"voting_method,omitempty"`
+	// how many options a vote can select, 0 and 1 mean one
+	MaxSelections uint32 `protobuf:"varint,8,opt,name=max_selections,json=maxSelections,proto3" json:"max_selections,omitempty"`
+	AllowWriteIns bool   `protobuf:"varint,9,opt,name=allow_write_ins,json=allowWriteIns,proto3" json:"allow_write_ins,omitempty"`
+	// votes need the poll's passcode, read only
+	HasPasscode bool `protobuf:"varint,10,opt,name=has_passcode,json=hasPasscode,proto3" json:"has_passcode,omitempty"`
+	// votes need an invitation of their voter, read only
+	InviteOnly bool `protobuf:"varint,11,opt,name=invite_only,json=inviteOnly,proto3" json:"invite_only,omitempty"`
+	// votes keep a hash of their voter instead of voter_id, can't change
+	// once the poll has votes
+	Anonymous bool `protobuf:"varint,12,opt,name=anonymous,proto3" json:"anonymous,omitempty"`
+}
+
+func (x *PollSettings) Reset() {
+	*x = PollSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PollSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollSettings) ProtoMessage() {}
+
+func (x *PollSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollSettings.ProtoReflect.Descriptor instead.
+func (*PollSettings) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PollSettings) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PollSettings) GetVoteRateLimit() uint32 {
+	if x != nil {
+		return x.VoteRateLimit
+	}
+	return 0
+}
+
+func (x *PollSettings) GetClosesAt() string {
+	if x != nil {
+		return x.ClosesAt
+	}
+	return ""
+}
+
+func (x *PollSettings) GetResultsPublished() bool {
+	if x != nil {
+		return x.ResultsPublished
+	}
+	return false
+}
+
+func (x *PollSettings) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+func (x *PollSettings) GetOpensAt() string {
+	if x != nil {
+		return x.OpensAt
+	}
+	return ""
+}
+
+func (x *PollSettings) GetVotingMethod() string {
+	if x != nil {
+		return x.VotingMethod
+	}
+	return ""
+}
+
+func (x *PollSettings) GetMaxSelections() uint32 {
+	if x != nil {
+		return x.MaxSelections
+	}
+	return 0
+}
+
+func (x *PollSettings) GetAllowWriteIns() bool {
+	if x != nil {
+		return x.AllowWriteIns
+	}
+	return false
+}
+
+func (x *PollSettings) GetHasPasscode() bool {
+	if x != nil {
+		return x.HasPasscode
+	}
+	return false
+}
+
+func (x *PollSettings) GetInviteOnly() bool {
+	if x != nil {
+		return x.InviteOnly
+	}
+	return false
+}
+
+func (x *PollSettings) GetAnonymous() bool {
+	if x != nil {
+		return x.Anonymous
+	}
+	return false
+}
+
+type Poll struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PollId       uint32        `protobuf:"varint,1,opt,name=poll_id,json=pollId,proto3" json:"poll_id,omitempty"`
+	PollTitle    string        `protobuf:"bytes,2,opt,name=poll_title,json=pollTitle,proto3" json:"poll_title,omitempty"`
+	PollQuestion string        `protobuf:"bytes,3,opt,name=poll_question,json=pollQuestion,proto3" json:"poll_question,omitempty"`
+	PollOptions  []*PollOption `protobuf:"bytes,4,rep,name=poll_options,json=pollOptions,proto3" json:"poll_options,omitempty"`
+	Settings     *PollSettings `protobuf:"bytes,5,opt,name=settings,proto3" json:"settings,omitempty"`
+	// goes up by one with every change
+	Version uint32 `protobuf:"varint,6,opt,name=version,proto3" json:"version,omitempty"`
+	// draft, scheduled, open or closed, worked out from settings when it is sent
+	Status string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	// lowercase, such as "budget", to group related polls
+	Tags []string `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	// only sent, to give the poll a passcode votes need, never sent back
+	Passcode string `protobuf:"bytes,9,opt,name=passcode,proto3" json:"passcode,omitempty"`
+}
+
+func (x *Poll) Reset() {
+	*x = Poll{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Poll) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Poll) ProtoMessage() {}
+
+func (x *Poll) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Poll.ProtoReflect.Descriptor instead.
+func (*Poll) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Poll) GetPollId() uint32 {
+	if x != nil {
+		return x.PollId
+	}
+	return 0
+}
+
+func (x *Poll) GetPollTitle() string {
+	if x != nil {
+		return x.PollTitle
+	}
+	return ""
+}
+
+func (x *Poll) GetPollQuestion() string {
+	if x != nil {
+		return x.PollQuestion
+	}
+	return ""
+}
+
+func (x *Poll) GetPollOptions() []*PollOption {
+	if x != nil {
+		return x.PollOptions
+	}
+	return nil
+}
+
+func (x *Poll) GetSettings() *PollSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *Poll) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Poll) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Poll) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Poll) GetPasscode() string {
+	if x != nil {
+		return x.Passcode
+	}
+	return ""
+}
+
+// ListPollsRequest pages like ?cursor on GET /polls, a cursor of 0
+// starts at the beginning and a next_cursor of 0 is the last page
+type ListPollsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cursor        uint64 `protobuf:"varint,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	IncludeDrafts bool   `protobuf:"varint,3,opt,name=include_drafts,json=includeDrafts,proto3" json:"include_drafts,omitempty"`
+	// only the polls with this tag, all of them if empty
+	Tag string `protobuf:"bytes,4,opt,name=tag,proto3" json:"tag,omitempty"`
+}
+
+func (x *ListPollsRequest) Reset() {
+	*x = ListPollsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPollsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPollsRequest) ProtoMessage() {}
+
+func (x *ListPollsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPollsRequest.ProtoReflect.Descriptor instead.
+func (*ListPollsRequest) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListPollsRequest) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *ListPollsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListPollsRequest) GetIncludeDrafts() bool {
+	if x != nil {
+		return x.IncludeDrafts
+	}
+	return false
+}
+
+func (x *ListPollsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type ListPollsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Polls      []*Poll `protobuf:"bytes,1,rep,name=polls,proto3" json:"polls,omitempty"`
+	NextCursor uint64  `protobuf:"varint,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+}
+
+func (x *ListPollsResponse) Reset() {
+	*x = ListPollsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPollsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPollsResponse) ProtoMessage() {}
+
+func (x *ListPollsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPollsResponse.ProtoReflect.Descriptor instead.
+func (*ListPollsResponse) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPollsResponse) GetPolls() []*Poll {
+	if x != nil {
+		return x.Polls
+	}
+	return nil
+}
+
+func (x *ListPollsResponse) GetNextCursor() uint64 {
+	if x != nil {
+		return x.NextCursor
+	}
+	return 0
+}
+
+type GetPollRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PollId uint32 `protobuf:"varint,1,opt,name=poll_id,json=pollId,proto3" json:"poll_id,omitempty"`
+}
+
+func (x *GetPollRequest) Reset() {
+	*x = GetPollRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPollRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPollRequest) ProtoMessage() {}
+
+func (x *GetPollRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPollRequest.ProtoReflect.Descriptor instead.
+func (*GetPollRequest) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPollRequest) GetPollId() uint32 {
+	if x != nil {
+		return x.PollId
+	}
+	return 0
+}
+
+// UpdatePollRequest is PUT /polls, force is the admin ?force=true
+type UpdatePollRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Poll  *Poll `protobuf:"bytes,1,opt,name=poll,proto3" json:"poll,omitempty"`
+	Force bool  `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+}
+
+func (x *UpdatePollRequest) Reset() {
+	*x = UpdatePollRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdatePollRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePollRequest) ProtoMessage() {}
+
+func (x *UpdatePollRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePollRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePollRequest) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdatePollRequest) GetPoll() *Poll {
+	if x != nil {
+		return x.Poll
+	}
+	return nil
+}
+
+func (x *UpdatePollRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type DeletePollRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PollId uint32 `protobuf:"varint,1,opt,name=poll_id,json=pollId,proto3" json:"poll_id,omitempty"`
+}
+
+func (x *DeletePollRequest) Reset() {
+	*x = DeletePollRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeletePollRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePollRequest) ProtoMessage() {}
+
+func (x *DeletePollRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePollRequest.ProtoReflect.Descriptor instead.
+func (*DeletePollRequest) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeletePollRequest) GetPollId() uint32 {
+	if x != nil {
+		return x.PollId
+	}
+	return 0
+}
+
+type DeletePollResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeletePollResponse) Reset() {
+	*x = DeletePollResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeletePollResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePollResponse) ProtoMessage() {}
+
+func (x *DeletePollResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePollResponse.ProtoReflect.Descriptor instead.
+func (*DeletePollResponse) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{8}
+}
+
+type OptionResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id      uint32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Text    string  `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Count   uint32  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Percent float64 `protobuf:"fixed64,4,opt,name=percent,proto3" json:"percent,omitempty"`
+}
+
+func (x *OptionResult) Reset() {
+	*x = OptionResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionResult) ProtoMessage() {}
+
+func (x *OptionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionResult.ProtoReflect.Descriptor instead.
+func (*OptionResult) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OptionResult) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *OptionResult) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *OptionResult) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *OptionResult) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+type PollWinner struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Winners     []uint32 `protobuf:"varint,1,rep,packed,name=winners,proto3" json:"winners,omitempty"`
+	WinningText []string `protobuf:"bytes,2,rep,name=winning_text,json=winningText,proto3" json:"winning_text,omitempty"`
+	Count       uint32   `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	IsTie       bool     `protobuf:"varint,4,opt,name=is_tie,json=isTie,proto3" json:"is_tie,omitempty"`
+}
+
+func (x *PollWinner) Reset() {
+	*x = PollWinner{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PollWinner) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollWinner) ProtoMessage() {}
+
+func (x *PollWinner) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollWinner.ProtoReflect.Descriptor instead.
+func (*PollWinner) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PollWinner) GetWinners() []uint32 {
+	if x != nil {
+		return x.Winners
+	}
+	return nil
+}
+
+func (x *PollWinner) GetWinningText() []string {
+	if x != nil {
+		return x.WinningText
+	}
+	return nil
+}
+
+func (x *PollWinner) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *PollWinner) GetIsTie() bool {
+	if x != nil {
+		return x.IsTie
+	}
+	return false
+}
+
+type PollReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Poll        *Poll             `protobuf:"bytes,1,opt,name=poll,proto3" json:"poll,omitempty"`
+	IsOpen      bool              `protobuf:"varint,2,opt,name=is_open,json=isOpen,proto3" json:"is_open,omitempty"`
+	TotalVotes  uint32            `protobuf:"varint,3,opt,name=total_votes,json=totalVotes,proto3" json:"total_votes,omitempty"`
+	Options     []*OptionResult   `protobuf:"bytes,4,rep,name=options,proto3" json:"options,omitempty"`
+	Winner      *PollWinner       `protobuf:"bytes,5,opt,name=winner,proto3" json:"winner,omitempty"`
+	BySource    map[string]uint32 `protobuf:"bytes,6,rep,name=by_source,json=bySource,proto3" json:"by_source,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	GeneratedAt string            `protobuf:"bytes,7,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+}
+
+func (x *PollReport) Reset() {
+	*x = PollReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_polls_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PollReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollReport) ProtoMessage() {}
+
+func (x *PollReport) ProtoReflect() protoreflect.Message {
+	mi := &file_polls_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollReport.ProtoReflect.Descriptor instead.
+func (*PollReport) Descriptor() ([]byte, []int) {
+	return file_polls_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PollReport) GetPoll() *Poll {
+	if x != nil {
+		return x.Poll
+	}
+	return nil
+}
+
+func (x *PollReport) GetIsOpen() bool {
+	if x != nil {
+		return x.IsOpen
+	}
+	return false
+}
+
+func (x *PollReport) GetTotalVotes() uint32 {
+	if x != nil {
+		return x.TotalVotes
+	}
+	return 0
+}
+
+func (x *PollReport) GetOptions() []*OptionResult {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *PollReport) GetWinner() *PollWinner {
+	if x != nil {
+		return x.Winner
+	}
+	return nil
+}
+
+func (x *PollReport) GetBySource() map[string]uint32 {
+	if x != nil {
+		return x.BySource
+	}
+	return nil
+}
+
+func (x *PollReport) GetGeneratedAt() string {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return ""
+}
+
+var File_polls_proto protoreflect.FileDescriptor
+
+var file_polls_proto_rawDesc = []byte{
+	0x0a, 0x0b, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x76,
+	0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x22, 0x5c,
+	0x0a, 0x0a, 0x50, 0x6f, 0x6c, 0x6c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0e,
+	0x70, 0x6f, 0x6c, 0x6c, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x6f, 0x6c, 0x6c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x49, 0x64, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x6f, 0x6c, 0x6c, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x70, 0x6f,
+	0x6c, 0x6c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x65, 0x78, 0x74, 0x22, 0xa5, 0x03, 0x0a,
+	0x0c, 0x50, 0x6f, 0x6c, 0x6c, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x76, 0x6f, 0x74, 0x65, 0x5f, 0x72, 0x61,
+	0x74, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d,
+	0x76, 0x6f, 0x74, 0x65, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1b, 0x0a,
+	0x09, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x41, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x73, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x50, 0x75,
+	0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x72, 0x63, 0x68, 0x69,
+	0x76, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x61, 0x72, 0x63, 0x68, 0x69,
+	0x76, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x73, 0x5f, 0x61, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6f, 0x70, 0x65, 0x6e, 0x73, 0x41, 0x74, 0x12, 0x23,
+	0x0a, 0x0d, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x6d, 0x61, 0x78,
+	0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x69, 0x6e, 0x73, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x57, 0x72, 0x69, 0x74, 0x65, 0x49,
+	0x6e, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x61, 0x73, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x68, 0x61, 0x73, 0x50, 0x61, 0x73,
+	0x73, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x5f,
+	0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x6e, 0x76, 0x69,
+	0x74, 0x65, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6e, 0x6f, 0x6e, 0x79, 0x6d,
+	0x6f, 0x75, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x6e, 0x6f, 0x6e, 0x79,
+	0x6d, 0x6f, 0x75, 0x73, 0x22, 0xc0, 0x02, 0x0a, 0x04, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x17, 0x0a,
+	0x07, 0x70, 0x6f, 0x6c, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06,
+	0x70, 0x6f, 0x6c, 0x6c, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x6f, 0x6c, 0x6c, 0x5f, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x6f, 0x6c, 0x6c,
+	0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6f, 0x6c, 0x6c, 0x5f, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f,
+	0x6c, 0x6c, 0x51, 0x75, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3e, 0x0a, 0x0c, 0x70, 0x6f,
+	0x6c, 0x6c, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x70,
+	0x6f, 0x6c, 0x6c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x08, 0x73, 0x65,
+	0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x76,
+	0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x6f, 0x6c, 0x6c, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x08, 0x73, 0x65, 0x74,
+	0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18,
+	0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x61, 0x73, 0x73, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70,
+	0x61, 0x73, 0x73, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x79, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x50,
+	0x6f, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63,
+	0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x63, 0x75, 0x72,
+	0x73, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x5f, 0x64, 0x72, 0x61, 0x66, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0d, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x44, 0x72, 0x61, 0x66, 0x74, 0x73,
+	0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74,
+	0x61, 0x67, 0x22, 0x61, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x05, 0x70, 0x6f, 0x6c, 0x6c, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x05, 0x70,
+	0x6f, 0x6c, 0x6c, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72,
+	0x73, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43,
+	0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0x29, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x6c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x6c, 0x6c, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x6c, 0x49, 0x64,
+	0x22, 0x54, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x04, 0x70, 0x6f, 0x6c, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6c, 0x6c,
+	0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x22, 0x2c, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x70,
+	0x6f, 0x6c, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x70, 0x6f,
+	0x6c, 0x6c, 0x49, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f,
+	0x6c, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x62, 0x0a, 0x0c, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65,
+	0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0x76,
+	0x0a, 0x0a, 0x50, 0x6f, 0x6c, 0x6c, 0x57, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07,
+	0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x07, 0x77,
+	0x69, 0x6e, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x77, 0x69, 0x6e, 0x6e, 0x69, 0x6e,
+	0x67, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x77, 0x69,
+	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x54, 0x65, 0x78, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x15, 0x0a, 0x06, 0x69, 0x73, 0x5f, 0x74, 0x69, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x05, 0x69, 0x73, 0x54, 0x69, 0x65, 0x22, 0x87, 0x03, 0x0a, 0x0a, 0x50, 0x6f, 0x6c, 0x6c, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x29, 0x0a, 0x04, 0x70, 0x6f, 0x6c, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x04, 0x70, 0x6f, 0x6c, 0x6c,
+	0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x69, 0x73, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x5f, 0x76, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x56, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x07, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x76, 0x6f,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x33, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x57, 0x69, 0x6e, 0x6e, 0x65, 0x72,
+	0x52, 0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x12, 0x46, 0x0a, 0x09, 0x62, 0x79, 0x5f, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x76, 0x6f,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f,
+	0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x2e, 0x42, 0x79, 0x53, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x62, 0x79, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x12, 0x21, 0x0a, 0x0c, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x1a, 0x3b, 0x0a, 0x0d, 0x42, 0x79, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x32, 0x90, 0x04, 0x0a, 0x08, 0x50, 0x6f, 0x6c, 0x6c, 0x73, 0x41, 0x50, 0x49, 0x12, 0x52, 0x0a,
+	0x09, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x73, 0x12, 0x21, 0x2e, 0x76, 0x6f, 0x74,
+	0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e,
+	0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x41, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x1f, 0x2e, 0x76,
+	0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x37, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x50, 0x6f, 0x6c, 0x6c, 0x12,
+	0x15, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x1a, 0x15, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x47, 0x0a,
+	0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x22, 0x2e, 0x76, 0x6f,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x15, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x55, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x22, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f,
+	0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x6c,
+	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e,
+	0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a,
+	0x0b, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x1f, 0x2e, 0x76,
+	0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x4d, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1f, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70,
+	0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x6c, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x42, 0x15, 0x5a, 0x13, 0x64, 0x72, 0x65, 0x78, 0x65, 0x6c, 0x2e, 0x65, 0x64,
+	0x75, 0x2f, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_polls_proto_rawDescOnce sync.Once
+	file_polls_proto_rawDescData = file_polls_proto_rawDesc
+)
+
+func file_polls_proto_rawDescGZIP() []byte {
+	file_polls_proto_rawDescOnce.Do(func() {
+		file_polls_proto_rawDescData = protoimpl.X.CompressGZIP(file_polls_proto_rawDescData)
+	})
+	return file_polls_proto_rawDescData
+}
+
+var file_polls_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_polls_proto_goTypes = []any{
+	(*PollOption)(nil),         // 0: voting.polls.v1.PollOption
+	(*PollSettings)(nil),       // 1: voting.polls.v1.PollSettings
+	(*Poll)(nil),               // 2: voting.polls.v1.Poll
+	(*ListPollsRequest)(nil),   // 3: voting.polls.v1.ListPollsRequest
+	(*ListPollsResponse)(nil),  // 4: voting.polls.v1.ListPollsResponse
+	(*GetPollRequest)(nil),     // 5: voting.polls.v1.GetPollRequest
+	(*UpdatePollRequest)(nil),  // 6: voting.polls.v1.UpdatePollRequest
+	(*DeletePollRequest)(nil),  // 7: voting.polls.v1.DeletePollRequest
+	(*DeletePollResponse)(nil), // 8: voting.polls.v1.DeletePollResponse
+	(*OptionResult)(nil),       // 9: voting.polls.v1.OptionResult
+	(*PollWinner)(nil),         // 10: voting.polls.v1.PollWinner
+	(*PollReport)(nil),         // 11: voting.polls.v1.PollReport
+	nil,                        // 12: voting.polls.v1.PollReport.BySourceEntry
+}
+var file_polls_proto_depIdxs = []int32{
+	0,  // 0: voting.polls.v1.Poll.poll_options:type_name -> voting.polls.v1.PollOption
+	1,  // 1: voting.polls.v1.Poll.settings:type_name -> voting.polls.v1.PollSettings
+	2,  // 2: voting.polls.v1.ListPollsResponse.polls:type_name -> voting.polls.v1.Poll
+	2,  // 3: voting.polls.v1.UpdatePollRequest.poll:type_name -> voting.polls.v1.Poll
+	2,  // 4: voting.polls.v1.PollReport.poll:type_name -> voting.polls.v1.Poll
+	9,  // 5: voting.polls.v1.PollReport.options:type_name -> voting.polls.v1.OptionResult
+	10, // 6: voting.polls.v1.PollReport.winner:type_name -> voting.polls.v1.PollWinner
+	12, // 7: voting.polls.v1.PollReport.by_source:type_name -> voting.polls.v1.PollReport.BySourceEntry
+	3,  // 8: voting.polls.v1.PollsAPI.ListPolls:input_type -> voting.polls.v1.ListPollsRequest
+	5,  // 9: voting.polls.v1.PollsAPI.GetPoll:input_type -> voting.polls.v1.GetPollRequest
+	2,  // 10: voting.polls.v1.PollsAPI.AddPoll:input_type -> voting.polls.v1.Poll
+	6,  // 11: voting.polls.v1.PollsAPI.UpdatePoll:input_type -> voting.polls.v1.UpdatePollRequest
+	7,  // 12: voting.polls.v1.PollsAPI.DeletePoll:input_type -> voting.polls.v1.DeletePollRequest
+	5,  // 13: voting.polls.v1.PollsAPI.PublishPoll:input_type -> voting.polls.v1.GetPollRequest
+	5,  // 14: voting.polls.v1.PollsAPI.GetPollReport:input_type -> voting.polls.v1.GetPollRequest
+	4,  // 15: voting.polls.v1.PollsAPI.ListPolls:output_type -> voting.polls.v1.ListPollsResponse
+	2,  // 16: voting.polls.v1.PollsAPI.GetPoll:output_type -> voting.polls.v1.Poll
+	2,  // 17: voting.polls.v1.PollsAPI.AddPoll:output_type -> voting.polls.v1.Poll
+	2,  // 18: voting.polls.v1.PollsAPI.UpdatePoll:output_type -> voting.polls.v1.Poll
+	8,  // 19: voting.polls.v1.PollsAPI.DeletePoll:output_type -> voting.polls.v1.DeletePollResponse
+	2,  // 20: voting.polls.v1.PollsAPI.PublishPoll:output_type -> voting.polls.v1.Poll
+	11, // 21: voting.polls.v1.PollsAPI.GetPollReport:output_type -> voting.polls.v1.PollReport
+	15, // [15:22] is the sub-list for method output_type
+	8,  // [8:15] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_polls_proto_init() }
+func file_polls_proto_init() {
+	if File_polls_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_polls_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*PollOption); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*PollSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Poll); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ListPollsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ListPollsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*GetPollRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdatePollRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*DeletePollRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*DeletePollResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*OptionResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*PollWinner); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_polls_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*PollReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_polls_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_polls_proto_goTypes,
+		DependencyIndexes: file_polls_proto_depIdxs,
+		MessageInfos:      file_polls_proto_msgTypes,
+	}.Build()
+	File_polls_proto = out.File
+	file_polls_proto_rawDesc = nil
+	file_polls_proto_goTypes = nil
+	file_polls_proto_depIdxs = nil
+}
@@ -0,0 +1,353 @@
+// The Polls API over gRPC.  The messages mirror the JSON the REST API
+// sends, field for field apart from Links, so that a poll read over either
+// is the same poll.  Timestamps are RFC 3339 strings, as in the JSON
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: polls.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PollsAPI_ListPolls_FullMethodName     = "/voting.polls.v1.PollsAPI/ListPolls"
+	PollsAPI_GetPoll_FullMethodName       = "/voting.polls.v1.PollsAPI/GetPoll"
+	PollsAPI_AddPoll_FullMethodName       = "/voting.polls.v1.PollsAPI/AddPoll"
+	PollsAPI_UpdatePoll_FullMethodName    = "/voting.polls.v1.PollsAPI/UpdatePoll"
+	PollsAPI_DeletePoll_FullMethodName    = "/voting.polls.v1.PollsAPI/DeletePoll"
+	PollsAPI_PublishPoll_FullMethodName   = "/voting.polls.v1.PollsAPI/PublishPoll"
+	PollsAPI_GetPollReport_FullMethodName = "/voting.polls.v1.PollsAPI/GetPollReport"
+)
+
+// PollsAPIClient is the client API for PollsAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PollsAPIClient interface {
+	ListPolls(ctx context.Context, in *ListPollsRequest, opts ...grpc.CallOption) (*ListPollsResponse, error)
+	GetPoll(ctx context.Context, in *GetPollRequest, opts ...grpc.CallOption) (*Poll, error)
+	AddPoll(ctx context.Context, in *Poll, opts ...grpc.CallOption) (*Poll, error)
+	UpdatePoll(ctx context.Context, in *UpdatePollRequest, opts ...grpc.CallOption) (*Poll, error)
+	DeletePoll(ctx context.Context, in *DeletePollRequest, opts ...grpc.CallOption) (*DeletePollResponse, error)
+	PublishPoll(ctx context.Context, in *GetPollRequest, opts ...grpc.CallOption) (*Poll, error)
+	GetPollReport(ctx context.Context, in *GetPollRequest, opts ...grpc.CallOption) (*PollReport, error)
+}
+
+type pollsAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPollsAPIClient(cc grpc.ClientConnInterface) PollsAPIClient {
+	return &pollsAPIClient{cc}
+}
+
+func (c *pollsAPIClient) ListPolls(ctx context.Context, in *ListPollsRequest, opts ...grpc.CallOption) (*ListPollsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPollsResponse)
+	err := c.cc.Invoke(ctx, PollsAPI_ListPolls_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pollsAPIClient) GetPoll(ctx context.Context, in *GetPollRequest, opts ...grpc.CallOption) (*Poll, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Poll)
+	err := c.cc.Invoke(ctx, PollsAPI_GetPoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pollsAPIClient) AddPoll(ctx context.Context, in *Poll, opts ...grpc.CallOption) (*Poll, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Poll)
+	err := c.cc.Invoke(ctx, PollsAPI_AddPoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pollsAPIClient) UpdatePoll(ctx context.Context, in *UpdatePollRequest, opts ...grpc.CallOption) (*Poll, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Poll)
+	err := c.cc.Invoke(ctx, PollsAPI_UpdatePoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pollsAPIClient) DeletePoll(ctx context.Context, in *DeletePollRequest, opts ...grpc.CallOption) (*DeletePollResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePollResponse)
+	err := c.cc.Invoke(ctx, PollsAPI_DeletePoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pollsAPIClient) PublishPoll(ctx context.Context, in *GetPollRequest, opts ...grpc.CallOption) (*Poll, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Poll)
+	err := c.cc.Invoke(ctx, PollsAPI_PublishPoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pollsAPIClient) GetPollReport(ctx context.Context, in *GetPollRequest, opts ...grpc.CallOption) (*PollReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PollReport)
+	err := c.cc.Invoke(ctx, PollsAPI_GetPollReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PollsAPIServer is the server API for PollsAPI service.
+// All implementations must embed UnimplementedPollsAPIServer
+// for forward compatibility.
+type PollsAPIServer interface {
+	ListPolls(context.Context, *ListPollsRequest) (*ListPollsResponse, error)
+	GetPoll(context.Context, *GetPollRequest) (*Poll, error)
+	AddPoll(context.Context, *Poll) (*Poll, error)
+	UpdatePoll(context.Context, *UpdatePollRequest) (*Poll, error)
+	DeletePoll(context.Context, *DeletePollRequest) (*DeletePollResponse, error)
+	PublishPoll(context.Context, *GetPollRequest) (*Poll, error)
+	GetPollReport(context.Context, *GetPollRequest) (*PollReport, error)
+	mustEmbedUnimplementedPollsAPIServer()
+}
+
+// UnimplementedPollsAPIServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPollsAPIServer struct{}
+
+func (UnimplementedPollsAPIServer) ListPolls(context.Context, *ListPollsRequest) (*ListPollsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPolls not implemented")
+}
+func (UnimplementedPollsAPIServer) GetPoll(context.Context, *GetPollRequest) (*Poll, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPoll not implemented")
+}
+func (UnimplementedPollsAPIServer) AddPoll(context.Context, *Poll) (*Poll, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPoll not implemented")
+}
+func (UnimplementedPollsAPIServer) UpdatePoll(context.Context, *UpdatePollRequest) (*Poll, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePoll not implemented")
+}
+func (UnimplementedPollsAPIServer) DeletePoll(context.Context, *DeletePollRequest) (*DeletePollResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePoll not implemented")
+}
+func (UnimplementedPollsAPIServer) PublishPoll(context.Context, *GetPollRequest) (*Poll, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishPoll not implemented")
+}
+func (UnimplementedPollsAPIServer) GetPollReport(context.Context, *GetPollRequest) (*PollReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPollReport not implemented")
+}
+func (UnimplementedPollsAPIServer) mustEmbedUnimplementedPollsAPIServer() {}
+func (UnimplementedPollsAPIServer) testEmbeddedByValue()                  {}
+
+// UnsafePollsAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PollsAPIServer will
+// result in compilation errors.
+type UnsafePollsAPIServer interface {
+	mustEmbedUnimplementedPollsAPIServer()
+}
+
+func RegisterPollsAPIServer(s grpc.ServiceRegistrar, srv PollsAPIServer) {
+	// If the following call pancis, it indicates UnimplementedPollsAPIServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PollsAPI_ServiceDesc, srv)
+}
+
+func _PollsAPI_ListPolls_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPollsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PollsAPIServer).ListPolls(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PollsAPI_ListPolls_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PollsAPIServer).ListPolls(ctx, req.(*ListPollsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PollsAPI_GetPoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PollsAPIServer).GetPoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PollsAPI_GetPoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PollsAPIServer).GetPoll(ctx, req.(*GetPollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PollsAPI_AddPoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Poll)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PollsAPIServer).AddPoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PollsAPI_AddPoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PollsAPIServer).AddPoll(ctx, req.(*Poll))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PollsAPI_UpdatePoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PollsAPIServer).UpdatePoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PollsAPI_UpdatePoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PollsAPIServer).UpdatePoll(ctx, req.(*UpdatePollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PollsAPI_DeletePoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PollsAPIServer).DeletePoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PollsAPI_DeletePoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PollsAPIServer).DeletePoll(ctx, req.(*DeletePollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PollsAPI_PublishPoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PollsAPIServer).PublishPoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PollsAPI_PublishPoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PollsAPIServer).PublishPoll(ctx, req.(*GetPollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PollsAPI_GetPollReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PollsAPIServer).GetPollReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PollsAPI_GetPollReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PollsAPIServer).GetPollReport(ctx, req.(*GetPollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PollsAPI_ServiceDesc is the grpc.ServiceDesc for PollsAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PollsAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "voting.polls.v1.PollsAPI",
+	HandlerType: (*PollsAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPolls",
+			Handler:    _PollsAPI_ListPolls_Handler,
+		},
+		{
+			MethodName: "GetPoll",
+			Handler:    _PollsAPI_GetPoll_Handler,
+		},
+		{
+			MethodName: "AddPoll",
+			Handler:    _PollsAPI_AddPoll_Handler,
+		},
+		{
+			MethodName: "UpdatePoll",
+			Handler:    _PollsAPI_UpdatePoll_Handler,
+		},
+		{
+			MethodName: "DeletePoll",
+			Handler:    _PollsAPI_DeletePoll_Handler,
+		},
+		{
+			MethodName: "PublishPoll",
+			Handler:    _PollsAPI_PublishPoll_Handler,
+		},
+		{
+			MethodName: "GetPollReport",
+			Handler:    _PollsAPI_GetPollReport_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "polls.proto",
+}
@@ -1,2 +1,2 @@
-#!/bin/bash
+#!/bin/bash
 docker run -it --rm --name better-polls -p 1090:1090 polls-api-better:v1
\ No newline at end of file
@@ -0,0 +1,7 @@
+//go:build tools
+
+// tools.go keeps the gqlgen code generator in go.mod, it is only run by
+// go generate, see graph/resolver.go
+package main
+
+import _ "github.com/99designs/gqlgen"
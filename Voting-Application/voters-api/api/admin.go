@@ -0,0 +1,37 @@
+package api
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader is the header administrative requests carry the
+// admin token in when FEATURE_AUTH is on, as it is by default
+const AdminTokenHeader = "X-Admin-Token"
+
+// RequireAdmin is middleware for the administrative endpoints.  When the
+// Auth feature is on, as it is unless FEATURE_AUTH=false, requests must
+// send the token from the ADMIN_TOKEN environment variable in the
+// X-Admin-Token header.  If ADMIN_TOKEN is not set every request is turned
+// away, rather than letting anyone in.  With the feature off every request
+// is let in, which is logged at startup
+func (va *VotersAPI) RequireAdmin(c *gin.Context) {
+	if !va.features.Auth {
+		c.Next()
+		return
+	}
+
+	token := os.Getenv("ADMIN_TOKEN")
+	sent := c.GetHeader(AdminTokenHeader)
+	if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+		log.Println("Rejected admin request to ", c.Request.URL.Path)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	c.Next()
+}
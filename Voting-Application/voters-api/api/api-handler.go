@@ -1,457 +1,1006 @@
-package api
-
-import (
-	"log"
-	"net/http"
-	"strconv"
-	"time"
-
-	"drexel.edu/voters/db"
-	"github.com/gin-gonic/gin"
-)
-
-// The api package creates and maintains a reference to the data handler
-// this is a good design practice
-type VotersAPI struct {
-	db *db.VoterList
-}
-
-var bootTime time.Time
-var calls uint
-
-func New() (*VotersAPI, error) {
-	dbHandler, err := db.NewVoterList()
-	if err != nil {
-		return nil, err
-	}
-
-	bootTime = time.Now()
-
-	return &VotersAPI{db: dbHandler}, nil
-}
-
-type PollRequest struct {
-	PollID   uint      `json:"PollID"`
-	VoteDate time.Time `json:"VoteDate"`
-}
-
-//Below we implement the API functions.  Some of the framework
-//things you will see include:
-//   1) How to extract a parameter from the URL, for example
-//	  the id parameter in /voters/:id
-//   2) How to extract the body of a POST request
-//   3) How to return JSON and a correctly formed HTTP status code
-//	  for example, 200 for OK, 404 for not found, etc.  This is done
-//	  using the c.JSON() function
-//   4) How to return an error code and abort the request.  This is
-//	  done using the c.AbortWithStatus() function
-
-// implementation for GET /voters
-// returns all voters
-func (va *VotersAPI) ListAllVoters(c *gin.Context) {
-
-	voterList, err := va.db.GetAllVoters()
-	if err != nil {
-		log.Println("Error Getting All Voters: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-	//Note that the database returns a nil slice if there are no items
-	//in the database.  We need to convert this to an empty slice
-	//so that the JSON marshalling works correctly.  We want to return
-	//an empty slice, not a nil slice. This will result in the json being []
-	if voterList == nil {
-		voterList = make([]db.Voter, 0)
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, voterList)
-}
-
-// implementation for GET /voters/:id
-// returns a single voter
-func (va *VotersAPI) GetVoter(c *gin.Context) {
-
-	//Note go is minimalistic, so we have to get the
-	//id parameter using the Param() function, and then
-	//convert it to an int64 using the strconv package
-	idS := c.Param("id")
-	id64, err := strconv.ParseInt(idS, 10, 32)
-	if err != nil {
-		log.Println("Error converting id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	//Note that ParseInt always returns an int64, so we have to
-	//convert it to an int before we can use it.
-	num := int(id64)
-	var numAsUint uint
-	if num >= 0 {
-		numAsUint = uint(num)
-	} else {
-		log.Println("VoterID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	voter, err := va.db.GetVoter(numAsUint)
-	if err != nil {
-		log.Println("Voter not found: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-
-	calls = calls + 1
-	//Git will automatically convert the struct to JSON
-	//and set the content-type header to application/json
-	c.JSON(http.StatusOK, voter)
-}
-
-// implementation for GET /crash
-// This simulates a crash to show some of the benefits of the
-// gin framework
-func (va *VotersAPI) CrashSim(c *gin.Context) {
-	//panic() is go's version of throwing an exception
-	panic("Simulating an unexpected crash")
-}
-
-// implementation for POST /voters
-// adds a new voter
-func (va *VotersAPI) AddVoter(c *gin.Context) {
-	var voter db.Voter
-
-	//With HTTP based APIs, a POST request will usually
-	//have a body that contains the data to be added
-	//to the database.  The body is usually JSON, so
-	//we need to bind the JSON to a struct that we
-	//can use in our code.
-	//This framework exposes the raw body via c.Request.Body
-	//but it also provides a helper function ShouldBindJSON()
-	//that will extract the body, convert it to JSON and
-	//bind it to a struct for us.  It will also report an error
-	//if the body is not JSON or if the JSON does not match
-	//the struct we are binding to.
-	if err := c.ShouldBindJSON(&voter); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.AddVoter(voter); err != nil {
-		log.Println("Error adding voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, voter)
-
-}
-
-// implementation for PUT /voters
-// Web api standards use PUT for Updates
-func (va *VotersAPI) UpdateVoter(c *gin.Context) {
-	var voter db.Voter
-	if err := c.ShouldBindJSON(&voter); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.UpdateVoter(voter); err != nil {
-		log.Println("Error updating voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, voter)
-}
-
-// implementation for DELETE /voters/:id
-// deletes a voter
-func (va *VotersAPI) DeleteVoter(c *gin.Context) {
-	idS := c.Param("id")
-	id64, err := strconv.ParseInt(idS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	num := int(id64)
-	var numAsUint uint
-	if num >= 0 {
-		numAsUint = uint(num)
-	} else {
-		log.Println("VoterID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.DeleteVoter(numAsUint); err != nil {
-		log.Println("Error deleting voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-}
-
-// implementation for DELETE /voters
-// deletes all voters
-func (va *VotersAPI) DeleteAllVoters(c *gin.Context) {
-
-	if err := va.db.DeleteAllVoters(); err != nil {
-		log.Println("Error deleting all voters: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-}
-
-// implementation for GET /voters/:id/polls
-// gets JUST the voter history for the voter with VoterID
-
-func (va *VotersAPI) GetVoterPolls(c *gin.Context) {
-	idS := c.Param("id")
-	id64, err := strconv.ParseInt(idS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	num := int(id64)
-	var numAsUint uint
-	if num >= 0 {
-		numAsUint = uint(num)
-	} else {
-		log.Println("VoterID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	voterPolls, err := va.db.GetVoterPolls(numAsUint)
-	if err != nil {
-		log.Println("Error deleting voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, voterPolls)
-}
-
-// implementation for GET /voters/:id/polls/:pollId
-// Gets JUST the single voter poll data with PollID = :pollId and VoterID = :id
-
-func (va *VotersAPI) GetVoterPoll(c *gin.Context) {
-	voterIdS := c.Param("id")
-	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting voter id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	voterNum := int(voterId64)
-	var voterNumAsUint uint
-	if voterNum >= 0 {
-		voterNumAsUint = uint(voterNum)
-	} else {
-		log.Println("VoterID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	pollIdS := c.Param("pollId")
-	pollId64, err := strconv.ParseInt(pollIdS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting poll id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	pollNum := int(pollId64)
-	var pollNumAsUint uint
-	if pollNum >= 0 {
-		pollNumAsUint = uint(pollNum)
-	} else {
-		log.Println("PollId needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	voterPoll, err := va.db.GetVoterPoll(voterNumAsUint, pollNumAsUint)
-	if err != nil {
-		log.Println("Error deleting voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, voterPoll)
-
-}
-
-// implementation for POST /voters/:id/polls/:pollId
-// Puts JUST the single voter poll data for the voter id
-
-func (va *VotersAPI) AddVoterPoll(c *gin.Context){
-	voterIdS := c.Param("id")
-	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting voter id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	voterNum := int(voterId64)
-	var voterNumAsUint uint
-	if voterNum >= 0 {
-		voterNumAsUint = uint(voterNum)
-	} else {
-		log.Println("VoterID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	var voter db.Voter
-		
-	if err := c.ShouldBindJSON(&voter); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.AddVoterPoll(voterNumAsUint, voter); err != nil {
-		log.Println("Error adding voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-
-}
-
-// implementation for DELETE /voters/:id/polls/
-// Deletes JUST the single voter poll data for the voter id
-
-func (va *VotersAPI) DeleteVoterPoll(c *gin.Context){
-	voterIdS := c.Param("id")
-	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting voter id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	voterNum := int(voterId64)
-	var voterNumAsUint uint
-	if voterNum >= 0 {
-		voterNumAsUint = uint(voterNum)
-	} else {
-		log.Println("VoterID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	pollIdS := c.Param("pollId")
-	pollId64, err := strconv.ParseInt(pollIdS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting poll id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	pollNum := int(pollId64)
-	var pollNumAsUint uint
-	if voterNum >= 0 {
-		pollNumAsUint = uint(pollNum)
-	} else {
-		log.Println("PollID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.DeleteVoterPoll(voterNumAsUint, pollNumAsUint); err != nil {
-		log.Println("Error adding voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-
-}
-
-// implementation for PUT /voters/:id/polls/
-// Updates JUST the single voter poll data for the voter id
-
-func (va *VotersAPI) UpdateVoterPoll(c *gin.Context){
-	voterIdS := c.Param("id")
-	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting voter id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	voterNum := int(voterId64)
-	var voterNumAsUint uint
-	if voterNum >= 0 {
-		voterNumAsUint = uint(voterNum)
-	} else {
-		log.Println("VoterID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	var voter db.Voter
-		
-	if err := c.ShouldBindJSON(&voter); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.UpdateVoterPoll(voterNumAsUint, voter); err != nil {
-		log.Println("Error adding voter: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-
-}
-
-// implementation for GET /voters/health
-// returns a "health" record indicating that the voter API is functioning properly
-
-func (va *VotersAPI) GetHealthData(c *gin.Context){
-
-	healthData, err := va.db.GetHealthData(bootTime, calls+1)
-	if err != nil {
-		log.Println("Error Getting health data: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-	
-	calls = calls + 1
-	c.JSON(http.StatusOK, healthData)
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"drexel.edu/voters/db"
+	"github.com/gin-gonic/gin"
+)
+
+// The api package creates and maintains a reference to the data handler
+// this is a good design practice
+type VotersAPI struct {
+	db       VoterStore
+	features Features
+	gateway  http.Handler
+}
+
+// Features holds the feature flags for the optional capabilities of the
+// service.  The flags are read once at startup in main.go and passed in
+// here so that route registration and middleware wiring can be made
+// conditional without changing any code
+type Features struct {
+	SSE        bool
+	Auth       bool
+	Metrics    bool
+	Crash      bool
+	StrictIDs  bool
+	StrictJSON bool
+	AutoIDs    bool
+	Gateway    bool
+}
+
+var bootTime time.Time
+var calls uint
+
+// New returns an api backed by redis, configured from the environment
+func New(features Features) (*VotersAPI, error) {
+	dbHandler, err := db.NewVoterList()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithStore(dbHandler, features), nil
+}
+
+// Features returns the feature flags the api was created with
+func (va *VotersAPI) Features() Features {
+	return va.features
+}
+
+type PollRequest struct {
+	PollID   uint      `json:"PollID"`
+	VoteDate time.Time `json:"VoteDate"`
+}
+
+//Below we implement the API functions.  Some of the framework
+//things you will see include:
+//   1) How to extract a parameter from the URL, for example
+//	  the id parameter in /voters/:id
+//   2) How to extract the body of a POST request
+//   3) How to return JSON and a correctly formed HTTP status code
+//	  for example, 200 for OK, 404 for not found, etc.  This is done
+//	  using the c.JSON() function
+//   4) How to return an error code and abort the request.  This is
+//	  done using the c.AbortWithStatus() function
+
+// implementation for GET /voters
+// returns all voters, a page of them with ?limit and ?offset, or with
+// ?cursor for lists too long to read in one go.  ?sort=LastName&order=desc
+// sorts the whole list before it is paged
+func (va *VotersAPI) ListAllVoters(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+	cursor, cursored, ok := parseCursor(c, page)
+	if !ok {
+		return
+	}
+	field, desc, ok := parseSort(c, cursored)
+	if !ok {
+		return
+	}
+	order := db.VoterSort{Field: field, Desc: desc}
+	if err := order.Validate(); err != nil {
+		log.Println("Error sorting voters: ", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if cursored {
+		voterList, next, err := va.db.GetVotersPage(cursor, page.Limit)
+		if err != nil {
+			log.Println("Error Getting Voters Page: ", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		calls = calls + 1
+		writeCursorPage(c, voterList, cursorPage{Limit: page.Limit, Cursor: cursor, NextCursor: next}, envelope)
+		return
+	}
+
+	voterList, err := va.db.GetAllVoters(order)
+	if err != nil {
+		log.Println("Error Getting All Voters: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	//Note that the database returns a nil slice if there are no items
+	//in the database.  We need to convert this to an empty slice
+	//so that the JSON marshalling works correctly.  We want to return
+	//an empty slice, not a nil slice. This will result in the json being []
+	if voterList == nil {
+		voterList = make([]db.Voter, 0)
+	}
+
+	calls = calls + 1
+	writeList(c, voterList, page, envelope)
+}
+
+// implementation for GET /voters/search
+// returns the voters whose first or last name contains ?q, ignoring case,
+// sorted by id.  Pages are read with ?limit and ?offset
+func (va *VotersAPI) SearchVoters(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	voterList, err := va.db.SearchVoters(c.Query("q"))
+	if err != nil {
+		log.Println("Error Searching Voters: ", err)
+		if errors.Is(err, db.ErrEmptySearch) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	writeList(c, voterList, page, envelope)
+}
+
+// implementation for GET /voters/:id
+// returns a single voter
+func (va *VotersAPI) GetVoter(c *gin.Context) {
+
+	//Note go is minimalistic, so we have to get the
+	//id parameter using the Param() function, and then
+	//convert it to an int64 using the strconv package
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	//Note that ParseInt always returns an int64, so we have to
+	//convert it to an int before we can use it.
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voter, err := va.db.GetVoter(numAsUint)
+	if err != nil {
+		log.Println("Error getting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	setETag(c, voter.Version)
+	//Git will automatically convert the struct to JSON
+	//and set the content-type header to application/json
+	c.JSON(http.StatusOK, voter)
+}
+
+// implementation for GET /crash
+// This simulates a crash to show some of the benefits of the
+// gin framework
+func (va *VotersAPI) CrashSim(c *gin.Context) {
+	//panic() is go's version of throwing an exception
+	panic("Simulating an unexpected crash")
+}
+
+// implementation for POST /voters
+// adds a new voter
+func (va *VotersAPI) AddVoter(c *gin.Context) {
+	var voter db.Voter
+
+	//With HTTP based APIs, a POST request will usually
+	//have a body that contains the data to be added
+	//to the database.  The body is usually JSON, so
+	//we need to bind the JSON to a struct that we
+	//can use in our code.
+	//This framework exposes the raw body via c.Request.Body
+	//but it also provides a helper function ShouldBindJSON()
+	//that will extract the body, convert it to JSON and
+	//bind it to a struct for us.  It will also report an error
+	//if the body is not JSON or if the JSON does not match
+	//the struct we are binding to.
+	if !va.bindJSON(c, &voter) {
+		return
+	}
+	//A voter sent without a VoterID is given the next free one
+	newID := voter.VoterID == 0 && va.features.AutoIDs
+	if !newID && !va.validBodyID(c, "VoterID", voter.VoterID) {
+		return
+	}
+
+	add := func() error { return va.db.AddVoter(voter) }
+	if newID {
+		add = func() error {
+			id, err := va.db.AddVoterNewID(voter)
+			voter.VoterID = id
+			return err
+		}
+	}
+	if err := add(); err != nil {
+		log.Println("Error adding voter: ", err)
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrAlreadyExists) || errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	//Send back the voter as it was stored, with the fields the db layer
+	//fills in, rather than what was sent.  The voter was created either way
+	stored, err := va.db.GetVoter(voter.VoterID)
+	if err != nil {
+		log.Println("Error reading back voter: ", err)
+		stored = voter
+	}
+
+	calls = calls + 1
+	c.Header("Location", "/voters/"+strconv.FormatUint(uint64(stored.VoterID), 10))
+	c.JSON(http.StatusCreated, stored)
+}
+
+// implementation for PUT /voters/:id
+// Web api standards use PUT for Updates.  The voter updated is the one
+// in the path, a body without a VoterID takes it from the path and a
+// body with a different one is rejected
+func (va *VotersAPI) UpdateVoter(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	var voter db.Voter
+	if !va.bindJSON(c, &voter) {
+		return
+	}
+	if voter.VoterID == 0 {
+		voter.VoterID = numAsUint
+	} else if voter.VoterID != numAsUint {
+		log.Println("VoterID in body does not match path: ", voter.VoterID, " != ", numAsUint)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "VoterID in the body must match the id in the path"})
+		return
+	}
+	if !va.validBodyID(c, "VoterID", voter.VoterID) {
+		return
+	}
+
+	//With If-Match the write is also turned away if the voter changes
+	//after this check, unless the body sends a Version of its own
+	version, ok := va.ifMatchVoter(c, voter.VoterID)
+	if !ok {
+		return
+	}
+	if voter.Version == 0 {
+		voter.Version = version
+	}
+
+	if err := va.db.UpdateVoter(voter); err != nil {
+		log.Println("Error updating voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(staleStatus(c), gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	//Send back the Version the voter has now, for the next update
+	if updated, err := va.db.GetVoter(voter.VoterID); err == nil {
+		voter = updated
+	}
+
+	calls = calls + 1
+	setETag(c, voter.Version)
+	c.JSON(http.StatusOK, voter)
+}
+
+// implementation for PATCH /voters/:id
+// applies the body, a JSON merge patch (RFC 7386), so only the fields
+// that are in it are updated and a field set to null is cleared.  Returns
+// just the fields that changed, as
+// {"changed": {"Field": {"from": ..., "to": ...}}}
+func (va *VotersAPI) PatchVoter(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	//We need the record as it is before the write so that we can
+	//tell what the patch changed
+	before, err := va.db.GetVoter(numAsUint)
+	if err != nil {
+		log.Println("Error getting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	//The patch is merged into the current record, so a client can
+	//change one field without sending back, and maybe clobbering, the
+	//rest
+	patch, err := c.GetRawData()
+	if err != nil {
+		bodyError(c, err)
+		return
+	}
+	var after db.Voter
+	if err := mergePatch(before, patch, &after, va.features.StrictJSON); err != nil {
+		log.Println("Error applying merge patch: ", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validBody(c, &after) {
+		return
+	}
+	for i, poll := range after.VoteHistory {
+		if !va.validBodyID(c, fmt.Sprintf("VoteHistory[%d].PollID", i), poll.PollID) {
+			return
+		}
+	}
+	after.VoterID = before.VoterID
+
+	//A patch that only renames the voter writes just the names, so it
+	//can't undo a poll being added at the same time
+	changed := changedFields(before, after)
+	update := func() error { return va.db.UpdateVoter(after) }
+	if len(changed) > 0 && onlyNamesChanged(changed) {
+		update = func() error { return va.db.UpdateVoterName(after.VoterID, after.FirstName, after.LastName) }
+	}
+
+	if err := update(); err != nil {
+		log.Println("Error patching voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrAlreadyExists) || errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"changed": changed})
+}
+
+// onlyNamesChanged is true if the only fields in changed are the names of
+// a voter
+func onlyNamesChanged(changed map[string]fieldChange) bool {
+	for field := range changed {
+		if field != "FirstName" && field != "LastName" {
+			return false
+		}
+	}
+	return true
+}
+
+// implementation for DELETE /voters/:id
+// deletes a voter
+func (va *VotersAPI) DeleteVoter(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := va.ifMatchVoter(c, numAsUint); !ok {
+		return
+	}
+
+	if err := va.db.DeleteVoter(numAsUint); err != nil {
+		log.Println("Error deleting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+}
+
+// implementation for DELETE /voters
+// deletes all voters
+func (va *VotersAPI) DeleteAllVoters(c *gin.Context) {
+
+	if err := va.db.DeleteAllVoters(); err != nil {
+		log.Println("Error deleting all voters: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+}
+
+// implementation for GET /voters/:id/polls
+// gets JUST the voter history for the voter with VoterID, a page of it
+// with ?limit and ?offset
+
+func (va *VotersAPI) GetVoterPolls(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	voterPolls, err := va.db.GetVoterPolls(numAsUint)
+	if err != nil {
+		log.Println("Error deleting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	writeList(c, voterPolls, page, envelope)
+}
+
+// implementation for GET /voters/:id/summary
+// returns the voter's name, how many polls they have voted in, and
+// the dates of their first and last votes
+
+func (va *VotersAPI) GetVoterSummary(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	summary, err := va.db.GetVoterSummary(numAsUint)
+	if err != nil {
+		log.Println("Error getting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, summary)
+}
+
+// implementation for GET /voters/:id/export
+// returns everything kept about the voter, their record, vote history
+// and votes from the votes API, as a JSON file to download for a subject
+// access request
+
+func (va *VotersAPI) ExportVoter(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	export, err := va.db.ExportVoter(numAsUint, time.Now())
+	if err != nil {
+		log.Println("Error exporting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	//The export has the voter's personal data, so it must not be kept
+	//by caches on the way
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="voter-%d-export.json"`, numAsUint))
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+// implementation for DELETE /voters/:id/erase
+// erases the voter, scrubbing and deleting them and anonymizing their
+// votes in the votes API, for a voter who asked to be forgotten
+
+func (va *VotersAPI) EraseVoter(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num > 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	erasure, err := va.db.EraseVoter(numAsUint, time.Now())
+	if err != nil {
+		log.Println("Error erasing voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		var sagaErr *db.SagaError
+		if errors.As(err, &sagaErr) {
+			//Tell the caller whether the voter was left as they were or
+			//the erasure has to be sent again to finish
+			status := http.StatusInternalServerError
+			if errors.Is(err, db.ErrDependencyUnavailable) {
+				status = http.StatusServiceUnavailable
+			} else if errors.Is(err, db.ErrConflict) {
+				status = http.StatusConflict
+			}
+			c.AbortWithStatusJSON(status, gin.H{"error": err.Error(), "step": sagaErr.Step, "compensated": sagaErr.Compensated})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	//Audited records the erasure without the voter in it
+	c.Set(auditErasedKey, true)
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, erasure)
+}
+
+// implementation for GET /voters/:id/polls/:pollId
+// Gets JUST the single voter poll data with PollID = :pollId and VoterID = :id
+
+func (va *VotersAPI) GetVoterPoll(c *gin.Context) {
+	voterIdS := c.Param("id")
+	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting voter id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterNum := int(voterId64)
+	var voterNumAsUint uint
+	if voterNum >= 0 {
+		voterNumAsUint = uint(voterNum)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	pollIdS := c.Param("pollId")
+	pollId64, err := strconv.ParseInt(pollIdS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting poll id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	pollNum := int(pollId64)
+	var pollNumAsUint uint
+	if pollNum >= 0 {
+		pollNumAsUint = uint(pollNum)
+	} else {
+		log.Println("PollId needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterPoll, err := va.db.GetVoterPoll(voterNumAsUint, pollNumAsUint)
+	if err != nil {
+		log.Println("Error deleting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, voterPoll)
+
+}
+
+// voterPollsBody is the body of AddVoterPoll and UpdateVoterPoll.  It
+// only has the voter's polls, so unlike a whole voter it can be sent
+// without the voter's names
+type voterPollsBody struct {
+	VoteHistory []db.VoterPoll `binding:"required,min=1"`
+}
+
+// implementation for POST /voters/:id/polls/:pollId
+// Puts JUST the single voter poll data for the voter id
+
+func (va *VotersAPI) AddVoterPoll(c *gin.Context){
+	voterIdS := c.Param("id")
+	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting voter id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterNum := int(voterId64)
+	var voterNumAsUint uint
+	if voterNum >= 0 {
+		voterNumAsUint = uint(voterNum)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	var body voterPollsBody
+		
+	if !va.bindJSON(c, &body) {
+		return
+	}
+	voter := db.Voter{VoteHistory: body.VoteHistory}
+	for i, poll := range voter.VoteHistory {
+		if !va.validBodyID(c, fmt.Sprintf("VoteHistory[%d].PollID", i), poll.PollID) {
+			return
+		}
+	}
+
+	if err := va.db.AddVoterPoll(voterNumAsUint, voter); err != nil {
+		log.Println("Error adding voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrAlreadyExists) || errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+
+}
+
+// implementation for POST /voters/:id/polls/batch
+// Adds an array of voter polls to the voter in one write.  Either they
+// are all added or none are, and the status of each one is returned
+
+func (va *VotersAPI) AddVoterPollsBatch(c *gin.Context){
+	voterIdS := c.Param("id")
+	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting voter id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterNum := int(voterId64)
+	var voterNumAsUint uint
+	if voterNum >= 0 {
+		voterNumAsUint = uint(voterNum)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	//The body is a bare array of the same entries as a voter's VoteHistory
+	var polls []db.VoterPoll
+
+	if !va.bindJSON(c, &polls) {
+		return
+	}
+	if len(polls) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no polls to add"})
+		return
+	}
+	for i, poll := range polls {
+		if !va.validBodyID(c, fmt.Sprintf("[%d].PollID", i), poll.PollID) {
+			return
+		}
+	}
+
+	if err := va.db.AddVoterPolls(voterNumAsUint, polls); err != nil {
+		log.Println("Error adding voter polls: ", err)
+		var batchErr *db.VoterPollsError
+		if errors.As(err, &batchErr) {
+			c.JSON(http.StatusConflict, gin.H{"results": batchErr.Results})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	results := make([]db.VoterPollResult, len(polls))
+	for i, poll := range polls {
+		results[i] = db.VoterPollResult{PollID: poll.PollID, Status: db.VoterPollAdded}
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"results": results})
+
+}
+
+// implementation for DELETE /voters/:id/polls/
+// Deletes JUST the single voter poll data for the voter id
+
+func (va *VotersAPI) DeleteVoterPoll(c *gin.Context){
+	voterIdS := c.Param("id")
+	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting voter id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterNum := int(voterId64)
+	var voterNumAsUint uint
+	if voterNum >= 0 {
+		voterNumAsUint = uint(voterNum)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	pollIdS := c.Param("pollId")
+	pollId64, err := strconv.ParseInt(pollIdS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting poll id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	pollNum := int(pollId64)
+	var pollNumAsUint uint
+	if voterNum >= 0 {
+		pollNumAsUint = uint(pollNum)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if err := va.db.DeleteVoterPoll(voterNumAsUint, pollNumAsUint); err != nil {
+		log.Println("Error adding voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+
+}
+
+// implementation for PUT /voters/:id/polls/
+// Updates JUST the single voter poll data for the voter id
+
+func (va *VotersAPI) UpdateVoterPoll(c *gin.Context){
+	voterIdS := c.Param("id")
+	voterId64, err := strconv.ParseInt(voterIdS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting voter id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voterNum := int(voterId64)
+	var voterNumAsUint uint
+	if voterNum >= 0 {
+		voterNumAsUint = uint(voterNum)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	var body voterPollsBody
+		
+	if !va.bindJSON(c, &body) {
+		return
+	}
+	voter := db.Voter{VoteHistory: body.VoteHistory}
+	for i, poll := range voter.VoteHistory {
+		if !va.validBodyID(c, fmt.Sprintf("VoteHistory[%d].PollID", i), poll.PollID) {
+			return
+		}
+	}
+
+	if err := va.db.UpdateVoterPoll(voterNumAsUint, voter); err != nil {
+		log.Println("Error adding voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+
+}
+
+// implementation for GET /schema
+// returns the field list and model version of the Voter model so that
+// clients can detect when the model has changed
+func (va *VotersAPI) GetSchema(c *gin.Context) {
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, db.VoterSchema())
+}
+
+// implementation for GET /voters/health
+// returns a "health" record indicating that the voter API is functioning properly
+
+func (va *VotersAPI) GetHealthData(c *gin.Context){
+
+	healthData, err := va.db.GetHealthData(bootTime, calls+1)
+	if err != nil {
+		log.Println("Error Getting health data: ", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+	
+	calls = calls + 1
+	c.JSON(http.StatusOK, healthData)
 }
\ No newline at end of file
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"drexel.edu/voters/db"
+	"github.com/gin-gonic/gin"
+)
+
+// ActorHeader is the header a client says who is making a request in,
+// for the audit log.  Nothing checks it, it is only as good as the
+// client.  Without it the client's address is recorded instead
+const ActorHeader = "X-Actor"
+
+// actor returns who is making the request c, for the audit log
+func actor(c *gin.Context) string {
+	if a := c.GetHeader(ActorHeader); a != "" {
+		return a
+	}
+	return c.ClientIP()
+}
+
+// auditErasedKey is set in the context by a handler that erased the voter
+// it was for, so that Audited records it without a snapshot of them
+const auditErasedKey = "auditErased"
+
+// auditID reads the id of an entity from s, ok is false if it is not a
+// positive integer
+func auditID(s string) (id uint, ok bool) {
+	id64, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || id64 == 0 {
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+// audit records entry, a change made by the request c, in the audit log.
+// The change has already been made by the time this is called, so a
+// failure is only logged
+func (va *VotersAPI) audit(c *gin.Context, entry db.AuditEntry) {
+	entry.Actor = actor(c)
+	entry.RequestID = c.Writer.Header().Get(RequestIDHeader)
+	entry.At = time.Now()
+	if err := va.db.RecordAudit(entry); err != nil {
+		log.Println("Error recording audit entry for ", entry.Entity, " ", entry.EntityID, ": ", err)
+	}
+}
+
+// auditVoter records the change the request c made to the voter with id,
+// from before to after, either of which is nil if the voter did not
+// exist then
+func (va *VotersAPI) auditVoter(c *gin.Context, id uint, before *db.Voter, after *db.Voter) {
+	if before == nil && after == nil {
+		return
+	}
+	entry, err := db.NewAuditEntry(db.AuditVoter, id, before, after)
+	if err != nil {
+		log.Println("Error making audit entry for voter ", id, ": ", err)
+		return
+	}
+	va.audit(c, entry)
+}
+
+// voterSnapshot returns the voter with id as it is now, or nil if there is
+// no such voter
+func (va *VotersAPI) voterSnapshot(id uint) (*db.Voter, error) {
+	voter, err := va.db.GetVoter(id)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &voter, nil
+}
+
+// Audited is middleware for the routes that change a voter.  It reads the
+// voter in the route's :id before the handler runs, and again after it if
+// the handler succeeds, and records the change in the audit log.  A voter
+// the handler adds is found from the Location it sends.  If a voter can't
+// be read the change is logged but not recorded.  A voter that was erased
+// is recorded as erased with neither snapshot, their earlier entries are
+// gone from the audit log and this one must not bring their name back
+func (va *VotersAPI) Audited(c *gin.Context) {
+	id, hasID := auditID(c.Param("id"))
+	var before *db.Voter
+	if hasID {
+		var err error
+		if before, err = va.voterSnapshot(id); err != nil {
+			log.Println("Error reading voter ", id, " to audit: ", err)
+			c.Next()
+			return
+		}
+	}
+
+	c.Next()
+	if c.Writer.Status() >= http.StatusMultipleChoices {
+		return
+	}
+	if c.GetBool(auditErasedKey) {
+		va.audit(c, db.AuditEntry{Entity: db.AuditVoter, EntityID: id, Action: db.AuditErased})
+		return
+	}
+
+	if created, ok := auditID(path.Base(c.Writer.Header().Get("Location"))); ok && created != id {
+		id, hasID, before = created, true, nil
+	}
+	if !hasID {
+		return
+	}
+	after, err := va.voterSnapshot(id)
+	if err != nil {
+		log.Println("Error reading voter ", id, " to audit: ", err)
+		return
+	}
+	va.auditVoter(c, id, before, after)
+}
+
+// AuditedAll is middleware for the routes that delete every voter.  It
+// reads them all before the handler runs, and records each one that is
+// gone after it, even if the handler failed part way through
+func (va *VotersAPI) AuditedAll(c *gin.Context) {
+	before, err := va.db.GetAllVoters(db.VoterSort{})
+	if err != nil {
+		log.Println("Error reading voters to audit: ", err)
+	}
+
+	c.Next()
+
+	for i := range before {
+		id := before[i].VoterID
+		if after, err := va.voterSnapshot(id); err != nil || after != nil {
+			continue
+		}
+		va.auditVoter(c, id, &before[i], nil)
+	}
+}
+
+// implementation for GET /audit
+// returns the entries in the audit log about ?entity, voter, poll or
+// vote, only those about the one with ?id if it is given, oldest first.
+// Pages are read with ?limit and ?offset
+func (va *VotersAPI) GetAudit(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	var id uint
+	if idS := c.Query("id"); idS != "" {
+		if id, ok = auditID(idS); !ok {
+			log.Println("Invalid id: ", idS)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "id must be a positive integer"})
+			return
+		}
+	}
+
+	entries, err := va.db.GetAudit(c.Query("entity"), id)
+	if err != nil {
+		log.Println("Error getting audit log: ", err)
+		if errors.Is(err, db.ErrInvalidAuditEntity) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrAuditUnavailable) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = make([]db.AuditEntry, 0)
+	}
+
+	calls = calls + 1
+	//The snapshots can have voters' names in them
+	c.Header("Cache-Control", "no-store")
+	writeList(c, entries, page, envelope)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"drexel.edu/voters/db"
+	"github.com/gin-gonic/gin"
+)
+
+// ETagHeader carries the ETag of a single voter, IfMatchHeader the ETag a
+// client read it with when it changes or deletes it
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+// etag returns the ETag of a voter with the given Version, a strong
+// entity tag of just the number, such as "3"
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// setETag sets the ETag header for a voter with the given Version
+func setETag(c *gin.Context, version uint) {
+	c.Header(ETagHeader, etag(version))
+}
+
+// hasIfMatch is true if the request has an If-Match header
+func hasIfMatch(c *gin.Context) bool {
+	return c.GetHeader(IfMatchHeader) != ""
+}
+
+// ifMatch checks the If-Match header against the ETag of a voter with
+// the given Version.  It matches if there is no header, if it is *, or if
+// one of its comma separated entity tags is the ETag, a weak W/ tag never
+// is.  If it does not match the request is aborted with a 412 and false
+// is returned
+func ifMatch(c *gin.Context, version uint) bool {
+	header := c.GetHeader(IfMatchHeader)
+	if header == "" {
+		return true
+	}
+	tag := etag(version)
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || t == tag {
+			return true
+		}
+	}
+	log.Println("If-Match does not match: ", header, " != ", tag)
+	c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match does not match the ETag " + tag + " of the voter"})
+	return false
+}
+
+// staleStatus is the status for an update turned away because its
+// Version was not the stored one, 412 if the client asked for that with
+// If-Match and 409 if it sent the Version in the body
+func staleStatus(c *gin.Context) int {
+	if hasIfMatch(c) {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusConflict
+}
+
+// ifMatchVoter checks the If-Match header of a request for voter id
+// against the voter as it is stored, returning its Version.  A request
+// without one is not checked and 0 is returned.  If the voter can't be
+// read or does not match the request is aborted and false is returned
+func (va *VotersAPI) ifMatchVoter(c *gin.Context, id uint) (uint, bool) {
+	if !hasIfMatch(c) {
+		return 0, true
+	}
+	voter, err := va.db.GetVoter(id)
+	if err != nil {
+		log.Println("Error getting voter: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return 0, false
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return 0, false
+	}
+	if !ifMatch(c, voter.Version) {
+		return 0, false
+	}
+	return voter.Version, true
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"drexel.edu/voters/pb"
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// newGateway returns the REST routes grpc-gateway generates from
+// proto/voters.proto and proto/voters_gateway.yaml.  They call the gRPC
+// service in process, so they run the same code as a gRPC call.  That is
+// not the code behind the original routes, parity_test.go checks the two
+// behave the same.  The JSON has the proto's field names, such as
+// voter_id, with every field sent even when it is empty
+func (va *VotersAPI) newGateway() http.Handler {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeader),
+		runtime.WithErrorHandler(gatewayError),
+		runtime.WithForwardResponseOption(gatewayCreated),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		}),
+	)
+	//Registering a server in process only fails if it is nil
+	if err := pb.RegisterVotersAPIHandlerServer(context.Background(), mux, &votersServer{va: va}); err != nil {
+		log.Println("Error registering the gateway: ", err)
+	}
+	return mux
+}
+
+// gatewayHeader passes X-Actor on to the gRPC service as its metadata,
+// along with the headers grpc-gateway passes on by default
+func gatewayHeader(key string) (string, bool) {
+	if strings.EqualFold(key, ActorHeader) {
+		return ActorMetadata, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayError sends the error of a call as {"error": message} with the
+// HTTP status of its gRPC code, which Problems turns into a problem like
+// the error of any other route.  FailedPrecondition, a stale version or a
+// locked field, is the 409 the REST routes send rather than
+// grpc-gateway's 400
+func gatewayError(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	s := status.Convert(err)
+	code := runtime.HTTPStatusFromCode(s.Code())
+	if s.Code() == codes.FailedPrecondition {
+		code = http.StatusConflict
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(gin.H{"error": s.Message()})
+}
+
+// gatewayCreated sends 201 for a call to AddVoter, as POST /voters does,
+// rather than grpc-gateway's 200
+func gatewayCreated(ctx context.Context, w http.ResponseWriter, m proto.Message) error {
+	if method, ok := runtime.RPCMethod(ctx); ok && strings.HasSuffix(method, "/AddVoter") {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return nil
+}
+
+// implementation for /v1/*
+// the REST routes generated from the protos, see newGateway
+func (va *VotersAPI) Gateway(c *gin.Context) {
+	va.gateway.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,287 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"drexel.edu/voters/db"
+	"drexel.edu/voters/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ActorMetadata is the metadata key a gRPC client says who is making a
+// call in, for the audit log, the same as the X-Actor header
+const ActorMetadata = "x-actor"
+
+// votersServer is the VotersAPI gRPC service.  It reads and writes the
+// same store as the REST routes, so a voter changed over one is changed
+// over the other
+type votersServer struct {
+	pb.UnimplementedVotersAPIServer
+	va *VotersAPI
+}
+
+// GRPCServer returns a gRPC server with the VotersAPI service registered,
+// backed by the same store as va.  main.go serves it on a second port
+func (va *VotersAPI) GRPCServer() *grpc.Server {
+	server := grpc.NewServer()
+	pb.RegisterVotersAPIServer(server, &votersServer{va: va})
+	return server
+}
+
+// grpcError turns an error from the store into a gRPC status, the same way
+// the REST routes turn it into an HTTP status
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrNotStorable):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, db.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, db.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// validID checks an id sent in a call the same way validBodyID checks one
+// sent in a body
+func (va *VotersAPI) validID(field string, id uint32) error {
+	if !va.features.StrictIDs {
+		return nil
+	}
+	if id == 0 || id > maxID {
+		return status.Errorf(codes.InvalidArgument, "%s must be between 1 and %d", field, maxID)
+	}
+	return nil
+}
+
+// grpcActor returns who is making the call ctx, for the audit log.  A
+// call through the gateway has the client's address in x-forwarded-for
+func grpcActor(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if a := md.Get(ActorMetadata); len(a) > 0 && a[0] != "" {
+			return a[0]
+		}
+		if a := md.Get("x-forwarded-for"); len(a) > 0 && a[0] != "" {
+			return a[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// auditCall records the change the call ctx made to the voter with id in
+// the audit log, as Audited does for the REST routes
+func (va *VotersAPI) auditCall(ctx context.Context, id uint, before *db.Voter, after *db.Voter) {
+	if before == nil && after == nil {
+		return
+	}
+	entry, err := db.NewAuditEntry(db.AuditVoter, id, before, after)
+	if err != nil {
+		log.Println("Error making audit entry for voter ", id, ": ", err)
+		return
+	}
+	entry.Actor = grpcActor(ctx)
+	entry.At = time.Now()
+	if err := va.db.RecordAudit(entry); err != nil {
+		log.Println("Error recording audit entry for voter ", id, ": ", err)
+	}
+}
+
+// toPBVoter is voter as the gRPC message
+func toPBVoter(voter db.Voter) *pb.Voter {
+	history := make([]*pb.VoterPoll, 0, len(voter.VoteHistory))
+	for _, poll := range voter.VoteHistory {
+		history = append(history, toPBVoterPoll(poll))
+	}
+	return &pb.Voter{
+		VoterId:     uint32(voter.VoterID),
+		FirstName:   voter.FirstName,
+		LastName:    voter.LastName,
+		VoteHistory: history,
+		Version:     uint32(voter.Version),
+	}
+}
+
+// toPBVoterPoll is poll as the gRPC message, its date as RFC 3339
+func toPBVoterPoll(poll db.VoterPoll) *pb.VoterPoll {
+	return &pb.VoterPoll{PollId: uint32(poll.PollID), VoteDate: poll.VoteDate.Format(time.RFC3339Nano)}
+}
+
+// fromPBVoterPoll is the gRPC message poll as a db.VoterPoll, a poll with
+// no date has the zero time as it would in JSON
+func (va *VotersAPI) fromPBVoterPoll(field string, poll *pb.VoterPoll) (db.VoterPoll, error) {
+	if poll == nil {
+		return db.VoterPoll{}, status.Errorf(codes.InvalidArgument, "%s is required", field)
+	}
+	if err := va.validID(field+".poll_id", poll.PollId); err != nil {
+		return db.VoterPoll{}, err
+	}
+	voterPoll := db.VoterPoll{PollID: uint(poll.PollId)}
+	if poll.VoteDate != "" {
+		date, err := time.Parse(time.RFC3339Nano, poll.VoteDate)
+		if err != nil {
+			return db.VoterPoll{}, status.Errorf(codes.InvalidArgument, "%s.vote_date must be an RFC 3339 time", field)
+		}
+		voterPoll.VoteDate = date
+	}
+	return voterPoll, nil
+}
+
+// fromPBVoter is the gRPC message voter as a db.Voter, checked the way a
+// voter sent to the REST routes is
+func (va *VotersAPI) fromPBVoter(voter *pb.Voter) (db.Voter, error) {
+	if voter.FirstName == "" || voter.LastName == "" {
+		return db.Voter{}, status.Error(codes.InvalidArgument, "first_name and last_name are required")
+	}
+	dbVoter := db.Voter{
+		VoterID:   uint(voter.VoterId),
+		FirstName: voter.FirstName,
+		LastName:  voter.LastName,
+		Version:   uint(voter.Version),
+	}
+	for i, poll := range voter.VoteHistory {
+		voterPoll, err := va.fromPBVoterPoll(fmt.Sprintf("vote_history[%d]", i), poll)
+		if err != nil {
+			return db.Voter{}, err
+		}
+		dbVoter.VoteHistory = append(dbVoter.VoteHistory, voterPoll)
+	}
+	return dbVoter, nil
+}
+
+func (s *votersServer) ListVoters(ctx context.Context, req *pb.ListVotersRequest) (*pb.ListVotersResponse, error) {
+	voterList, next, err := s.va.db.GetVotersPage(req.Cursor, int(req.Limit))
+	if err != nil {
+		log.Println("Error Getting Voters Page: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	resp := &pb.ListVotersResponse{Voters: make([]*pb.Voter, 0, len(voterList)), NextCursor: next}
+	for _, voter := range voterList {
+		resp.Voters = append(resp.Voters, toPBVoter(voter))
+	}
+	return resp, nil
+}
+
+func (s *votersServer) GetVoter(ctx context.Context, req *pb.GetVoterRequest) (*pb.Voter, error) {
+	voter, err := s.va.db.GetVoter(uint(req.VoterId))
+	if err != nil {
+		log.Println("Error getting voter: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	return toPBVoter(voter), nil
+}
+
+// AddVoter adds voter, a voter sent without a voter_id is given the next
+// free one as with POST /voters
+func (s *votersServer) AddVoter(ctx context.Context, req *pb.Voter) (*pb.Voter, error) {
+	voter, err := s.va.fromPBVoter(req)
+	if err != nil {
+		return nil, err
+	}
+	newID := voter.VoterID == 0 && s.va.features.AutoIDs
+	if !newID {
+		if err := s.va.validID("voter_id", req.VoterId); err != nil {
+			return nil, err
+		}
+		err = s.va.db.AddVoter(voter)
+	} else {
+		voter.VoterID, err = s.va.db.AddVoterNewID(voter)
+	}
+	if err != nil {
+		log.Println("Error adding voter: ", err)
+		return nil, grpcError(err)
+	}
+
+	stored, err := s.va.db.GetVoter(voter.VoterID)
+	if err != nil {
+		log.Println("Error reading back voter: ", err)
+		stored = voter
+	}
+
+	calls = calls + 1
+	s.va.auditCall(ctx, stored.VoterID, nil, &stored)
+	return toPBVoter(stored), nil
+}
+
+// UpdateVoter replaces the voter with voter_id.  With a version the update
+// is turned away if the voter has changed since, as with If-Match
+func (s *votersServer) UpdateVoter(ctx context.Context, req *pb.Voter) (*pb.Voter, error) {
+	if err := s.va.validID("voter_id", req.VoterId); err != nil {
+		return nil, err
+	}
+	voter, err := s.va.fromPBVoter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	before, _ := s.va.voterSnapshot(voter.VoterID)
+	if err := s.va.db.UpdateVoter(voter); err != nil {
+		log.Println("Error updating voter: ", err)
+		return nil, grpcError(err)
+	}
+	updated, err := s.va.db.GetVoter(voter.VoterID)
+	if err != nil {
+		log.Println("Error reading back voter: ", err)
+		updated = voter
+	}
+
+	calls = calls + 1
+	s.va.auditCall(ctx, voter.VoterID, before, &updated)
+	return toPBVoter(updated), nil
+}
+
+func (s *votersServer) DeleteVoter(ctx context.Context, req *pb.DeleteVoterRequest) (*pb.DeleteVoterResponse, error) {
+	id := uint(req.VoterId)
+	before, _ := s.va.voterSnapshot(id)
+	if err := s.va.db.DeleteVoter(id); err != nil {
+		log.Println("Error deleting voter: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	s.va.auditCall(ctx, id, before, nil)
+	return &pb.DeleteVoterResponse{}, nil
+}
+
+// AddVoterPoll adds poll to the voter's history, as POST /voters/:id/polls
+func (s *votersServer) AddVoterPoll(ctx context.Context, req *pb.AddVoterPollRequest) (*pb.Voter, error) {
+	poll, err := s.va.fromPBVoterPoll("poll", req.Poll)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uint(req.VoterId)
+	before, _ := s.va.voterSnapshot(id)
+	if err := s.va.db.AddVoterPoll(id, db.Voter{VoteHistory: []db.VoterPoll{poll}}); err != nil {
+		log.Println("Error adding voter poll: ", err)
+		return nil, grpcError(err)
+	}
+	voter, err := s.va.db.GetVoter(id)
+	if err != nil {
+		log.Println("Error reading back voter: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	s.va.auditCall(ctx, id, before, &voter)
+	return toPBVoter(voter), nil
+}
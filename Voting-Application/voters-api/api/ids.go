@@ -0,0 +1,33 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxID is the largest id we accept.  Ids in the path are parsed as 32 bit
+// numbers, so ids in a body are held to the same range
+const maxID = math.MaxInt32
+
+// validBodyID checks an id that was bound from a request body the same way
+// ids in the path are checked.  ShouldBindJSON will bind any value that
+// fits in a uint, so without this a body can carry ids that could never
+// be asked for in a path.  If the id is 0 or out of range the request is
+// aborted with a 400 naming the field and false is returned.  The check
+// is skipped when the StrictIDs feature is off
+func (va *VotersAPI) validBodyID(c *gin.Context, field string, id uint) bool {
+	if !va.features.StrictIDs {
+		return true
+	}
+	if id == 0 || id > maxID {
+		msg := fmt.Sprintf("%s must be between 1 and %d", field, maxID)
+		log.Println("Invalid id in body: ", msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return false
+	}
+	return true
+}
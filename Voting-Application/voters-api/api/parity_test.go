@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The REST routes and the /v1 routes generated from the protos are handled
+// by different code, the handlers in api-handler.go and the gRPC methods in
+// grpc.go.  These tests send the same requests to both and check that they
+// store the same voter and fail the same way, so the two can't drift apart
+// unnoticed
+
+// parityVoterID is the first of the ids the tests use, far past any id the
+// seed data or AddVoterNewID hands out
+const parityVoterID = 900101
+
+// newParityRouter connects to the redis at REDIS_URL, or the default
+// location, and skips the test if there isn't one.  It returns a router
+// with the REST routes the tests use, registered as in main.go, and /v1
+func newParityRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	va, err := New(Features{StrictIDs: true, AutoIDs: true, Gateway: true})
+	if err != nil {
+		t.Skip("redis is not available: ", err)
+	}
+
+	r := gin.New()
+	r.Use(Problems())
+	r.POST("/voters", va.AddVoter)
+	r.GET("/voters/:id", va.GetVoter)
+	r.PUT("/voters/:id", va.UpdateVoter)
+	r.DELETE("/voters/:id", va.DeleteVoter)
+	r.Any("/v1/*path", va.Gateway)
+	return r
+}
+
+// serve sends r the request and returns the response
+func serve(r http.Handler, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// parityVoter is the part of a voter both kinds of route send, whichever
+// names they give the fields
+type parityVoter struct {
+	ID        uint
+	FirstName string
+	LastName  string
+	Version   uint
+}
+
+// readVoter reads the voter with id over REST, or over /v1 if v1 is set
+func readVoter(t *testing.T, r http.Handler, id uint, v1 bool) parityVoter {
+	t.Helper()
+
+	path := "/voters/" + strconv.FormatUint(uint64(id), 10)
+	if v1 {
+		path = "/v1" + path
+	}
+	w := serve(r, http.MethodGet, path, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body.String())
+	}
+
+	var voter parityVoter
+	if v1 {
+		var sent struct {
+			VoterID   uint   `json:"voter_id"`
+			FirstName string `json:"first_name"`
+			LastName  string `json:"last_name"`
+			Version   uint   `json:"version"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &sent); err != nil {
+			t.Fatal("Error reading voter: ", err)
+		}
+		voter = parityVoter{sent.VoterID, sent.FirstName, sent.LastName, sent.Version}
+	} else {
+		var sent struct {
+			VoterID   uint
+			FirstName string
+			LastName  string
+			Version   uint
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &sent); err != nil {
+			t.Fatal("Error reading voter: ", err)
+		}
+		voter = parityVoter{sent.VoterID, sent.FirstName, sent.LastName, sent.Version}
+	}
+	return voter
+}
+
+func TestParityWrittenVotersReadTheSame(t *testing.T) {
+	r := newParityRouter(t)
+	restID, v1ID := uint(parityVoterID), uint(parityVoterID+1)
+	for _, id := range []uint{restID, v1ID} {
+		serve(r, http.MethodDelete, "/voters/"+strconv.FormatUint(uint64(id), 10), "")
+		defer serve(r, http.MethodDelete, "/voters/"+strconv.FormatUint(uint64(id), 10), "")
+	}
+
+	if w := serve(r, http.MethodPost, "/voters", `{"VoterID": 900101, "FirstName": "Ada", "LastName": "Lovelace"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /voters: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPost, "/v1/voters", `{"voter_id": 900102, "first_name": "Ada", "last_name": "Lovelace"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /v1/voters: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPut, "/voters/900101", `{"FirstName": "Grace", "LastName": "Hopper", "Version": 1}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT /voters/900101: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, http.MethodPut, "/v1/voters/900102", `{"first_name": "Grace", "last_name": "Hopper", "version": 1}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT /v1/voters/900102: status %d: %s", w.Code, w.Body.String())
+	}
+
+	//Each voter reads the same over both kinds of route, and the two
+	//voters, written the same way over different routes, match
+	for _, id := range []uint{restID, v1ID} {
+		rest, v1 := readVoter(t, r, id, false), readVoter(t, r, id, true)
+		if rest != v1 {
+			t.Errorf("voter %d reads %+v over REST but %+v over /v1", id, rest, v1)
+		}
+	}
+	rest, v1 := readVoter(t, r, restID, false), readVoter(t, r, v1ID, false)
+	v1.ID = rest.ID
+	if rest != v1 {
+		t.Errorf("voter written over REST is %+v but over /v1 is %+v", rest, v1)
+	}
+}
+
+func TestParityErrorsMatch(t *testing.T) {
+	r := newParityRouter(t)
+	serve(r, http.MethodDelete, "/voters/900103", "")
+	defer serve(r, http.MethodDelete, "/voters/900103", "")
+	if w := serve(r, http.MethodPost, "/voters", `{"VoterID": 900103, "FirstName": "Ada", "LastName": "Lovelace"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /voters: status %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		restPath, restBody string
+		v1Path, v1Body     string
+	}{
+		{"missing voter", http.MethodGet, "/voters/900199", "", "/v1/voters/900199", ""},
+		{"update missing voter", http.MethodPut, "/voters/900199", `{"FirstName": "A", "LastName": "B"}`,
+			"/v1/voters/900199", `{"first_name": "A", "last_name": "B"}`},
+		{"stale version", http.MethodPut, "/voters/900103", `{"FirstName": "A", "LastName": "B", "Version": 7}`,
+			"/v1/voters/900103", `{"first_name": "A", "last_name": "B", "version": 7}`},
+		{"id out of range", http.MethodPost, "/voters", `{"VoterID": 4294967295, "FirstName": "A", "LastName": "B"}`,
+			"/v1/voters", `{"voter_id": 4294967295, "first_name": "A", "last_name": "B"}`},
+	}
+	for _, test := range tests {
+		rest := serve(r, test.method, test.restPath, test.restBody)
+		v1 := serve(r, test.method, test.v1Path, test.v1Body)
+		if rest.Code != v1.Code {
+			t.Errorf("%s: REST sent %d but /v1 sent %d", test.name, rest.Code, v1.Code)
+		}
+		if ct := v1.Header().Get("Content-Type"); ct != ProblemContentType {
+			t.Errorf("%s: /v1 sent %q rather than a problem", test.name, ct)
+		}
+	}
+}
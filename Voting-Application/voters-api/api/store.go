@@ -0,0 +1,46 @@
+package api
+
+import (
+	"time"
+
+	"drexel.edu/voters/db"
+)
+
+// VoterStore is everything the api needs from the data layer.  *db.VoterList
+// is the real implementation, anything else that satisfies it, such as a
+// fake in a test or a VoterList wired to a custom redis client, can be
+// passed to NewWithStore
+type VoterStore interface {
+	GetAllVoters(order db.VoterSort) ([]db.Voter, error)
+	GetVotersPage(cursor uint64, count int) ([]db.Voter, uint64, error)
+	SearchVoters(q string) ([]db.Voter, error)
+	GetVoter(id uint) (db.Voter, error)
+	AddVoter(voter db.Voter) error
+	AddVoterNewID(voter db.Voter) (uint, error)
+	UpdateVoter(voter db.Voter) error
+	UpdateVoterName(id uint, firstName string, lastName string) error
+	DeleteVoter(id uint) error
+	DeleteAllVoters() error
+	GetVoterPolls(id uint) ([]db.VoterPoll, error)
+	GetVoterPoll(voterId, pollId uint) (db.VoterPoll, error)
+	GetVoterSummary(id uint) (db.VoterSummary, error)
+	ExportVoter(id uint, now time.Time) (db.VoterExport, error)
+	EraseVoter(id uint, now time.Time) (db.VoterErasure, error)
+	RecordAudit(entry db.AuditEntry) error
+	GetAudit(entity string, id uint) ([]db.AuditEntry, error)
+	AddVoterPoll(voterId uint, requestVoter db.Voter) error
+	AddVoterPolls(id uint, polls []db.VoterPoll) error
+	UpdateVoterPoll(voterId uint, requestVoter db.Voter) error
+	DeleteVoterPoll(voterId uint, pollId uint) error
+	GetHealthData(bootTime time.Time, calls uint) (db.HealthData, error)
+}
+
+// NewWithStore returns an api backed by store instead of the redis backed
+// store New creates
+func NewWithStore(store VoterStore, features Features) *VotersAPI {
+	bootTime = time.Now()
+
+	va := &VotersAPI{db: store, features: features}
+	va.gateway = va.newGateway()
+	return va
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// setNew writes value as the document at key with JSON.SET's NX option,
+// so it is only written if there is no document there yet.  Checking
+// first and then writing would let two requests adding the same voter at
+// once both see it missing, this is one command so one of them gets
+// ErrAlreadyExists instead
+func (c *cache) setNew(key string, value any) error {
+	doc, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	err = c.cacheClient.Do(c.context, "JSON.SET", key, ".", string(doc), "NX").Err()
+	if errors.Is(err, redis.Nil) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// voterCreator is a voterStore that can add a voter only if its id is
+// free in one step.  Other stores are checked and then written, so two
+// voters added with the same id at once can both go in
+type voterCreator interface {
+	create(voter Voter) error
+}
+
+func (r *redisStore) create(voter Voter) error {
+	if err := r.setNew(redisKeyFromId(voter.VoterID), voter); err != nil {
+		return err
+	}
+	r.reindexNames(nil, &voter)
+	return nil
+}
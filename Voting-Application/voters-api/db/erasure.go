@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// VoterErasure is what EraseVoter did to a voter who asked to be
+// forgotten
+type VoterErasure struct {
+	VoterID uint
+	//VotesAnonymized is how many of their votes, deleted ones in the
+	//votes API's trash included, no longer say who cast them
+	VotesAnonymized int
+	ErasedAt        time.Time
+}
+
+// EraseVoter accepts a voter id and erases the voter, for a voter who
+// asked to be forgotten.  It is a saga of three steps:
+//
+//  1. the voter's names and VoteHistory are scrubbed, which is undone if
+//     the next step fails
+//  2. the votes API takes the voter off every vote they cast, the point
+//     of no return
+//  3. the voter is deleted
+//
+// So a failure either leaves the voter as they were, or leaves them
+// scrubbed with their votes anonymized, and EraseVoter can be called
+// again to finish.  Calling it again after step 2 finds no votes to
+// anonymize, as the votes API does nothing more the second time.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//
+//					(3) The votes API must be reachable
+//
+// Postconditions:
+//
+//	    (1) The voter will be deleted and none of their votes will
+//			have their VoterID, the erasure will be returned
+//		(2) The voter's entries, and those of the votes they cast, will
+//			be taken out of the audit log
+//		(3) If a step fails a *SagaError will be returned, saying
+//			whether the steps before it were undone.  If the votes
+//			API could not be reached it wraps ErrDependencyUnavailable,
+//			if the voter changed while being scrubbed ErrConflict
+//		(4) If there is an error, it will be returned
+func (v *VoterList) EraseVoter(id uint, now time.Time) (VoterErasure, error) {
+
+	voter, err := v.store.get(id)
+	if err != nil {
+		return VoterErasure{}, notFoundOr(err, "voter does not exist")
+	}
+
+	erasure := VoterErasure{VoterID: id}
+	steps := []sagaStep{
+		{
+			name: "scrub voter",
+			do: func() error {
+				scrubbed := voter
+				scrubbed.FirstName = ""
+				scrubbed.LastName = ""
+				scrubbed.VoteHistory = []VoterPoll{}
+				return v.UpdateVoter(scrubbed)
+			},
+			undo: func() error {
+				//Only put the voter back if nothing has changed it since
+				//it was scrubbed
+				restored := voter
+				restored.Version = voter.Version + 1
+				return v.UpdateVoter(restored)
+			},
+		},
+		{
+			name: "anonymize votes",
+			do: func() error {
+				n, err := v.votes.anonymizeVotes(context.Background(), id)
+				erasure.VotesAnonymized = n
+				return err
+			},
+		},
+		{
+			name: "delete voter",
+			do: func() error {
+				//Someone else deleting the voter first is fine, it's
+				//gone either way
+				if err := v.store.delete(id); err != nil && !errors.Is(err, ErrNotFound) {
+					return err
+				}
+				return nil
+			},
+		},
+	}
+	if err := runSaga("erasure of voter", steps); err != nil {
+		return VoterErasure{}, err
+	}
+	//The audit log has the voter's names in it too, and their votes with
+	//their VoterID.  The voter is gone by now, so a failure is only
+	//logged
+	if v.audit != nil {
+		if err := v.audit.forget(AuditVoter, id); err != nil {
+			log.Println("Error taking voter ", id, " out of the audit log: ", err)
+		}
+		if err := v.audit.forgetVotesOf(id); err != nil {
+			log.Println("Error taking the votes of voter ", id, " out of the audit log: ", err)
+		}
+	}
+
+	erasure.ErasedAt = now.UTC()
+	return erasure, nil
+}
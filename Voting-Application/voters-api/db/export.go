@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// VoterExport is everything that is kept about a voter, for a subject
+// access request, returned by GET /voters/:id/export.  Votes are the
+// voter's votes as the votes API has them, whatever fields they have.
+// Votes in anonymous polls are not tied to the voter, so they are not in
+// it
+type VoterExport struct {
+	ExportedAt  time.Time
+	Voter       Voter
+	VoteHistory []VoterPoll
+	Votes       []json.RawMessage
+}
+
+// ExportVoter accepts a voter id and returns everything that is kept about
+// the voter, their record and vote history from the DB and their votes
+// from the votes API, as of now.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//
+//					(3) The votes API must be reachable
+//
+// Postconditions:
+//
+//	    (1) The export will be returned, if the voter exists
+//		(2) If the votes API can't be reached or answers with an
+//			error, an error wrapping ErrDependencyUnavailable will be
+//			returned
+//		(3) If there is an error, it will be returned
+//			along with an empty export
+//		(4) The database file will not be modified
+func (v *VoterList) ExportVoter(id uint, now time.Time) (VoterExport, error) {
+
+	voter, err := v.store.get(id)
+	if err != nil {
+		return VoterExport{}, notFoundOr(err, "voter does not exist")
+	}
+
+	votes, err := v.votes.votesByVoter(context.Background(), id)
+	if err != nil {
+		return VoterExport{}, err
+	}
+
+	history := voter.VoteHistory
+	if history == nil {
+		history = []VoterPoll{}
+	}
+	return VoterExport{
+		ExportedAt:  now.UTC(),
+		Voter:       voter,
+		VoteHistory: history,
+		Votes:       votes,
+	}, nil
+}
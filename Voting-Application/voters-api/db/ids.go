@@ -0,0 +1,187 @@
+package db
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// VoterIDCounterKey is the redis counter new voter ids are taken from.
+// Like the name index it is kept out of the voters: prefix
+const VoterIDCounterKey = "voter-next-id"
+
+// IDSchemeSequential and IDSchemeRandom are the ways ID_SCHEME can have
+// new ids picked.  Sequential ids come from the counter, random ones are
+// picked at random, so they are hard to guess and don't give away how
+// many records there are
+const (
+	IDSchemeSequential = "sequential"
+	IDSchemeRandom     = "random"
+)
+
+// NewIDRetries is how many ids AddVoterNewID tries when the ones it is
+// given were already taken, by clients that chose their own or by
+// another random pick
+const NewIDRetries = 10
+
+// maxNewID is the highest id that is given out, the highest id that can
+// be asked for in a path
+const maxNewID = math.MaxInt32
+
+// ErrIDsExhausted is returned when every id up to maxNewID has been given
+// out
+var ErrIDsExhausted = errors.New("no ids are left to give out")
+
+// idAllocator is a voterStore that can give out new voter ids itself,
+// so that replicas adding voters at the same time don't get the same one.
+// Stores that can't are given the id after the highest one they have
+type idAllocator interface {
+	newID() (uint, error)
+}
+
+// ErrInvalidIDScheme is returned by CheckIDScheme when ID_SCHEME is not
+// one of the schemes there are
+var ErrInvalidIDScheme = fmt.Errorf("ID_SCHEME must be %s or %s", IDSchemeSequential, IDSchemeRandom)
+
+// CheckIDScheme returns ErrInvalidIDScheme if ID_SCHEME is set to a scheme
+// there is none of, such as uuid, so that the service can refuse to start
+// rather than give out ids some other way than the one asked for
+func CheckIDScheme() error {
+	if s := os.Getenv("ID_SCHEME"); s != "" && s != IDSchemeSequential && s != IDSchemeRandom {
+		return fmt.Errorf("%w, not %q", ErrInvalidIDScheme, s)
+	}
+	return nil
+}
+
+// idSchemeFromEnv returns ID_SCHEME, or IDSchemeSequential if it is not
+// set or not a known scheme, see CheckIDScheme
+func idSchemeFromEnv() string {
+	scheme := IDSchemeSequential
+	if s := os.Getenv("ID_SCHEME"); s != "" {
+		if s == IDSchemeSequential || s == IDSchemeRandom {
+			scheme = s
+		} else {
+			log.Println("Invalid ID_SCHEME, using default: ", s)
+		}
+	}
+	return scheme
+}
+
+// randomID returns an id picked at random from 1 to maxNewID
+func randomID() (uint, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(maxNewID))
+	if err != nil {
+		return 0, err
+	}
+	return uint(n.Uint64()) + 1, nil
+}
+
+// nextID returns the next id from the redis counter at counterKey.  When
+// there is no counter yet it is started at the highest id of the keys
+// with the given prefixes, so ids clients chose before it existed are
+// not given out again
+func (c *cache) nextID(counterKey string, prefixes ...string) (uint, error) {
+	exists, err := c.cacheClient.Exists(c.context, counterKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if exists == 0 {
+		var highest uint64
+		for _, prefix := range prefixes {
+			keys, err := c.scanKeys(prefix + "*")
+			if err != nil {
+				return 0, err
+			}
+			for _, key := range keys {
+				//Anything else under the prefix is not a record
+				id, err := strconv.ParseUint(strings.TrimPrefix(key, prefix), 10, 32)
+				if err == nil && id > highest {
+					highest = id
+				}
+			}
+		}
+		//SETNX, so a replica that started the counter first wins and
+		//the ids it has given out since are kept
+		if err := c.cacheClient.SetNX(c.context, counterKey, highest, 0).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	id, err := c.cacheClient.Incr(c.context, counterKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if id > maxNewID {
+		return 0, ErrIDsExhausted
+	}
+	return uint(id), nil
+}
+
+func (r *redisStore) newID() (uint, error) {
+	return r.nextID(VoterIDCounterKey, RedisKeyPrefix)
+}
+
+// newVoterID picks the id for AddVoterNewID the way the ID_SCHEME the
+// VoterList was created with says
+func (v *VoterList) newVoterID() (uint, error) {
+	if v.idScheme == IDSchemeRandom {
+		return randomID()
+	}
+	if alloc, ok := v.store.(idAllocator); ok {
+		return alloc.newID()
+	}
+
+	all, err := v.store.list()
+	if err != nil {
+		return 0, err
+	}
+	var id uint
+	for _, existing := range all {
+		if existing.VoterID > id {
+			id = existing.VoterID
+		}
+	}
+	if id >= maxNewID {
+		return 0, ErrIDsExhausted
+	}
+	return id + 1, nil
+}
+
+// AddVoterNewID adds a voter under an id the DB picks, whatever VoterID
+// it was given, and returns the id.  With redis sequential ids come from
+// VoterIDCounterKey, so they are never given out twice, see ID_SCHEME
+// for random ones.  An id that is already taken is skipped
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The voter will be added to the DB under the returned id
+//		(2) If no free id was found within NewIDRetries tries an error
+//			wrapping ErrConflict will be returned
+//		(3) If there is an error, it will be returned
+func (v *VoterList) AddVoterNewID(voter Voter) (uint, error) {
+
+	for i := 0; i < NewIDRetries; i++ {
+		id, err := v.newVoterID()
+		if err != nil {
+			return 0, err
+		}
+
+		voter.VoterID = id
+		err = v.AddVoter(voter)
+		if errors.Is(err, ErrAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return id, nil
+	}
+	return 0, fmt.Errorf("%w: no free voter id after %d tries", ErrConflict, NewIDRetries)
+}
@@ -0,0 +1,170 @@
+//go:build mongo
+
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDefaultLocation is the server used with -db mongo when MONGO_URL
+// is not set
+const MongoDefaultLocation = "mongodb://localhost:27017"
+
+// Voters are kept in the voters collection of the voting database
+const (
+	MongoDatabase   = "voting"
+	MongoCollection = "voters"
+)
+
+// mongoTimeout bounds every call to mongo, so that an unreachable server
+// fails the request instead of hanging it
+const mongoTimeout = 5 * time.Second
+
+// mongoStore keeps voters as documents in a MongoDB collection, for
+// deployments that already run mongo and don't want to run redis with
+// ReJSON as well.  The driver maps the Voter fields to lower case names,
+// so VoterID is voterid and the poll ids are votehistory.pollid
+type mongoStore struct {
+	client *mongo.Client
+	voters *mongo.Collection
+}
+
+// NewMongoVoterList connects to the mongo server in MONGO_URL, creates
+// the indexes it needs and returns a VoterList that keeps its voters
+// there instead of redis.  It is only available in builds made with
+// -tags mongo
+func NewMongoVoterList() (*VoterList, error) {
+	mongoUrl := os.Getenv("MONGO_URL")
+	if mongoUrl == "" {
+		mongoUrl = MongoDefaultLocation
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoUrl))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	//VoterID is unique, like the redis key it replaces.  The poll ids in
+	//the vote histories are indexed so that voters can be found by poll
+	voters := client.Database(MongoDatabase).Collection(MongoCollection)
+	_, err = voters.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "voterid", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "votehistory.pollid", Value: 1}}},
+	})
+	if err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	voterList := &VoterList{
+		healthInfo: HealthData{},
+		store:      &mongoStore{client: client, voters: voters},
+		idScheme:   idSchemeFromEnv(),
+		votes:      votesServiceFromEnv(),
+	}
+	voterList.seedFromEnv()
+
+	return voterList, nil
+}
+
+func (m *mongoStore) get(id uint) (Voter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	var voter Voter
+	err := m.voters.FindOne(ctx, bson.M{"voterid": id}).Decode(&voter)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Voter{}, ErrNotFound
+	}
+	if err != nil {
+		return Voter{}, err
+	}
+	return voter, nil
+}
+
+// put writes the whole voter, replacing it if it is already there
+func (m *mongoStore) put(voter Voter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := m.voters.ReplaceOne(ctx, bson.M{"voterid": voter.VoterID}, voter,
+		options.Replace().SetUpsert(true))
+	return err
+}
+
+func (m *mongoStore) delete(id uint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	result, err := m.voters.DeleteOne(ctx, bson.M{"voterid": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *mongoStore) list() ([]Voter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	cursor, err := m.voters.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "voterid", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var voterList []Voter
+	if err := cursor.All(ctx, &voterList); err != nil {
+		return nil, err
+	}
+	return voterList, nil
+}
+
+// listPage uses the last voter id of the previous page as the cursor,
+// voter ids start at 1 so 0 is the start
+func (m *mongoStore) listPage(cursor uint64, count int) ([]Voter, uint64, error) {
+	if count <= 0 {
+		count = DefaultScanBatchSize
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	cursorOpts := options.Find().SetSort(bson.D{{Key: "voterid", Value: 1}}).SetLimit(int64(count))
+	found, err := m.voters.Find(ctx, bson.M{"voterid": bson.M{"$gt": cursor}}, cursorOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+	var voterList []Voter
+	if err := found.All(ctx, &voterList); err != nil {
+		return nil, 0, err
+	}
+
+	//A short page is the last one
+	var next uint64
+	if len(voterList) == count {
+		next = uint64(voterList[len(voterList)-1].VoterID)
+	}
+	return voterList, next, nil
+}
+
+func (m *mongoStore) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	return m.client.Ping(ctx, nil)
+}
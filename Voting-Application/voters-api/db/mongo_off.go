@@ -0,0 +1,14 @@
+//go:build !mongo
+
+package db
+
+import "errors"
+
+// ErrMongoNotBuilt is returned when the MongoDB store is asked for by a
+// build made without -tags mongo, which leaves out the MongoDB driver
+var ErrMongoNotBuilt = errors.New("built without MongoDB support, rebuild with -tags mongo")
+
+// NewMongoVoterList is only available in builds made with -tags mongo
+func NewMongoVoterList() (*VoterList, error) {
+	return nil, ErrMongoNotBuilt
+}
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// VoterWatchRetries is how many times a path update of a voter is tried
+// when the voter keeps changing underneath it
+const VoterWatchRetries = 10
+
+// errVoterPollExists is returned when a poll is added to a voter that
+// already has it
+var errVoterPollExists = fmt.Errorf("%w: %s", ErrAlreadyExists, VoterPollExists)
+
+// voterPathStore is a voterStore that can change part of a voter in
+// place rather than writing the whole voter back, so that two requests
+// changing different parts of the same voter don't undo each other.
+// setName changes just the names, appendPoll adds one poll to the end of
+// the voter's history, or returns errVoterPollExists if it is already
+// there.  Stores that can't do this are updated by reading the voter and
+// writing it back
+type voterPathStore interface {
+	setName(id uint, firstName string, lastName string) error
+	appendPoll(id uint, poll VoterPoll) error
+}
+
+// watchVoter runs fn in a redis transaction that only goes through if
+// the voter has not changed since fn started reading it, trying again
+// up to VoterWatchRetries times.  fn must do its reads with tx and its
+// writes with tx.TxPipelined
+func (r *redisStore) watchVoter(id uint, fn func(tx *redis.Tx, key string) error) error {
+	key := redisKeyFromId(id)
+	for i := 0; i < VoterWatchRetries; i++ {
+		err := r.cacheClient.Watch(r.context, func(tx *redis.Tx) error {
+			return fn(tx, key)
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: voter %d kept changing: %w", ErrConflict, id, redis.TxFailedErr)
+}
+
+// txDo sends a command on the watched connection of tx, which has no Do
+// of its own
+func (r *redisStore) txDo(tx *redis.Tx, args ...interface{}) *redis.Cmd {
+	cmd := redis.NewCmd(r.context, args...)
+	_ = tx.Process(r.context, cmd)
+	return cmd
+}
+
+// setName uses JSON.SET on just .FirstName and .LastName, and swaps the
+// old names for the new ones in the name index and bumps the Version in
+// the same transaction
+func (r *redisStore) setName(id uint, firstName string, lastName string) error {
+	first, err := json.Marshal(firstName)
+	if err != nil {
+		return err
+	}
+	last, err := json.Marshal(lastName)
+	if err != nil {
+		return err
+	}
+
+	return r.watchVoter(id, func(tx *redis.Tx, key string) error {
+		//Asking for more than one path returns them keyed by path
+		doc, err := r.txDo(tx, "JSON.GET", key, ".FirstName", ".LastName").Text()
+		if errors.Is(err, redis.Nil) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var names map[string]string
+		if err := json.Unmarshal([]byte(doc), &names); err != nil {
+			return err
+		}
+		old := Voter{VoterID: id, FirstName: names[".FirstName"], LastName: names[".LastName"]}
+		updated := Voter{VoterID: id, FirstName: firstName, LastName: lastName}
+		version, err := r.txVersion(tx, key)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(r.context, func(pipe redis.Pipeliner) error {
+			pipe.Do(r.context, "JSON.SET", key, ".FirstName", string(first))
+			pipe.Do(r.context, "JSON.SET", key, ".LastName", string(last))
+			pipe.Do(r.context, "JSON.SET", key, "$.Version", version+1)
+			r.unindexName(pipe, old)
+			r.indexName(pipe, updated)
+			return nil
+		})
+		return err
+	})
+}
+
+// appendPoll uses JSON.ARRAPPEND to add the poll to .VoteHistory, only
+// the poll is sent to redis, along with the bumped Version.  A voter
+// whose history was stored as null has it set to an array of just the
+// poll instead
+func (r *redisStore) appendPoll(id uint, poll VoterPoll) error {
+	pollJSON, err := json.Marshal(poll)
+	if err != nil {
+		return err
+	}
+
+	return r.watchVoter(id, func(tx *redis.Tx, key string) error {
+		historyType, err := r.txDo(tx, "JSON.TYPE", key, ".VoteHistory").Text()
+		if errors.Is(err, redis.Nil) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		isArray := historyType == "array"
+		if isArray {
+			//Only the ids of the matching polls come back, not the
+			//whole history
+			filter := fmt.Sprintf("$.VoteHistory[?(@.PollID==%d)].PollID", poll.PollID)
+			matches, err := r.txDo(tx, "JSON.GET", key, filter).Text()
+			if err != nil {
+				return err
+			}
+			var ids []uint
+			if err := json.Unmarshal([]byte(matches), &ids); err != nil {
+				return err
+			}
+			if len(ids) > 0 {
+				return errVoterPollExists
+			}
+		}
+
+		version, err := r.txVersion(tx, key)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(r.context, func(pipe redis.Pipeliner) error {
+			pipe.Do(r.context, "JSON.SET", key, "$.Version", version+1)
+			if isArray {
+				pipe.Do(r.context, "JSON.ARRAPPEND", key, ".VoteHistory", string(pollJSON))
+			} else {
+				pipe.Do(r.context, "JSON.SET", key, ".VoteHistory", "["+string(pollJSON)+"]")
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+// UpdateVoterName changes just the first and last name of a voter.  With
+// redis only the names are written, with JSON.SET on their paths, so a
+// poll added to the voter at the same time is not lost
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The voter's names will be updated in the DB, the rest of
+//			the voter is left as it is
+//		(2) If the voter does not exist ErrNotFound will be returned
+//		(3) If there is an error, it will be returned
+func (v *VoterList) UpdateVoterName(id uint, firstName string, lastName string) error {
+
+	if paths, ok := v.store.(voterPathStore); ok {
+		return notFoundOr(paths.setName(id, firstName, lastName), "voter does not exist")
+	}
+
+	voter, err := v.store.get(id)
+	if err != nil {
+		return notFoundOr(err, "voter does not exist")
+	}
+	voter.FirstName = firstName
+	voter.LastName = lastName
+	return v.UpdateVoter(voter)
+}
+
+// AppendVoterPoll adds a poll to the end of a voter's history.  With
+// redis only the poll is written, with JSON.ARRAPPEND, so a change to the
+// voter's name at the same time is not lost
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//
+//					(3) The poll must not already be in the voter's
+//						history
+//
+// Postconditions:
+//
+//	    (1) The poll will be added to the end of the voter's history
+//		(2) If the voter does not exist ErrNotFound will be returned
+//		(3) If there is an error, it will be returned
+func (v *VoterList) AppendVoterPoll(id uint, poll VoterPoll) error {
+
+	if err := validateStorable(poll); err != nil {
+		return err
+	}
+
+	if paths, ok := v.store.(voterPathStore); ok {
+		return notFoundOr(paths.appendPoll(id, poll), "voter does not exist")
+	}
+
+	voter, err := v.store.get(id)
+	if err != nil {
+		return notFoundOr(err, "voter does not exist")
+	}
+	for _, existing := range voter.VoteHistory {
+		if existing.PollID == poll.PollID {
+			return errVoterPollExists
+		}
+	}
+	voter.VoteHistory = append(voter.VoteHistory, poll)
+	return v.UpdateVoter(voter)
+}
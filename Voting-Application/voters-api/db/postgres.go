@@ -0,0 +1,158 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresDefaultLocation is the database used with -db postgres when
+// DATABASE_URL is not set
+const PostgresDefaultLocation = "postgres://postgres@localhost:5432/voting?sslmode=disable"
+
+// postgresSchema creates the voters table if it is not there yet.  Each
+// voter is kept whole as a JSONB document, the same shape it has in
+// redis, keyed by its id
+const postgresSchema = `CREATE TABLE IF NOT EXISTS voters (
+	voter_id BIGINT PRIMARY KEY,
+	doc      JSONB  NOT NULL
+)`
+
+// postgresStore keeps voters in a postgres table, for deployments that
+// need the voters to be durable
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresVoterList connects to the postgres database in DATABASE_URL,
+// creates the voters table if it is not there and returns a VoterList
+// that keeps its voters there instead of redis
+func NewPostgresVoterList() (*VoterList, error) {
+	databaseUrl := os.Getenv("DATABASE_URL")
+	if databaseUrl == "" {
+		databaseUrl = PostgresDefaultLocation
+	}
+
+	db, err := sql.Open("postgres", databaseUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	voterList := &VoterList{
+		healthInfo: HealthData{},
+		store:      &postgresStore{db: db},
+		idScheme:   idSchemeFromEnv(),
+		votes:      votesServiceFromEnv(),
+	}
+	voterList.seedFromEnv()
+
+	return voterList, nil
+}
+
+// scanVoters reads the doc column of each of rows as a voter
+func scanVoters(rows *sql.Rows) ([]Voter, error) {
+	defer rows.Close()
+
+	var voterList []Voter
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var voter Voter
+		if err := json.Unmarshal(doc, &voter); err != nil {
+			return nil, err
+		}
+		voterList = append(voterList, voter)
+	}
+	return voterList, rows.Err()
+}
+
+func (p *postgresStore) get(id uint) (Voter, error) {
+	var doc []byte
+	err := p.db.QueryRow(`SELECT doc FROM voters WHERE voter_id = $1`, id).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Voter{}, ErrNotFound
+	}
+	if err != nil {
+		return Voter{}, err
+	}
+
+	var voter Voter
+	if err := json.Unmarshal(doc, &voter); err != nil {
+		return Voter{}, err
+	}
+	return voter, nil
+}
+
+// put writes the whole voter, replacing it if it is already there
+func (p *postgresStore) put(voter Voter) error {
+	doc, err := json.Marshal(voter)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.Exec(`INSERT INTO voters (voter_id, doc) VALUES ($1, $2)
+		ON CONFLICT (voter_id) DO UPDATE SET doc = excluded.doc`, voter.VoterID, string(doc))
+	return err
+}
+
+func (p *postgresStore) delete(id uint) error {
+	result, err := p.db.Exec(`DELETE FROM voters WHERE voter_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *postgresStore) list() ([]Voter, error) {
+	rows, err := p.db.Query(`SELECT doc FROM voters ORDER BY voter_id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanVoters(rows)
+}
+
+// listPage uses the last voter id of the previous page as the cursor,
+// voter ids start at 1 so 0 is the start
+func (p *postgresStore) listPage(cursor uint64, count int) ([]Voter, uint64, error) {
+	if count <= 0 {
+		count = DefaultScanBatchSize
+	}
+	rows, err := p.db.Query(`SELECT doc FROM voters WHERE voter_id > $1 ORDER BY voter_id LIMIT $2`, cursor, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	voterList, err := scanVoters(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	//A short page is the last one
+	var next uint64
+	if len(voterList) == count {
+		next = uint64(voterList[len(voterList)-1].VoterID)
+	}
+	return voterList, next, nil
+}
+
+func (p *postgresStore) ping() error {
+	return p.db.Ping()
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+	"log"
+)
+
+// sagaStep is one step of a saga.  undo puts back what do changed, for
+// when a later step fails.  A step with no undo can't be taken back, once
+// it is done the saga is past its point of no return and the steps after
+// it have to be ones that can simply be run again until they succeed
+type sagaStep struct {
+	name string
+	do   func() error
+	undo func() error
+}
+
+// SagaError is returned when a step of a saga fails.  If Compensated is
+// set the steps before it were undone, so nothing was changed and the
+// saga can be started again.  Otherwise the saga got past its point of no
+// return, or could not undo a step, and has to be run again to finish
+type SagaError struct {
+	Saga        string
+	Step        string
+	Compensated bool
+	Err         error
+}
+
+func (e *SagaError) Error() string {
+	if e.Compensated {
+		return fmt.Sprintf("%s failed at %s and was undone: %v", e.Saga, e.Step, e.Err)
+	}
+	return fmt.Sprintf("%s failed at %s and is not finished, run it again: %v", e.Saga, e.Step, e.Err)
+}
+
+func (e *SagaError) Unwrap() error {
+	return e.Err
+}
+
+// runSaga runs the steps of the saga named name in order.  If one fails,
+// the steps done before it are undone in reverse, unless one of them
+// could not be, and a *SagaError is returned
+func runSaga(name string, steps []sagaStep) error {
+	for i, step := range steps {
+		err := step.do()
+		if err == nil {
+			continue
+		}
+		log.Println("Error in "+name+" at "+step.name+": ", err)
+
+		sagaErr := &SagaError{Saga: name, Step: step.name, Compensated: true, Err: err}
+		for j := i - 1; j >= 0; j-- {
+			if steps[j].undo == nil {
+				sagaErr.Compensated = false
+				break
+			}
+			if undoErr := steps[j].undo(); undoErr != nil {
+				log.Println("Error undoing "+steps[j].name+" of "+name+": ", undoErr)
+				sagaErr.Compensated = false
+				break
+			}
+		}
+		return sagaErr
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// DefaultScanBatchSize is how many keys each SCAN asks redis for when
+// SCAN_BATCH_SIZE is not set.  It is only a hint, redis can return more
+// or fewer
+const DefaultScanBatchSize = 100
+
+// scanBatchSizeFromEnv returns SCAN_BATCH_SIZE, or the default if it is
+// not set or not a positive number
+func scanBatchSizeFromEnv() int64 {
+	batch := int64(DefaultScanBatchSize)
+	if batchS := os.Getenv("SCAN_BATCH_SIZE"); batchS != "" {
+		if n, err := strconv.ParseInt(batchS, 10, 64); err == nil && n > 0 {
+			batch = n
+		} else {
+			log.Println("Invalid SCAN_BATCH_SIZE, using default: ", batchS)
+		}
+	}
+	return batch
+}
+
+// scanKeys returns every key matching pattern.  It walks the keys with
+// SCAN rather than KEYS so that a large database is never blocked while
+// they are listed.  A key added or removed during the walk may or may
+// not be returned, as with any SCAN
+func (c *cache) scanKeys(pattern string) ([]string, error) {
+	var keys []string
+	//SCAN can return a key more than once, only keep the first
+	seen := make(map[string]bool)
+	var cursor uint64
+	for {
+		batch, next, err := c.cacheClient.Scan(c.context, cursor, pattern, c.scanBatch).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range batch {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+// scanPage runs a single SCAN for keys matching pattern, starting at
+// cursor, 0 for the first page.  count is the number of keys to ask for,
+// 0 for the batch size.  The next cursor is returned with the keys and is
+// 0 once every key has been seen.  A page can have fewer keys than count,
+// even none, before the end is reached
+func (c *cache) scanPage(pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+	if count <= 0 {
+		count = c.scanBatch
+	}
+	return c.cacheClient.Scan(c.context, cursor, pattern, count).Result()
+}
+
+// getDocsFromRedis reads the whole JSON document at each of keys with
+// JSON.MGET, up to the scan batch size of keys per command, so that a
+// list costs a round trip per batch rather than one per key.  The
+// results line up with keys, a key that no longer exists gives nil
+func (c *cache) getDocsFromRedis(keys []string) ([][]byte, error) {
+	docs := make([][]byte, 0, len(keys))
+	for start := 0; start < len(keys); start += int(c.scanBatch) {
+		end := start + int(c.scanBatch)
+		if end > len(keys) {
+			end = len(keys)
+		}
+		res, err := c.jsonHelper.JSONMGet(".", keys[start:end]...)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range res.([]interface{}) {
+			if doc == nil {
+				docs = append(docs, nil)
+				continue
+			}
+			docs = append(docs, doc.([]byte))
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// VoterNameIndexKey is the sorted set the redis store searches voters
+// with.  Every member has a score of 0, so redis keeps them in lexical
+// order, and is a suffix of a voter's lower cased first or last name, a
+// NUL, then the voter's id.  Keeping every suffix rather than just the
+// whole name lets a ZRANGEBYLEX for the members starting with the query
+// find it anywhere in a name.  Like the vote indexes it is kept out of
+// the voters: prefix
+const VoterNameIndexKey = "voter-names"
+
+// nameIndexSep separates the name from the id in a name index member
+const nameIndexSep = "\x00"
+
+// ErrEmptySearch is returned when voters are searched for nothing
+var ErrEmptySearch = errors.New("q must not be empty")
+
+// voterSearcher is a voterStore that keeps its own index to search voters
+// by name.  search returns the voters whose first or last name contains
+// q, which is already lower cased, in any order.  Stores that don't keep
+// an index are searched by reading every voter
+type voterSearcher interface {
+	search(q string) ([]Voter, error)
+}
+
+// nameIndexMembers returns the members voter has in the name index
+func nameIndexMembers(voter Voter) []string {
+	id := strconv.FormatUint(uint64(voter.VoterID), 10)
+
+	var members []string
+	seen := make(map[string]bool)
+	for _, name := range []string{voter.FirstName, voter.LastName} {
+		name = strings.ToLower(name)
+		//Ranging over a string steps a rune at a time, so every suffix
+		//is valid UTF-8
+		for i := range name {
+			member := name[i:] + nameIndexSep + id
+			if !seen[member] {
+				seen[member] = true
+				members = append(members, member)
+			}
+		}
+	}
+	return members
+}
+
+// matchesSearch is true if q, already lower cased, is part of voter's
+// first or last name
+func matchesSearch(voter Voter, q string) bool {
+	return strings.Contains(strings.ToLower(voter.FirstName), q) ||
+		strings.Contains(strings.ToLower(voter.LastName), q)
+}
+
+// indexName adds voter's names to the name index
+func (r *redisStore) indexName(pipe redis.Pipeliner, voter Voter) {
+	members := nameIndexMembers(voter)
+	if len(members) == 0 {
+		return
+	}
+	zs := make([]*redis.Z, 0, len(members))
+	for _, member := range members {
+		zs = append(zs, &redis.Z{Score: 0, Member: member})
+	}
+	pipe.ZAdd(r.context, VoterNameIndexKey, zs...)
+}
+
+// unindexName removes voter's names from the name index
+func (r *redisStore) unindexName(pipe redis.Pipeliner, voter Voter) {
+	members := nameIndexMembers(voter)
+	if len(members) == 0 {
+		return
+	}
+	ms := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		ms = append(ms, member)
+	}
+	pipe.ZRem(r.context, VoterNameIndexKey, ms...)
+}
+
+// reindexNames swaps old's names in the name index for voter's, either
+// of them can be nil for a voter that is being added or deleted.  The
+// voter itself has already been written, so a failure is only logged,
+// search checks every voter it finds against the query anyway
+func (r *redisStore) reindexNames(old *Voter, voter *Voter) {
+	_, err := r.cacheClient.TxPipelined(r.context, func(pipe redis.Pipeliner) error {
+		if old != nil {
+			r.unindexName(pipe, *old)
+		}
+		if voter != nil {
+			r.indexName(pipe, *voter)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Error updating the name index: ", err)
+	}
+}
+
+// buildNameIndex indexes the name of every voter if there is no name
+// index yet, as there won't be for voters added before it existed
+func (r *redisStore) buildNameIndex() error {
+	built, err := r.cacheClient.Exists(r.context, VoterNameIndexKey).Result()
+	if err != nil {
+		return err
+	}
+	if built != 0 {
+		return nil
+	}
+
+	voterList, err := r.list()
+	if err != nil {
+		return err
+	}
+	_, err = r.cacheClient.TxPipelined(r.context, func(pipe redis.Pipeliner) error {
+		for _, voter := range voterList {
+			r.indexName(pipe, voter)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *redisStore) search(q string) ([]Voter, error) {
+	//No member of the index has a 0xff byte, it is never part of UTF-8,
+	//so this range is every member that starts with q
+	members, err := r.cacheClient.ZRangeByLex(r.context, VoterNameIndexKey, &redis.ZRangeBy{
+		Min: "[" + q,
+		Max: "[" + q + "\xff",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, member := range members {
+		id := member[strings.LastIndex(member, nameIndexSep)+1:]
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, RedisKeyPrefix+id)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	voters, err := r.getItemsFromRedis(keys)
+	if err != nil {
+		return nil, err
+	}
+	//The index is updated after the voter is written, so it can be
+	//briefly behind, only keep the voters that really match
+	var voterList []Voter
+	for _, voter := range voters {
+		if matchesSearch(voter, q) {
+			voterList = append(voterList, voter)
+		}
+	}
+	return voterList, nil
+}
+
+// SearchVoters returns the voters whose first or last name contains q,
+// ignoring case, sorted by id.  The redis store finds them with its name
+// index, other stores read every voter
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) q must not be empty
+//
+// Postconditions:
+//
+//	    (1) The matching voters will be returned, an empty slice if
+//			there are none
+//		(2) If q is empty ErrEmptySearch will be returned
+//		(3) If there is an error, it will be returned
+//		(4) The database file will not be modified
+func (v *VoterList) SearchVoters(q string) ([]Voter, error) {
+
+	q = strings.ToLower(strings.TrimSpace(q))
+	if q == "" {
+		return nil, ErrEmptySearch
+	}
+
+	var voterList []Voter
+	if searcher, ok := v.store.(voterSearcher); ok {
+		found, err := searcher.search(q)
+		if err != nil {
+			return nil, err
+		}
+		voterList = found
+	} else {
+		all, err := v.store.list()
+		if err != nil {
+			return nil, err
+		}
+		for _, voter := range all {
+			if matchesSearch(voter, q) {
+				voterList = append(voterList, voter)
+			}
+		}
+	}
+
+	sort.Slice(voterList, func(i, j int) bool {
+		return voterList[i].VoterID < voterList[j].VoterID
+	})
+	if voterList == nil {
+		voterList = []Voter{}
+	}
+	return voterList, nil
+}
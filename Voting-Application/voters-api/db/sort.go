@@ -0,0 +1,56 @@
+package db
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrInvalidSort is returned when voters are sorted by a field they can't
+// be sorted by
+var ErrInvalidSort = errors.New("sort must be VoterID, FirstName or LastName")
+
+// VoterSort picks the order GetAllVoters returns voters in.  The zero
+// value is by VoterID, smallest first, as the list has always been
+type VoterSort struct {
+	//Field is VoterID, FirstName or LastName, empty for VoterID
+	Field string
+	//Desc reverses the order
+	Desc bool
+}
+
+// Validate returns ErrInvalidSort if Field isn't one voters can be sorted
+// by
+func (s VoterSort) Validate() error {
+	switch s.Field {
+	case "", "VoterID", "FirstName", "LastName":
+		return nil
+	}
+	return ErrInvalidSort
+}
+
+// sortVoters sorts voterList in place.  Voters with the same name stay in
+// VoterID order, whichever way the list is sorted, so that pages of the
+// list are stable
+func (s VoterSort) sortVoters(voterList []Voter) {
+	sort.Slice(voterList, func(i, j int) bool {
+		a, b := voterList[i], voterList[j]
+		var x, y string
+		switch s.Field {
+		case "FirstName":
+			x, y = a.FirstName, b.FirstName
+		case "LastName":
+			x, y = a.LastName, b.LastName
+		}
+		if x == y {
+			//Only a sort by id runs backwards on the id
+			if s.Desc && (s.Field == "" || s.Field == "VoterID") {
+				return a.VoterID > b.VoterID
+			}
+			return a.VoterID < b.VoterID
+		}
+		if s.Desc {
+			return x > y
+		}
+		return x < y
+	})
+}
@@ -0,0 +1,157 @@
+//go:build sqlite
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema creates the voters table if it is not there yet.  Each
+// voter is kept whole as a JSON document, the same shape it has in
+// redis, keyed by its id
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS voters (
+	voter_id INTEGER PRIMARY KEY,
+	doc      TEXT    NOT NULL
+)`
+
+// sqliteStore keeps voters in a SQLite file, so the voters API can be run
+// locally with nothing else installed and keep its data between runs
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteVoterList opens, or creates, the SQLite database file at path
+// and returns a VoterList that keeps its voters there instead of redis.
+// It is only available in builds made with -tags sqlite
+func NewSQLiteVoterList(path string) (*VoterList, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	//SQLite only allows one writer at a time, sharing one connection
+	//stops concurrent requests failing with "database is locked"
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	voterList := &VoterList{
+		healthInfo: HealthData{},
+		store:      &sqliteStore{db: db},
+		idScheme:   idSchemeFromEnv(),
+		votes:      votesServiceFromEnv(),
+	}
+	voterList.seedFromEnv()
+
+	return voterList, nil
+}
+
+func (s *sqliteStore) get(id uint) (Voter, error) {
+	var doc []byte
+	err := s.db.QueryRow(`SELECT doc FROM voters WHERE voter_id = ?`, id).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Voter{}, ErrNotFound
+	}
+	if err != nil {
+		return Voter{}, err
+	}
+
+	var voter Voter
+	if err := json.Unmarshal(doc, &voter); err != nil {
+		return Voter{}, err
+	}
+	return voter, nil
+}
+
+// put writes the whole voter, replacing it if it is already there
+func (s *sqliteStore) put(voter Voter) error {
+	doc, err := json.Marshal(voter)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO voters (voter_id, doc) VALUES (?, ?)
+		ON CONFLICT (voter_id) DO UPDATE SET doc = excluded.doc`, voter.VoterID, string(doc))
+	return err
+}
+
+func (s *sqliteStore) delete(id uint) error {
+	result, err := s.db.Exec(`DELETE FROM voters WHERE voter_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *sqliteStore) list() ([]Voter, error) {
+	rows, err := s.db.Query(`SELECT doc FROM voters ORDER BY voter_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var voterList []Voter
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var voter Voter
+		if err := json.Unmarshal(doc, &voter); err != nil {
+			return nil, err
+		}
+		voterList = append(voterList, voter)
+	}
+	return voterList, rows.Err()
+}
+
+// listPage uses the last voter id of the previous page as the cursor,
+// voter ids start at 1 so 0 is the start
+func (s *sqliteStore) listPage(cursor uint64, count int) ([]Voter, uint64, error) {
+	if count <= 0 {
+		count = DefaultScanBatchSize
+	}
+	rows, err := s.db.Query(`SELECT doc FROM voters WHERE voter_id > ? ORDER BY voter_id LIMIT ?`, cursor, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var voterList []Voter
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, 0, err
+		}
+		var voter Voter
+		if err := json.Unmarshal(doc, &voter); err != nil {
+			return nil, 0, err
+		}
+		voterList = append(voterList, voter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	//A short page is the last one
+	var next uint64
+	if len(voterList) == count {
+		next = uint64(voterList[len(voterList)-1].VoterID)
+	}
+	return voterList, next, nil
+}
+
+func (s *sqliteStore) ping() error {
+	return s.db.Ping()
+}
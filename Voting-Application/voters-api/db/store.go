@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nitishm/go-rejson/v4"
+)
+
+// voterStore is where the voters are kept.  VoterList does all of its
+// reads and writes through a voterStore, so the backend can be swapped
+// without touching the rules in VoterList.  put writes the whole voter,
+// VoterList checks whether the voter exists first.  listPage returns
+// about count voters from cursor on, 0 for the start, and the cursor to
+// carry on from, 0 after the last page.  What a cursor means is up to
+// each store
+type voterStore interface {
+	get(id uint) (Voter, error)
+	put(voter Voter) error
+	delete(id uint) error
+	list() ([]Voter, error)
+	listPage(cursor uint64, count int) ([]Voter, uint64, error)
+	ping() error
+}
+
+type cache struct {
+	cacheClient *redis.Client
+	jsonHelper  *rejson.Handler
+	context     context.Context
+	//scanBatch is the COUNT hint each SCAN sends, see scan.go
+	scanBatch int64
+}
+
+// redisStore keeps voters in redis as ReJSON documents under
+// voters:<id>, with their names in the name index, see search.go
+type redisStore struct {
+	cache
+}
+
+//------------------------------------------------------------
+// REDIS HELPERS
+//------------------------------------------------------------
+
+// In redis, our keys will be strings, they will look like
+// voters:<number>.  This function will take an integer and
+// return a string that can be used as a key in redis
+func redisKeyFromId(id uint) string {
+	return fmt.Sprintf("%s%d", RedisKeyPrefix, id)
+}
+
+// Helper to return a VoterList from redis provided a key
+func (r *redisStore) getItemFromRedis(key string, voter *Voter) error {
+
+	//Lets query redis for the voter, note we can return parts of the
+	//json structure, the second parameter "." means return the entire
+	//json structure
+	//ReJSON reports a key that does not exist as a redis nil, anything
+	//else is a real error talking to redis
+	voterObject, err := r.jsonHelper.JSONGet(key, ".")
+	if err != nil {
+		if err.Error() == RedisNilError {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	//JSONGet returns an "any" object, or empty interface,
+	//we need to convert it to a byte array, which is the
+	//underlying type of the object, then we can unmarshal
+	//it into our voter struct
+	err = json.Unmarshal(voterObject.([]byte), voter)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Helper to return the voters at keys from redis, reading them in
+// batches rather than one at a time.  Keys that have been deleted since
+// they were listed are skipped
+func (r *redisStore) getItemsFromRedis(keys []string) ([]Voter, error) {
+	docs, err := r.getDocsFromRedis(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var voterList []Voter
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		var voter Voter
+		if err := json.Unmarshal(doc, &voter); err != nil {
+			return nil, err
+		}
+		voterList = append(voterList, voter)
+	}
+	return voterList, nil
+}
+
+func (r *redisStore) get(id uint) (Voter, error) {
+	var voter Voter
+	if err := r.getItemFromRedis(redisKeyFromId(id), &voter); err != nil {
+		return Voter{}, err
+	}
+	return voter, nil
+}
+
+func (r *redisStore) put(voter Voter) error {
+	//The old names have to come out of the name index
+	var old *Voter
+	if existing, err := r.get(voter.VoterID); err == nil {
+		old = &existing
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	//There is no update in ReJSON, so we just overwrite the voter
+	if _, err := r.jsonHelper.JSONSet(redisKeyFromId(voter.VoterID), ".", voter); err != nil {
+		return err
+	}
+	r.reindexNames(old, &voter)
+	return nil
+}
+
+func (r *redisStore) delete(id uint) error {
+	old, err := r.get(id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	numDeleted, err := r.cacheClient.Del(r.context, redisKeyFromId(id)).Result()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	r.reindexNames(&old, nil)
+	return nil
+}
+
+func (r *redisStore) list() ([]Voter, error) {
+	pattern := RedisKeyPrefix + "*"
+	ks, err := r.scanKeys(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return r.getItemsFromRedis(ks)
+}
+
+// listPage uses the SCAN cursor, so a page can have more or fewer than
+// count voters, and it is count keys that are asked for, 0 for the batch
+// size
+func (r *redisStore) listPage(cursor uint64, count int) ([]Voter, uint64, error) {
+	ks, next, err := r.scanPage(RedisKeyPrefix+"*", cursor, int64(count))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	voterList, err := r.getItemsFromRedis(ks)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return voterList, next, nil
+}
+
+func (r *redisStore) ping() error {
+	return r.cacheClient.Ping(r.context).Err()
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// benchVoterCount is how many voters BenchmarkReadVoters lists, and
+// benchVoterID the first of their ids, far past any id the seed data or
+// AddVoterNewID hands out
+const (
+	benchVoterCount = 10000
+	benchVoterID    = 900001
+)
+
+// newTestRedisStore connects to the redis at REDIS_URL, or the default
+// location, and skips the benchmark if there isn't one.  It has to be
+// redis-stack, like the one docker-compose.yaml runs
+func newTestRedisStore(tb testing.TB) *redisStore {
+	tb.Helper()
+
+	location := os.Getenv("REDIS_URL")
+	if location == "" {
+		location = RedisDefaultLocation
+	}
+	voterList, err := NewWithCacheInstance(location)
+	if err != nil {
+		tb.Skip("redis is not available at ", location, ": ", err)
+	}
+	return voterList.store.(*redisStore)
+}
+
+// commandCounter is a redis hook that counts the commands a client sends,
+// a pipeline counting as one round trip
+type commandCounter struct {
+	n int64
+}
+
+func (c *commandCounter) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	atomic.AddInt64(&c.n, 1)
+	return ctx, nil
+}
+
+func (c *commandCounter) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+func (c *commandCounter) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	atomic.AddInt64(&c.n, 1)
+	return ctx, nil
+}
+
+func (c *commandCounter) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+// BenchmarkReadVoters reads benchVoterCount voters the way list does, in
+// JSON.MGET batches, and the way it used to, a JSON.GET per voter, and
+// reports the redis round trips each listing cost
+func BenchmarkReadVoters(b *testing.B) {
+	store := newTestRedisStore(b)
+
+	keys := make([]string, 0, benchVoterCount)
+	for i := uint(0); i < benchVoterCount; i++ {
+		voter := Voter{
+			VoterID:     benchVoterID + i,
+			FirstName:   "Bench",
+			LastName:    "Voter",
+			VoteHistory: []VoterPoll{},
+		}
+		key := redisKeyFromId(voter.VoterID)
+		if _, err := store.jsonHelper.JSONSet(key, ".", voter); err != nil {
+			b.Fatal("Error adding voter: ", err)
+		}
+		keys = append(keys, key)
+	}
+	b.Cleanup(func() { store.cacheClient.Del(store.context, keys...) })
+
+	counter := &commandCounter{}
+	store.cacheClient.AddHook(counter)
+
+	for _, bm := range []struct {
+		name string
+		read func() (int, error)
+	}{
+		{"mget", func() (int, error) {
+			voterList, err := store.getItemsFromRedis(keys)
+			return len(voterList), err
+		}},
+		{"one-by-one", func() (int, error) {
+			n := 0
+			for _, key := range keys {
+				var voter Voter
+				if err := store.getItemFromRedis(key, &voter); err != nil {
+					return n, err
+				}
+				n++
+			}
+			return n, nil
+		}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			atomic.StoreInt64(&counter.n, 0)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				n, err := bm.read()
+				if err != nil {
+					b.Fatal("Error reading voters: ", err)
+				}
+				if n != benchVoterCount {
+					b.Fatal("read ", n, " voters, want ", benchVoterCount)
+				}
+			}
+			b.ReportMetric(float64(atomic.LoadInt64(&counter.n))/float64(b.N), "redis-cmds/op")
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrVersionMismatch is returned when a voter is updated with a Version
+// that is not the stored one, it has been changed since it was read.  It
+// is also ErrConflict
+var ErrVersionMismatch = fmt.Errorf("%w: version does not match the stored one", ErrConflict)
+
+// versionMismatch returns ErrVersionMismatch saying which version was
+// expected and which is stored
+func versionMismatch(expected uint, stored uint) error {
+	return fmt.Errorf("%w, expected %d but it is %d", ErrVersionMismatch, expected, stored)
+}
+
+// setVersionedScript writes ARGV[3] at path ARGV[2] of the document at
+// KEYS[1] and adds one to its Version, if its Version is still ARGV[1].
+// It returns {1, the new version} when it writes, {0, the stored version}
+// when the version has moved on and {-1, 0} when there is no document.  A
+// document from before there were versions is version 0
+var setVersionedScript = redis.NewScript(`
+local doc = redis.call('JSON.GET', KEYS[1], '$.Version')
+if not doc then
+	return {-1, 0}
+end
+local version = cjson.decode(doc)[1] or 0
+if version ~= tonumber(ARGV[1]) then
+	return {0, version}
+end
+redis.call('JSON.SET', KEYS[1], ARGV[2], ARGV[3])
+redis.call('JSON.SET', KEYS[1], '$.Version', version + 1)
+return {1, version + 1}
+`)
+
+// setVersioned writes value at path of the document at key, and adds one
+// to the document's Version, but only if the Version is still version.
+// The check and the write are one script, so nothing can change the
+// document in between
+func (c *cache) setVersioned(key string, path string, value any, version uint) error {
+	doc, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	res, err := setVersionedScript.Run(c.context, c.cacheClient, []string{key}, version, path, string(doc)).Result()
+	if err != nil {
+		return err
+	}
+	reply, ok := res.([]interface{})
+	if !ok || len(reply) != 2 {
+		return fmt.Errorf("unexpected versioned write reply %v", res)
+	}
+	status, _ := reply[0].(int64)
+	stored, _ := reply[1].(int64)
+	switch status {
+	case 1:
+		return nil
+	case 0:
+		return versionMismatch(version, uint(stored))
+	}
+	return ErrNotFound
+}
+
+// versionedStore is a voterStore that can check the version of a voter
+// and write it in one step.  Other stores check the version and then
+// write, so a write that lands in between is lost
+type versionedStore interface {
+	putVersion(voter Voter, version uint) error
+}
+
+func (r *redisStore) putVersion(voter Voter, version uint) error {
+	//The old names have to come out of the name index.  Versions only
+	//go up, so if the write goes through these are the names it replaced
+	old, err := r.get(voter.VoterID)
+	if err != nil {
+		return err
+	}
+	if old.Version != version {
+		return versionMismatch(version, old.Version)
+	}
+
+	if err := r.setVersioned(redisKeyFromId(voter.VoterID), ".", voter, version); err != nil {
+		return err
+	}
+	r.reindexNames(&old, &voter)
+	return nil
+}
+
+// txVersion reads the Version of the voter at key on the watched
+// connection of tx, 0 for a voter from before there were versions
+func (r *redisStore) txVersion(tx *redis.Tx, key string) (uint, error) {
+	doc, err := r.txDo(tx, "JSON.GET", key, "$.Version").Text()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	var versions []uint
+	if err := json.Unmarshal([]byte(doc), &versions); err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	return versions[0], nil
+}
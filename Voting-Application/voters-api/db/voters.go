@@ -1,564 +1,747 @@
-package db
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"time"
-	"log"
-	"os"
-
-	"github.com/go-redis/redis/v8"
-	"github.com/nitishm/go-rejson/v4"
-)
-
-type voterPoll struct{
-	PollID uint
-	VoteDate time.Time
-}
-  
-type Voter struct{
-	VoterID uint
-	FirstName string
-	LastName string
-	VoteHistory []voterPoll
-	Links	[]string
-}
-
-const (
-	RedisNilError        = "redis: nil"
-	RedisDefaultLocation = "0.0.0.0:6379"
-	RedisKeyPrefix       = "voters:"
-)
-
-type cache struct {
-	cacheClient *redis.Client
-	jsonHelper  *rejson.Handler
-	context     context.Context
-}
-
-type healthData struct{
-	Uptime time.Duration
-	APIcalls uint
-}
-
-type VoterList struct {
-	healthInfo healthData
-	cache
-}
-
-//constructor for VoterList struct
-func NewVoterList() (*VoterList, error) {
-	//We will use an override if the REDIS_URL is provided as an environment
-	//variable, which is the preferred way to wire up a docker container
-	redisUrl := os.Getenv("REDIS_URL")
-	//This handles the default condition
-	if redisUrl == "" {
-		redisUrl = RedisDefaultLocation
-	}
-	return NewWithCacheInstance(redisUrl)
-}
-
-// NewWithCacheInstance is a constructor function that returns a pointer to a new
-// Voter struct.  It accepts a string that represents the location of the redis
-// cache.
-func NewWithCacheInstance(location string) (*VoterList, error) {
-
-	//Connect to redis.  Other options can be provided, but the
-	//defaults are OK
-	client := redis.NewClient(&redis.Options{
-		Addr: location,
-	})
-
-	//We use this context to coordinate betwen our go code and
-	//the redis operaitons
-	ctx := context.Background()
-
-	//This is the reccomended way to ensure that our redis connection
-	//is working
-	err := client.Ping(ctx).Err()
-	if err != nil {
-		log.Println("Error connecting to redis" + err.Error())
-		return nil, err
-	}
-
-	//By default, redis manages keys and values, where the values
-	//are either strings, sets, maps, etc.  Redis has an extension
-	//module called ReJSON that allows us to store JSON objects
-	//however, we need a companion library in order to work with it
-	//Below we create an instance of the JSON helper and associate
-	//it with our redis connnection
-	jsonHelper := rejson.NewReJSONHandler()
-	jsonHelper.SetGoRedisClientWithContext(ctx, client)
-
-	//Return a pointer to a new voterList struct
-	voterList := &VoterList{
-		healthInfo: healthData{},
-		cache: cache{
-			cacheClient: client,
-			jsonHelper:  jsonHelper,
-			context:     ctx,
-		},
-	}
-	return voterList, nil
-}
-
-//------------------------------------------------------------
-// REDIS HELPERS
-//------------------------------------------------------------
-
-// In redis, our keys will be strings, they will look like
-// voters:<number>.  This function will take an integer and
-// return a string that can be used as a key in redis
-func redisKeyFromId(id uint) string {
-	return fmt.Sprintf("%s%d", RedisKeyPrefix, id)
-}
-
-// Helper to return a VoterList from redis provided a key
-func (v *VoterList) getItemFromRedis(key string, voter *Voter) error {
-
-	//Lets query redis for the voter, note we can return parts of the
-	//json structure, the second parameter "." means return the entire
-	//json structure
-	voterObject, err := v.jsonHelper.JSONGet(key, ".")
-	if err != nil {
-		return err
-	}
-
-	//JSONGet returns an "any" object, or empty interface,
-	//we need to convert it to a byte array, which is the
-	//underlying type of the object, then we can unmarshal
-	//it into our voter struct
-	err = json.Unmarshal(voterObject.([]byte), voter)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-//------------------------------------------------------------
-// THESE ARE THE PUBLIC FUNCTIONS THAT SUPPORT OUR VOTER APP
-//------------------------------------------------------------
-
-// AddVoter accepts a Voter and adds it to the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must not already exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if so, return an error
-//
-// Postconditions:
-//
-//	    (1) The voter will be added to the DB
-//		(2) The DB file will be saved with the voter added
-//		(3) If there is an error, it will be returned
-func (v *VoterList) AddVoter(voter Voter) error {
-
-	//Before we add an voter to the DB, lets make sure
-	//it does not exist, if it does, return an error
-	redisKey := redisKeyFromId(voter.VoterID)
-	var existingVoter Voter
-	if err := v.getItemFromRedis(redisKey, &existingVoter); err == nil {
-		return errors.New("voter already exists")
-	}
-
-	//Add voter to database with JSON Set
-	voter.Links = []string{"GET All Voters: 1080/voters/", "POST Voter: 1080/voters/:id", "DELETE All Voters: 1080/voters", "DELETE Voter: 1080/voters/:id","GET Voter Polls: voters/:id/polls","GET Voter Poll: voters/:id/polls/:pollId","POST Voter Poll: voters/:id/polls","DELETE Voter Poll: voters/:id/polls/:pollId","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Polls: 1090/polls/","POST Poll: 1090/polls/:id"}
-	if _, err := v.jsonHelper.JSONSet(redisKey, ".", voter); err != nil {
-		return err
-	}
-
-	//If everything is ok, return nil for the error
-	return nil
-}
-
-// DeleteVoter accepts a voter id and removes it from the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The voter will be removed from the DB
-//		(2) The DB file will be saved with the voter removed
-//		(3) If there is an error, it will be returned
-func (v *VoterList) DeleteVoter(id uint) error {
-
-	pattern := redisKeyFromId(id)
-	numDeleted, err := v.cacheClient.Del(v.context, pattern).Result()
-	if err != nil {
-		return err
-	}
-	if numDeleted == 0 {
-		return errors.New("voter does not exist")
-	}
-
-	return nil
-}
-
-// DeleteAllVoters removes all voters from the DB.
-// It will be exposed via a DELETE /voters endpoint
-func (v *VoterList) DeleteAllVoters() error {
-
-	pattern := RedisKeyPrefix + "*"
-	ks, _ := v.cacheClient.Keys(v.context, pattern).Result()
-	//Note delete can take a collection of keys.  In go we can
-	//expand a slice into individual arguments by using the ...
-	//operator
-	numDeleted, err := v.cacheClient.Del(v.context, ks...).Result()
-	if err != nil {
-		return err
-	}
-
-	if numDeleted != int64(len(ks)) {
-		return errors.New("one or more voters could not be deleted")
-	}
-
-	return nil
-}
-
-// UpdateVoter accepts a voter and updates it in the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The voter will be updated in the DB
-//		(2) The DB file will be saved with the voter updated
-//		(3) If there is an error, it will be returned
-func (v *VoterList) UpdateVoter(voter Voter) error {
-
-	// Check if voter exists before trying to update it
-	// this is a good practice, return an error if the
-	// voter does not exist
-	redisKey := redisKeyFromId(voter.VoterID)
-	var existingVoter Voter
-	if err := v.getItemFromRedis(redisKey, &existingVoter); err != nil {
-		return errors.New("voter does not exist")
-	}
-
-	//Add voter to database with JSON Set.  Note there is no update
-	//functionality, so we just overwrite the existing voter
-	voter.Links = []string{"GET All Voters: 1080/voters/", "POST Voter: 1080/voters/:id", "DELETE All Voters: 1080/voters", "DELETE Voter: 1080/voters/:id","GET Voter Polls: voters/:id/polls","GET Voter Poll: voters/:id/polls/:pollId","POST Voter Poll: voters/:id/polls","DELETE Voter Poll: voters/:id/polls/:pollId","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Polls: 1090/polls/","POST Poll: 1090/polls/:id"}
-	if _, err := v.jsonHelper.JSONSet(redisKey, ".", voter); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// GetVoter accepts a voter id and returns the voter from the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The voter will be returned, if it exists
-//		(2) If there is an error, it will be returned
-//			along with an empty Voter
-//		(3) The database file will not be modified
-func (v *VoterList) GetVoter(id uint) (Voter, error) {
-
-	// Check if voter exists before trying to get it
-	// this is a good practice, return an error if the
-	// voter does not exist
-	var voter Voter
-	pattern := redisKeyFromId(id)
-	err := v.getItemFromRedis(pattern, &voter)
-	if err != nil {
-		return Voter{}, errors.New("voter does not exist")
-	}
-
-	return voter, nil
-}
-
-// GetAllVoters returns all voters from the DB.  If successful it
-// returns a slice of all of the voters to the caller
-// Preconditions:   (1) The database file must exist and be a valid
-//
-// Postconditions:
-//
-//	    (1) All voters will be returned, if any exist
-//		(2) If there is an error, it will be returned
-//			along with an empty slice
-//		(3) The database file will not be modified
-func (v *VoterList) GetAllVoters() ([]Voter, error) {
-
-	//Now that we have the DB loaded, lets crate a slice
-	var voterList []Voter
-	var voter Voter
-
-	//Lets query redis for all of the items
-	pattern := RedisKeyPrefix + "*"
-	ks, _ := v.cacheClient.Keys(v.context, pattern).Result()
-	for _, key := range ks {
-		err := v.getItemFromRedis(key, &voter)
-		if err != nil {
-			return nil, err
-		}
-		voterList = append(voterList, voter)
-	}
-
-	if len(voterList) < 1 {
-		voterList = append(voterList, Voter{
-			VoterID: 0,
-			FirstName: "",
-			LastName: "",
-			VoteHistory: []voterPoll{},
-			Links: []string{"GET All Voters: 1080/voters/", "POST Voter: 1080/voters/:id", "DELETE All Voters: 1080/voters", "DELETE Voter: 1080/voters/:id","GET Voter Polls: voters/:id/polls","GET Voter Poll: voters/:id/polls/:pollId","POST Voter Poll: voters/:id/polls","DELETE Voter Poll: voters/:id/polls/:pollId","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Polls: 1090/polls/","POST Poll: 1090/polls/:id"},
-		})
-	}
-
-	//Now that we have all of our voters in a slice, return it
-	return voterList, nil
-}
-
-// PrintVoter accepts a Voter and prints it to the console
-// in a JSON pretty format. As some help, look at the
-// json.MarshalIndent() function from our in class go tutorial.
-func (v *VoterList) PrintVoter(voter Voter) {
-	jsonBytes, _ := json.MarshalIndent(voter, "", "  ")
-	fmt.Println(string(jsonBytes))
-}
-
-// PrintAllVoters accepts a slice of Voters and prints them to the console
-// in a JSON pretty format.  It should call PrintVoter() to print each voter
-// versus repeating the code.
-func (v *VoterList) PrintAllVoters(voterList []Voter) {
-	for _, voter := range voterList {
-		v.PrintVoter(voter)
-	}
-}
-
-// JsonToVoter accepts a json string and returns a Voter
-// This is helpful because the CLI accepts voters for insertion
-// and updates in JSON format.  We need to convert it to a Voter
-// struct to perform any operations on it.
-func (v *VoterList) JsonToVoter(jsonString string) (Voter, error) {
-	var voter Voter
-	err := json.Unmarshal([]byte(jsonString), &voter)
-	if err != nil {
-		return Voter{}, err
-	}
-
-	return voter, nil
-}
-
-// GetVoterPolls accepts a voter id and returns polls from that voter.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//      (1) All polls will be returned, if any exist
-//		(2) If there is an error, it will be returned
-//			along with an empty slice
-//		(3) The database file will not be modified
-func (v *VoterList) GetVoterPolls(id uint) ([]voterPoll, error) {
-
-	// we should check if voter exists before trying to retriece polls
-	// this is a good practice, return an error if the
-	// voter does not exist
-
-	var voter Voter
-	pattern := redisKeyFromId(id)
-	err := v.getItemFromRedis(pattern, &voter)
-	if err != nil {
-		return nil, errors.New("voter does not exist")
-	}
-
-	return voter.VoteHistory, nil
-}
-
-
-// GetVoterPoll accepts a voter id and poll id and returns the requested poll.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter and poll must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be returned, if it exists
-//		(2) If there is an error, it will be returned
-//			along with an empty poll
-//		(3) The database file will not be modified
-func (v *VoterList) GetVoterPoll(voterId, pollId uint) (voterPoll , error) {
-
-    // we should if voter exists before trying to retrieve polls
-    // this is a good practice, return an error if the
-    // voter does not exist
-
-	var voter Voter
-	pattern := redisKeyFromId(voterId)
-	err := v.getItemFromRedis(pattern, &voter)
-	if err != nil {
-		return voterPoll{}, errors.New("voter does not exist")
-	}
-
-    for _, poll := range voter.VoteHistory {
-        if poll.PollID == pollId{
-			return poll, nil
-        }
-    }
-
-    return voterPoll{}, errors.New("poll not found for given voter")
-}
-
-// AddVoterPoll accepts a voter id and new poll to add to the voter.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be added to the DB
-//		(2) The DB file will be saved with the poll added
-//		(3) If there is an error, it will be returned
-func (v *VoterList) AddVoterPoll(voterId uint, requestVoter Voter) error {
-
-	// Check if voter exists before trying to update it
-	// this is a good practice, return an error if the
-	// voter does not exist
-
-	var voter Voter
-	pattern := redisKeyFromId(voterId)
-	err := v.getItemFromRedis(pattern, &voter)
-	if err != nil {
-		return errors.New("voter does not exist")
-	}
-	
-	requestPoll := requestVoter.VoteHistory[0]
-
-	for _, poll := range voter.VoteHistory {
-        if poll.PollID == requestPoll.PollID{
-			return errors.New("poll already exists in voter")
-        }
-    }
-
-	voter.VoteHistory = append(voter.VoteHistory, requestPoll)
-	v.UpdateVoter(voter)
-
-	return nil
-}
-
-// DeleteVoterPoll accepts a voter id and a poll to add to the voter.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be deleted from the DB
-//		(2) The DB file will be saved with the poll deleted
-//		(3) If there is an error, it will be returned
-func (v *VoterList) DeleteVoterPoll(voterId uint, pollId uint) error {
-
-	// Check if voter exists before trying to update it
-	// this is a good practice, return an error if the
-	// voter does not exist
-
-	var voter Voter
-	pattern := redisKeyFromId(voterId)
-	err := v.getItemFromRedis(pattern, &voter)
-	if err != nil {
-		return errors.New("voter does not exist")
-	}
-
-	index := -1
-    for i, poll := range voter.VoteHistory {
-        if poll.PollID == pollId{
-            index = i
-            break
-        }
-    }	
-
-	if index == -1{
-		return errors.New("poll does not exist in voter")
-	}
-	
-	voter.VoteHistory[index] = voter.VoteHistory[len(voter.VoteHistory)-1]
-	voter.VoteHistory = voter.VoteHistory[:len(voter.VoteHistory)-1]
-	v.UpdateVoter(voter)
-
-	return nil
-}
-
-// UpdateVoterPoll accepts a voter id and poll to update fpr the voter.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The voter must exist in the DB
-//	    				because we use the voter.VoterId as the key, this
-//						function must check if the voter already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The poll will be updated in the DB
-//		(2) The DB file will be saved with the poll updated
-//		(3) If there is an error, it will be returned
-func (v *VoterList) UpdateVoterPoll(voterId uint, requestVoter Voter) error {
-
-	// Check if voter exists before trying to update it
-	// this is a good practice, return an error if the
-	// voter does not exist
-
-	var voter Voter
-	pattern := redisKeyFromId(voterId)
-	err := v.getItemFromRedis(pattern, &voter)
-	if err != nil {
-		return errors.New("voter does not exist")
-	}
-
-	requestPoll := requestVoter.VoteHistory[0]
-
-	index := -1
-    for i, poll := range voter.VoteHistory {
-        if poll.PollID == requestPoll.PollID{
-            index = i
-            break
-        }
-    }	
-
-    if index == -1 {
-        return errors.New("poll does not exist in voter")
-    } 
-	
-	voter.VoteHistory[index] = requestPoll
-	v.UpdateVoter(voter)
-
-	return nil
-}
-
-func (v *VoterList) GetHealthData(bootTime time.Time, calls uint) (healthData, error){
-
-	v.healthInfo = healthData{Uptime: time.Now().Sub(bootTime), APIcalls: calls}
-
-	return v.healthInfo, nil
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nitishm/go-rejson/v4"
+)
+
+// VoterPoll records a poll the voter has voted in, and when
+type VoterPoll struct{
+	PollID uint
+	VoteDate time.Time
+}
+  
+type Voter struct{
+	VoterID uint
+	FirstName string `binding:"required"`
+	LastName string `binding:"required"`
+	VoteHistory []VoterPoll
+	//Version goes up by one with every change, see versions.go
+	Version uint
+	Links	[]string
+}
+
+// MarshalJSON writes a voter with VoterID first and Links last, whatever
+// order the fields end up declared in, so that clients can rely on that
+// order.  Unmarshalling is the default and takes the fields in any order
+func (v Voter) MarshalJSON() ([]byte, error) {
+	type plainVoter Voter
+	return marshalOrdered(plainVoter(v), "VoterID", "Links")
+}
+
+const (
+	RedisNilError        = "redis: nil"
+	RedisDefaultLocation = "0.0.0.0:6379"
+	RedisKeyPrefix       = "voters:"
+)
+
+// ErrNotFound is returned when a voter, or a poll in their history, is
+// not there.  Any other error means redis could not be reached or sent
+// back something we can't read, so callers should not report those as a
+// missing voter
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyExists is returned when a voter, or a poll in their history,
+// is added but is already there
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrConflict is returned when a change to a voter can't be made in the
+// state the voter is in, such as when it kept changing while the change
+// was being made
+var ErrConflict = errors.New("conflict")
+
+// HealthData is the health record returned by the health endpoint
+type HealthData struct{
+	Uptime time.Duration
+	APIcalls uint
+	//RedisLatency is how long a PING to the store took, the name is
+	//kept from when redis was the only store
+	RedisLatency time.Duration
+}
+
+// VoterSummary is the aggregate view of a voter returned by
+// GET /voters/:id/summary.  The vote dates are null if the voter
+// has not voted in any polls
+type VoterSummary struct {
+	FirstName     string     `json:"firstName"`
+	LastName      string     `json:"lastName"`
+	PollCount     int        `json:"pollCount"`
+	FirstVoteDate *time.Time `json:"firstVoteDate"`
+	LastVoteDate  *time.Time `json:"lastVoteDate"`
+}
+
+// Statuses of the entries of a batch of voter polls
+const (
+	VoterPollAdded     = "added"
+	VoterPollNotAdded  = "not added"
+	VoterPollExists    = "poll already exists in voter"
+	VoterPollDuplicate = "poll is in the batch more than once"
+)
+
+// VoterPollResult is the outcome of one entry of a batch of voter polls
+type VoterPollResult struct {
+	PollID uint   `json:"pollId"`
+	Status string `json:"status"`
+}
+
+// VoterPollsError is returned by AddVoterPolls when one or more entries of
+// the batch cannot be added.  Results has the status of every entry, in
+// the order they were given
+type VoterPollsError struct {
+	Results []VoterPollResult
+}
+
+func (e *VoterPollsError) Error() string {
+	return "one or more polls could not be added to voter"
+}
+
+type VoterList struct {
+	healthInfo HealthData
+	//store is where the voters are kept, see store.go
+	store voterStore
+	//idScheme is how AddVoterNewID picks ids, see ids.go
+	idScheme string
+	//votes is the votes API an export reads votes from, see export.go
+	votes votesService
+	//audit is where changes are recorded, nil if they are not, see
+	//audit.go
+	audit *auditLog
+}
+
+//constructor for VoterList struct
+func NewVoterList() (*VoterList, error) {
+	//We will use an override if the REDIS_URL is provided as an environment
+	//variable, which is the preferred way to wire up a docker container
+	redisUrl := os.Getenv("REDIS_URL")
+	//This handles the default condition
+	if redisUrl == "" {
+		redisUrl = RedisDefaultLocation
+	}
+	voterList, err := NewWithCacheInstance(redisUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	voterList.seedFromEnv()
+
+	return voterList, nil
+}
+
+// NewWithCacheInstance is a constructor function that returns a pointer to a new
+// Voter struct.  It accepts a string that represents the location of the redis
+// cache.
+func NewWithCacheInstance(location string) (*VoterList, error) {
+
+	//Connect to redis.  Other options can be provided, but the
+	//defaults are OK
+	client := redis.NewClient(&redis.Options{
+		Addr: location,
+	})
+
+	//We use this context to coordinate betwen our go code and
+	//the redis operaitons
+	ctx := context.Background()
+
+	//This is the reccomended way to ensure that our redis connection
+	//is working
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		log.Println("Error connecting to redis" + err.Error())
+		return nil, err
+	}
+
+	//By default, redis manages keys and values, where the values
+	//are either strings, sets, maps, etc.  Redis has an extension
+	//module called ReJSON that allows us to store JSON objects
+	//however, we need a companion library in order to work with it
+	//Below we create an instance of the JSON helper and associate
+	//it with our redis connnection
+	jsonHelper := rejson.NewReJSONHandler()
+	jsonHelper.SetGoRedisClientWithContext(ctx, client)
+
+	store := &redisStore{
+		cache: cache{
+			cacheClient: client,
+			jsonHelper:  jsonHelper,
+			context:     ctx,
+			scanBatch:   scanBatchSizeFromEnv(),
+		},
+	}
+	//Voters added before there was a name index can't be searched for
+	//until they are indexed
+	if err := store.buildNameIndex(); err != nil {
+		log.Println("Error building the name index: ", err)
+	}
+
+	//Return a pointer to a new voterList struct
+	voterList := &VoterList{
+		healthInfo: HealthData{},
+		store:      store,
+		idScheme:   idSchemeFromEnv(),
+		votes:      votesServiceFromEnv(),
+		audit:      &auditLog{cache: store.cache},
+	}
+	return voterList, nil
+}
+
+// notFoundOr returns a not found error saying msg if err is ErrNotFound,
+// otherwise err is a problem with the store and is passed back as it is
+func notFoundOr(err error, msg string) error {
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, msg)
+	}
+	return err
+}
+
+//------------------------------------------------------------
+// THESE ARE THE PUBLIC FUNCTIONS THAT SUPPORT OUR VOTER APP
+//------------------------------------------------------------
+
+// AddVoter accepts a Voter and adds it to the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must not already exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if so, return an error
+//
+// Postconditions:
+//
+//	    (1) The voter will be added to the DB
+//		(2) The DB file will be saved with the voter added
+//		(3) If there is an error, it will be returned
+func (v *VoterList) AddVoter(voter Voter) error {
+
+	//Before we add an voter to the DB, lets make sure
+	//it does not exist, if it does, return an error
+	if _, err := v.store.get(voter.VoterID); err == nil {
+		return fmt.Errorf("%w: voter already exists", ErrAlreadyExists)
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	//Add voter to database
+	voter.Links = []string{"GET All Voters: 1080/voters/", "POST Voter: 1080/voters/:id", "DELETE All Voters: 1080/voters", "DELETE Voter: 1080/voters/:id","GET Voter Polls: voters/:id/polls","GET Voter Poll: voters/:id/polls/:pollId","POST Voter Poll: voters/:id/polls","DELETE Voter Poll: voters/:id/polls/:pollId","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Polls: 1090/polls/","POST Poll: 1090/polls/:id"}
+	voter.Version = 1
+	if err := validateVoterStorable(voter); err != nil {
+		return err
+	}
+	//The check above turns most duplicates away, a store that can add
+	//only if the id is free also catches one added since
+	if creator, ok := v.store.(voterCreator); ok {
+		if err := creator.create(voter); err != nil {
+			if errors.Is(err, ErrAlreadyExists) {
+				return fmt.Errorf("%w: voter already exists", err)
+			}
+			return err
+		}
+		return nil
+	}
+	if err := v.store.put(voter); err != nil {
+		return err
+	}
+
+	//If everything is ok, return nil for the error
+	return nil
+}
+
+// DeleteVoter accepts a voter id and removes it from the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The voter will be removed from the DB
+//		(2) The DB file will be saved with the voter removed
+//		(3) If there is an error, it will be returned
+func (v *VoterList) DeleteVoter(id uint) error {
+
+	if err := v.store.delete(id); err != nil {
+		return notFoundOr(err, "voter does not exist")
+	}
+
+	return nil
+}
+
+// DeleteAllVoters removes all voters from the DB.
+// It will be exposed via a DELETE /voters endpoint
+func (v *VoterList) DeleteAllVoters() error {
+
+	voterList, err := v.store.list()
+	if err != nil {
+		return err
+	}
+	for _, voter := range voterList {
+		//Someone else deleting a voter first is fine, it's gone either way
+		if err := v.store.delete(voter.VoterID); err != nil && !errors.Is(err, ErrNotFound) {
+			return errors.New("one or more voters could not be deleted")
+		}
+	}
+
+	return nil
+}
+
+// UpdateVoter accepts a voter and updates it in the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The voter will be updated in the DB
+//		(2) The DB file will be saved with the voter updated
+//		(3) If there is an error, it will be returned
+func (v *VoterList) UpdateVoter(voter Voter) error {
+
+	// Check if voter exists before trying to update it
+	// this is a good practice, return an error if the
+	// voter does not exist
+	existing, err := v.store.get(voter.VoterID)
+	if err != nil {
+		return notFoundOr(err, "voter does not exist")
+	}
+	//A voter sent with no Version overwrites whatever is stored
+	if voter.Version != 0 && voter.Version != existing.Version {
+		return versionMismatch(voter.Version, existing.Version)
+	}
+
+	//Write the whole voter, overwriting the existing one as long as it
+	//has not changed since it was read above
+	voter.Links = []string{"GET All Voters: 1080/voters/", "POST Voter: 1080/voters/:id", "DELETE All Voters: 1080/voters", "DELETE Voter: 1080/voters/:id","GET Voter Polls: voters/:id/polls","GET Voter Poll: voters/:id/polls/:pollId","POST Voter Poll: voters/:id/polls","DELETE Voter Poll: voters/:id/polls/:pollId","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Polls: 1090/polls/","POST Poll: 1090/polls/:id"}
+	voter.Version = existing.Version + 1
+	if err := validateVoterStorable(voter); err != nil {
+		return err
+	}
+	if versioned, ok := v.store.(versionedStore); ok {
+		return notFoundOr(versioned.putVersion(voter, existing.Version), "voter does not exist")
+	}
+	if err := v.store.put(voter); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetVoter accepts a voter id and returns the voter from the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The voter will be returned, if it exists
+//		(2) If there is an error, it will be returned
+//			along with an empty Voter
+//		(3) The database file will not be modified
+func (v *VoterList) GetVoter(id uint) (Voter, error) {
+
+	// Check if voter exists before trying to get it
+	// this is a good practice, return an error if the
+	// voter does not exist
+	voter, err := v.store.get(id)
+	if err != nil {
+		return Voter{}, notFoundOr(err, "voter does not exist")
+	}
+
+	return voter, nil
+}
+
+// GetAllVoters returns all voters from the DB, in the order order asks
+// for.  If successful it returns a slice of all of the voters to the
+// caller
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) order must be valid, see VoterSort.Validate
+//
+// Postconditions:
+//
+//	    (1) All voters will be returned, sorted, if any exist
+//		(2) If there is an error, it will be returned
+//			along with an empty slice
+//		(3) The database file will not be modified
+func (v *VoterList) GetAllVoters(order VoterSort) ([]Voter, error) {
+
+	//Lets query the store for all of the items
+	voterList, err := v.store.list()
+	if err != nil {
+		return nil, err
+	}
+	//Redis lists keys in no particular order, sort the whole list before
+	//it is paged so that pages of the list are stable
+	order.sortVoters(voterList)
+
+	if len(voterList) < 1 {
+		voterList = append(voterList, Voter{
+			VoterID: 0,
+			FirstName: "",
+			LastName: "",
+			VoteHistory: []VoterPoll{},
+			Links: []string{"GET All Voters: 1080/voters/", "POST Voter: 1080/voters/:id", "DELETE All Voters: 1080/voters", "DELETE Voter: 1080/voters/:id","GET Voter Polls: voters/:id/polls","GET Voter Poll: voters/:id/polls/:pollId","POST Voter Poll: voters/:id/polls","DELETE Voter Poll: voters/:id/polls/:pollId","GET All Votes: 1100/votes/","POST Vote: 1100/votes/:id","GET All Polls: 1090/polls/","POST Poll: 1090/polls/:id"},
+		})
+	}
+
+	//Now that we have all of our voters in a slice, return it
+	return voterList, nil
+}
+
+// GetVotersPage returns a page of the voters in the DB, starting at
+// cursor, 0 for the first page, for clients that can't wait for every
+// voter at once.  count is roughly how many voters to return, 0 for the
+// store's default.  Unlike GetAllVoters the voters are only sorted within
+// the page, and an empty DB gives an empty page.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) cursor must be 0 or a cursor returned by an
+//						earlier call
+//
+// Postconditions:
+//
+//	    (1) The page of voters will be returned, along with the
+//			cursor of the next page, 0 if this was the last page.
+//			A page may be empty before the last one
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (v *VoterList) GetVotersPage(cursor uint64, count int) ([]Voter, uint64, error) {
+
+	voterList, next, err := v.store.listPage(cursor, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(voterList, func(i, j int) bool {
+		return voterList[i].VoterID < voterList[j].VoterID
+	})
+
+	return voterList, next, nil
+}
+
+// PrintVoter accepts a Voter and prints it to the console
+// in a JSON pretty format. As some help, look at the
+// json.MarshalIndent() function from our in class go tutorial.
+func (v *VoterList) PrintVoter(voter Voter) {
+	jsonBytes, _ := json.MarshalIndent(voter, "", "  ")
+	fmt.Println(string(jsonBytes))
+}
+
+// PrintAllVoters accepts a slice of Voters and prints them to the console
+// in a JSON pretty format.  It should call PrintVoter() to print each voter
+// versus repeating the code.
+func (v *VoterList) PrintAllVoters(voterList []Voter) {
+	for _, voter := range voterList {
+		v.PrintVoter(voter)
+	}
+}
+
+// JsonToVoter accepts a json string and returns a Voter
+// This is helpful because the CLI accepts voters for insertion
+// and updates in JSON format.  We need to convert it to a Voter
+// struct to perform any operations on it.
+func (v *VoterList) JsonToVoter(jsonString string) (Voter, error) {
+	var voter Voter
+	err := json.Unmarshal([]byte(jsonString), &voter)
+	if err != nil {
+		return Voter{}, err
+	}
+
+	return voter, nil
+}
+
+// GetVoterPolls accepts a voter id and returns polls from that voter.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//      (1) All polls will be returned, if any exist
+//		(2) If there is an error, it will be returned
+//			along with an empty slice
+//		(3) The database file will not be modified
+func (v *VoterList) GetVoterPolls(id uint) ([]VoterPoll, error) {
+
+	// we should check if voter exists before trying to retriece polls
+	// this is a good practice, return an error if the
+	// voter does not exist
+
+	voter, err := v.store.get(id)
+	if err != nil {
+		return nil, notFoundOr(err, "voter does not exist")
+	}
+
+	return voter.VoteHistory, nil
+}
+
+
+// GetVoterSummary accepts a voter id and returns a summary of the voter
+// and their vote history.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//
+// Postconditions:
+//
+//	    (1) The summary will be returned, if the voter exists
+//		(2) If there is an error, it will be returned
+//			along with an empty summary
+//		(3) The database file will not be modified
+func (v *VoterList) GetVoterSummary(id uint) (VoterSummary, error) {
+
+	voter, err := v.store.get(id)
+	if err != nil {
+		return VoterSummary{}, notFoundOr(err, "voter does not exist")
+	}
+
+	summary := VoterSummary{
+		FirstName: voter.FirstName,
+		LastName:  voter.LastName,
+		PollCount: len(voter.VoteHistory),
+	}
+
+	//The history is not kept in date order, so look at every entry
+	for i := range voter.VoteHistory {
+		date := voter.VoteHistory[i].VoteDate
+		if summary.FirstVoteDate == nil || date.Before(*summary.FirstVoteDate) {
+			summary.FirstVoteDate = &date
+		}
+		if summary.LastVoteDate == nil || date.After(*summary.LastVoteDate) {
+			summary.LastVoteDate = &date
+		}
+	}
+
+	return summary, nil
+}
+
+// GetVoterPoll accepts a voter id and poll id and returns the requested poll.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter and poll must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The poll will be returned, if it exists
+//		(2) If there is an error, it will be returned
+//			along with an empty poll
+//		(3) The database file will not be modified
+func (v *VoterList) GetVoterPoll(voterId, pollId uint) (VoterPoll , error) {
+
+    // we should if voter exists before trying to retrieve polls
+    // this is a good practice, return an error if the
+    // voter does not exist
+
+	voter, err := v.store.get(voterId)
+	if err != nil {
+		return VoterPoll{}, notFoundOr(err, "voter does not exist")
+	}
+
+    for _, poll := range voter.VoteHistory {
+        if poll.PollID == pollId{
+			return poll, nil
+        }
+    }
+
+    return VoterPoll{}, fmt.Errorf("%w: poll not found for given voter", ErrNotFound)
+}
+
+// AddVoterPoll accepts a voter id and new poll to add to the voter.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The poll will be added to the DB
+//		(2) The DB file will be saved with the poll added
+//		(3) If there is an error, it will be returned
+func (v *VoterList) AddVoterPoll(voterId uint, requestVoter Voter) error {
+
+	requestPoll := requestVoter.VoteHistory[0]
+
+	//Only the new poll is written, see AppendVoterPoll, so a name
+	//change made at the same time is kept
+	return v.AppendVoterPoll(voterId, requestPoll)
+}
+
+// AddVoterPolls accepts a voter id and a batch of polls to add to the
+// voter.  Either every poll is added, with a single write of the voter,
+// or none are.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//
+//					(3) None of the polls may already be in the voter's
+//						history or be in the batch more than once
+//
+// Postconditions:
+//
+//	    (1) The polls will be added to the DB
+//		(2) If any poll can't be added a *VoterPollsError is returned
+//			with the status of each entry, and nothing is added
+//		(3) If there is an error, it will be returned
+func (v *VoterList) AddVoterPolls(id uint, polls []VoterPoll) error {
+
+	voter, err := v.store.get(id)
+	if err != nil {
+		return notFoundOr(err, "voter does not exist")
+	}
+
+	existing := make(map[uint]bool, len(voter.VoteHistory))
+	for _, poll := range voter.VoteHistory {
+		existing[poll.PollID] = true
+	}
+
+	//Check every entry before writing anything so that the caller can
+	//be told about all of the problems at once
+	results := make([]VoterPollResult, len(polls))
+	seen := make(map[uint]bool, len(polls))
+	rejected := false
+	for i, poll := range polls {
+		results[i] = VoterPollResult{PollID: poll.PollID, Status: VoterPollAdded}
+		switch {
+		case existing[poll.PollID]:
+			results[i].Status = VoterPollExists
+			rejected = true
+		case seen[poll.PollID]:
+			results[i].Status = VoterPollDuplicate
+			rejected = true
+		}
+		seen[poll.PollID] = true
+	}
+	if rejected {
+		for i := range results {
+			if results[i].Status == VoterPollAdded {
+				results[i].Status = VoterPollNotAdded
+			}
+		}
+		return &VoterPollsError{Results: results}
+	}
+
+	voter.VoteHistory = append(voter.VoteHistory, polls...)
+	return v.UpdateVoter(voter)
+}
+
+// DeleteVoterPoll accepts a voter id and a poll to add to the voter.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The poll will be deleted from the DB
+//		(2) The DB file will be saved with the poll deleted
+//		(3) If there is an error, it will be returned
+func (v *VoterList) DeleteVoterPoll(voterId uint, pollId uint) error {
+
+	// Check if voter exists before trying to update it
+	// this is a good practice, return an error if the
+	// voter does not exist
+
+	voter, err := v.store.get(voterId)
+	if err != nil {
+		return notFoundOr(err, "voter does not exist")
+	}
+
+	index := -1
+    for i, poll := range voter.VoteHistory {
+        if poll.PollID == pollId{
+            index = i
+            break
+        }
+    }	
+
+	if index == -1{
+		return fmt.Errorf("%w: poll does not exist in voter", ErrNotFound)
+	}
+	
+	voter.VoteHistory[index] = voter.VoteHistory[len(voter.VoteHistory)-1]
+	voter.VoteHistory = voter.VoteHistory[:len(voter.VoteHistory)-1]
+	//The voter keeps the Version it was read with, so this fails rather
+	//than undo a change made since
+	return v.UpdateVoter(voter)
+}
+
+// UpdateVoterPoll accepts a voter id and poll to update fpr the voter.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must exist in the DB
+//	    				because we use the voter.VoterId as the key, this
+//						function must check if the voter already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The poll will be updated in the DB
+//		(2) The DB file will be saved with the poll updated
+//		(3) If there is an error, it will be returned
+func (v *VoterList) UpdateVoterPoll(voterId uint, requestVoter Voter) error {
+
+	// Check if voter exists before trying to update it
+	// this is a good practice, return an error if the
+	// voter does not exist
+
+	voter, err := v.store.get(voterId)
+	if err != nil {
+		return notFoundOr(err, "voter does not exist")
+	}
+
+	requestPoll := requestVoter.VoteHistory[0]
+
+	index := -1
+    for i, poll := range voter.VoteHistory {
+        if poll.PollID == requestPoll.PollID{
+            index = i
+            break
+        }
+    }	
+
+    if index == -1 {
+        return fmt.Errorf("%w: poll does not exist in voter", ErrNotFound)
+    } 
+	
+	voter.VoteHistory[index] = requestPoll
+	return v.UpdateVoter(voter)
+}
+
+func (v *VoterList) GetHealthData(bootTime time.Time, calls uint) (HealthData, error){
+
+	//Time a PING so that a slow or unreachable store shows up in the
+	//health record
+	start := time.Now()
+	if err := v.store.ping(); err != nil {
+		return HealthData{}, err
+	}
+	latency := time.Since(start)
+
+	v.healthInfo = HealthData{Uptime: time.Now().Sub(bootTime), APIcalls: calls, RedisLatency: latency}
+
+	return v.healthInfo, nil
 }
\ No newline at end of file
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"drexel.edu/voting-application/client/httpclient"
+)
+
+// DefaultVotesAPIURL is where the votes API is looked for when
+// VOTES_API_URL is not set
+const DefaultVotesAPIURL = "http://localhost:1100"
+
+// DefaultVotesAPITimeout is how long each attempt at a request to the
+// votes API has when VOTES_API_TIMEOUT is not set
+const DefaultVotesAPITimeout = 10 * time.Second
+
+// maxVotesBody is the largest response that is read from the votes API
+const maxVotesBody = 8 << 20
+
+// ErrDependencyUnavailable is returned when the votes API can't be
+// reached, or it answers with an error
+var ErrDependencyUnavailable = errors.New("dependency unavailable")
+
+// votesService is the votes API, that an export reads the voter's votes
+// from and an erasure anonymizes them through
+type votesService struct {
+	url    string
+	client *httpclient.Client
+	//adminToken is sent in X-Admin-Token, for the votes API's
+	//administrative endpoints when its FEATURE_AUTH is on
+	adminToken string
+}
+
+// votesServiceFromEnv returns the votes API at VOTES_API_URL, or the
+// default, with VOTES_API_TIMEOUT for each attempt at a request and
+// ADMIN_TOKEN as the admin token.  Both requests can safely be sent again,
+// so they are retried, see httpclient
+func votesServiceFromEnv() votesService {
+	votes := votesService{url: DefaultVotesAPIURL, adminToken: os.Getenv("ADMIN_TOKEN")}
+	if u := os.Getenv("VOTES_API_URL"); u != "" {
+		votes.url = u
+	}
+	votes.url = strings.TrimSuffix(votes.url, "/")
+
+	timeout := DefaultVotesAPITimeout
+	if t := os.Getenv("VOTES_API_TIMEOUT"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil || d <= 0 {
+			log.Println("Invalid VOTES_API_TIMEOUT, using default: ", t)
+		} else {
+			timeout = d
+		}
+	}
+	config := httpclient.DefaultConfig()
+	config.AttemptTimeout = timeout
+	config.MaxBody = maxVotesBody
+	votes.client = httpclient.New("votes", config)
+	return votes
+}
+
+// send makes a request with method for path to the votes API and returns
+// the body of a 2xx.  Anything else is ErrDependencyUnavailable
+func (s votesService) send(ctx context.Context, method string, path string) ([]byte, error) {
+	unavailable := fmt.Errorf("%w: votes", ErrDependencyUnavailable)
+
+	header := http.Header{}
+	if s.adminToken != "" {
+		header.Set("X-Admin-Token", s.adminToken)
+	}
+
+	resp, err := s.client.DoWithHeader(ctx, method, s.url+path, header)
+	if err != nil {
+		log.Println("Error reaching votes: ", err)
+		return nil, unavailable
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		log.Println("Unexpected status from votes: ", resp.StatusCode)
+		return nil, unavailable
+	}
+	return resp.Body, nil
+}
+
+// votesByVoter returns the votes of the voter with id from
+// GET /votes/byvoter/:voterId, each as its JSON
+func (s votesService) votesByVoter(ctx context.Context, id uint) ([]json.RawMessage, error) {
+	body, err := s.send(ctx, http.MethodGet, fmt.Sprintf("/votes/byvoter/%d", id))
+	if err != nil {
+		return nil, err
+	}
+
+	votes := []json.RawMessage{}
+	if err := json.Unmarshal(body, &votes); err != nil {
+		log.Println("Error reading votes: ", err)
+		return nil, fmt.Errorf("%w: votes", ErrDependencyUnavailable)
+	}
+	return votes, nil
+}
+
+// anonymizeVotes takes the voter with id off every vote they cast with
+// POST /votes/byvoter/:voterId/anonymize and returns how many votes it
+// changed.  The votes API does nothing more when it is called again
+func (s votesService) anonymizeVotes(ctx context.Context, id uint) (int, error) {
+	body, err := s.send(ctx, http.MethodPost, fmt.Sprintf("/votes/byvoter/%d/anonymize", id))
+	if err != nil {
+		return 0, err
+	}
+
+	var erasure struct {
+		Anonymized int
+		Trashed    int
+	}
+	if err := json.Unmarshal(body, &erasure); err != nil {
+		log.Println("Error reading votes: ", err)
+		return 0, fmt.Errorf("%w: votes", ErrDependencyUnavailable)
+	}
+	return erasure.Anonymized + erasure.Trashed, nil
+}
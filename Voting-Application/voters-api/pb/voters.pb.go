@@ -0,0 +1,707 @@
+// The Voters API over gRPC.  The messages mirror the JSON the REST API
+// sends, field for field apart from Links, so that a voter read over either
+// is the same voter.  Timestamps are RFC 3339 strings, as in the JSON
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: voters.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type VoterPoll struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PollId   uint32 `protobuf:"varint,1,opt,name=poll_id,json=pollId,proto3" json:"poll_id,omitempty"`
+	VoteDate string `protobuf:"bytes,2,opt,name=vote_date,json=voteDate,proto3" json:"vote_date,omitempty"`
+}
+
+func (x *VoterPoll) Reset() {
+	*x = VoterPoll{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VoterPoll) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoterPoll) ProtoMessage() {}
+
+func (x *VoterPoll) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoterPoll.ProtoReflect.Descriptor instead.
+func (*VoterPoll) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *VoterPoll) GetPollId() uint32 {
+	if x != nil {
+		return x.PollId
+	}
+	return 0
+}
+
+func (x *VoterPoll) GetVoteDate() string {
+	if x != nil {
+		return x.VoteDate
+	}
+	return ""
+}
+
+type Voter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VoterId     uint32       `protobuf:"varint,1,opt,name=voter_id,json=voterId,proto3" json:"voter_id,omitempty"`
+	FirstName   string       `protobuf:"bytes,2,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName    string       `protobuf:"bytes,3,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	VoteHistory []*VoterPoll `protobuf:"bytes,4,rep,name=vote_history,json=voteHistory,proto3" json:"vote_history,omitempty"`
+	// goes up by one with every change
+	Version uint32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *Voter) Reset() {
+	*x = Voter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Voter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Voter) ProtoMessage() {}
+
+func (x *Voter) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Voter.ProtoReflect.Descriptor instead.
+func (*Voter) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Voter) GetVoterId() uint32 {
+	if x != nil {
+		return x.VoterId
+	}
+	return 0
+}
+
+func (x *Voter) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *Voter) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *Voter) GetVoteHistory() []*VoterPoll {
+	if x != nil {
+		return x.VoteHistory
+	}
+	return nil
+}
+
+func (x *Voter) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// ListVotersRequest pages like ?cursor on GET /voters, a cursor of 0
+// starts at the beginning and a next_cursor of 0 is the last page
+type ListVotersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cursor uint64 `protobuf:"varint,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit  uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *ListVotersRequest) Reset() {
+	*x = ListVotersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListVotersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVotersRequest) ProtoMessage() {}
+
+func (x *ListVotersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVotersRequest.ProtoReflect.Descriptor instead.
+func (*ListVotersRequest) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListVotersRequest) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *ListVotersRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListVotersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Voters     []*Voter `protobuf:"bytes,1,rep,name=voters,proto3" json:"voters,omitempty"`
+	NextCursor uint64   `protobuf:"varint,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+}
+
+func (x *ListVotersResponse) Reset() {
+	*x = ListVotersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListVotersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVotersResponse) ProtoMessage() {}
+
+func (x *ListVotersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVotersResponse.ProtoReflect.Descriptor instead.
+func (*ListVotersResponse) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListVotersResponse) GetVoters() []*Voter {
+	if x != nil {
+		return x.Voters
+	}
+	return nil
+}
+
+func (x *ListVotersResponse) GetNextCursor() uint64 {
+	if x != nil {
+		return x.NextCursor
+	}
+	return 0
+}
+
+type GetVoterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VoterId uint32 `protobuf:"varint,1,opt,name=voter_id,json=voterId,proto3" json:"voter_id,omitempty"`
+}
+
+func (x *GetVoterRequest) Reset() {
+	*x = GetVoterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetVoterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVoterRequest) ProtoMessage() {}
+
+func (x *GetVoterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVoterRequest.ProtoReflect.Descriptor instead.
+func (*GetVoterRequest) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetVoterRequest) GetVoterId() uint32 {
+	if x != nil {
+		return x.VoterId
+	}
+	return 0
+}
+
+type DeleteVoterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VoterId uint32 `protobuf:"varint,1,opt,name=voter_id,json=voterId,proto3" json:"voter_id,omitempty"`
+}
+
+func (x *DeleteVoterRequest) Reset() {
+	*x = DeleteVoterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteVoterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVoterRequest) ProtoMessage() {}
+
+func (x *DeleteVoterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVoterRequest.ProtoReflect.Descriptor instead.
+func (*DeleteVoterRequest) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteVoterRequest) GetVoterId() uint32 {
+	if x != nil {
+		return x.VoterId
+	}
+	return 0
+}
+
+type DeleteVoterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteVoterResponse) Reset() {
+	*x = DeleteVoterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteVoterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVoterResponse) ProtoMessage() {}
+
+func (x *DeleteVoterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVoterResponse.ProtoReflect.Descriptor instead.
+func (*DeleteVoterResponse) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{6}
+}
+
+type AddVoterPollRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VoterId uint32     `protobuf:"varint,1,opt,name=voter_id,json=voterId,proto3" json:"voter_id,omitempty"`
+	Poll    *VoterPoll `protobuf:"bytes,2,opt,name=poll,proto3" json:"poll,omitempty"`
+}
+
+func (x *AddVoterPollRequest) Reset() {
+	*x = AddVoterPollRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_voters_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddVoterPollRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddVoterPollRequest) ProtoMessage() {}
+
+func (x *AddVoterPollRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_voters_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddVoterPollRequest.ProtoReflect.Descriptor instead.
+func (*AddVoterPollRequest) Descriptor() ([]byte, []int) {
+	return file_voters_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AddVoterPollRequest) GetVoterId() uint32 {
+	if x != nil {
+		return x.VoterId
+	}
+	return 0
+}
+
+func (x *AddVoterPollRequest) GetPoll() *VoterPoll {
+	if x != nil {
+		return x.Poll
+	}
+	return nil
+}
+
+var File_voters_proto protoreflect.FileDescriptor
+
+var file_voters_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10,
+	0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31,
+	0x22, 0x41, 0x0a, 0x09, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x17, 0x0a,
+	0x07, 0x70, 0x6f, 0x6c, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06,
+	0x70, 0x6f, 0x6c, 0x6c, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x76, 0x6f, 0x74, 0x65, 0x5f, 0x64,
+	0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x76, 0x6f, 0x74, 0x65, 0x44,
+	0x61, 0x74, 0x65, 0x22, 0xb8, 0x01, 0x0a, 0x05, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x19, 0x0a,
+	0x08, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x72, 0x73,
+	0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x3e, 0x0a, 0x0c, 0x76, 0x6f, 0x74, 0x65, 0x5f, 0x68, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x76, 0x6f, 0x74,
+	0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f,
+	0x74, 0x65, 0x72, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x0b, 0x76, 0x6f, 0x74, 0x65, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x41,
+	0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x22, 0x66, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x76, 0x6f, 0x74, 0x65, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67,
+	0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72,
+	0x52, 0x06, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74,
+	0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6e,
+	0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0x2c, 0x0a, 0x0f, 0x47, 0x65, 0x74,
+	0x56, 0x6f, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08,
+	0x76, 0x6f, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x76, 0x6f, 0x74, 0x65, 0x72, 0x49, 0x64, 0x22, 0x2f, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a,
+	0x08, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x49, 0x64, 0x22, 0x15, 0x0a, 0x13, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x61, 0x0a, 0x13, 0x41, 0x64, 0x64, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x50, 0x6f, 0x6c, 0x6c, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x49,
+	0x64, 0x12, 0x2f, 0x0a, 0x04, 0x70, 0x6f, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x04, 0x70, 0x6f,
+	0x6c, 0x6c, 0x32, 0xd7, 0x03, 0x0a, 0x09, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x41, 0x50, 0x49,
+	0x12, 0x57, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x12, 0x23,
+	0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74,
+	0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x72,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x08, 0x47, 0x65, 0x74,
+	0x56, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x21, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x56, 0x6f, 0x74, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e,
+	0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65,
+	0x72, 0x12, 0x3c, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x17, 0x2e,
+	0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x1a, 0x17, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x12,
+	0x3f, 0x0a, 0x0b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x17,
+	0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x1a, 0x17, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67,
+	0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72,
+	0x12, 0x5a, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x12,
+	0x24, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x56,
+	0x6f, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0c,
+	0x41, 0x64, 0x64, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x25, 0x2e, 0x76,
+	0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x64, 0x64, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x6f, 0x74,
+	0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x42, 0x16, 0x5a, 0x14,
+	0x64, 0x72, 0x65, 0x78, 0x65, 0x6c, 0x2e, 0x65, 0x64, 0x75, 0x2f, 0x76, 0x6f, 0x74, 0x65, 0x72,
+	0x73, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_voters_proto_rawDescOnce sync.Once
+	file_voters_proto_rawDescData = file_voters_proto_rawDesc
+)
+
+func file_voters_proto_rawDescGZIP() []byte {
+	file_voters_proto_rawDescOnce.Do(func() {
+		file_voters_proto_rawDescData = protoimpl.X.CompressGZIP(file_voters_proto_rawDescData)
+	})
+	return file_voters_proto_rawDescData
+}
+
+var file_voters_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_voters_proto_goTypes = []any{
+	(*VoterPoll)(nil),           // 0: voting.voters.v1.VoterPoll
+	(*Voter)(nil),               // 1: voting.voters.v1.Voter
+	(*ListVotersRequest)(nil),   // 2: voting.voters.v1.ListVotersRequest
+	(*ListVotersResponse)(nil),  // 3: voting.voters.v1.ListVotersResponse
+	(*GetVoterRequest)(nil),     // 4: voting.voters.v1.GetVoterRequest
+	(*DeleteVoterRequest)(nil),  // 5: voting.voters.v1.DeleteVoterRequest
+	(*DeleteVoterResponse)(nil), // 6: voting.voters.v1.DeleteVoterResponse
+	(*AddVoterPollRequest)(nil), // 7: voting.voters.v1.AddVoterPollRequest
+}
+var file_voters_proto_depIdxs = []int32{
+	0, // 0: voting.voters.v1.Voter.vote_history:type_name -> voting.voters.v1.VoterPoll
+	1, // 1: voting.voters.v1.ListVotersResponse.voters:type_name -> voting.voters.v1.Voter
+	0, // 2: voting.voters.v1.AddVoterPollRequest.poll:type_name -> voting.voters.v1.VoterPoll
+	2, // 3: voting.voters.v1.VotersAPI.ListVoters:input_type -> voting.voters.v1.ListVotersRequest
+	4, // 4: voting.voters.v1.VotersAPI.GetVoter:input_type -> voting.voters.v1.GetVoterRequest
+	1, // 5: voting.voters.v1.VotersAPI.AddVoter:input_type -> voting.voters.v1.Voter
+	1, // 6: voting.voters.v1.VotersAPI.UpdateVoter:input_type -> voting.voters.v1.Voter
+	5, // 7: voting.voters.v1.VotersAPI.DeleteVoter:input_type -> voting.voters.v1.DeleteVoterRequest
+	7, // 8: voting.voters.v1.VotersAPI.AddVoterPoll:input_type -> voting.voters.v1.AddVoterPollRequest
+	3, // 9: voting.voters.v1.VotersAPI.ListVoters:output_type -> voting.voters.v1.ListVotersResponse
+	1, // 10: voting.voters.v1.VotersAPI.GetVoter:output_type -> voting.voters.v1.Voter
+	1, // 11: voting.voters.v1.VotersAPI.AddVoter:output_type -> voting.voters.v1.Voter
+	1, // 12: voting.voters.v1.VotersAPI.UpdateVoter:output_type -> voting.voters.v1.Voter
+	6, // 13: voting.voters.v1.VotersAPI.DeleteVoter:output_type -> voting.voters.v1.DeleteVoterResponse
+	1, // 14: voting.voters.v1.VotersAPI.AddVoterPoll:output_type -> voting.voters.v1.Voter
+	9, // [9:15] is the sub-list for method output_type
+	3, // [3:9] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_voters_proto_init() }
+func file_voters_proto_init() {
+	if File_voters_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_voters_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*VoterPoll); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_voters_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Voter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_voters_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ListVotersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_voters_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ListVotersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_voters_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GetVoterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_voters_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteVoterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_voters_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteVoterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_voters_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*AddVoterPollRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_voters_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_voters_proto_goTypes,
+		DependencyIndexes: file_voters_proto_depIdxs,
+		MessageInfos:      file_voters_proto_msgTypes,
+	}.Build()
+	File_voters_proto = out.File
+	file_voters_proto_rawDesc = nil
+	file_voters_proto_goTypes = nil
+	file_voters_proto_depIdxs = nil
+}
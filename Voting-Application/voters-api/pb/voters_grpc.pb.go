@@ -0,0 +1,315 @@
+// The Voters API over gRPC.  The messages mirror the JSON the REST API
+// sends, field for field apart from Links, so that a voter read over either
+// is the same voter.  Timestamps are RFC 3339 strings, as in the JSON
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: voters.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VotersAPI_ListVoters_FullMethodName   = "/voting.voters.v1.VotersAPI/ListVoters"
+	VotersAPI_GetVoter_FullMethodName     = "/voting.voters.v1.VotersAPI/GetVoter"
+	VotersAPI_AddVoter_FullMethodName     = "/voting.voters.v1.VotersAPI/AddVoter"
+	VotersAPI_UpdateVoter_FullMethodName  = "/voting.voters.v1.VotersAPI/UpdateVoter"
+	VotersAPI_DeleteVoter_FullMethodName  = "/voting.voters.v1.VotersAPI/DeleteVoter"
+	VotersAPI_AddVoterPoll_FullMethodName = "/voting.voters.v1.VotersAPI/AddVoterPoll"
+)
+
+// VotersAPIClient is the client API for VotersAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VotersAPIClient interface {
+	ListVoters(ctx context.Context, in *ListVotersRequest, opts ...grpc.CallOption) (*ListVotersResponse, error)
+	GetVoter(ctx context.Context, in *GetVoterRequest, opts ...grpc.CallOption) (*Voter, error)
+	AddVoter(ctx context.Context, in *Voter, opts ...grpc.CallOption) (*Voter, error)
+	UpdateVoter(ctx context.Context, in *Voter, opts ...grpc.CallOption) (*Voter, error)
+	DeleteVoter(ctx context.Context, in *DeleteVoterRequest, opts ...grpc.CallOption) (*DeleteVoterResponse, error)
+	AddVoterPoll(ctx context.Context, in *AddVoterPollRequest, opts ...grpc.CallOption) (*Voter, error)
+}
+
+type votersAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVotersAPIClient(cc grpc.ClientConnInterface) VotersAPIClient {
+	return &votersAPIClient{cc}
+}
+
+func (c *votersAPIClient) ListVoters(ctx context.Context, in *ListVotersRequest, opts ...grpc.CallOption) (*ListVotersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVotersResponse)
+	err := c.cc.Invoke(ctx, VotersAPI_ListVoters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votersAPIClient) GetVoter(ctx context.Context, in *GetVoterRequest, opts ...grpc.CallOption) (*Voter, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Voter)
+	err := c.cc.Invoke(ctx, VotersAPI_GetVoter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votersAPIClient) AddVoter(ctx context.Context, in *Voter, opts ...grpc.CallOption) (*Voter, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Voter)
+	err := c.cc.Invoke(ctx, VotersAPI_AddVoter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votersAPIClient) UpdateVoter(ctx context.Context, in *Voter, opts ...grpc.CallOption) (*Voter, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Voter)
+	err := c.cc.Invoke(ctx, VotersAPI_UpdateVoter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votersAPIClient) DeleteVoter(ctx context.Context, in *DeleteVoterRequest, opts ...grpc.CallOption) (*DeleteVoterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteVoterResponse)
+	err := c.cc.Invoke(ctx, VotersAPI_DeleteVoter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votersAPIClient) AddVoterPoll(ctx context.Context, in *AddVoterPollRequest, opts ...grpc.CallOption) (*Voter, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Voter)
+	err := c.cc.Invoke(ctx, VotersAPI_AddVoterPoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VotersAPIServer is the server API for VotersAPI service.
+// All implementations must embed UnimplementedVotersAPIServer
+// for forward compatibility.
+type VotersAPIServer interface {
+	ListVoters(context.Context, *ListVotersRequest) (*ListVotersResponse, error)
+	GetVoter(context.Context, *GetVoterRequest) (*Voter, error)
+	AddVoter(context.Context, *Voter) (*Voter, error)
+	UpdateVoter(context.Context, *Voter) (*Voter, error)
+	DeleteVoter(context.Context, *DeleteVoterRequest) (*DeleteVoterResponse, error)
+	AddVoterPoll(context.Context, *AddVoterPollRequest) (*Voter, error)
+	mustEmbedUnimplementedVotersAPIServer()
+}
+
+// UnimplementedVotersAPIServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVotersAPIServer struct{}
+
+func (UnimplementedVotersAPIServer) ListVoters(context.Context, *ListVotersRequest) (*ListVotersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVoters not implemented")
+}
+func (UnimplementedVotersAPIServer) GetVoter(context.Context, *GetVoterRequest) (*Voter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVoter not implemented")
+}
+func (UnimplementedVotersAPIServer) AddVoter(context.Context, *Voter) (*Voter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddVoter not implemented")
+}
+func (UnimplementedVotersAPIServer) UpdateVoter(context.Context, *Voter) (*Voter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateVoter not implemented")
+}
+func (UnimplementedVotersAPIServer) DeleteVoter(context.Context, *DeleteVoterRequest) (*DeleteVoterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVoter not implemented")
+}
+func (UnimplementedVotersAPIServer) AddVoterPoll(context.Context, *AddVoterPollRequest) (*Voter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddVoterPoll not implemented")
+}
+func (UnimplementedVotersAPIServer) mustEmbedUnimplementedVotersAPIServer() {}
+func (UnimplementedVotersAPIServer) testEmbeddedByValue()                   {}
+
+// UnsafeVotersAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VotersAPIServer will
+// result in compilation errors.
+type UnsafeVotersAPIServer interface {
+	mustEmbedUnimplementedVotersAPIServer()
+}
+
+func RegisterVotersAPIServer(s grpc.ServiceRegistrar, srv VotersAPIServer) {
+	// If the following call pancis, it indicates UnimplementedVotersAPIServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VotersAPI_ServiceDesc, srv)
+}
+
+func _VotersAPI_ListVoters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVotersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotersAPIServer).ListVoters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotersAPI_ListVoters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotersAPIServer).ListVoters(ctx, req.(*ListVotersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotersAPI_GetVoter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVoterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotersAPIServer).GetVoter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotersAPI_GetVoter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotersAPIServer).GetVoter(ctx, req.(*GetVoterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotersAPI_AddVoter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Voter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotersAPIServer).AddVoter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotersAPI_AddVoter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotersAPIServer).AddVoter(ctx, req.(*Voter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotersAPI_UpdateVoter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Voter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotersAPIServer).UpdateVoter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotersAPI_UpdateVoter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotersAPIServer).UpdateVoter(ctx, req.(*Voter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotersAPI_DeleteVoter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVoterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotersAPIServer).DeleteVoter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotersAPI_DeleteVoter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotersAPIServer).DeleteVoter(ctx, req.(*DeleteVoterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotersAPI_AddVoterPoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddVoterPollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotersAPIServer).AddVoterPoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotersAPI_AddVoterPoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotersAPIServer).AddVoterPoll(ctx, req.(*AddVoterPollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// VotersAPI_ServiceDesc is the grpc.ServiceDesc for VotersAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VotersAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "voting.voters.v1.VotersAPI",
+	HandlerType: (*VotersAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListVoters",
+			Handler:    _VotersAPI_ListVoters_Handler,
+		},
+		{
+			MethodName: "GetVoter",
+			Handler:    _VotersAPI_GetVoter_Handler,
+		},
+		{
+			MethodName: "AddVoter",
+			Handler:    _VotersAPI_AddVoter_Handler,
+		},
+		{
+			MethodName: "UpdateVoter",
+			Handler:    _VotersAPI_UpdateVoter_Handler,
+		},
+		{
+			MethodName: "DeleteVoter",
+			Handler:    _VotersAPI_DeleteVoter_Handler,
+		},
+		{
+			MethodName: "AddVoterPoll",
+			Handler:    _VotersAPI_AddVoterPoll_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "voters.proto",
+}
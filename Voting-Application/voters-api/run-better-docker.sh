@@ -1,2 +1,2 @@
-#!/bin/bash
+#!/bin/bash
 docker run -it --rm --name better-voters -p 1080:1080 voters-api-better:v1
\ No newline at end of file
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"drexel.edu/votes/db"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader is the header administrative requests carry the
+// admin token in when FEATURE_AUTH is on, as it is by default
+const AdminTokenHeader = "X-Admin-Token"
+
+// RequireAdmin is middleware for the administrative endpoints.  When the
+// Auth feature is on, as it is unless FEATURE_AUTH=false, requests must
+// send the token from the ADMIN_TOKEN environment variable in the
+// X-Admin-Token header.  If ADMIN_TOKEN is not set every request is turned
+// away, rather than letting anyone in.  With the feature off every request
+// is let in, which is logged at startup
+func (va *VotesAPI) RequireAdmin(c *gin.Context) {
+	if !va.features.Auth {
+		c.Next()
+		return
+	}
+
+	token := os.Getenv("ADMIN_TOKEN")
+	sent := c.GetHeader(AdminTokenHeader)
+	if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+		log.Println("Rejected admin request to ", c.Request.URL.Path)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	c.Next()
+}
+
+// implementation for POST /admin/votes/pause
+// stops new votes from being accepted until voting is resumed
+func (va *VotesAPI) PauseVoting(c *gin.Context) {
+
+	if err := va.db.PauseVoting(); err != nil {
+		log.Println("Error pausing voting: ", err)
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"paused": true})
+}
+
+// implementation for POST /admin/votes/resume
+// starts accepting new votes again
+func (va *VotesAPI) ResumeVoting(c *gin.Context) {
+
+	if err := va.db.ResumeVoting(); err != nil {
+		log.Println("Error resuming voting: ", err)
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"paused": false})
+}
+
+// implementation for POST /admin/votes/cleanup-placeholders
+// removes the all zero placeholder vote left behind by old list
+// responses being saved, and returns what was removed
+func (va *VotesAPI) CleanupPlaceholderVotes(c *gin.Context) {
+
+	removed, err := va.db.RemovePlaceholderVotes()
+	if err != nil {
+		log.Println("Error removing placeholder votes: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	for i := range removed {
+		va.auditVote(c, removed[i].VoteID, &removed[i], nil)
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
+
+// implementation for POST /admin/votes/recount
+// recounts the votes of every poll and fixes any poll vote counter
+// that has drifted, returning the counters that were corrected
+func (va *VotesAPI) RecountVotes(c *gin.Context) {
+
+	corrected, err := va.db.RecountVotes()
+	if err != nil {
+		log.Println("Error recounting votes: ", err)
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"corrected": corrected})
+}
+
+// implementation for GET /admin/drift
+// counts the votes with no matching voter history entry and the voter
+// history entries with no matching vote
+func (va *VotesAPI) GetDrift(c *gin.Context) {
+
+	drift, err := va.db.CheckDrift()
+	if err != nil {
+		log.Println("Error checking drift: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, drift)
+}
@@ -1,222 +1,933 @@
-package api
-
-import (
-	"log"
-	"net/http"
-	"strconv"
-	"time"
-
-	"drexel.edu/votes/db"
-	"github.com/gin-gonic/gin"
-)
-
-// The api package creates and maintains a reference to the data handler
-// this is a good design practice
-type VotesAPI struct {
-	db *db.VoteList
-}
-
-var bootTime time.Time
-var calls uint
-
-func New() (*VotesAPI, error) {
-	dbHandler, err := db.NewVoteList()
-	if err != nil {
-		return nil, err
-	}
-
-	bootTime = time.Now()
-
-	return &VotesAPI{db: dbHandler}, nil
-}
-
-type VoteRequest struct {
-	VoteID		uint	`json:"VoteID"`
-	VoterID		uint	`json:"VoterID"`
-	PollID		uint	`json:"PollID"`
-	VoteValue	uint	`json:"VoteValue"`
-}
-
-// implementation for GET /votes
-// returns all votes
-func (va *VotesAPI) ListAllVotes(c *gin.Context) {
-
-	voteList, err := va.db.GetAllVotes()
-	if err != nil {
-		log.Println("Error Getting All Votes: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-	//Note that the database returns a nil slice if there are no items
-	//in the database.  We need to convert this to an empty slice
-	//so that the JSON marshalling works correctly.  We want to return
-	//an empty slice, not a nil slice. This will result in the json being []
-	if voteList == nil {
-		voteList = make([]db.Vote, 0)
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, voteList)
-}
-
-// implementation for GET /votes/:id
-// returns a single vote
-func (va *VotesAPI) GetVote(c *gin.Context) {
-
-	//Note go is minimalistic, so we have to get the
-	//id parameter using the Param() function, and then
-	//convert it to an int64 using the strconv package
-	idS := c.Param("id")
-	id64, err := strconv.ParseInt(idS, 10, 32)
-	if err != nil {
-		log.Println("Error converting id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	//Note that ParseInt always returns an int64, so we have to
-	//convert it to an int before we can use it.
-	num := int(id64)
-	var numAsUint uint
-	if num >= 0 {
-		numAsUint = uint(num)
-	} else {
-		log.Println("VoteID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	vote, err := va.db.GetVote(numAsUint)
-	if err != nil {
-		log.Println("Vote not found: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-
-	calls = calls + 1
-	//Git will automatically convert the struct to JSON
-	//and set the content-type header to application/json
-	c.JSON(http.StatusOK, vote)
-}
-
-// implementation for GET /crash
-// This simulates a crash to show some of the benefits of the
-// gin framework
-func (va *VotesAPI) CrashSim(c *gin.Context) {
-	//panic() is go's version of throwing an exception
-	panic("Simulating an unexpected crash")
-}
-
-// implementation for POST /votess
-// adds a new vote
-func (va *VotesAPI) AddVote(c *gin.Context) {
-	var vote db.Vote
-
-	//With HTTP based APIs, a POST request will usually
-	//have a body that contains the data to be added
-	//to the database.  The body is usually JSON, so
-	//we need to bind the JSON to a struct that we
-	//can use in our code.
-	//This framework exposes the raw body via c.Request.Body
-	//but it also provides a helper function ShouldBindJSON()
-	//that will extract the body, convert it to JSON and
-	//bind it to a struct for us.  It will also report an error
-	//if the body is not JSON or if the JSON does not match
-	//the struct we are binding to.
-	if err := c.ShouldBindJSON(&vote); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.AddVote(vote); err != nil {
-		log.Println("Error adding vote: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, vote)
-}
-
-// implementation for PUT /votes
-// Web api standards use PUT for Updates
-func (va *VotesAPI) UpdateVote(c *gin.Context) {
-	var vote db.Vote
-	if err := c.ShouldBindJSON(&vote); err != nil {
-		log.Println("Error binding JSON: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.UpdateVote(vote); err != nil {
-		log.Println("Error updating vote: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.JSON(http.StatusOK, vote)
-}
-
-// implementation for DELETE /votes/:id
-// deletes a vote
-func (va *VotesAPI) DeleteVote(c *gin.Context) {
-	idS := c.Param("id")
-	id64, err := strconv.ParseInt(idS, 10, 32)
-
-	if err != nil {
-		log.Println("Error converting id to int64: ", err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	num := int(id64)
-	var numAsUint uint
-	if num >= 0 {
-		numAsUint = uint(num)
-	} else {
-		log.Println("VoteID needs to be a positive value")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if err := va.db.DeleteVote(numAsUint); err != nil {
-		log.Println("Error deleting vote: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-}
-
-// implementation for DELETE /votes
-// deletes all votes
-func (va *VotesAPI) DeleteAllVotes(c *gin.Context) {
-
-	if err := va.db.DeleteAllVotes(); err != nil {
-		log.Println("Error deleting all votes: ", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-
-	calls = calls + 1
-	c.Status(http.StatusOK)
-}
-
-// implementation for GET /votes/health
-// returns a "health" record indicating that the votes API is functioning properly
-
-func (va *VotesAPI) GetHealthData(c *gin.Context){
-
-	healthData, err := va.db.GetHealthData(bootTime, calls+1)
-	if err != nil {
-		log.Println("Error Getting health data: ", err)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-	
-	calls = calls + 1
-	c.JSON(http.StatusOK, healthData)
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"drexel.edu/votes/db"
+	"github.com/gin-gonic/gin"
+)
+
+// The api package creates and maintains a reference to the data handler
+// this is a good design practice
+type VotesAPI struct {
+	db       VoteStore
+	features Features
+	clock    Clock
+	//wsOrigins are the origins GET /ws takes connections from, see
+	//ws.go
+	wsOrigins wsOrigins
+	gateway   http.Handler
+}
+
+// Features holds the feature flags for the optional capabilities of the
+// service.  The flags are read once at startup in main.go and passed in
+// here so that route registration and middleware wiring can be made
+// conditional without changing any code
+type Features struct {
+	SSE        bool
+	Auth       bool
+	Metrics    bool
+	Crash      bool
+	StrictIDs  bool
+	StrictJSON bool
+	AutoIDs    bool
+	TestClock  bool
+	Gateway    bool
+}
+
+// SourceHeader is the header a client can name the channel a vote came
+// in through in, if the vote itself doesn't say
+const SourceHeader = "X-Vote-Source"
+
+var bootTime time.Time
+var calls uint
+
+// New returns an api backed by redis, configured from the environment
+func New(features Features) (*VotesAPI, error) {
+	dbHandler, err := db.NewVoteList()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithStore(dbHandler, features), nil
+}
+
+// Features returns the feature flags the api was created with
+func (va *VotesAPI) Features() Features {
+	return va.features
+}
+
+type VoteRequest struct {
+	VoteID		uint	`json:"VoteID"`
+	VoterID		uint	`json:"VoterID"`
+	PollID		uint	`json:"PollID"`
+	VoteValue	uint	`json:"VoteValue"`
+}
+
+// implementation for GET /votes
+// returns all votes, a page of them with ?limit and ?offset, or with
+// ?cursor for lists too long to read in one go, only the votes from one
+// channel with ?source, only the votes of one poll with ?pollId
+func (va *VotesAPI) ListAllVotes(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+	cursor, cursored, ok := parseCursor(c, page)
+	if !ok {
+		return
+	}
+
+	pollIdS, byPoll := c.GetQuery("pollId")
+	var pollId uint
+	if byPoll {
+		if cursored {
+			log.Println("Both cursor and pollId given")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "cursor can't be used with pollId"})
+			return
+		}
+		id64, err := strconv.ParseInt(pollIdS, 10, 32)
+		if err != nil || id64 < 0 {
+			log.Println("Error converting pollId to int64: ", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "pollId must be a non-negative integer"})
+			return
+		}
+		pollId = uint(id64)
+	}
+
+	source := c.Query("source")
+	if source != "" {
+		if err := db.ValidateSource(source); err != nil {
+			log.Println("Error filtering votes: ", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var voteList []db.Vote
+	var next uint64
+	var err error
+	switch {
+	case cursored:
+		voteList, next, err = va.db.GetVotesPage(cursor, page.Limit)
+	case byPoll:
+		voteList, err = va.db.GetVotesByPoll(pollId)
+	default:
+		voteList, err = va.db.GetAllVotes()
+	}
+	if err != nil {
+		log.Println("Error Getting All Votes: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if source != "" {
+		filtered := make([]db.Vote, 0, len(voteList))
+		for _, vote := range voteList {
+			if vote.Source == source {
+				filtered = append(filtered, vote)
+			}
+		}
+		voteList = filtered
+	}
+	//Note that the database returns a nil slice if there are no items
+	//in the database.  We need to convert this to an empty slice
+	//so that the JSON marshalling works correctly.  We want to return
+	//an empty slice, not a nil slice. This will result in the json being []
+	if voteList == nil {
+		voteList = make([]db.Vote, 0)
+	}
+
+	calls = calls + 1
+	if cursored {
+		writeCursorPage(c, voteList, cursorPage{Limit: page.Limit, Cursor: encodeCursor(cursor), NextCursor: encodeCursor(next)}, envelope)
+		return
+	}
+	writeList(c, voteList, page, envelope)
+}
+
+// implementation for GET /polls/:id/votes
+// returns every vote cast on a poll, a page of them with ?limit and
+// ?offset, so that clients don't have to filter GET /votes themselves
+func (va *VotesAPI) GetPollVotes(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("PollID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voteList, err := va.db.GetPollVotes(numAsUint)
+	if err != nil {
+		log.Println("Error getting votes of poll: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	writeList(c, voteList, page, envelope)
+}
+
+// implementation for GET /votes/orphans
+// lists the votes whose voter or poll no longer exists, without deleting
+// them whatever ORPHAN_SWEEP_ACTION is
+func (va *VotesAPI) GetOrphanVotes(c *gin.Context) {
+
+	sweep, err := va.db.FindOrphans()
+	if err != nil {
+		log.Println("Error finding orphaned votes: ", err)
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, sweep)
+}
+
+// implementation for GET /votes/byvoter/:voterId
+// returns every vote a voter has cast, a page of them with ?limit and
+// ?offset, for auditing a voter's ballot history
+func (va *VotesAPI) GetVotesByVoter(c *gin.Context) {
+
+	page, envelope, ok := parseListPage(c)
+	if !ok {
+		return
+	}
+
+	idS := c.Param("voterId")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+	if err != nil {
+		log.Println("Error converting voterId to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	voteList, err := va.db.GetVotesByVoter(numAsUint)
+	if err != nil {
+		log.Println("Error getting votes of voter: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	writeList(c, voteList, page, envelope)
+}
+
+// implementation for POST /votes/byvoter/:voterId/anonymize
+// takes a voter off every vote they cast, for the voters API erasing
+// them, and returns how many votes were anonymized
+func (va *VotesAPI) AnonymizeVoterVotes(c *gin.Context) {
+
+	idS := c.Param("voterId")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+	if err != nil {
+		log.Println("Error converting voterId to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num > 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoterID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	erasure, err := va.db.AnonymizeVoterVotes(numAsUint)
+	if err != nil {
+		log.Println("Error anonymizing votes of voter: ", err)
+		if errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, erasure)
+}
+
+// implementation for GET /votes/:id
+// returns a single vote
+func (va *VotesAPI) GetVote(c *gin.Context) {
+
+	//Note go is minimalistic, so we have to get the
+	//id parameter using the Param() function, and then
+	//convert it to an int64 using the strconv package
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	//Note that ParseInt always returns an int64, so we have to
+	//convert it to an int before we can use it.
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoteID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	vote, err := va.db.GetVote(numAsUint)
+	if err != nil {
+		log.Println("Error getting vote: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	setETag(c, vote.Version)
+	//Git will automatically convert the struct to JSON
+	//and set the content-type header to application/json
+	c.JSON(http.StatusOK, vote)
+}
+
+// implementation for GET /crash
+// This simulates a crash to show some of the benefits of the
+// gin framework
+func (va *VotesAPI) CrashSim(c *gin.Context) {
+	//panic() is go's version of throwing an exception
+	panic("Simulating an unexpected crash")
+}
+
+// implementation for POST /votess
+// adds a new vote
+func (va *VotesAPI) AddVote(c *gin.Context) {
+	var vote db.Vote
+
+	//With HTTP based APIs, a POST request will usually
+	//have a body that contains the data to be added
+	//to the database.  The body is usually JSON, so
+	//we need to bind the JSON to a struct that we
+	//can use in our code.
+	//This framework exposes the raw body via c.Request.Body
+	//but it also provides a helper function ShouldBindJSON()
+	//that will extract the body, convert it to JSON and
+	//bind it to a struct for us.  It will also report an error
+	//if the body is not JSON or if the JSON does not match
+	//the struct we are binding to.
+	if !va.bindJSON(c, &vote) {
+		return
+	}
+	//A vote sent without a VoteID is given the next free one
+	newID := vote.VoteID == 0 && va.features.AutoIDs
+	if (!newID && !va.validBodyID(c, "VoteID", vote.VoteID)) ||
+		!va.validBodyID(c, "VoterID", vote.VoterID) ||
+		!va.validBodyID(c, "PollID", vote.PollID) {
+		return
+	}
+	//Clients that can't change the body they send, such as a kiosk, can
+	//say where the vote came from in a header instead
+	if vote.Source == "" {
+		vote.Source = c.GetHeader(SourceHeader)
+	}
+	if vote.Source == "" {
+		vote.Source = db.SourceUnknown
+	}
+
+	now, ok := va.now(c)
+	if !ok {
+		return
+	}
+
+	passcode := c.GetHeader(db.PasscodeHeader)
+	invitation := c.GetHeader(db.InvitationHeader)
+	add := func() error { return va.db.AddVote(vote, passcode, invitation, now) }
+	if newID {
+		add = func() error {
+			id, err := va.db.AddVoteNewID(vote, passcode, invitation, now)
+			vote.VoteID = id
+			return err
+		}
+	}
+	if err := add(); err != nil {
+		log.Println("Error adding vote: ", err)
+		if errors.Is(err, db.ErrVotingPaused) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "voting paused"})
+			return
+		}
+		//The voter or poll lookup timed out, the error says which
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		//The vote refers to a voter or poll that does not exist
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidPasscode) || errors.Is(err, db.ErrInvalidInvitation) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if abortInvalidVoteValue(c, err) {
+			return
+		}
+		if errors.Is(err, db.ErrInvalidSource) || errors.Is(err, db.ErrInvalidRankings) ||
+			errors.Is(err, db.ErrInvalidSelections) || errors.Is(err, db.ErrInvalidWriteIn) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrVoteRateExceeded) {
+			c.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrAlreadyExists) || errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	//Send back the vote as it was stored, with the fields the db layer
+	//fills in, rather than what was sent.  The vote was created either way
+	stored, err := va.db.GetVote(vote.VoteID)
+	if err != nil {
+		log.Println("Error reading back vote: ", err)
+		stored = vote
+	}
+
+	//The receipt is what the voter keeps to check their vote with later,
+	//a vote that was stored is not turned away for want of one
+	receipted := db.ReceiptedVote{Vote: stored}
+	receipt, err := va.db.IssueReceipt(stored, now)
+	if err == nil {
+		receipted.Receipt = &receipt
+	} else if !errors.Is(err, db.ErrReceiptsOff) {
+		log.Println("Error issuing receipt: ", err)
+	}
+
+	calls = calls + 1
+	c.Header("Location", "/votes/"+strconv.FormatUint(uint64(stored.VoteID), 10))
+	c.JSON(http.StatusCreated, receipted)
+}
+
+// implementation for POST /votes/verify-receipt
+// checks that a receipt from POST /votes is genuine, and whether the vote
+// is still recorded as it was when it was cast.  The body is the receipt
+func (va *VotesAPI) VerifyReceipt(c *gin.Context) {
+	var receipt db.VoteReceipt
+	if !va.bindJSON(c, &receipt) {
+		return
+	}
+
+	check, err := va.db.VerifyReceipt(receipt)
+	if err != nil {
+		log.Println("Error verifying receipt: ", err)
+		if errors.Is(err, db.ErrInvalidReceipt) {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrReceiptsOff) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, check)
+}
+
+// implementation for PUT /votes
+// Web api standards use PUT for Updates
+func (va *VotesAPI) UpdateVote(c *gin.Context) {
+	var vote db.Vote
+	if !va.bindJSON(c, &vote) {
+		return
+	}
+	if !va.validBodyID(c, "VoteID", vote.VoteID) ||
+		!va.validBodyID(c, "VoterID", vote.VoterID) ||
+		!va.validBodyID(c, "PollID", vote.PollID) {
+		return
+	}
+
+	//With If-Match the write is also turned away if the vote changes
+	//after this check, unless the body sends a Version of its own
+	version, ok := va.ifMatchVote(c, vote.VoteID)
+	if !ok {
+		return
+	}
+	if vote.Version == 0 {
+		vote.Version = version
+	}
+
+	now, ok := va.now(c)
+	if !ok {
+		return
+	}
+
+	err := va.db.UpdateVote(vote, c.GetHeader(db.PasscodeHeader), c.GetHeader(db.InvitationHeader), now)
+	if err != nil {
+		log.Println("Error updating vote: ", err)
+		if errors.Is(err, db.ErrVotingPaused) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "voting paused"})
+			return
+		}
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidPasscode) || errors.Is(err, db.ErrInvalidInvitation) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if abortInvalidVoteValue(c, err) {
+			return
+		}
+		if errors.Is(err, db.ErrInvalidSource) || errors.Is(err, db.ErrInvalidRankings) ||
+			errors.Is(err, db.ErrInvalidSelections) || errors.Is(err, db.ErrInvalidWriteIn) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) {
+			c.AbortWithStatusJSON(staleStatus(c), gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	//Send back the Version the vote has now, for the next update
+	if updated, err := va.db.GetVote(vote.VoteID); err == nil {
+		vote = updated
+	}
+
+	calls = calls + 1
+	setETag(c, vote.Version)
+	c.JSON(http.StatusOK, vote)
+}
+
+// implementation for PATCH /votes/:id
+// updates only the fields that are in the body and returns just the
+// fields that changed, as {"changed": {"Field": {"from": ..., "to": ...}}}
+func (va *VotesAPI) PatchVote(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoteID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	//We need the record as it is before the write so that we can
+	//tell what the patch changed
+	before, err := va.db.GetVote(numAsUint)
+	if err != nil {
+		log.Println("Error getting vote: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	//Binding the body into a copy of the current record only
+	//overwrites the fields that are in the body
+	var after db.Vote
+	if err := deepCopy(before, &after); err != nil {
+		log.Println("Error copying vote: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !va.bindJSON(c, &after) {
+		return
+	}
+	if !va.validBodyID(c, "VoterID", after.VoterID) ||
+		!va.validBodyID(c, "PollID", after.PollID) {
+		return
+	}
+	after.VoteID = before.VoteID
+
+	now, ok := va.now(c)
+	if !ok {
+		return
+	}
+
+	if err := va.db.UpdateVote(after, c.GetHeader(db.PasscodeHeader), c.GetHeader(db.InvitationHeader), now); err != nil {
+		log.Println("Error patching vote: ", err)
+		if errors.Is(err, db.ErrVotingPaused) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "voting paused"})
+			return
+		}
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidPasscode) || errors.Is(err, db.ErrInvalidInvitation) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if abortInvalidVoteValue(c, err) {
+			return
+		}
+		if errors.Is(err, db.ErrInvalidSource) || errors.Is(err, db.ErrInvalidRankings) ||
+			errors.Is(err, db.ErrInvalidSelections) || errors.Is(err, db.ErrInvalidWriteIn) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrVersionMismatch) || errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, gin.H{"changed": changedFields(before, after)})
+}
+
+// implementation for DELETE /votes/:id
+// deletes a vote
+func (va *VotesAPI) DeleteVote(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoteID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := va.ifMatchVote(c, numAsUint); !ok {
+		return
+	}
+
+	if err := va.db.DeleteVote(numAsUint); err != nil {
+		log.Println("Error deleting vote: ", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+}
+
+// implementation for PUT /votes/byballot
+// changes the option of the vote a voter already cast in a poll that is
+// still open, in one write, and returns the vote.  The body is the vote
+// without its VoteID
+func (va *VotesAPI) RecastVote(c *gin.Context) {
+	var vote db.Vote
+	if !va.bindJSON(c, &vote) {
+		return
+	}
+	if !va.validBodyID(c, "VoterID", vote.VoterID) ||
+		!va.validBodyID(c, "PollID", vote.PollID) {
+		return
+	}
+	if vote.Source == "" {
+		vote.Source = c.GetHeader(SourceHeader)
+	}
+
+	now, ok := va.now(c)
+	if !ok {
+		return
+	}
+
+	//The vote being recast is only known by its voter and poll, so it is
+	//read here for the audit log rather than by Audited
+	var before *db.Vote
+	if votes, err := va.db.GetVotesByVoter(vote.VoterID); err == nil {
+		for i := range votes {
+			if votes[i].PollID == vote.PollID {
+				before = &votes[i]
+				break
+			}
+		}
+	}
+
+	recast, err := va.db.RecastVote(vote, c.GetHeader(db.PasscodeHeader), now)
+	if err != nil {
+		log.Println("Error recasting vote: ", err)
+		if errors.Is(err, db.ErrVotingPaused) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "voting paused"})
+			return
+		}
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotStorable) {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrInvalidPasscode) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if abortInvalidVoteValue(c, err) {
+			return
+		}
+		if errors.Is(err, db.ErrInvalidSource) || errors.Is(err, db.ErrInvalidRankings) ||
+			errors.Is(err, db.ErrInvalidSelections) || errors.Is(err, db.ErrInvalidWriteIn) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if before != nil && before.VoteID == recast.VoteID {
+		va.auditVote(c, recast.VoteID, before, &recast)
+	}
+
+	calls = calls + 1
+	setETag(c, recast.Version)
+	c.JSON(http.StatusOK, recast)
+}
+
+// implementation for DELETE /votes/byballot?voterId=&pollId=
+// withdraws a voter's vote in a poll that is still open, and takes the
+// poll out of their VoteHistory, returning the votes withdrawn
+func (va *VotesAPI) RetractVote(c *gin.Context) {
+
+	ids := make(map[string]uint, 2)
+	for _, name := range []string{"voterId", "pollId"} {
+		id64, err := strconv.ParseInt(c.Query(name), 10, 32)
+		if err != nil || id64 < 0 {
+			log.Println("Error converting "+name+" to int64: ", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": name + " must be a non-negative integer"})
+			return
+		}
+		ids[name] = uint(id64)
+	}
+
+	now, ok := va.now(c)
+	if !ok {
+		return
+	}
+
+	votes, err := va.db.RetractVote(ids["voterId"], ids["pollId"], now)
+	if err != nil {
+		log.Println("Error retracting vote: ", err)
+		if errors.Is(err, db.ErrVotingPaused) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "voting paused"})
+			return
+		}
+		if errors.Is(err, db.ErrDependencyUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	for i := range votes {
+		va.auditVote(c, votes[i].VoteID, &votes[i], nil)
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, votes)
+}
+
+// implementation for POST /votes/:id/restore
+// restores a deleted vote from the trash
+func (va *VotesAPI) RestoreVote(c *gin.Context) {
+	idS := c.Param("id")
+	id64, err := strconv.ParseInt(idS, 10, 32)
+
+	if err != nil {
+		log.Println("Error converting id to int64: ", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	num := int(id64)
+	var numAsUint uint
+	if num >= 0 {
+		numAsUint = uint(num)
+	} else {
+		log.Println("VoteID needs to be a positive value")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	vote, err := va.db.RestoreVote(numAsUint)
+	if err != nil {
+		log.Println("Error restoring vote: ", err)
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, db.ErrAlreadyExists) || errors.Is(err, db.ErrConflict) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, vote)
+}
+
+// implementation for DELETE /votes
+// deletes all votes
+func (va *VotesAPI) DeleteAllVotes(c *gin.Context) {
+
+	if err := va.db.DeleteAllVotes(); err != nil {
+		log.Println("Error deleting all votes: ", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	calls = calls + 1
+	c.Status(http.StatusOK)
+}
+
+// implementation for GET /schema
+// returns the field list and model version of the Vote model so that
+// clients can detect when the model has changed
+func (va *VotesAPI) GetSchema(c *gin.Context) {
+
+	calls = calls + 1
+	c.JSON(http.StatusOK, db.VoteSchema())
+}
+
+// implementation for GET /votes/health
+// returns a "health" record indicating that the votes API is functioning properly
+
+func (va *VotesAPI) GetHealthData(c *gin.Context){
+
+	healthData, err := va.db.GetHealthData(bootTime, calls+1)
+	if err != nil {
+		log.Println("Error Getting health data: ", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+	
+	calls = calls + 1
+	c.JSON(http.StatusOK, healthData)
 }
\ No newline at end of file
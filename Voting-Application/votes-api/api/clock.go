@@ -0,0 +1,55 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestNowHeader is the header integration tests can send an RFC 3339 time
+// in to handle the request as if it were that time, when the TestClock
+// feature is on
+const TestNowHeader = "X-Test-Now"
+
+// Clock tells the api what time it is.  The api uses the system clock
+// unless it is given another one with SetClock
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock replaces the clock the api reads the time from
+func (va *VotesAPI) SetClock(clock Clock) {
+	va.clock = clock
+}
+
+// now returns the time to handle the request at.  When the TestClock
+// feature is on, a time in the X-Test-Now header overrides the clock for
+// this request, so tests can check closing times and rate windows
+// without sleeping.  When it is off the header is ignored entirely.  If
+// the header can't be parsed the request is aborted with a 400 and ok is
+// false
+func (va *VotesAPI) now(c *gin.Context) (now time.Time, ok bool) {
+	if !va.features.TestClock {
+		return va.clock.Now(), true
+	}
+
+	nowS := c.GetHeader(TestNowHeader)
+	if nowS == "" {
+		return va.clock.Now(), true
+	}
+	now, err := time.Parse(time.RFC3339, nowS)
+	if err != nil {
+		log.Println("Error parsing ", TestNowHeader, ": ", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": TestNowHeader + " must be an RFC 3339 time"})
+		return time.Time{}, false
+	}
+	return now, true
+}
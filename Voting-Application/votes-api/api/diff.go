@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// fieldChange describes how one field changed in an update
+type fieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// changedFields compares the exported top level fields of before and
+// after, which must be the same struct type, and returns the ones that
+// differ keyed by field name.  Links are generated by the db layer on
+// every write so they are never reported
+func changedFields(before, after any) map[string]fieldChange {
+	changed := make(map[string]fieldChange)
+
+	b := reflect.ValueOf(before)
+	a := reflect.ValueOf(after)
+	for i := 0; i < b.NumField(); i++ {
+		field := b.Type().Field(i)
+		if !field.IsExported() || field.Name == "Links" {
+			continue
+		}
+		from := b.Field(i).Interface()
+		to := a.Field(i).Interface()
+		if !reflect.DeepEqual(from, to) {
+			changed[field.Name] = fieldChange{From: from, To: to}
+		}
+	}
+
+	return changed
+}
+
+// deepCopy copies src into dst by way of JSON, so that slices in the copy
+// do not share memory with the original
+func deepCopy(src any, dst any) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"drexel.edu/votes/db"
+	"drexel.edu/votes/pb"
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// newGateway returns the REST routes grpc-gateway generates from
+// proto/votes.proto and proto/votes_gateway.yaml.  They call the gRPC
+// service in process, so they run the same code as a gRPC call.  That is
+// not the code behind the original routes, parity_test.go checks the two
+// behave the same.  The JSON has the proto's field names, such as
+// vote_id, with every field sent even when it is empty
+func (va *VotesAPI) newGateway() http.Handler {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeader),
+		runtime.WithErrorHandler(gatewayError),
+		runtime.WithForwardResponseOption(gatewayCreated),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		}),
+	)
+	//Registering a server in process only fails if it is nil
+	if err := pb.RegisterVotesAPIHandlerServer(context.Background(), mux, &votesServer{va: va}); err != nil {
+		log.Println("Error registering the gateway: ", err)
+	}
+	return mux
+}
+
+// gatewayHeader passes X-Actor, X-Vote-Source, X-Poll-Passcode,
+// X-Poll-Invitation and Idempotency-Key on to the gRPC service as its
+// metadata, along with the headers grpc-gateway passes on by default
+func gatewayHeader(key string) (string, bool) {
+	for _, header := range []string{ActorHeader, SourceHeader, db.PasscodeHeader, db.InvitationHeader, IdempotencyKeyHeader} {
+		if strings.EqualFold(key, header) {
+			return strings.ToLower(header), true
+		}
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayError sends the error of a call as {"error": message} with the
+// HTTP status of its gRPC code, which Problems turns into a problem like
+// the error of any other route.  FailedPrecondition, a stale version or a
+// locked field, is the 409 the REST routes send rather than
+// grpc-gateway's 400, and a vote value that is not an option the 422
+func gatewayError(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	s := status.Convert(err)
+	code := runtime.HTTPStatusFromCode(s.Code())
+	body := gin.H{"error": s.Message()}
+	switch s.Code() {
+	case codes.FailedPrecondition:
+		code = http.StatusConflict
+	case codes.InvalidArgument:
+		//A vote value that is not an option, see invalidVoteValueStatus
+		for _, detail := range s.Details() {
+			if bad, ok := detail.(*errdetails.BadRequest); ok {
+				code = http.StatusUnprocessableEntity
+				for _, violation := range bad.GetFieldViolations() {
+					body[violation.GetField()] = violation.GetDescription()
+				}
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+// gatewayCreated sends 201 for a call to AddVote, as POST /votes does,
+// rather than grpc-gateway's 200
+func gatewayCreated(ctx context.Context, w http.ResponseWriter, m proto.Message) error {
+	if method, ok := runtime.RPCMethod(ctx); ok && strings.HasSuffix(method, "/AddVote") {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return nil
+}
+
+// implementation for /v1/*
+// the REST routes generated from the protos, see newGateway
+func (va *VotesAPI) Gateway(c *gin.Context) {
+	va.gateway.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,491 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"drexel.edu/votes/db"
+	"drexel.edu/votes/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ActorMetadata is the metadata key a gRPC client says who is making a
+// call in, for the audit log, the same as the X-Actor header.  The vote's
+// source, passcode and invitation are sent in the metadata named after
+// their headers, x-vote-source, x-poll-passcode and x-poll-invitation
+const ActorMetadata = "x-actor"
+
+// votesServer is the VotesAPI gRPC service.  It reads and writes the same
+// store as the REST routes, so a vote changed over one is changed over
+// the other
+type votesServer struct {
+	pb.UnimplementedVotesAPIServer
+	va *VotesAPI
+}
+
+// GRPCServer returns a gRPC server with the VotesAPI service registered,
+// backed by the same store as va.  main.go serves it on a second port
+func (va *VotesAPI) GRPCServer() *grpc.Server {
+	server := grpc.NewServer()
+	pb.RegisterVotesAPIServer(server, &votesServer{va: va})
+	return server
+}
+
+// grpcError turns an error from the store into a gRPC status, the same way
+// the REST routes turn it into an HTTP status
+func grpcError(err error) error {
+	var invalid *db.InvalidVoteValueError
+	switch {
+	case errors.As(err, &invalid):
+		return invalidVoteValueStatus(invalid)
+	case errors.Is(err, db.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrVotingPaused), errors.Is(err, db.ErrDependencyUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, db.ErrInvalidPasscode), errors.Is(err, db.ErrInvalidInvitation):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, db.ErrNotStorable), errors.Is(err, db.ErrInvalidVoteValue),
+		errors.Is(err, db.ErrInvalidSource), errors.Is(err, db.ErrInvalidRankings),
+		errors.Is(err, db.ErrInvalidSelections), errors.Is(err, db.ErrInvalidWriteIn):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrVoteRateExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, db.ErrNeedsRedis):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, db.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, db.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, db.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// invalidVoteValueStatus is the InvalidArgument status for a vote whose
+// VoteValue is not one of its poll's options.  The options that are go in a
+// BadRequest detail, for vote_value, which is how gatewayError tells it
+// apart and sends a 422 as POST /votes does
+func invalidVoteValueStatus(invalid *db.InvalidVoteValueError) error {
+	options := make([]string, len(invalid.ValidOptions))
+	for i, option := range invalid.ValidOptions {
+		options[i] = strconv.FormatUint(uint64(option), 10)
+	}
+	s, err := status.New(codes.InvalidArgument, invalid.Error()).WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       "vote_value",
+			Description: "valid options are " + strings.Join(options, ", "),
+		}},
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, invalid.Error())
+	}
+	return s.Err()
+}
+
+// validID checks an id sent in a call the same way validBodyID checks one
+// sent in a body
+func (va *VotesAPI) validID(field string, id uint32) error {
+	if !va.features.StrictIDs {
+		return nil
+	}
+	if id == 0 || id > maxID {
+		return status.Errorf(codes.InvalidArgument, "%s must be between 1 and %d", field, maxID)
+	}
+	return nil
+}
+
+// metadataValue returns the first value of key in the metadata of the
+// call ctx, "" if there is none
+func metadataValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// grpcActor returns who is making the call ctx, for the audit log.  A
+// call through the gateway has the client's address in x-forwarded-for
+func grpcActor(ctx context.Context) string {
+	if a := metadataValue(ctx, ActorMetadata); a != "" {
+		return a
+	}
+	if a := metadataValue(ctx, "x-forwarded-for"); a != "" {
+		return a
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// auditCall records the change the call ctx made to the vote with id in
+// the audit log, as Audited does for the REST routes
+func (va *VotesAPI) auditCall(ctx context.Context, id uint, before *db.Vote, after *db.Vote) {
+	if before == nil && after == nil {
+		return
+	}
+	entry, err := db.NewAuditEntry(db.AuditVote, id, before, after)
+	if err != nil {
+		log.Println("Error making audit entry for vote ", id, ": ", err)
+		return
+	}
+	entry.Actor = grpcActor(ctx)
+	entry.At = time.Now()
+	if err := va.db.RecordAudit(entry); err != nil {
+		log.Println("Error recording audit entry for vote ", id, ": ", err)
+	}
+}
+
+// toUint32s is ids as the gRPC message field
+func toUint32s(ids []uint) []uint32 {
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, uint32(id))
+	}
+	return out
+}
+
+// fromUint32s is the gRPC message field ids as a []uint
+func fromUint32s(ids []uint32) []uint {
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, uint(id))
+	}
+	return out
+}
+
+// toPBVote is vote as the gRPC message
+func toPBVote(vote db.Vote) *pb.Vote {
+	return &pb.Vote{
+		VoteId:      uint32(vote.VoteID),
+		VoterId:     uint32(vote.VoterID),
+		PollId:      uint32(vote.PollID),
+		VoteValue:   uint32(vote.VoteValue),
+		Source:      vote.Source,
+		Version:     uint32(vote.Version),
+		Rankings:    toUint32s(vote.Rankings),
+		VoteValues:  toUint32s(vote.VoteValues),
+		WriteInText: vote.WriteInText,
+		VoterHash:   vote.VoterHash,
+	}
+}
+
+// toPBVotes is voteList as the gRPC message, with next_cursor next
+func toPBVotes(voteList []db.Vote, next uint64) *pb.ListVotesResponse {
+	resp := &pb.ListVotesResponse{Votes: make([]*pb.Vote, 0, len(voteList)), NextCursor: next}
+	for _, vote := range voteList {
+		resp.Votes = append(resp.Votes, toPBVote(vote))
+	}
+	return resp
+}
+
+// fromPBVote is the gRPC message vote as a db.Vote, checked the way a vote
+// sent to the REST routes is.  The voter_hash is never taken from a client
+func (va *VotesAPI) fromPBVote(vote *pb.Vote) (db.Vote, error) {
+	if vote.VoteValue == 0 && vote.WriteInText == "" {
+		return db.Vote{}, status.Error(codes.InvalidArgument, "vote_value is required without write_in_text")
+	}
+	if err := va.validID("voter_id", vote.VoterId); err != nil {
+		return db.Vote{}, err
+	}
+	if err := va.validID("poll_id", vote.PollId); err != nil {
+		return db.Vote{}, err
+	}
+	return db.Vote{
+		VoteID:      uint(vote.VoteId),
+		VoterID:     uint(vote.VoterId),
+		PollID:      uint(vote.PollId),
+		VoteValue:   uint(vote.VoteValue),
+		VoteValues:  fromUint32s(vote.VoteValues),
+		Rankings:    fromUint32s(vote.Rankings),
+		WriteInText: vote.WriteInText,
+		Source:      vote.Source,
+		Version:     uint(vote.Version),
+	}, nil
+}
+
+func (s *votesServer) ListVotes(ctx context.Context, req *pb.ListVotesRequest) (*pb.ListVotesResponse, error) {
+	voteList, next, err := s.va.db.GetVotesPage(req.Cursor, int(req.Limit))
+	if err != nil {
+		log.Println("Error Getting Votes Page: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	return toPBVotes(voteList, next), nil
+}
+
+func (s *votesServer) GetVote(ctx context.Context, req *pb.GetVoteRequest) (*pb.Vote, error) {
+	vote, err := s.va.db.GetVote(uint(req.VoteId))
+	if err != nil {
+		log.Println("Error getting vote: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	return toPBVote(vote), nil
+}
+
+// AddVote adds vote, a vote sent without a vote_id is given the next free
+// one as with POST /votes.  No receipt is issued, receipts are only sent
+// back over REST
+// AddVote adds vote as POST /votes does, with its receipt while receipts
+// are on.  A call with an idempotency-key in its metadata is handled as
+// Idempotent handles a request with the header: a retry with the same key
+// and vote is sent the vote added the first time, with
+// idempotent-replayed set in the header metadata
+func (s *votesServer) AddVote(ctx context.Context, req *pb.Vote) (*pb.Vote, error) {
+	key := metadataValue(ctx, IdempotencyKeyHeader)
+	if key == "" {
+		return s.addVote(ctx, req)
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		log.Println("Idempotency-Key too long: ", len(key))
+		return nil, status.Error(codes.InvalidArgument, "idempotency-key must be at most 255 characters")
+	}
+
+	//The vote stands in for the body REST hashes
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	sum := sha256.Sum256(data)
+	requestHash := hex.EncodeToString(sum[:])
+
+	stored, err := s.va.db.ClaimIdempotencyKey(key, requestHash)
+	if err != nil {
+		log.Println("Error claiming Idempotency-Key: ", err)
+		switch {
+		case errors.Is(err, db.ErrIdempotencyKeyReused):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, db.ErrIdempotencyInFlight):
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, grpcError(err)
+	}
+	if stored != nil {
+		var vote pb.Vote
+		if err := protojson.Unmarshal(stored.Body, &vote); err != nil {
+			log.Println("Error reading kept response: ", err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err := grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(IdempotentReplayedHeader), "true")); err != nil {
+			log.Println("Error setting header: ", err)
+		}
+		return &vote, nil
+	}
+
+	vote, err := s.addVote(ctx, req)
+	if err != nil {
+		if err := s.va.db.ReleaseIdempotencyKey(key); err != nil {
+			log.Println("Error releasing Idempotency-Key: ", err)
+		}
+		return nil, err
+	}
+	body, err := protojson.Marshal(vote)
+	if err == nil {
+		err = s.va.db.CompleteIdempotencyKey(key, db.IdempotentResponse{
+			RequestHash: requestHash,
+			Status:      http.StatusCreated,
+			Body:        body,
+		})
+	}
+	if err != nil {
+		//The vote was added, a retry will be told the key is still
+		//being handled until it expires
+		log.Println("Error keeping Idempotency-Key response: ", err)
+	}
+	return vote, nil
+}
+
+// addVote is AddVote without the idempotency key
+func (s *votesServer) addVote(ctx context.Context, req *pb.Vote) (*pb.Vote, error) {
+	vote, err := s.va.fromPBVote(req)
+	if err != nil {
+		return nil, err
+	}
+	newID := vote.VoteID == 0 && s.va.features.AutoIDs
+	if !newID {
+		if err := s.va.validID("vote_id", req.VoteId); err != nil {
+			return nil, err
+		}
+	}
+	if vote.Source == "" {
+		vote.Source = metadataValue(ctx, SourceHeader)
+	}
+	if vote.Source == "" {
+		vote.Source = db.SourceUnknown
+	}
+
+	now := s.va.clock.Now()
+	passcode := metadataValue(ctx, db.PasscodeHeader)
+	invitation := metadataValue(ctx, db.InvitationHeader)
+	if newID {
+		vote.VoteID, err = s.va.db.AddVoteNewID(vote, passcode, invitation, now)
+	} else {
+		err = s.va.db.AddVote(vote, passcode, invitation, now)
+	}
+	if err != nil {
+		log.Println("Error adding vote: ", err)
+		//The vote refers to a voter or poll that does not exist
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, grpcError(err)
+	}
+
+	stored, err := s.va.db.GetVote(vote.VoteID)
+	if err != nil {
+		log.Println("Error reading back vote: ", err)
+		stored = vote
+	}
+
+	//As over REST, a vote that was stored is not turned away for want of
+	//a receipt
+	resp := toPBVote(stored)
+	receipt, err := s.va.db.IssueReceipt(stored, now)
+	if err == nil {
+		resp.Receipt = &pb.VoteReceipt{
+			VoteId:    uint32(receipt.VoteID),
+			PollId:    uint32(receipt.PollID),
+			IssuedAt:  receipt.IssuedAt.Format(time.RFC3339Nano),
+			Signature: receipt.Signature,
+			Seal:      receipt.Seal,
+		}
+	} else if !errors.Is(err, db.ErrReceiptsOff) {
+		log.Println("Error issuing receipt: ", err)
+	}
+
+	calls = calls + 1
+	s.va.auditCall(ctx, stored.VoteID, nil, &stored)
+	return resp, nil
+}
+
+// UpdateVote replaces the vote with vote_id, as PUT /votes.  With a version
+// the update is turned away if the vote has changed since, as with
+// If-Match.  The poll's passcode and the voter's invitation are read from
+// the metadata as for AddVote
+func (s *votesServer) UpdateVote(ctx context.Context, req *pb.Vote) (*pb.Vote, error) {
+	if err := s.va.validID("vote_id", req.VoteId); err != nil {
+		return nil, err
+	}
+	vote, err := s.va.fromPBVote(req)
+	if err != nil {
+		return nil, err
+	}
+
+	before, _ := s.va.voteSnapshot(vote.VoteID)
+	passcode := metadataValue(ctx, db.PasscodeHeader)
+	invitation := metadataValue(ctx, db.InvitationHeader)
+	if err := s.va.db.UpdateVote(vote, passcode, invitation, s.va.clock.Now()); err != nil {
+		log.Println("Error updating vote: ", err)
+		return nil, grpcError(err)
+	}
+	updated, err := s.va.db.GetVote(vote.VoteID)
+	if err != nil {
+		log.Println("Error reading back vote: ", err)
+		updated = vote
+	}
+
+	calls = calls + 1
+	s.va.auditCall(ctx, vote.VoteID, before, &updated)
+	return toPBVote(updated), nil
+}
+
+func (s *votesServer) DeleteVote(ctx context.Context, req *pb.DeleteVoteRequest) (*pb.DeleteVoteResponse, error) {
+	id := uint(req.VoteId)
+	before, _ := s.va.voteSnapshot(id)
+	if err := s.va.db.DeleteVote(id); err != nil {
+		log.Println("Error deleting vote: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	s.va.auditCall(ctx, id, before, nil)
+	return &pb.DeleteVoteResponse{}, nil
+}
+
+// GetVotesByPoll returns the votes of the poll, as GET /polls/:id/votes, a
+// poll that does not exist is NotFound
+func (s *votesServer) GetVotesByPoll(ctx context.Context, req *pb.GetVotesByPollRequest) (*pb.ListVotesResponse, error) {
+	voteList, err := s.va.db.GetPollVotes(uint(req.PollId))
+	if err != nil {
+		log.Println("Error getting votes by poll: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	return toPBVotes(voteList, 0), nil
+}
+
+func (s *votesServer) GetVotesByVoter(ctx context.Context, req *pb.GetVotesByVoterRequest) (*pb.ListVotesResponse, error) {
+	voteList, err := s.va.db.GetVotesByVoter(uint(req.VoterId))
+	if err != nil {
+		log.Println("Error getting votes by voter: ", err)
+		return nil, grpcError(err)
+	}
+
+	calls = calls + 1
+	return toPBVotes(voteList, 0), nil
+}
+
+// WatchVoteEvents streams the vote events of poll_ids, every poll if none
+// are given, as /ws does.  Like /ws it is only there with FEATURE_SSE on
+func (s *votesServer) WatchVoteEvents(req *pb.WatchVoteEventsRequest, stream pb.VotesAPI_WatchVoteEventsServer) error {
+	if !s.va.features.SSE {
+		return status.Error(codes.Unimplemented, "vote events are off, FEATURE_SSE is not set")
+	}
+	filter := &pollFilter{polls: make(map[uint]bool)}
+	for _, id := range req.PollIds {
+		filter.polls[uint(id)] = true
+	}
+
+	events, stop := s.va.db.WatchVoteEvents()
+	defer stop()
+
+	calls = calls + 1
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "fell too far behind the vote events")
+			}
+			if !filter.wants(event) {
+				continue
+			}
+			msg := &pb.VoteEvent{Type: event.Type}
+			if event.Vote != nil {
+				msg.Vote = toPBVote(*event.Vote)
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
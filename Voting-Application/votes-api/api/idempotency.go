@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"drexel.edu/votes/db"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader carries a key the client picks for a POST /votes,
+// such as a UUID, and sends again when it retries the same request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set to true on a response that is the kept
+// response of an earlier request with the same Idempotency-Key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLen is the longest Idempotency-Key accepted
+const maxIdempotencyKeyLen = 255
+
+// recordingWriter keeps a copy of the body written through it, so that
+// Idempotent can keep the response
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap lets http.ResponseController reach the connection, see Limits
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Idempotent is middleware for POST /votes.  A request with an
+// Idempotency-Key header claims the key, and if it succeeds its response
+// is kept for VOTE_IDEMPOTENCY_TTL.  A retry with the same key and body
+// is sent that response again rather than adding the vote twice or
+// failing because it already exists.  The same key with a different body
+// is a 422, and one still being handled a 409.  A request that fails
+// frees its key, so a retry is handled again.  Requests without the
+// header are untouched
+func (va *VotesAPI) Idempotent(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		log.Println("Idempotency-Key too long: ", len(key))
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+		return
+	}
+
+	//The handler still has to read the body, so it is put back after
+	//it is hashed
+	body, err := c.GetRawData()
+	if err != nil {
+		bodyError(c, err)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	requestHash := hex.EncodeToString(sum[:])
+
+	stored, err := va.db.ClaimIdempotencyKey(key, requestHash)
+	if err != nil {
+		log.Println("Error claiming Idempotency-Key: ", err)
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrIdempotencyInFlight) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, db.ErrNeedsRedis) {
+			c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if stored != nil {
+		if stored.Location != "" {
+			c.Header("Location", stored.Location)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(stored.Status, "application/json; charset=utf-8", stored.Body)
+		c.Abort()
+		return
+	}
+
+	w := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	status := w.Status()
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		if err := va.db.ReleaseIdempotencyKey(key); err != nil {
+			log.Println("Error releasing Idempotency-Key: ", err)
+		}
+		return
+	}
+	err = va.db.CompleteIdempotencyKey(key, db.IdempotentResponse{
+		RequestHash: requestHash,
+		Status:      status,
+		Location:    w.Header().Get("Location"),
+		Body:        w.body.Bytes(),
+	})
+	if err != nil {
+		//The vote was added, a retry will be told the key is still
+		//being handled until the claim runs out
+		log.Println("Error keeping Idempotency-Key response: ", err)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes is the largest request body accepted when
+// MAX_BODY_BYTES is not set
+const DefaultMaxBodyBytes = 1 << 20
+
+// DefaultRequestTimeout is how long a request with a body has when
+// REQUEST_TIMEOUT is not set
+const DefaultRequestTimeout = 30 * time.Second
+
+// Limits is middleware for the routes that take a body, POST, PUT and
+// PATCH.  A body over maxBody bytes is turned away with a 413, one the
+// client is still sending after timeout with a 408, see bodyError.  The
+// request's context also ends after timeout.  0 turns either limit off.
+// Other methods, so streams and websockets, are untouched
+func Limits(maxBody int64, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		if maxBody > 0 {
+			if c.Request.ContentLength > maxBody {
+				log.Println("Request body too large: ", c.Request.ContentLength)
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLargeMessage(maxBody)})
+				return
+			}
+			//Content-Length can be missing, MaxBytesReader stops a
+			//body that goes on past the limit as it is read
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+		}
+
+		if timeout > 0 {
+			deadline := time.Now().Add(timeout)
+			err := http.NewResponseController(c.Writer).SetReadDeadline(deadline)
+			if err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Println("Error setting read deadline: ", err)
+			}
+			ctx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+	}
+}
+
+// tooLargeMessage is the error of a 413
+func tooLargeMessage(maxBody int64) string {
+	return "request body is over " + strconv.FormatInt(maxBody, 10) + " bytes"
+}
+
+// bodyError aborts a request whose body could not be read or decoded,
+// with a 413 if it was over the Limits size, a 408 if the client took too
+// long sending it, and a 400 saying why otherwise
+func bodyError(c *gin.Context, err error) {
+	log.Println("Error reading body: ", err)
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLargeMessage(tooLarge.Limit)})
+		return
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		c.AbortWithStatusJSON(http.StatusRequestTimeout, gin.H{"error": "request body was not sent in time"})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// implementation for GET /metrics
+// returns the data drift found by the last drift check, and the orphaned
+// votes found by the last orphan sweep, as gauges in the Prometheus text
+// exposition format.  Each gauge is left out until its first check has
+// run
+func (va *VotesAPI) GetMetrics(c *gin.Context) {
+
+	var out bytes.Buffer
+	if drift, ok := va.db.LastDrift(); ok {
+		fmt.Fprintln(&out, "# HELP data_drift_total Votes and voter history entries without a match, as of the last drift check.")
+		fmt.Fprintln(&out, "# TYPE data_drift_total gauge")
+		fmt.Fprintf(&out, "data_drift_total{kind=%q} %d\n", "votes_without_history", drift.VotesWithoutHistory)
+		fmt.Fprintf(&out, "data_drift_total{kind=%q} %d\n", "history_without_votes", drift.HistoryWithoutVotes)
+	}
+	if sweep, ok := va.db.LastOrphans(); ok {
+		fmt.Fprintln(&out, "# HELP orphan_votes_total Votes whose voter or poll no longer exists, as of the last orphan sweep.")
+		fmt.Fprintln(&out, "# TYPE orphan_votes_total gauge")
+		fmt.Fprintf(&out, "orphan_votes_total %d\n", len(sweep.Votes))
+	}
+
+	calls = calls + 1
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", out.Bytes())
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/base64"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TotalCountHeader carries the length of the whole list when a list
+// endpoint returns a bare array
+const TotalCountHeader = "X-Total-Count"
+
+// listPage is the part of a list a client asked for with ?limit and
+// ?offset, along with how long the whole list is.  A limit of 0 means
+// the rest of the list
+type listPage struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// listEnvelope is what list endpoints return for ?envelope=true
+type listEnvelope[T any] struct {
+	Data []T      `json:"data"`
+	Page listPage `json:"page"`
+}
+
+// parseListPage reads the ?limit, ?offset and ?envelope query parameters
+// that every list endpoint takes.  If any of them is invalid the request
+// is aborted with a 400 naming the parameter and ok is false
+func parseListPage(c *gin.Context) (page listPage, envelope bool, ok bool) {
+	if page.Limit, ok = queryCount(c, "limit"); !ok {
+		return listPage{}, false, false
+	}
+	if page.Offset, ok = queryCount(c, "offset"); !ok {
+		return listPage{}, false, false
+	}
+
+	if envelopeS := c.Query("envelope"); envelopeS != "" {
+		b, err := strconv.ParseBool(envelopeS)
+		if err != nil {
+			log.Println("Error converting envelope to bool: ", err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "envelope must be true or false"})
+			return listPage{}, false, false
+		}
+		envelope = b
+	}
+
+	return page, envelope, true
+}
+
+// queryCount reads a non-negative integer query parameter, 0 if it is
+// not given.  If it is invalid the request is aborted with a 400
+func queryCount(c *gin.Context, name string) (int, bool) {
+	valS := c.Query(name)
+	if valS == "" {
+		return 0, true
+	}
+	val, err := strconv.Atoi(valS)
+	if err != nil || val < 0 {
+		log.Println("Invalid ", name, ": ", valS)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": name + " must be a non-negative integer"})
+		return 0, false
+	}
+	return val, true
+}
+
+// writeList sends the page of items the client asked for.  By default it
+// is the bare array the list endpoints have always returned, with the
+// length of the whole list in the X-Total-Count header.  With
+// ?envelope=true it is {"data": [...], "page": {limit, offset, total}}
+// instead, the same shape from every list endpoint of every service
+func writeList[T any](c *gin.Context, items []T, page listPage, envelope bool) {
+	page.Total = len(items)
+
+	start := page.Offset
+	if start > len(items) {
+		start = len(items)
+	}
+	end := len(items)
+	//Compared this way round so a huge ?limit can't overflow
+	if page.Limit > 0 && page.Limit < end-start {
+		end = start + page.Limit
+	}
+	//Never send null, an empty page is []
+	data := make([]T, 0, end-start)
+	data = append(data, items[start:end]...)
+
+	if envelope {
+		c.JSON(http.StatusOK, listEnvelope[T]{Data: data, Page: page})
+		return
+	}
+	c.Header(TotalCountHeader, strconv.Itoa(page.Total))
+	c.JSON(http.StatusOK, data)
+}
+
+// NextCursorHeader carries the cursor of the next page when a list
+// endpoint is paged with ?cursor and returns a bare array.  It is 0 once
+// the end of the list has been reached
+const NextCursorHeader = "X-Next-Cursor"
+
+// cursorPage is the part of a list a client asked for with ?cursor and
+// ?limit, with the cursors as the client sees them, see encodeCursor
+type cursorPage struct {
+	Limit      int    `json:"limit"`
+	Cursor     string `json:"cursor"`
+	NextCursor string `json:"nextCursor"`
+}
+
+// cursorPrefix marks what a cursor wraps, so that the format can change
+// without old cursors being misread
+const cursorPrefix = "scan:"
+
+// encodeCursor turns a redis SCAN cursor into the opaque cursor clients
+// are given.  0, the start and end of the list, is left as "0" so that
+// clients can start a walk and tell when it is done without decoding
+// anything
+func encodeCursor(cursor uint64) string {
+	if cursor == 0 {
+		return "0"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatUint(cursor, 10)))
+}
+
+// decodeCursor turns a cursor from encodeCursor back into the SCAN
+// cursor, ok is false if it isn't one
+func decodeCursor(cursorS string) (cursor uint64, ok bool) {
+	if cursorS == "0" {
+		return 0, true
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursorS)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, false
+	}
+	cursor, err = strconv.ParseUint(strings.TrimPrefix(string(raw), cursorPrefix), 10, 64)
+	return cursor, err == nil
+}
+
+// cursorEnvelope is what list endpoints return for ?cursor with
+// ?envelope=true
+type cursorEnvelope[T any] struct {
+	Data []T        `json:"data"`
+	Page cursorPage `json:"page"`
+}
+
+// parseCursor reads the ?cursor query parameter.  cursored is false if it
+// is not given, the list is then paged with ?offset as usual.  If the
+// cursor is invalid, or ?offset is given as well, the request is aborted
+// with a 400 and ok is false
+func parseCursor(c *gin.Context, page listPage) (cursor uint64, cursored bool, ok bool) {
+	cursorS, cursored := c.GetQuery("cursor")
+	if !cursored {
+		return 0, false, true
+	}
+	if page.Offset != 0 {
+		log.Println("Both cursor and offset given")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "cursor can't be used with offset"})
+		return 0, false, false
+	}
+	cursor, ok = decodeCursor(cursorS)
+	if !ok {
+		log.Println("Invalid cursor: ", cursorS)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "cursor must be 0 or the nextCursor of an earlier page"})
+		return 0, false, false
+	}
+	return cursor, true, true
+}
+
+// writeCursorPage sends a page of a list that was read from a cursor.
+// Only the next cursor is known, not the length of the whole list, so it
+// is sent in the X-Next-Cursor header, or in the page with
+// ?envelope=true.  The page is not cut down to the limit, a cursor can't
+// resume part way through the items it returned
+func writeCursorPage[T any](c *gin.Context, items []T, page cursorPage, envelope bool) {
+	//Never send null, an empty page is []
+	if items == nil {
+		items = make([]T, 0)
+	}
+
+	if envelope {
+		c.JSON(http.StatusOK, cursorEnvelope[T]{Data: items, Page: page})
+		return
+	}
+	c.Header(NextCursorHeader, page.NextCursor)
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The REST routes and the /v1 routes generated from the protos are handled
+// by different code, the handlers in api-handler.go and the gRPC methods in
+// grpc.go.  These tests send the same requests to both and check that they
+// store the same vote and fail the same way, so the two can't drift apart
+// unnoticed
+
+// parityPollID is the poll the tests vote in and parityVoteID the first of
+// the vote and voter ids they use, far past any id the seed data or
+// AddVoteNewID hands out
+const (
+	parityPollID = 900001
+	parityVoteID = 900101
+)
+
+// parityPoll is parityPollID as the polls API sends it, open with two
+// options
+const parityPoll = `{"PollID": 900001, "Status": "open", "PollOptions": [{"PollOptionID": 1}, {"PollOptionID": 2}], "Settings": {"Status": "published"}}`
+
+// newParityRouter connects to the redis at REDIS_URL, or the default
+// location, and skips the test if there isn't one.  The voters and polls
+// APIs are stood in for by a server that has every voter and just
+// parityPoll.  It returns a router with the REST routes the tests use,
+// registered as in main.go, and /v1
+func newParityRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	deps := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/voters/"):
+			id := strings.TrimPrefix(r.URL.Path, "/voters/")
+			w.Write([]byte(`{"VoterID": ` + id + `}`))
+		case r.URL.Path == "/polls/"+strconv.Itoa(parityPollID):
+			w.Write([]byte(parityPoll))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(deps.Close)
+	t.Setenv("VOTERS_API_URL", deps.URL)
+	t.Setenv("POLLS_API_URL", deps.URL)
+	t.Setenv("VOTE_DEPENDENCY_CACHE_TTL", "0")
+	t.Setenv("VOTE_RECEIPT_SECRET", "parity-test-secret-that-is-long-enough")
+
+	gin.SetMode(gin.TestMode)
+	va, err := New(Features{StrictIDs: true, AutoIDs: true, Gateway: true})
+	if err != nil {
+		t.Skip("redis is not available: ", err)
+	}
+
+	r := gin.New()
+	r.Use(Problems())
+	r.POST("/votes", va.Idempotent, va.AddVote)
+	r.PUT("/votes", va.UpdateVote)
+	r.GET("/votes/:id", va.GetVote)
+	r.DELETE("/votes/:id", va.DeleteVote)
+	r.Any("/v1/*path", va.Gateway)
+	return r
+}
+
+// serve sends r the request, with the headers in header, and returns the
+// response
+func serve(r http.Handler, method string, path string, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// parityVote is the part of a vote both kinds of route send, whichever
+// names they give the fields
+type parityVote struct {
+	ID        uint
+	VoterID   uint
+	PollID    uint
+	VoteValue uint
+	Source    string
+	Version   uint
+	//HasReceipt is only read from what adding the vote sent back
+	HasReceipt bool
+}
+
+// decodeVote reads the vote in w, sent over REST or over /v1 if v1 is set
+func decodeVote(t *testing.T, w *httptest.ResponseRecorder, v1 bool) parityVote {
+	t.Helper()
+
+	if v1 {
+		var sent struct {
+			VoteID    uint   `json:"vote_id"`
+			VoterID   uint   `json:"voter_id"`
+			PollID    uint   `json:"poll_id"`
+			VoteValue uint   `json:"vote_value"`
+			Source    string `json:"source"`
+			Version   uint   `json:"version"`
+			Receipt   *struct {
+				Signature string `json:"signature"`
+			} `json:"receipt"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &sent); err != nil {
+			t.Fatal("Error reading vote: ", err)
+		}
+		return parityVote{sent.VoteID, sent.VoterID, sent.PollID, sent.VoteValue, sent.Source, sent.Version,
+			sent.Receipt != nil && sent.Receipt.Signature != ""}
+	}
+	var sent struct {
+		VoteID    uint
+		VoterID   uint
+		PollID    uint
+		VoteValue uint
+		Source    string
+		Version   uint
+		Receipt   *struct {
+			Signature string
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &sent); err != nil {
+		t.Fatal("Error reading vote: ", err)
+	}
+	return parityVote{sent.VoteID, sent.VoterID, sent.PollID, sent.VoteValue, sent.Source, sent.Version,
+		sent.Receipt != nil && sent.Receipt.Signature != ""}
+}
+
+// readVote reads the vote with id over REST, or over /v1 if v1 is set
+func readVote(t *testing.T, r http.Handler, id uint, v1 bool) parityVote {
+	t.Helper()
+
+	path := "/votes/" + strconv.FormatUint(uint64(id), 10)
+	if v1 {
+		path = "/v1" + path
+	}
+	w := serve(r, http.MethodGet, path, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body.String())
+	}
+	return decodeVote(t, w, v1)
+}
+
+func TestParityAddedVotesReadTheSame(t *testing.T) {
+	r := newParityRouter(t)
+	restID, v1ID := uint(parityVoteID), uint(parityVoteID+1)
+	for _, id := range []uint{restID, v1ID} {
+		serve(r, http.MethodDelete, "/votes/"+strconv.FormatUint(uint64(id), 10), "")
+		defer serve(r, http.MethodDelete, "/votes/"+strconv.FormatUint(uint64(id), 10), "")
+	}
+
+	//Each is sent twice with the same Idempotency-Key, the second is
+	//sent what the first added rather than adding the vote again.  The
+	//keys are new each run, a key from an earlier run would be replayed
+	run := strconv.FormatInt(time.Now().UnixNano(), 10)
+	restBody := `{"VoteID": 900101, "VoterID": 900101, "PollID": 900001, "VoteValue": 2}`
+	v1Body := `{"vote_id": 900102, "voter_id": 900102, "poll_id": 900001, "vote_value": 2}`
+	var added [2]parityVote
+	for i, v1 := range []bool{false, true} {
+		path, body, key := "/votes", restBody, "parity-rest-"+run
+		if v1 {
+			path, body, key = "/v1/votes", v1Body, "parity-v1-"+run
+		}
+		first := serve(r, http.MethodPost, path, body, SourceHeader, "kiosk", IdempotencyKeyHeader, key)
+		if first.Code != http.StatusCreated {
+			t.Fatalf("POST %s: status %d: %s", path, first.Code, first.Body.String())
+		}
+		retry := serve(r, http.MethodPost, path, body, SourceHeader, "kiosk", IdempotencyKeyHeader, key)
+		if retry.Code != first.Code {
+			t.Errorf("POST %s retried: status %d rather than %d: %s", path, retry.Code, first.Code, retry.Body.String())
+		}
+		replayed := retry.Header().Get(IdempotentReplayedHeader)
+		if v1 {
+			replayed = retry.Header().Get("Grpc-Metadata-" + IdempotentReplayedHeader)
+		}
+		if replayed != "true" {
+			t.Errorf("POST %s retried: not sent as a replay", path)
+		}
+		added[i] = decodeVote(t, first, v1)
+		if again := decodeVote(t, retry, v1); again != added[i] {
+			t.Errorf("POST %s retried: sent %+v rather than %+v", path, again, added[i])
+		}
+	}
+
+	//Both were sent back with a receipt and the same fields
+	added[1].ID, added[1].VoterID = added[0].ID, added[0].VoterID
+	if added[0] != added[1] || !added[0].HasReceipt {
+		t.Errorf("vote added over REST is %+v but over /v1 is %+v", added[0], added[1])
+	}
+
+	//Each vote reads the same over both kinds of route
+	for _, id := range []uint{restID, v1ID} {
+		rest, v1 := readVote(t, r, id, false), readVote(t, r, id, true)
+		if rest != v1 {
+			t.Errorf("vote %d reads %+v over REST but %+v over /v1", id, rest, v1)
+		}
+	}
+}
+
+func TestParityErrorsMatch(t *testing.T) {
+	r := newParityRouter(t)
+	serve(r, http.MethodDelete, "/votes/900103", "")
+	defer serve(r, http.MethodDelete, "/votes/900103", "")
+	if w := serve(r, http.MethodPost, "/votes", `{"VoteID": 900103, "VoterID": 900103, "PollID": 900001, "VoteValue": 1}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /votes: status %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		restPath, restBody string
+		v1Path, v1Body     string
+	}{
+		{"missing vote", http.MethodGet, "/votes/900199", "", "/v1/votes/900199", ""},
+		{"missing poll", http.MethodPost, "/votes", `{"VoteID": 900104, "VoterID": 900104, "PollID": 900002, "VoteValue": 1}`,
+			"/v1/votes", `{"vote_id": 900104, "voter_id": 900104, "poll_id": 900002, "vote_value": 1}`},
+		{"not an option", http.MethodPost, "/votes", `{"VoteID": 900104, "VoterID": 900104, "PollID": 900001, "VoteValue": 9}`,
+			"/v1/votes", `{"vote_id": 900104, "voter_id": 900104, "poll_id": 900001, "vote_value": 9}`},
+		{"bad source", http.MethodPost, "/votes", `{"VoteID": 900104, "VoterID": 900104, "PollID": 900001, "VoteValue": 1, "Source": "fax"}`,
+			"/v1/votes", `{"vote_id": 900104, "voter_id": 900104, "poll_id": 900001, "vote_value": 1, "source": "fax"}`},
+		{"vote already exists", http.MethodPost, "/votes", `{"VoteID": 900103, "VoterID": 900105, "PollID": 900001, "VoteValue": 1}`,
+			"/v1/votes", `{"vote_id": 900103, "voter_id": 900105, "poll_id": 900001, "vote_value": 1}`},
+		{"updated to not an option", http.MethodPut, "/votes", `{"VoteID": 900103, "VoterID": 900103, "PollID": 900001, "VoteValue": 9}`,
+			"/v1/votes/900103", `{"voter_id": 900103, "poll_id": 900001, "vote_value": 9}`},
+		{"stale version", http.MethodPut, "/votes", `{"VoteID": 900103, "VoterID": 900103, "PollID": 900001, "VoteValue": 2, "Version": 7}`,
+			"/v1/votes/900103", `{"voter_id": 900103, "poll_id": 900001, "vote_value": 2, "version": 7}`},
+	}
+	for _, test := range tests {
+		rest := serve(r, test.method, test.restPath, test.restBody)
+		v1 := serve(r, test.method, test.v1Path, test.v1Body)
+		if rest.Code != v1.Code {
+			t.Errorf("%s: REST sent %d but /v1 sent %d", test.name, rest.Code, v1.Code)
+		}
+		if ct := v1.Header().Get("Content-Type"); ct != ProblemContentType {
+			t.Errorf("%s: /v1 sent %q rather than a problem", test.name, ct)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"time"
+
+	"drexel.edu/votes/db"
+)
+
+// VoteStore is everything the api needs from the data layer.  *db.VoteList
+// is the real implementation, anything else that satisfies it, such as a
+// fake in a test or a VoteList wired to a custom redis client, can be
+// passed to NewWithStore
+type VoteStore interface {
+	GetAllVotes() ([]db.Vote, error)
+	GetVotesPage(cursor uint64, count int) ([]db.Vote, uint64, error)
+	GetVotesByPoll(pollId uint) ([]db.Vote, error)
+	GetPollVotes(pollId uint) ([]db.Vote, error)
+	GetVotesByVoter(voterId uint) ([]db.Vote, error)
+	AnonymizeVoterVotes(voterId uint) (db.VoterErasure, error)
+	GetVote(id uint) (db.Vote, error)
+	AddVote(vote db.Vote, passcode string, invitation string, now time.Time) error
+	AddVoteNewID(vote db.Vote, passcode string, invitation string, now time.Time) (uint, error)
+	ClaimIdempotencyKey(key string, requestHash string) (*db.IdempotentResponse, error)
+	CompleteIdempotencyKey(key string, response db.IdempotentResponse) error
+	ReleaseIdempotencyKey(key string) error
+	UpdateVote(vote db.Vote, passcode string, invitation string, now time.Time) error
+	DeleteVote(id uint) error
+	RetractVote(voterId uint, pollId uint, now time.Time) ([]db.Vote, error)
+	RecastVote(vote db.Vote, passcode string, now time.Time) (db.Vote, error)
+	RestoreVote(id uint) (db.Vote, error)
+	DeleteAllVotes() error
+	RemovePlaceholderVotes() ([]db.Vote, error)
+	RecountVotes() ([]db.CountCorrection, error)
+	PauseVoting() error
+	ResumeVoting() error
+	CheckDrift() (db.DataDrift, error)
+	LastDrift() (db.DataDrift, bool)
+	FindOrphans() (db.OrphanSweep, error)
+	LastOrphans() (db.OrphanSweep, bool)
+	WatchVoteEvents() (<-chan db.VoteEvent, func())
+	IssueReceipt(vote db.Vote, now time.Time) (db.VoteReceipt, error)
+	VerifyReceipt(receipt db.VoteReceipt) (db.ReceiptCheck, error)
+	RecordAudit(entry db.AuditEntry) error
+	GetAudit(entity string, id uint) ([]db.AuditEntry, error)
+	GetHealthData(bootTime time.Time, calls uint) (db.HealthData, error)
+}
+
+// NewWithStore returns an api backed by store instead of the redis backed
+// store New creates
+func NewWithStore(store VoteStore, features Features) *VotesAPI {
+	bootTime = time.Now()
+
+	va := &VotesAPI{db: store, features: features, clock: systemClock{}, wsOrigins: wsOriginsFromEnv()}
+	va.gateway = va.newGateway()
+	return va
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"drexel.edu/votes/db"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// fieldError is one field of a request body that broke one of the
+// binding rules on its struct.  Field is where it is in the body, such as
+// "PollOptions[1].PollOptionText", Rule is the rule it broke
+type fieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// unmarshalerType is json.Unmarshaler, types that decode themselves are
+// not checked by exactFields
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// strictJSON is gin's JSON binding with unknown fields turned away, so a
+// typo such as "Firstname" is an error rather than a field left empty
+type strictJSON struct{}
+
+func (strictJSON) Name() string {
+	return "json"
+}
+
+func (strictJSON) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := exactFields(data, reflect.TypeOf(obj)); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(obj); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// exactFields returns an error naming a field of the JSON in data that t
+// has no field for with exactly that name, looking inside nested objects
+// and arrays too.  encoding/json matches names ignoring case, so
+// DisallowUnknownFields alone would take "Firstname" as FirstName.  JSON
+// that does not fit t is left for the decoder to report
+func exactFields(data []byte, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil
+		}
+		fields := jsonFields(t)
+		for name, raw := range obj {
+			ft, ok := fields[name]
+			if !ok {
+				return fmt.Errorf("json: unknown field %q", name)
+			}
+			if err := exactFields(raw, ft); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil
+		}
+		for _, item := range items {
+			if err := exactFields(item, t.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields returns the types of the fields of the struct t keyed by
+// the name they have in JSON, with the fields of embedded structs
+// promoted the way encoding/json does
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for n, nt := range jsonFields(ft) {
+				fields[n] = nt
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// bindJSON binds the request body to obj and checks it against the
+// binding tags of obj.  With the StrictJSON feature on a field obj does
+// not have, or has with a different case, is an error too.  A body that
+// can't be read or decoded is aborted by bodyError, one that breaks a
+// rule with a 422 listing every field that did.  false is returned if
+// the request was aborted
+func (va *VotesAPI) bindJSON(c *gin.Context, obj any) bool {
+	var err error
+	if va.features.StrictJSON {
+		err = c.ShouldBindWith(obj, strictJSON{})
+	} else {
+		err = c.ShouldBindJSON(obj)
+	}
+	if err == nil {
+		return true
+	}
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		abortInvalid(c, invalid)
+		return false
+	}
+	bodyError(c, err)
+	return false
+}
+
+// validBody checks obj against its binding tags the same way bindJSON
+// does, for a body that was not bound with it such as a merged patch
+func validBody(c *gin.Context, obj any) bool {
+	err := binding.Validator.ValidateStruct(obj)
+	if err == nil {
+		return true
+	}
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		abortInvalid(c, invalid)
+		return false
+	}
+	log.Println("Error validating body: ", err)
+	c.AbortWithStatus(http.StatusInternalServerError)
+	return false
+}
+
+// abortInvalid aborts with a 422 whose "fields" has a fieldError for
+// every rule that was broken
+func abortInvalid(c *gin.Context, invalid validator.ValidationErrors) {
+	log.Println("Error validating body: ", invalid)
+	fields := make([]fieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		fields = append(fields, fieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+		"error":  "request body failed validation",
+		"fields": fields,
+	})
+}
+
+// abortInvalidVoteValue aborts with a 422 if err is for a vote whose
+// VoteValue is not one of its poll's options, with the ones that are in
+// "validOptions".  false is returned for any other error
+func abortInvalidVoteValue(c *gin.Context, err error) bool {
+	var invalid *db.InvalidVoteValueError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+		"error":        err.Error(),
+		"validOptions": invalid.ValidOptions,
+	})
+	return true
+}
+
+// fieldPath returns where fe is in the body, its namespace without the
+// name of the struct at the top
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+// fieldMessage describes the rule fe broke
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + " is required"
+	case "required_without":
+		return fe.Field() + " is required without " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("%s must have at least %s entries", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	}
+	return fmt.Sprintf("%s breaks the %s rule", fe.Field(), fe.Tag())
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"drexel.edu/votes/db"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// wsOrigins are the origins a browser may open GET /ws from, from
+// WS_ALLOWED_ORIGINS, a comma separated list such as
+// https://results.example.com,http://localhost:3000.  CORS does not apply
+// to websocket upgrades, so without this check any page a voter visits
+// could open one.  With none listed only the service's own origin is let
+// in, "*" lets in every origin
+type wsOrigins struct {
+	any     bool
+	allowed map[string]bool
+}
+
+// wsOriginsFromEnv reads WS_ALLOWED_ORIGINS
+func wsOriginsFromEnv() wsOrigins {
+	origins := wsOrigins{allowed: make(map[string]bool)}
+	for _, origin := range strings.Split(os.Getenv("WS_ALLOWED_ORIGINS"), ",") {
+		origin = strings.TrimSpace(origin)
+		switch origin {
+		case "":
+		case "*":
+			origins.any = true
+		default:
+			origins.allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+		}
+	}
+	return origins
+}
+
+// check makes sure the websocket handshake req came from an allowed
+// origin.  A request without an Origin is not from a browser, which
+// always sends one, so it is let in
+func (o wsOrigins) check(config *websocket.Config, req *http.Request) error {
+	originS := req.Header.Get("Origin")
+	if originS == "" || o.any {
+		return nil
+	}
+	origin, err := url.Parse(originS)
+	if err != nil || origin.Host == "" {
+		return errors.New("invalid websocket origin " + originS)
+	}
+	if len(o.allowed) == 0 {
+		if strings.EqualFold(origin.Host, req.Host) {
+			return nil
+		}
+	} else if o.allowed[strings.ToLower(origin.Scheme+"://"+origin.Host)] {
+		return nil
+	}
+	log.Println("Rejected websocket from origin ", originS)
+	return errors.New("websocket origin not allowed: " + originS)
+}
+
+// wsRequest is a message a websocket client sends to change which polls
+// it is sent the events of
+type wsRequest struct {
+	Subscribe   []uint `json:"subscribe"`
+	Unsubscribe []uint `json:"unsubscribe"`
+}
+
+// pollFilter is the set of polls a websocket client wants the events of,
+// every poll while it is empty
+type pollFilter struct {
+	lock  sync.Mutex
+	polls map[uint]bool
+}
+
+func (f *pollFilter) apply(req wsRequest) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, id := range req.Subscribe {
+		f.polls[id] = true
+	}
+	for _, id := range req.Unsubscribe {
+		delete(f.polls, id)
+	}
+}
+
+func (f *pollFilter) wants(event db.VoteEvent) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if len(f.polls) == 0 || event.Vote == nil {
+		return true
+	}
+	return f.polls[event.Vote.PollID]
+}
+
+// implementation for GET /ws
+// upgrades to a websocket that is sent every vote event as JSON, only
+// those of the polls named with ?pollId if any are.  The client can
+// change the polls by sending {"subscribe": [ids]} or
+// {"unsubscribe": [ids]}
+func (va *VotesAPI) VoteEventsSocket(c *gin.Context) {
+	filter := &pollFilter{polls: make(map[uint]bool)}
+	for _, pollIdS := range c.QueryArray("pollId") {
+		id64, err := strconv.ParseInt(pollIdS, 10, 32)
+		if err != nil || id64 < 0 {
+			log.Println("Error converting pollId to int64: ", pollIdS)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "pollId must be a non-negative integer"})
+			return
+		}
+		filter.polls[uint(id64)] = true
+	}
+
+	server := websocket.Server{
+		Handshake: va.wsOrigins.check,
+		Handler: func(ws *websocket.Conn) {
+			va.serveVoteEvents(ws, filter)
+		},
+	}
+
+	calls = calls + 1
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveVoteEvents sends the vote events filter wants to ws until the
+// client goes away, or falls so far behind that it is dropped
+func (va *VotesAPI) serveVoteEvents(ws *websocket.Conn, filter *pollFilter) {
+	defer ws.Close()
+
+	events, stop := va.db.WatchVoteEvents()
+	defer stop()
+
+	//Read the client's requests until it hangs up
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var msg []byte
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				return
+			}
+			var req wsRequest
+			if err := json.Unmarshal(msg, &req); err != nil {
+				log.Println("Ignoring invalid websocket request: ", string(msg))
+				continue
+			}
+			filter.apply(req)
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !filter.wants(event) {
+				continue
+			}
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		}
+	}
+}
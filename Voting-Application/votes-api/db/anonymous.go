@@ -0,0 +1,125 @@
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+// AnonymousSaltKeyPrefix is the prefix of the keys the salt of each
+// anonymous poll is kept under, anon-salt:<poll id>.  Each poll has its
+// own, so the same voter has a different hash in every poll and their
+// votes can't be matched up across polls
+const AnonymousSaltKeyPrefix = "anon-salt:"
+
+// AnonymousVotersKeyPattern matches the voter sets of every anonymous
+// poll.  Each holds the hashes of the voters with a vote in the poll,
+// anon-voters:<poll id>, so a voter can only vote once in it although
+// their votes don't say who they are
+const AnonymousVotersKeyPattern = "anon-voters:*"
+
+// ErrAlreadyVoted is returned when a voter votes again in an anonymous
+// poll
+var ErrAlreadyVoted = fmt.Errorf("%w: voter has already voted in this anonymous poll", ErrConflict)
+
+func anonymousSaltKey(pollId uint) string {
+	return fmt.Sprintf("%s%d", AnonymousSaltKeyPrefix, pollId)
+}
+
+func anonymousVotersKey(pollId uint) string {
+	return fmt.Sprintf("anon-voters:%d", pollId)
+}
+
+// anonymousPepperFromEnv returns VOTE_ANONYMOUS_PEPPER, a secret that goes
+// into every voter hash along with the poll's salt.  Voter ids are small
+// numbers, so with only the salt, which is in redis, anyone who can read
+// redis could work a voter out by hashing every id.  It has to be the same
+// on every replica and never change while there are anonymous polls
+func anonymousPepperFromEnv() []byte {
+	pepper := os.Getenv("VOTE_ANONYMOUS_PEPPER")
+	if pepper == "" {
+		return nil
+	}
+	return []byte(pepper)
+}
+
+// anonymousSalt returns the salt of the poll with pollId.  A poll that
+// has none is given one, SETNX so that replicas that create it at once end
+// up with the same one
+func (v *VoteList) anonymousSalt(pollId uint) (string, error) {
+	key := anonymousSaltKey(pollId)
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	if err := v.cacheClient.SetNX(v.context, key, hex.EncodeToString(b), 0).Err(); err != nil {
+		return "", err
+	}
+	return v.cacheClient.Get(v.context, key).Result()
+}
+
+// anonymousVoterHash returns the hash the voter with voterId has in the
+// anonymous poll with pollId, the HMAC-SHA256 of the voter id keyed with
+// the pepper and the poll's salt
+func (v *VoteList) anonymousVoterHash(pollId uint, voterId uint) (string, error) {
+	salt, err := v.anonymousSalt(pollId)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, append(append([]byte{}, v.anonymousPepper...), salt...))
+	mac.Write([]byte(strconv.FormatUint(uint64(voterId), 10)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// anonymize takes the voter off a vote for an anonymous poll, leaving
+// their hash in VoterHash, and marks them as having voted in the poll.
+// The mark is made first, so of two votes from one voter at once only one
+// gets through.  If the vote is not stored after all, unmarkVoted takes
+// the mark off again.  The salts and marks are kept in redis
+func (v *VoteList) anonymize(vote *Vote) error {
+	if err := v.needsRedis(); err != nil {
+		return err
+	}
+	hash, err := v.anonymousVoterHash(vote.PollID, vote.VoterID)
+	if err != nil {
+		return err
+	}
+	added, err := v.cacheClient.SAdd(v.context, anonymousVotersKey(vote.PollID), hash).Result()
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		return ErrAlreadyVoted
+	}
+	vote.VoterID = 0
+	vote.VoterHash = hash
+	return nil
+}
+
+// markVoted marks the voter of an anonymous vote as having voted in its
+// poll again, for a vote that is restored.  Like the indexes a failure is
+// only logged
+func (v *VoteList) markVoted(vote Vote) {
+	if vote.VoterHash == "" || v.needsRedis() != nil {
+		return
+	}
+	if err := v.cacheClient.SAdd(v.context, anonymousVotersKey(vote.PollID), vote.VoterHash).Err(); err != nil {
+		log.Println("Error marking the voter of vote ", vote.VoteID, " as voted: ", err)
+	}
+}
+
+// unmarkVoted takes the mark off the voter of an anonymous vote that is
+// deleted, or was not stored, so they can vote in the poll again
+func (v *VoteList) unmarkVoted(vote Vote) {
+	if vote.VoterHash == "" || v.needsRedis() != nil {
+		return
+	}
+	if err := v.cacheClient.SRem(v.context, anonymousVotersKey(vote.PollID), vote.VoterHash).Err(); err != nil {
+		log.Println("Error unmarking the voter of vote ", vote.VoteID, ": ", err)
+	}
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RetractVote accepts a voter id and a poll id and withdraws the voter's
+// vote in the poll while it is still open.  The poll is taken out of the
+// voter's VoteHistory in the voters API first, so a retraction that fails
+// there changes nothing and can be sent again, then the vote is moved to
+// the trash like any other deleted vote.  A voter with more than one vote
+// in the poll has all of them withdrawn.  now is the time to decide
+// whether the poll is open at.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must have voted in the poll
+//
+//					(3) The poll must be open, as for AddVote
+//
+// Postconditions:
+//
+//	    (1) The voter's votes in the poll will be moved to the trash
+//			and returned, sorted by id
+//		(2) The poll will no longer be in the voter's VoteHistory, a
+//			history without it is not an error
+//		(3) If the voter has not voted in the poll ErrNotFound is
+//			returned, if the poll is not open ErrConflict
+//		(4) If the voters or polls API can't be reached
+//			ErrDependencyUnavailable is returned and nothing is changed
+//		(5) If there is an error, it will be returned
+func (v *VoteList) RetractVote(voterId uint, pollId uint, now time.Time) ([]Vote, error) {
+
+	if v.VotingPaused() {
+		return nil, ErrVotingPaused
+	}
+	votes, err := v.ballotVotes(voterId, pollId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := v.openPoll(pollId, now); err != nil {
+		return nil, err
+	}
+
+	if err := v.removeVoteHistory(voterId, pollId); err != nil {
+		return nil, err
+	}
+
+	retracted := make([]Vote, 0, len(votes))
+	for _, vote := range votes {
+		err := v.DeleteVote(vote.VoteID)
+		if errors.Is(err, ErrNotFound) {
+			//Deleted since we found it
+			continue
+		}
+		if err != nil {
+			return retracted, err
+		}
+		retracted = append(retracted, vote)
+	}
+	return retracted, nil
+}
+
+// RecastVote accepts a voter's vote in a poll that is still open and
+// replaces the VoteValue, VoteValues, Rankings and WriteInText of the vote
+// they already cast in it with the ones in vote, in a single write.  The
+// vote keeps its id, so the poll's vote count, the indexes and the voter's
+// VoteHistory are left as they are, and the poll's results change from
+// the old option to the new one at once, where a delete and an add would
+// leave a moment with no vote.  A Source in vote replaces the stored
+// one, and a Version in vote has to be the stored one.  passcode is the
+// one the vote was sent with, for polls that have one.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The voter must have exactly one vote in the poll
+//
+//					(3) The poll must be open, and passcode must be
+//						its passcode if it has one, as for AddVote
+//
+// Postconditions:
+//
+//	    (1) The vote will have the new VoteValue and be returned
+//		(2) If the voter has not voted in the poll ErrNotFound is
+//			returned, if they have more than one vote in it or the
+//			poll is not open ErrConflict
+//		(3) If the new VoteValue is not one of the poll's options an
+//			*InvalidVoteValueError is returned
+//		(4) If the vote changed since it was read, or vote has a
+//			Version that is not the stored one, ErrVersionMismatch
+//			is returned
+//		(5) If there is an error, it will be returned
+func (v *VoteList) RecastVote(vote Vote, passcode string, now time.Time) (Vote, error) {
+
+	if v.VotingPaused() {
+		return Vote{}, ErrVotingPaused
+	}
+	votes, err := v.ballotVotes(vote.VoterID, vote.PollID)
+	if err != nil {
+		return Vote{}, err
+	}
+	if len(votes) > 1 {
+		return Vote{}, fmt.Errorf("%w: voter has more than one vote in poll, retract them first", ErrConflict)
+	}
+	existingVote := votes[0]
+	if vote.Version != 0 && vote.Version != existingVote.Version {
+		return Vote{}, versionMismatch(vote.Version, existingVote.Version)
+	}
+	checkPoll, err := v.openPoll(vote.PollID, now)
+	if err != nil {
+		return Vote{}, err
+	}
+	if err := v.checkPasscode(checkPoll, passcode); err != nil {
+		return Vote{}, err
+	}
+
+	recast := existingVote
+	recast.VoteValue = vote.VoteValue
+	recast.VoteValues = vote.VoteValues
+	recast.Rankings = vote.Rankings
+	recast.WriteInText = vote.WriteInText
+	if vote.Source != "" {
+		recast.Source = vote.Source
+	}
+	if err := ValidateSource(recast.Source); err != nil {
+		return Vote{}, err
+	}
+	if err := validateRankings(&recast); err != nil {
+		return Vote{}, err
+	}
+	if err := validateSelections(&recast); err != nil {
+		return Vote{}, err
+	}
+	if err := validateWriteIn(&recast); err != nil {
+		return Vote{}, err
+	}
+	if err := checkPoll.checkVoteValue(recast); err != nil {
+		return Vote{}, err
+	}
+	if err := checkPoll.checkSelections(recast); err != nil {
+		return Vote{}, err
+	}
+	if err := checkPoll.checkWriteIn(recast); err != nil {
+		return Vote{}, err
+	}
+	recast.Version = existingVote.Version + 1
+	if err := validateVoteStorable(recast); err != nil {
+		return Vote{}, err
+	}
+	if err := v.store.putVersion(recast, existingVote.Version); err != nil {
+		return Vote{}, notFoundOr(err, "vote does not exist")
+	}
+	v.publishVoteEvent(VoteUpdated, recast)
+
+	return recast, nil
+}
+
+// ballotVotes returns the votes of the voter with voterId in the poll with
+// pollId, sorted by id, or ErrNotFound if there are none.  Votes in an
+// anonymous poll have no voter, so they are never found.  They are not
+// looked up by the voter's hash either, the server can work that out for
+// any voter, so anyone could retract or read back any voter's ballot, or
+// tell whether they voted, from the voter id alone
+func (v *VoteList) ballotVotes(voterId uint, pollId uint) ([]Vote, error) {
+	voterVotes, err := v.GetVotesByVoter(voterId)
+	if err != nil {
+		return nil, err
+	}
+	votes := make([]Vote, 0, 1)
+	for _, vote := range voterVotes {
+		if vote.PollID == pollId {
+			votes = append(votes, vote)
+		}
+	}
+	if len(votes) == 0 {
+		return nil, fmt.Errorf("%w: voter has not voted in poll", ErrNotFound)
+	}
+	return votes, nil
+}
+
+// openPoll looks the poll with pollId up and returns it, or ErrConflict if
+// it is not taking votes at now.  Like AddVote, a poll the polls API
+// doesn't know is only an error with strict refs, otherwise it is the
+// zero pollRef
+func (v *VoteList) openPoll(pollId uint, now time.Time) (pollRef, error) {
+	var checkPoll pollRef
+	if err := v.getDependency(v.polls, pollId, &checkPoll); err != nil {
+		if v.strictRefs || !errors.Is(err, ErrNotFound) {
+			return pollRef{}, notFoundOr(err, "poll does not exists")
+		}
+	}
+	return checkPoll, checkPoll.checkOpen(now)
+}
+
+// removeVoteHistory takes the poll with pollId out of the VoteHistory of
+// the voter with voterId through the voters API.  A voter or history entry
+// that is already gone is not an error
+func (v *VoteList) removeVoteHistory(voterId uint, pollId uint) error {
+	ctx, cancel := context.WithTimeout(v.context, v.dependencyTimeout)
+	defer cancel()
+
+	path := fmt.Sprintf("%s/%d/polls/%d", v.voters.path, voterId, pollId)
+	_, _, err := v.voters.send(ctx, http.MethodDelete, path)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
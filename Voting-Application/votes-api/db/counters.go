@@ -0,0 +1,140 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+)
+
+// CountKeyPattern matches the vote counter of every poll
+const CountKeyPattern = "poll:*:count"
+
+// Each poll has a count of its votes, it looks like poll:<number>:count.
+// The count is kept up to date as votes are added, moved and removed so
+// that it can be read without scanning every vote
+func countKeyFromPollId(id uint) string {
+	return fmt.Sprintf("poll:%d:count", id)
+}
+
+// adjustPollCount adds delta to the vote count of a poll.  The vote has
+// already been written by the time this is called, so a failure is only
+// logged, RecountVotes will put the count right
+func (c *cache) adjustPollCount(pollId uint, delta int64) {
+	if err := c.cacheClient.IncrBy(c.context, countKeyFromPollId(pollId), delta).Err(); err != nil {
+		log.Println("Error adjusting vote count of poll ", pollId, ": ", err)
+	}
+}
+
+// CountCorrection records a poll vote counter that RecountVotes found to
+// be wrong, and the values it had and now has
+type CountCorrection struct {
+	PollID uint  `json:"pollId"`
+	Was    int64 `json:"was"`
+	Now    int64 `json:"now"`
+}
+
+// RecountVotes counts the votes of every poll by scanning all of the
+// votes, and overwrites any poll vote counter that has drifted from that.
+// The vote indexes of every poll and voter are rebuilt from the same scan.  Votes that
+// arrive while the recount is running may leave a counter or index off
+// by those votes, running it again will fix it
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) Every poll counter and vote index will match the votes
+//			in the DB, those of polls and voters with no votes are
+//			removed
+//		(2) The counters that were corrected will be returned, sorted
+//			by poll id, an empty slice if none were
+//		(3) If the votes are not kept in redis, which is where the
+//			counters and indexes are, ErrNeedsRedis will be returned
+//		(4) If there is an error, it will be returned
+func (v *VoteList) RecountVotes() ([]CountCorrection, error) {
+	if err := v.needsRedis(); err != nil {
+		return nil, err
+	}
+
+	//Count the votes themselves
+	actual := make(map[uint]int64)
+	byPoll := make(map[uint][]uint)
+	byVoter := make(map[uint][]uint)
+	ks, err := v.scanKeys(RedisKeyPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	votes, err := v.getItemsFromRedis(ks)
+	if err != nil {
+		return nil, err
+	}
+	for _, vote := range votes {
+		actual[vote.PollID]++
+		byPoll[vote.PollID] = append(byPoll[vote.PollID], vote.VoteID)
+		if vote.VoterID != 0 {
+			byVoter[vote.VoterID] = append(byVoter[vote.VoterID], vote.VoteID)
+		}
+	}
+	if err := v.rebuildIndex(PollVotesKeyPattern, "poll-votes:%d", byPoll); err != nil {
+		return nil, err
+	}
+	if err := v.rebuildIndex(VoterVotesKeyPattern, "voter-votes:%d", byVoter); err != nil {
+		return nil, err
+	}
+	if err := v.cacheClient.Set(v.context, VoterIndexBuiltKey, "1", 0).Err(); err != nil {
+		return nil, err
+	}
+
+	//Read what the counters say
+	stored := make(map[uint]int64)
+	countKeys, err := v.scanKeys(CountKeyPattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range countKeys {
+		var pollId uint
+		if _, err := fmt.Sscanf(key, "poll:%d:count", &pollId); err != nil {
+			continue
+		}
+		val, err := v.cacheClient.Get(v.context, key).Result()
+		if err != nil {
+			if err.Error() == RedisNilError {
+				continue
+			}
+			return nil, err
+		}
+		count, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			//Not a number, so it is wrong whatever it says
+			count = -1
+		}
+		stored[pollId] = count
+	}
+
+	corrections := []CountCorrection{}
+	for pollId, count := range actual {
+		if stored[pollId] == count {
+			continue
+		}
+		if err := v.cacheClient.Set(v.context, countKeyFromPollId(pollId), count, 0).Err(); err != nil {
+			return nil, err
+		}
+		corrections = append(corrections, CountCorrection{PollID: pollId, Was: stored[pollId], Now: count})
+	}
+	for pollId, count := range stored {
+		if _, ok := actual[pollId]; ok {
+			continue
+		}
+		if err := v.cacheClient.Del(v.context, countKeyFromPollId(pollId)).Err(); err != nil {
+			return nil, err
+		}
+		if count != 0 {
+			corrections = append(corrections, CountCorrection{PollID: pollId, Was: count, Now: 0})
+		}
+	}
+
+	sort.Slice(corrections, func(i, j int) bool {
+		return corrections[i].PollID < corrections[j].PollID
+	})
+	return corrections, nil
+}
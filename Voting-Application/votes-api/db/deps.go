@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"drexel.edu/voting-application/client/httpclient"
+)
+
+// DefaultVotersAPIURL and DefaultPollsAPIURL are where the voters and
+// polls APIs are looked for when VOTERS_API_URL and POLLS_API_URL are not
+// set
+const (
+	DefaultVotersAPIURL = "http://localhost:1080"
+	DefaultPollsAPIURL  = "http://localhost:1090"
+)
+
+// DefaultDependencyCacheTTL is how long a voter or poll that was looked up
+// is reused when VOTE_DEPENDENCY_CACHE_TTL is not set
+const DefaultDependencyCacheTTL = 5 * time.Second
+
+// maxCachedDependencies is how many voters or polls a dependency keeps
+// before it drops the ones that have run out
+const maxCachedDependencies = 10000
+
+// dependency is another service the votes API looks voters or polls up
+// in over HTTP, so that it does not need to share their database.  Items
+// that were found are kept for cacheTTL, one that was not found is asked
+// for again every time, so a voter added just before its vote is seen.
+// The requests are retried and go through a circuit breaker, see
+// httpclient
+type dependency struct {
+	//name is the service, for ErrDependencyUnavailable
+	name string
+	//baseURL is where the service is, e.g. http://localhost:1080
+	baseURL string
+	//path is the path of the list, an item is at path/<id>
+	path   string
+	client *httpclient.Client
+	//adminToken is sent in X-Admin-Token, for the endpoints of the
+	//service that need it when it has FEATURE_AUTH on
+	adminToken string
+	cacheTTL   time.Duration
+
+	mu     sync.Mutex
+	cached map[uint]cachedDependency
+}
+
+// cachedDependency is an item a dependency looked up, as JSON
+type cachedDependency struct {
+	doc     []byte
+	expires time.Time
+}
+
+// newDependency returns a dependency on the service named name, at the URL
+// in the environment variable urlEnv or else defaultURL, that keeps its
+// items under path
+func newDependency(name string, urlEnv string, defaultURL string, path string) *dependency {
+	baseURL := defaultURL
+	if u := os.Getenv(urlEnv); u != "" {
+		baseURL = u
+	}
+	return &dependency{
+		name:       name,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		path:       path,
+		client:     httpclient.New(name, dependencyClientConfigFromEnv()),
+		adminToken: os.Getenv("ADMIN_TOKEN"),
+		cacheTTL:   dependencyCacheTTLFromEnv(),
+		cached:     make(map[uint]cachedDependency),
+	}
+}
+
+// dependencyCacheTTLFromEnv returns VOTE_DEPENDENCY_CACHE_TTL, or the
+// default if it is not set or not a valid duration.  0 turns the cache off
+func dependencyCacheTTLFromEnv() time.Duration {
+	ttl := DefaultDependencyCacheTTL
+	if ttlS := os.Getenv("VOTE_DEPENDENCY_CACHE_TTL"); ttlS != "" {
+		if d, err := time.ParseDuration(ttlS); err == nil && d >= 0 {
+			ttl = d
+		} else {
+			log.Println("Invalid VOTE_DEPENDENCY_CACHE_TTL, using default: ", ttlS)
+		}
+	}
+	return ttl
+}
+
+// dependencyClientConfigFromEnv returns the retries and circuit breaker
+// settings of the dependencies, the httpclient defaults where they are
+// not set in the environment
+func dependencyClientConfigFromEnv() httpclient.Config {
+	config := httpclient.DefaultConfig()
+
+	//How many more times a lookup is tried after a network error,
+	//timeout or 5xx, 0 turns retries off
+	if retries := os.Getenv("VOTE_DEPENDENCY_RETRIES"); retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			log.Println("Invalid VOTE_DEPENDENCY_RETRIES, using default: ", retries)
+		} else {
+			config.Retries = n
+		}
+	}
+
+	//How long each try has, as a go duration string such as "500ms".
+	//VOTE_DEPENDENCY_TIMEOUT still bounds the lookup as a whole
+	if timeout := os.Getenv("VOTE_DEPENDENCY_ATTEMPT_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			log.Println("Invalid VOTE_DEPENDENCY_ATTEMPT_TIMEOUT, using default: ", timeout)
+		} else {
+			config.AttemptTimeout = d
+		}
+	}
+
+	//How many lookups in a row can fail before the service is not
+	//called for VOTE_DEPENDENCY_BREAKER_OPEN
+	if failures := os.Getenv("VOTE_DEPENDENCY_BREAKER_FAILURES"); failures != "" {
+		n, err := strconv.Atoi(failures)
+		if err != nil || n <= 0 {
+			log.Println("Invalid VOTE_DEPENDENCY_BREAKER_FAILURES, using default: ", failures)
+		} else {
+			config.FailureThreshold = n
+		}
+	}
+
+	if open := os.Getenv("VOTE_DEPENDENCY_BREAKER_OPEN"); open != "" {
+		d, err := time.ParseDuration(open)
+		if err != nil || d <= 0 {
+			log.Println("Invalid VOTE_DEPENDENCY_BREAKER_OPEN, using default: ", open)
+		} else {
+			config.OpenFor = d
+		}
+	}
+	return config
+}
+
+// unavailable is ErrDependencyUnavailable naming the service
+func (d *dependency) unavailable() error {
+	return fmt.Errorf("%w: %s", ErrDependencyUnavailable, d.name)
+}
+
+// fromCache returns the item with id if it was looked up less than the
+// cache TTL ago
+func (d *dependency) fromCache(id uint) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	item, ok := d.cached[id]
+	if !ok || time.Now().After(item.expires) {
+		return nil, false
+	}
+	return item.doc, true
+}
+
+// keep caches the item with id for the cache TTL
+func (d *dependency) keep(id uint, doc []byte) {
+	if d.cacheTTL <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	if len(d.cached) >= maxCachedDependencies {
+		for cachedID, item := range d.cached {
+			if now.After(item.expires) {
+				delete(d.cached, cachedID)
+			}
+		}
+	}
+	//Still full of items that have not run out, start again rather
+	//than grow without end
+	if len(d.cached) >= maxCachedDependencies {
+		d.cached = make(map[uint]cachedDependency)
+	}
+	d.cached[id] = cachedDependency{doc: doc, expires: now.Add(d.cacheTTL)}
+}
+
+// fetch sends a GET for path, which can have a query, to the service, see
+// send
+func (d *dependency) fetch(ctx context.Context, path string) ([]byte, http.Header, error) {
+	return d.send(ctx, http.MethodGet, path)
+}
+
+// send sends a request with method for path to the service and returns
+// the body and headers of a 2xx.  A 404 is ErrNotFound, and anything else,
+// the service not answering in time or at all or its circuit breaker
+// being open included, is ErrDependencyUnavailable
+func (d *dependency) send(ctx context.Context, method string, path string) ([]byte, http.Header, error) {
+	return d.sendWithHeader(ctx, method, path, nil)
+}
+
+// sendWithHeader is send with header added to the request
+func (d *dependency) sendWithHeader(ctx context.Context, method string, path string, header http.Header) ([]byte, http.Header, error) {
+	return d.response(d.client.DoWithHeader(ctx, method, d.baseURL+path, d.withAdminToken(header)))
+}
+
+// sendOnce is sendWithHeader without retries, for a request that uses
+// something up, which a retry after an attempt that timed out but went
+// through would find already used
+func (d *dependency) sendOnce(ctx context.Context, method string, path string, header http.Header) ([]byte, http.Header, error) {
+	return d.response(d.client.DoOnceWithHeader(ctx, method, d.baseURL+path, d.withAdminToken(header)))
+}
+
+// withAdminToken returns header with the admin token added, if there is
+// one
+func (d *dependency) withAdminToken(header http.Header) http.Header {
+	if d.adminToken == "" {
+		return header
+	}
+	withToken := header.Clone()
+	if withToken == nil {
+		withToken = make(http.Header)
+	}
+	withToken.Set("X-Admin-Token", d.adminToken)
+	return withToken
+}
+
+// response returns the body and headers of resp, ErrNotFound for a 404,
+// or d.unavailable() if err is set
+func (d *dependency) response(resp *httpclient.Response, err error) ([]byte, http.Header, error) {
+	if err != nil {
+		if !errors.Is(err, httpclient.ErrCircuitOpen) {
+			log.Println("Error reaching "+d.name+": ", err)
+		}
+		return nil, nil, d.unavailable()
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, ErrNotFound
+	}
+	return resp.Body, resp.Header, nil
+}
+
+// get returns the item with id as JSON, from the cache if it was looked
+// up recently
+func (d *dependency) get(ctx context.Context, id uint) ([]byte, error) {
+	if doc, ok := d.fromCache(id); ok {
+		return doc, nil
+	}
+	doc, _, err := d.fetch(ctx, d.path+"/"+strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		return nil, err
+	}
+	d.keep(id, doc)
+	return doc, nil
+}
+
+// walk calls fn with every page of the service's list, as a JSON array,
+// following its ?cursor pages until it says there are no more.  Each
+// page has timeout to arrive
+func (d *dependency) walk(parent context.Context, timeout time.Duration, fn func(page []byte) error) error {
+	cursor := "0"
+	for {
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		page, header, err := d.fetch(ctx, d.path+"?cursor="+cursor)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+
+		cursor = header.Get("X-Next-Cursor")
+		if cursor == "" || cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// getDependency reads the voter or poll with id from the service that
+// owns it, dep, into item.  The lookup gives up after the dependency
+// timeout rather than holding up the vote, in which case
+// ErrDependencyUnavailable is returned naming the service
+func (v *VoteList) getDependency(dep *dependency, id uint, item any) error {
+	ctx, cancel := context.WithTimeout(v.context, v.dependencyTimeout)
+	defer cancel()
+
+	doc, err := dep.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(doc, item)
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// voterRef holds the parts of a voter, owned by the voters API, that are
+// needed to match voter histories against votes
+type voterRef struct {
+	VoterID     uint
+	VoteHistory []struct {
+		PollID uint
+	}
+}
+
+// DataDrift counts the votes and voter history entries that don't match
+// up.  A vote should have an entry for its poll in its voter's history,
+// and every history entry should have a vote, but adding a vote and
+// adding the history entry are separate calls to separate services
+type DataDrift struct {
+	VotesWithoutHistory uint      `json:"votesWithoutHistory"`
+	HistoryWithoutVotes uint      `json:"historyWithoutVotes"`
+	CheckedAt           time.Time `json:"checkedAt"`
+}
+
+// voterPollKey identifies a voter voting in a poll
+type voterPollKey struct {
+	voterId uint
+	pollId  uint
+}
+
+// CheckDrift compares every vote with every voter's history and counts
+// the ones that don't have a match.  The result is also kept for
+// LastDrift.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The drift will be returned
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (v *VoteList) CheckDrift() (DataDrift, error) {
+
+	votes := make(map[voterPollKey]bool)
+	anonymousPolls := make(map[uint]bool)
+	all, err := v.store.list()
+	if err != nil {
+		return DataDrift{}, err
+	}
+	for _, vote := range all {
+		//The votes of anonymous polls don't say whose they are, so they
+		//can't be matched with histories either way
+		if vote.VoterHash != "" {
+			anonymousPolls[vote.PollID] = true
+			continue
+		}
+		votes[voterPollKey{voterId: vote.VoterID, pollId: vote.PollID}] = true
+	}
+
+	//The voters are read a page at a time from the voters API, which
+	//owns them
+	history := make(map[voterPollKey]bool)
+	err = v.voters.walk(v.context, v.dependencyTimeout, func(page []byte) error {
+		var voters []voterRef
+		if err := json.Unmarshal(page, &voters); err != nil {
+			return err
+		}
+		for _, voter := range voters {
+			for _, poll := range voter.VoteHistory {
+				if anonymousPolls[poll.PollID] {
+					continue
+				}
+				history[voterPollKey{voterId: voter.VoterID, pollId: poll.PollID}] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return DataDrift{}, err
+	}
+
+	drift := DataDrift{CheckedAt: time.Now()}
+	for key := range votes {
+		if !history[key] {
+			drift.VotesWithoutHistory++
+		}
+	}
+	for key := range history {
+		if !votes[key] {
+			drift.HistoryWithoutVotes++
+		}
+	}
+
+	v.lastDrift.Store(&drift)
+	return drift, nil
+}
+
+// LastDrift returns the result of the last drift check, false if there
+// hasn't been one
+func (v *VoteList) LastDrift() (DataDrift, bool) {
+	drift := v.lastDrift.Load()
+	if drift == nil {
+		return DataDrift{}, false
+	}
+	return *drift, true
+}
+
+// watchDrift checks for drift every interval in the background, so that
+// the drift metric stays current without anyone asking for it
+func (v *VoteList) watchDrift(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := v.CheckDrift(); err != nil {
+				log.Println("Error checking drift: ", err)
+			}
+		}
+	}()
+}
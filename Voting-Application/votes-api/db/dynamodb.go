@@ -0,0 +1,379 @@
+//go:build dynamodb
+
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDefaultTable is the table the votes are kept in when
+// DYNAMODB_TABLE is not set
+const DynamoDefaultTable = "votes"
+
+// The global secondary indexes that stand in for the poll and voter
+// indexes kept in redis, see pollindex.go and voterindex.go
+const (
+	DynamoPollIndex  = "PollID-index"
+	DynamoVoterIndex = "VoterID-index"
+)
+
+// dynamoTimeout bounds every call to DynamoDB, so that an unreachable
+// endpoint fails the request instead of hanging it.  Creating the table
+// is given dynamoCreateTimeout instead, a new table takes a while to
+// become active
+const (
+	dynamoTimeout       = 5 * time.Second
+	dynamoCreateTimeout = 2 * time.Minute
+)
+
+// dynamoBatchSize is the most deletes BatchWriteItem takes at once
+const dynamoBatchSize = 25
+
+// dynamoStore keeps votes as items in a DynamoDB table keyed by VoteID,
+// so the votes API can run on Lambda or Fargate without a redis to look
+// after.  Each item is the vote, with its fields as attributes of the
+// same names, so PollID and VoterID can be the keys of the indexes
+type dynamoStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoVoteList connects to DynamoDB with the usual AWS settings from
+// the environment, AWS_REGION and the credentials, creates the table in
+// DYNAMODB_TABLE if it is not there and returns a VoteList that keeps its
+// votes there instead of redis.  DYNAMODB_ENDPOINT points it somewhere
+// other than AWS, such as DynamoDB Local.  The trash, the vote counters,
+// pausing, rate limits, anonymous polls, idempotency keys and the audit
+// log need redis, so they are not available.  It is only available in
+// builds made with -tags dynamodb
+func NewDynamoVoteList() (*VoteList, error) {
+	table := os.Getenv("DYNAMODB_TABLE")
+	if table == "" {
+		table = DynamoDefaultTable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoCreateTimeout)
+	defer cancel()
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		if endpoint := os.Getenv("DYNAMODB_ENDPOINT"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+
+	store := &dynamoStore{client: client, table: table}
+	if err := store.provision(ctx); err != nil {
+		return nil, err
+	}
+
+	voteList := newVoteList(store, withoutRedis())
+	voteList.startFromEnv()
+
+	return voteList, nil
+}
+
+// provision creates the table and its indexes if the table is not there
+// yet, and waits for it to become active.  Several replicas starting at
+// once may all try, those that lose just wait for the winner's table
+func (d *dynamoStore) provision(ctx context.Context) error {
+	_, err := d.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(d.table)})
+	var notFound *types.ResourceNotFoundException
+	if err == nil || !errors.As(err, &notFound) {
+		return err
+	}
+
+	_, err = d.client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:   aws.String(d.table),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("VoteID"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("PollID"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("VoterID"), AttributeType: types.ScalarAttributeTypeN},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("VoteID"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			dynamoIndex(DynamoPollIndex, "PollID"),
+			dynamoIndex(DynamoVoterIndex, "VoterID"),
+		},
+	})
+	var inUse *types.ResourceInUseException
+	if err != nil && !errors.As(err, &inUse) {
+		return err
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(d.client)
+	return waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(d.table)}, dynamoCreateTimeout)
+}
+
+// dynamoIndex is a global secondary index on the number attribute
+// attribute that has the whole votes in it
+func dynamoIndex(name string, attribute string) types.GlobalSecondaryIndex {
+	return types.GlobalSecondaryIndex{
+		IndexName: aws.String(name),
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(attribute), KeyType: types.KeyTypeHash},
+		},
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+	}
+}
+
+// dynamoKey is the key of the item of the vote with id
+func dynamoKey(id uint64) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"VoteID": &types.AttributeValueMemberN{Value: strconv.FormatUint(id, 10)},
+	}
+}
+
+// unmarshalVotes reads items as votes
+func unmarshalVotes(items []map[string]types.AttributeValue) ([]Vote, error) {
+	var voteList []Vote
+	if err := attributevalue.UnmarshalListOfMaps(items, &voteList); err != nil {
+		return nil, err
+	}
+	return voteList, nil
+}
+
+func (d *dynamoStore) get(id uint) (Vote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	result, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.table),
+		Key:            dynamoKey(uint64(id)),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return Vote{}, err
+	}
+	if result.Item == nil {
+		return Vote{}, ErrNotFound
+	}
+
+	var vote Vote
+	if err := attributevalue.UnmarshalMap(result.Item, &vote); err != nil {
+		return Vote{}, err
+	}
+	return vote, nil
+}
+
+// create only writes the vote if there is no item with its id, so of two
+// votes added with the same id at once only one goes in
+func (d *dynamoStore) create(vote Vote) error {
+	item, err := attributevalue.MarshalMap(vote)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(d.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(VoteID)"),
+	})
+	var failed *types.ConditionalCheckFailedException
+	if errors.As(err, &failed) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// putVersion checks the version in the condition of the same PutItem
+// that writes the vote
+func (d *dynamoStore) putVersion(vote Vote, version uint) error {
+	vote.Version = version + 1
+	item, err := attributevalue.MarshalMap(vote)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(d.table),
+		Item:                item,
+		ConditionExpression: aws.String("Version = :version"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(version), 10)},
+		},
+	})
+	var failed *types.ConditionalCheckFailedException
+	if errors.As(err, &failed) {
+		return missedVersion(d, vote.VoteID, version)
+	}
+	return err
+}
+
+func (d *dynamoStore) delete(vote Vote) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	_, err := d.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(d.table),
+		Key:                 dynamoKey(uint64(vote.VoteID)),
+		ConditionExpression: aws.String("attribute_exists(VoteID)"),
+	})
+	var failed *types.ConditionalCheckFailedException
+	if errors.As(err, &failed) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// deleteAll deletes the votes dynamoBatchSize at a time, sending the
+// deletes DynamoDB did not get to again until they are all done
+func (d *dynamoStore) deleteAll() error {
+	voteList, err := d.list()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	var requests []types.WriteRequest
+	for _, vote := range voteList {
+		requests = append(requests, types.WriteRequest{
+			DeleteRequest: &types.DeleteRequest{Key: dynamoKey(uint64(vote.VoteID))},
+		})
+	}
+	for len(requests) > 0 {
+		n := min(len(requests), dynamoBatchSize)
+		result, err := d.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{d.table: requests[:n]},
+		})
+		if err != nil {
+			return err
+		}
+		requests = append(result.UnprocessedItems[d.table], requests[n:]...)
+	}
+	return nil
+}
+
+func (d *dynamoStore) list() ([]Vote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	var voteList []Vote
+	pages := dynamodb.NewScanPaginator(d.client, &dynamodb.ScanInput{
+		TableName:      aws.String(d.table),
+		ConsistentRead: aws.Bool(true),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		votes, err := unmarshalVotes(page.Items)
+		if err != nil {
+			return nil, err
+		}
+		voteList = append(voteList, votes...)
+	}
+	sortVotes(voteList)
+	return voteList, nil
+}
+
+// listPage pages through a Scan of the table, which is in DynamoDB's own
+// order rather than by id.  The cursor is one more than the VoteID of the
+// last vote scanned, so that a vote with id 0 can still end a page
+func (d *dynamoStore) listPage(cursor uint64, count int) ([]Vote, uint64, error) {
+	count = pageCount(count)
+	input := &dynamodb.ScanInput{
+		TableName:      aws.String(d.table),
+		ConsistentRead: aws.Bool(true),
+		Limit:          aws.Int32(int32(count)),
+	}
+	if cursor > 0 {
+		input.ExclusiveStartKey = dynamoKey(cursor - 1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	page, err := d.client.Scan(ctx, input)
+	if err != nil {
+		return nil, 0, err
+	}
+	voteList, err := unmarshalVotes(page.Items)
+	if err != nil {
+		return nil, 0, err
+	}
+	sortVotes(voteList)
+
+	var next uint64
+	if last, ok := page.LastEvaluatedKey["VoteID"].(*types.AttributeValueMemberN); ok {
+		id, err := strconv.ParseUint(last.Value, 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		next = id + 1
+	}
+	return voteList, next, nil
+}
+
+// query returns the votes whose attribute, the key of index, is id.
+// Global secondary indexes can't be read consistently, so a vote written
+// a moment ago may not be there yet
+func (d *dynamoStore) query(index string, attribute string, id uint) ([]Vote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	var voteList []Vote
+	pages := dynamodb.NewQueryPaginator(d.client, &dynamodb.QueryInput{
+		TableName:                aws.String(d.table),
+		IndexName:                aws.String(index),
+		KeyConditionExpression:   aws.String("#key = :id"),
+		ExpressionAttributeNames: map[string]string{"#key": attribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(id), 10)},
+		},
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		votes, err := unmarshalVotes(page.Items)
+		if err != nil {
+			return nil, err
+		}
+		voteList = append(voteList, votes...)
+	}
+	sortVotes(voteList)
+	return voteList, nil
+}
+
+func (d *dynamoStore) byPoll(pollId uint) ([]Vote, error) {
+	return d.query(DynamoPollIndex, "PollID", pollId)
+}
+
+func (d *dynamoStore) byVoter(voterId uint) ([]Vote, error) {
+	return d.query(DynamoVoterIndex, "VoterID", voterId)
+}
+
+func (d *dynamoStore) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoTimeout)
+	defer cancel()
+
+	_, err := d.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(d.table)})
+	return err
+}
@@ -0,0 +1,14 @@
+//go:build !dynamodb
+
+package db
+
+import "errors"
+
+// ErrDynamoNotBuilt is returned when the DynamoDB store is asked for by a
+// build made without -tags dynamodb, which leaves out the AWS SDK
+var ErrDynamoNotBuilt = errors.New("built without DynamoDB support, rebuild with -tags dynamodb")
+
+// NewDynamoVoteList is only available in builds made with -tags dynamodb
+func NewDynamoVoteList() (*VoteList, error) {
+	return nil, ErrDynamoNotBuilt
+}
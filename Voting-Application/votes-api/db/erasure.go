@@ -0,0 +1,127 @@
+package db
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// VoterErasure is what AnonymizeVoterVotes did to the votes of a voter
+// who asked to be forgotten
+type VoterErasure struct {
+	VoterID uint
+	//Anonymized is how many of their votes, and Trashed how many of
+	//their deleted votes in the trash, no longer say who cast them
+	Anonymized int
+	Trashed    int
+}
+
+// erasedVoterHash returns a random VoterHash for a vote whose voter has
+// been erased.  It matches no voter, so the vote can't be tied back to
+// them, but like the votes of an anonymous poll it is still counted and
+// is not taken for an orphan for having no voter
+func erasedVoterHash() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// anonymizeVote takes the voter off the vote, as it was read, and gives
+// it an erased voter hash.  write stores it if the vote's Version is
+// still version
+func anonymizeVote(vote Vote, write func(vote Vote, version uint) error) (Vote, error) {
+	hash, err := erasedVoterHash()
+	if err != nil {
+		return Vote{}, err
+	}
+	version := vote.Version
+	vote.VoterID = 0
+	vote.VoterHash = hash
+	vote.Version++
+	if err := write(vote, version); err != nil {
+		return Vote{}, err
+	}
+	return vote, nil
+}
+
+// AnonymizeVoterVotes accepts a voter id and takes the voter off every
+// vote they cast, the ones in the trash included, for a voter who is
+// being erased by the voters API.  The votes keep their polls and values,
+// so the polls' results don't change.  Calling it again does nothing more,
+// so a voters API that did not hear back can simply call it again.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) No vote, in the DB or the trash, will have the VoterID
+//			and the voter's vote index, if the store has one, will be
+//			gone
+//		(2) Each vote that had it will have VoterID 0, a random
+//			VoterHash and its Version up by one
+//		(3) If a vote changes while it is being anonymized an error
+//			wrapping ErrVersionMismatch will be returned, and the
+//			votes done before it stay done
+//		(4) If there is an error, it will be returned
+func (v *VoteList) AnonymizeVoterVotes(voterId uint) (VoterErasure, error) {
+
+	erasure := VoterErasure{VoterID: voterId}
+	if voterId == 0 {
+		return erasure, nil
+	}
+
+	votes, err := v.GetVotesByVoter(voterId)
+	if err != nil {
+		return VoterErasure{}, err
+	}
+	for _, vote := range votes {
+		anonymized, err := anonymizeVote(vote, v.store.putVersion)
+		if errors.Is(err, ErrNotFound) {
+			//Deleted since the votes were read, it is in the trash
+			continue
+		}
+		if err != nil {
+			return VoterErasure{}, fmt.Errorf("anonymizing vote %d: %w", vote.VoteID, err)
+		}
+		v.publishVoteEvent(VoteUpdated, anonymized)
+		erasure.Anonymized++
+	}
+	//The rest is the index and the trash, which only redis has
+	if v.needsRedis() != nil {
+		return erasure, nil
+	}
+	if err := v.cacheClient.Del(v.context, voterVotesKeyFromVoterId(voterId)).Err(); err != nil {
+		return VoterErasure{}, err
+	}
+
+	//Deleted votes are not in the voter's index, every vote in the trash
+	//has to be looked at.  Anonymizing them leaves their expiry alone
+	ks, err := v.scanKeys(TrashKeyPrefix + "*")
+	if err != nil {
+		return VoterErasure{}, err
+	}
+	for _, key := range ks {
+		var vote Vote
+		if err := v.getItemFromRedis(key, &vote); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				//Restored or purged since the keys were listed
+				continue
+			}
+			return VoterErasure{}, err
+		}
+		if vote.VoterID != voterId {
+			continue
+		}
+		trashed := func(vote Vote, version uint) error {
+			return v.setVersioned(key, ".", vote, version)
+		}
+		if _, err := anonymizeVote(vote, trashed); err != nil && !errors.Is(err, ErrNotFound) {
+			return VoterErasure{}, fmt.Errorf("anonymizing deleted vote %d: %w", vote.VoteID, err)
+		}
+		erasure.Trashed++
+	}
+
+	return erasure, nil
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// VotesChangedChannel is the redis pub/sub channel the votes API
+// publishes to whenever the votes of a poll change, with the poll id as
+// the message, or "*" when every vote was deleted.  The polls API listens
+// on it to push fresh results to anyone streaming them
+const VotesChangedChannel = "votes:changed"
+
+// VoteEventsChannel is the redis pub/sub channel every replica of the
+// votes API publishes its VoteEvents to as JSON, so that each replica
+// can pass on the events of the others to its own watchers
+const VoteEventsChannel = "votes:events"
+
+// The kinds of VoteEvent.  A vote that is moved to another poll is sent
+// as deleted from the old poll and created in the new one, so that
+// watchers of a single poll see it come and go
+const (
+	VoteCreated  = "vote-created"
+	VoteUpdated  = "vote-updated"
+	VoteDeleted  = "vote-deleted"
+	VotesCleared = "votes-cleared"
+)
+
+// VoteEventBuffer is how many events a watcher can fall behind by before
+// it is dropped
+const VoteEventBuffer = 64
+
+// VoteEvent is a change to the votes.  Vote is the vote as it now is, or
+// as it was before it was deleted, and is left out of VotesCleared
+type VoteEvent struct {
+	Type string `json:"type"`
+	Vote *Vote  `json:"vote,omitempty"`
+}
+
+// publishVoteEvent tells anyone listening that a vote has changed.  The
+// vote has already been written by the time this is called, so a failure
+// is only logged, listeners will catch up on the next change
+func (v *VoteList) publishVoteEvent(kind string, vote Vote) {
+	event := VoteEvent{Type: kind, Vote: &vote}
+	v.publishVotesChanged(strconv.FormatUint(uint64(vote.PollID), 10))
+	v.publishEvent(event)
+}
+
+// publishVotesCleared tells anyone listening that every vote was deleted
+func (v *VoteList) publishVotesCleared() {
+	v.publishVotesChanged("*")
+	v.publishEvent(VoteEvent{Type: VotesCleared})
+}
+
+// publishVotesChanged is only for the polls API, which listens in redis
+func (v *VoteList) publishVotesChanged(msg string) {
+	if v.needsRedis() != nil {
+		return
+	}
+	if err := v.cacheClient.Publish(v.context, VotesChangedChannel, msg).Err(); err != nil {
+		log.Println("Error publishing votes changed: ", err)
+	}
+}
+
+// publishEvent sends event to every replica through redis, this one
+// included.  Without redis there is no way to reach the others, so it
+// goes straight to this replica's watchers
+func (v *VoteList) publishEvent(event VoteEvent) {
+	if v.needsRedis() != nil {
+		v.eventWatchers.send(event)
+		return
+	}
+	msg, err := json.Marshal(event)
+	if err != nil {
+		log.Println("Error encoding vote event: ", err)
+		return
+	}
+	if err := v.cacheClient.Publish(v.context, VoteEventsChannel, msg).Err(); err != nil {
+		log.Println("Error publishing vote event: ", err)
+	}
+}
+
+// voteEventWatchers hands the vote events this replica hears about to
+// the callers of WatchVoteEvents.  There is one redis subscription
+// however many are watching
+type voteEventWatchers struct {
+	lock     sync.Mutex
+	watchers map[chan VoteEvent]struct{}
+}
+
+func newVoteEventWatchers() *voteEventWatchers {
+	return &voteEventWatchers{watchers: make(map[chan VoteEvent]struct{})}
+}
+
+func (w *voteEventWatchers) add() chan VoteEvent {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	ch := make(chan VoteEvent, VoteEventBuffer)
+	w.watchers[ch] = struct{}{}
+	return ch
+}
+
+// remove stops sending to ch and closes it, if that hasn't been done
+// already
+func (w *voteEventWatchers) remove(ch chan VoteEvent) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if _, ok := w.watchers[ch]; ok {
+		delete(w.watchers, ch)
+		close(ch)
+	}
+}
+
+// send passes event on to every watcher.  A watcher whose buffer is full
+// has fallen too far behind to be sent a complete picture, so it is
+// dropped, rather than holding up everyone else
+func (w *voteEventWatchers) send(event VoteEvent) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for ch := range w.watchers {
+		select {
+		case ch <- event:
+		default:
+			log.Println("Dropping vote event watcher that fell behind")
+			delete(w.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// WatchVoteEvents returns a channel that receives every VoteEvent from
+// every replica, and a function that stops the watching.  The channel is
+// closed when the watching stops, including when the watcher falls more
+// than VoteEventBuffer events behind
+func (v *VoteList) WatchVoteEvents() (<-chan VoteEvent, func()) {
+	ch := v.eventWatchers.add()
+	return ch, func() {
+		v.eventWatchers.remove(ch)
+	}
+}
+
+// subscribeVoteEvents listens for the vote events published by every
+// replica and passes them on to the watchers.  It returns once the
+// subscription is in place, the listening carries on in the background
+func (v *VoteList) subscribeVoteEvents() error {
+	pubsub := v.cacheClient.Subscribe(v.context, VoteEventsChannel)
+	if _, err := pubsub.Receive(v.context); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	go func(ch <-chan *redis.Message) {
+		for msg := range ch {
+			var event VoteEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Println("Ignoring invalid vote event: ", msg.Payload)
+				continue
+			}
+			v.eventWatchers.send(event)
+		}
+	}(pubsub.Channel())
+
+	return nil
+}
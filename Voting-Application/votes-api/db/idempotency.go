@@ -0,0 +1,128 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// IdempotencyKeyPrefix is where what happened to a POST /votes sent with
+// an Idempotency-Key is kept, under the prefix and the key.  Like the
+// other indexes it is kept out of the votes: prefix
+const IdempotencyKeyPrefix = "vote-idempotency:"
+
+// DefaultIdempotencyTTL is how long the response to a request with an
+// Idempotency-Key is kept when VOTE_IDEMPOTENCY_TTL is not set
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyPendingTTL is how long a key is held for a request that is
+// still being handled.  It is longer than a request can take by default,
+// and short so that a replica that dies half way through doesn't hold
+// the key for long
+const IdempotencyPendingTTL = time.Minute
+
+// ErrIdempotencyInFlight is returned by ClaimIdempotencyKey while another
+// request with the same key is still being handled
+var ErrIdempotencyInFlight = fmt.Errorf("%w: a request with this Idempotency-Key is still being handled", ErrConflict)
+
+// ErrIdempotencyKeyReused is returned by ClaimIdempotencyKey when the key
+// was already used for a request with a different body
+var ErrIdempotencyKeyReused = errors.New("Idempotency-Key was already used with a different request body")
+
+// IdempotentResponse is what is kept for an Idempotency-Key.  RequestHash
+// is the hash of the body of the request that claimed the key, the rest
+// is the response it got, Status is 0 until it has one
+type IdempotentResponse struct {
+	RequestHash string
+	Status      int
+	Location    string          `json:",omitempty"`
+	Body        json.RawMessage `json:",omitempty"`
+}
+
+// ClaimIdempotencyKey claims key for a request whose body hashes to
+// requestHash.  If nothing has used the key it is held for the request
+// and nil is returned, the request should be handled and then passed to
+// CompleteIdempotencyKey or ReleaseIdempotencyKey.  If a request with the
+// same body already got a response, that response is returned to be sent
+// again
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) If the key was free it will be held for IdempotencyPendingTTL
+//		(2) If the key is held by a request still being handled
+//			ErrIdempotencyInFlight will be returned
+//		(3) If the key was used with a different body
+//			ErrIdempotencyKeyReused will be returned
+//		(4) If the votes are not kept in redis, where the keys are
+//			held, ErrNeedsRedis will be returned
+//		(5) If there is an error, it will be returned
+func (v *VoteList) ClaimIdempotencyKey(key string, requestHash string) (*IdempotentResponse, error) {
+	if err := v.needsRedis(); err != nil {
+		return nil, err
+	}
+
+	redisKey := IdempotencyKeyPrefix + key
+	pending, err := json.Marshal(IdempotentResponse{RequestHash: requestHash})
+	if err != nil {
+		return nil, err
+	}
+
+	//The key can expire or be released between the SETNX and the GET,
+	//in which case it is free to claim again
+	for i := 0; i < 2; i++ {
+		claimed, err := v.cacheClient.SetNX(v.context, redisKey, pending, IdempotencyPendingTTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			return nil, nil
+		}
+
+		doc, err := v.cacheClient.Get(v.context, redisKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var stored IdempotentResponse
+		if err := json.Unmarshal(doc, &stored); err != nil {
+			return nil, err
+		}
+		if stored.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if stored.Status == 0 {
+			return nil, ErrIdempotencyInFlight
+		}
+		return &stored, nil
+	}
+	return nil, ErrIdempotencyInFlight
+}
+
+// CompleteIdempotencyKey keeps the response to the request that claimed
+// key, so that a retry of it gets the same response, for the idempotency
+// TTL from now
+func (v *VoteList) CompleteIdempotencyKey(key string, response IdempotentResponse) error {
+	if err := v.needsRedis(); err != nil {
+		return err
+	}
+	doc, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return v.cacheClient.Set(v.context, IdempotencyKeyPrefix+key, doc, v.idempotencyTTL).Err()
+}
+
+// ReleaseIdempotencyKey frees key after the request that claimed it
+// failed, so that a retry is handled again rather than sent the failure
+func (v *VoteList) ReleaseIdempotencyKey(key string) error {
+	if err := v.needsRedis(); err != nil {
+		return err
+	}
+	return v.cacheClient.Del(v.context, IdempotencyKeyPrefix+key).Err()
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Votes are indexed by poll and by voter.  Each index is a redis set of
+// vote ids per poll or voter, kept up to date as votes are added, moved
+// and removed so that the votes of one poll or voter can be found
+// without scanning every vote.  A failure to update an index is only
+// logged, the vote has already been written, RecountVotes rebuilds them
+
+// votesInIndex reads the votes whose ids are in the index set at key,
+// only keeping those keep returns true for, since an index can lag a
+// vote being moved.  If the set is empty, unindexed is asked whether
+// that might be because the votes were cast before the index existed, in
+// which case every vote is scanned instead.  The votes are sorted by id
+func (c *cache) votesInIndex(key string, unindexed func() (bool, error), keep func(Vote) bool) ([]Vote, error) {
+
+	ids, err := c.cacheClient.SMembers(c.context, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var ks []string
+	if len(ids) > 0 {
+		ks = make([]string, 0, len(ids))
+		for _, id := range ids {
+			ks = append(ks, RedisKeyPrefix+id)
+		}
+	} else {
+		scan, err := unindexed()
+		if err != nil {
+			return nil, err
+		}
+		if !scan {
+			return []Vote{}, nil
+		}
+		if ks, err = c.scanKeys(RedisKeyPrefix + "*"); err != nil {
+			return nil, err
+		}
+	}
+
+	all, err := c.getItemsFromRedis(ks)
+	if err != nil {
+		return nil, err
+	}
+	voteList := make([]Vote, 0, len(all))
+	for _, vote := range all {
+		if keep(vote) {
+			voteList = append(voteList, vote)
+		}
+	}
+	sort.Slice(voteList, func(i, j int) bool {
+		return voteList[i].VoteID < voteList[j].VoteID
+	})
+
+	return voteList, nil
+}
+
+// rebuildIndex replaces every set of an index with the vote ids in byId,
+// keyed by poll or voter id.  pattern matches every set of the index and
+// keyFormat makes the key of a set from an id, e.g. "poll-votes:*" and
+// "poll-votes:%d".  The sets of ids with no votes are removed
+func (c *cache) rebuildIndex(pattern string, keyFormat string, byId map[uint][]uint) error {
+	indexKeys, err := c.scanKeys(pattern)
+	if err != nil {
+		return err
+	}
+	for _, key := range indexKeys {
+		var id uint
+		if _, err := fmt.Sscanf(key, keyFormat, &id); err != nil {
+			continue
+		}
+		if _, ok := byId[id]; ok {
+			continue
+		}
+		if err := c.cacheClient.Del(c.context, key).Err(); err != nil {
+			return err
+		}
+	}
+
+	for id, voteIds := range byId {
+		members := make([]interface{}, 0, len(voteIds))
+		for _, voteId := range voteIds {
+			members = append(members, strconv.FormatUint(uint64(voteId), 10))
+		}
+		key := fmt.Sprintf(keyFormat, id)
+		//Swap the whole set at once so readers never see it half built
+		_, err := c.cacheClient.TxPipelined(c.context, func(pipe redis.Pipeliner) error {
+			pipe.Del(c.context, key)
+			pipe.SAdd(c.context, key, members...)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// InvitationHeader is the header a vote for an invite only poll has to
+// send its voter's invitation token in, or the token of their ballot
+// link
+const InvitationHeader = "X-Poll-Invitation"
+
+// ErrInvalidInvitation is returned when a vote for an invite only poll
+// does not have an invitation token for its voter that has not been used
+var ErrInvalidInvitation = errors.New("poll invitation is missing, used or wrong")
+
+// redeemInvitation uses up the invitation of the voter with voterId to the
+// poll with token, if the poll is invite only.  The polls API keeps only a hash
+// of the tokens and deletes an invitation once it is used, so the token is
+// sent there rather than checked here.  It is called once the vote has
+// been stored, so a vote turned away for another reason, or that fails to
+// store, doesn't use up the invitation.  A poll
+// that was not found is the zero pollRef, which is not invite only
+func (v *VoteList) redeemInvitation(poll pollRef, voterId uint, token string) error {
+	if !poll.Settings.InviteOnly {
+		return nil
+	}
+	if token == "" {
+		return fmt.Errorf("%w: poll %d needs the voter's invitation in %s", ErrInvalidInvitation, poll.PollID, InvitationHeader)
+	}
+
+	ctx, cancel := context.WithTimeout(v.context, v.dependencyTimeout)
+	defer cancel()
+
+	path := fmt.Sprintf("%s/%d/invitations/%d", v.polls.path, poll.PollID, voterId)
+	header := http.Header{InvitationHeader: {token}}
+	//Only tried once, a retry after an attempt that used the invitation
+	//up but timed out would be told it is not valid
+	doc, _, err := v.polls.sendOnce(ctx, http.MethodDelete, path, header)
+	if err != nil {
+		return notFoundOr(err, "poll does not exists")
+	}
+	var redeemed struct {
+		Valid bool `json:"valid"`
+	}
+	if err := json.Unmarshal(doc, &redeemed); err != nil {
+		return err
+	}
+	if !redeemed.Valid {
+		return fmt.Errorf("%w: no invitation to poll %d for voter %d with that token", ErrInvalidInvitation, poll.PollID, voterId)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+//go:build mongo
+
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDefaultLocation is the server used with -db mongo when MONGO_URL
+// is not set
+const MongoDefaultLocation = "mongodb://localhost:27017"
+
+// Votes are kept in the votes collection of the voting database, the
+// same database the voters and polls APIs keep theirs in
+const (
+	MongoDatabase   = "voting"
+	MongoCollection = "votes"
+)
+
+// mongoTimeout bounds every call to mongo, so that an unreachable server
+// fails the request instead of hanging it
+const mongoTimeout = 5 * time.Second
+
+// mongoStore keeps votes as documents in a MongoDB collection.  The
+// driver maps the Vote fields to lower case names, so VoteID is voteid.
+// The indexes on pollid and voterid stand in for the poll and voter
+// indexes kept in redis, see pollindex.go and voterindex.go
+type mongoStore struct {
+	client *mongo.Client
+	votes  *mongo.Collection
+}
+
+// NewMongoVoteList connects to the mongo server in MONGO_URL, creates
+// the indexes it needs and returns a VoteList that keeps its votes there
+// instead of redis.  The trash, the vote counters, pausing, rate limits,
+// anonymous polls, idempotency keys and the audit log need redis, so they
+// are not available.  It is only available in builds made with -tags
+// mongo
+func NewMongoVoteList() (*VoteList, error) {
+	mongoUrl := os.Getenv("MONGO_URL")
+	if mongoUrl == "" {
+		mongoUrl = MongoDefaultLocation
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoUrl))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	//VoteID is unique, like the redis key it replaces, which is also what
+	//stops the same vote going in twice.  The poll and voter ids are
+	//indexed so that the votes of one can be found without a full scan
+	votes := client.Database(MongoDatabase).Collection(MongoCollection)
+	_, err = votes.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "voteid", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "pollid", Value: 1}}},
+		{Keys: bson.D{{Key: "voterid", Value: 1}}},
+	})
+	if err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	voteList := newVoteList(&mongoStore{client: client, votes: votes}, withoutRedis())
+	voteList.startFromEnv()
+
+	return voteList, nil
+}
+
+func (m *mongoStore) get(id uint) (Vote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	var vote Vote
+	err := m.votes.FindOne(ctx, bson.M{"voteid": id}).Decode(&vote)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Vote{}, ErrNotFound
+	}
+	if err != nil {
+		return Vote{}, err
+	}
+	return vote, nil
+}
+
+// create relies on the unique index on voteid, so of two votes added
+// with the same id at once only one goes in
+func (m *mongoStore) create(vote Vote) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := m.votes.InsertOne(ctx, vote)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// putVersion checks the version in the filter of the same ReplaceOne
+// that writes the vote
+func (m *mongoStore) putVersion(vote Vote, version uint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	vote.Version = version + 1
+	result, err := m.votes.ReplaceOne(ctx, bson.M{"voteid": vote.VoteID, "version": version}, vote)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return missedVersion(m, vote.VoteID, version)
+	}
+	return nil
+}
+
+func (m *mongoStore) delete(vote Vote) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	result, err := m.votes.DeleteOne(ctx, bson.M{"voteid": vote.VoteID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *mongoStore) deleteAll() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := m.votes.DeleteMany(ctx, bson.M{})
+	return err
+}
+
+// findVotes returns the votes matching filter in VoteID order
+func (m *mongoStore) findVotes(filter bson.M, opts *options.FindOptions) ([]Vote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	cursor, err := m.votes.Find(ctx, filter, opts.SetSort(bson.D{{Key: "voteid", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var voteList []Vote
+	if err := cursor.All(ctx, &voteList); err != nil {
+		return nil, err
+	}
+	return voteList, nil
+}
+
+func (m *mongoStore) list() ([]Vote, error) {
+	return m.findVotes(bson.M{}, options.Find())
+}
+
+// listPage uses the last vote id of the previous page as the cursor,
+// vote ids start at 1 so 0 is the start
+func (m *mongoStore) listPage(cursor uint64, count int) ([]Vote, uint64, error) {
+	count = pageCount(count)
+	voteList, err := m.findVotes(bson.M{"voteid": bson.M{"$gt": cursor}}, options.Find().SetLimit(int64(count)))
+	if err != nil {
+		return nil, 0, err
+	}
+	return voteList, lastVoteID(voteList, count), nil
+}
+
+func (m *mongoStore) byPoll(pollId uint) ([]Vote, error) {
+	return m.findVotes(bson.M{"pollid": pollId}, options.Find())
+}
+
+func (m *mongoStore) byVoter(voterId uint) ([]Vote, error) {
+	return m.findVotes(bson.M{"voterid": voterId}, options.Find())
+}
+
+func (m *mongoStore) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	return m.client.Ping(ctx, nil)
+}
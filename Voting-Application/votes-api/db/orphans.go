@@ -0,0 +1,186 @@
+package db
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// OrphanActionFlag and OrphanActionDelete are what ORPHAN_SWEEP_ACTION can
+// have the background sweep do with the orphaned votes it finds.  Flag
+// only keeps them for GET /votes/orphans and the metrics, delete also
+// moves them to the trash, where they can be restored from like any
+// other deleted vote
+const (
+	OrphanActionFlag   = "flag"
+	OrphanActionDelete = "delete"
+)
+
+// OrphanVote is a vote whose voter or poll, or both, no longer exist
+type OrphanVote struct {
+	VoteID       uint `json:"voteId"`
+	VoterID      uint `json:"voterId"`
+	PollID       uint `json:"pollId"`
+	MissingVoter bool `json:"missingVoter"`
+	MissingPoll  bool `json:"missingPoll"`
+}
+
+// OrphanSweep is the result of looking for orphaned votes.  Deleted is
+// how many of them were moved to the trash, always 0 when only flagging
+type OrphanSweep struct {
+	Votes     []OrphanVote `json:"votes"`
+	Deleted   uint         `json:"deleted"`
+	CheckedAt time.Time    `json:"checkedAt"`
+}
+
+// orphanActionFromEnv returns ORPHAN_SWEEP_ACTION, or OrphanActionFlag if
+// it is not set or not a known action
+func orphanActionFromEnv() string {
+	action := OrphanActionFlag
+	if s := os.Getenv("ORPHAN_SWEEP_ACTION"); s != "" {
+		if s == OrphanActionFlag || s == OrphanActionDelete {
+			action = s
+		} else {
+			log.Println("Invalid ORPHAN_SWEEP_ACTION, using default: ", s)
+		}
+	}
+	return action
+}
+
+// refExists looks the voter or poll with id up in dep, it is false only
+// when the service says it is not there
+func (v *VoteList) refExists(dep *dependency, id uint, seen map[uint]bool) (bool, error) {
+	if exists, ok := seen[id]; ok {
+		return exists, nil
+	}
+	var item struct{}
+	err := v.getDependency(dep, id, &item)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	seen[id] = err == nil
+	return seen[id], nil
+}
+
+// FindOrphans looks up the voter and poll of every vote, each one once,
+// and returns the votes with one that no longer exists, sorted by id.
+// The result is also kept for LastOrphans.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The orphaned votes will be returned, an empty slice if
+//			there are none
+//		(2) If the voters or polls API can't be reached
+//			ErrDependencyUnavailable is returned, a vote is never
+//			taken for an orphan because its voter or poll could not
+//			be looked up
+//		(3) If there is an error, it will be returned
+//		(4) The database file will not be modified
+func (v *VoteList) FindOrphans() (OrphanSweep, error) {
+
+	all, err := v.store.list()
+	if err != nil {
+		return OrphanSweep{}, err
+	}
+
+	voters := make(map[uint]bool)
+	polls := make(map[uint]bool)
+	sweep := OrphanSweep{Votes: []OrphanVote{}}
+	for _, vote := range all {
+		//The voter of an anonymous vote is not known, only the poll
+		//can be checked
+		voterExists := vote.VoterHash != ""
+		if !voterExists {
+			voterExists, err = v.refExists(v.voters, vote.VoterID, voters)
+			if err != nil {
+				return OrphanSweep{}, err
+			}
+		}
+		pollExists, err := v.refExists(v.polls, vote.PollID, polls)
+		if err != nil {
+			return OrphanSweep{}, err
+		}
+		if voterExists && pollExists {
+			continue
+		}
+		sweep.Votes = append(sweep.Votes, OrphanVote{
+			VoteID:       vote.VoteID,
+			VoterID:      vote.VoterID,
+			PollID:       vote.PollID,
+			MissingVoter: !voterExists,
+			MissingPoll:  !pollExists,
+		})
+	}
+
+	sort.Slice(sweep.Votes, func(i, j int) bool {
+		return sweep.Votes[i].VoteID < sweep.Votes[j].VoteID
+	})
+	sweep.CheckedAt = time.Now()
+	v.lastOrphans.Store(&sweep)
+	return sweep, nil
+}
+
+// SweepOrphans finds the orphaned votes and, if the orphan action is
+// delete, moves them to the trash.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The orphaned votes will be returned, with how many of them
+//			were deleted
+//		(2) If a vote can't be deleted the sweep stops there and the
+//			error is returned, the votes before it stay deleted
+//		(3) If there is an error, it will be returned
+func (v *VoteList) SweepOrphans() (OrphanSweep, error) {
+
+	sweep, err := v.FindOrphans()
+	if err != nil || v.orphanAction != OrphanActionDelete {
+		return sweep, err
+	}
+
+	for _, orphan := range sweep.Votes {
+		err := v.DeleteVote(orphan.VoteID)
+		if errors.Is(err, ErrNotFound) {
+			//Deleted since we found it
+			continue
+		}
+		if err != nil {
+			return sweep, err
+		}
+		sweep.Deleted++
+	}
+	v.lastOrphans.Store(&sweep)
+	return sweep, nil
+}
+
+// LastOrphans returns the result of the last orphan sweep, false if there
+// hasn't been one
+func (v *VoteList) LastOrphans() (OrphanSweep, bool) {
+	sweep := v.lastOrphans.Load()
+	if sweep == nil {
+		return OrphanSweep{}, false
+	}
+	return *sweep, true
+}
+
+// watchOrphans sweeps for orphaned votes every interval in the
+// background
+func (v *VoteList) watchOrphans(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			sweep, err := v.SweepOrphans()
+			if err != nil {
+				log.Println("Error sweeping orphaned votes: ", err)
+				continue
+			}
+			if len(sweep.Votes) > 0 {
+				log.Println("Orphaned votes found, deleted: ", len(sweep.Votes), sweep.Deleted)
+			}
+		}
+	}()
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// PasscodeHeader is the header a vote for a poll with a passcode has to
+// send it in
+const PasscodeHeader = "X-Poll-Passcode"
+
+// ErrInvalidPasscode is returned when a vote for a poll with a passcode
+// does not have it
+var ErrInvalidPasscode = errors.New("poll passcode is missing or wrong")
+
+// checkPasscode makes sure passcode is the passcode of the poll, if it has
+// one.  The polls API keeps only a hash of it, so the passcode is sent
+// there to be checked rather than checked here.  A poll that was not
+// found is the zero pollRef, which has no passcode
+func (v *VoteList) checkPasscode(poll pollRef, passcode string) error {
+	if !poll.Settings.HasPasscode {
+		return nil
+	}
+	if passcode == "" {
+		return fmt.Errorf("%w: poll %d needs its passcode in %s", ErrInvalidPasscode, poll.PollID, PasscodeHeader)
+	}
+
+	ctx, cancel := context.WithTimeout(v.context, v.dependencyTimeout)
+	defer cancel()
+
+	path := fmt.Sprintf("%s/%d/passcode", v.polls.path, poll.PollID)
+	header := http.Header{PasscodeHeader: {passcode}}
+	doc, _, err := v.polls.sendWithHeader(ctx, http.MethodGet, path, header)
+	if err != nil {
+		return notFoundOr(err, "poll does not exists")
+	}
+	var check struct {
+		Valid bool `json:"valid"`
+	}
+	if err := json.Unmarshal(doc, &check); err != nil {
+		return err
+	}
+	if !check.Valid {
+		return fmt.Errorf("%w: wrong passcode for poll %d", ErrInvalidPasscode, poll.PollID)
+	}
+	return nil
+}
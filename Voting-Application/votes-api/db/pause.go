@@ -0,0 +1,90 @@
+package db
+
+import (
+	"errors"
+	"log"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// VotingPausedKey is set in redis while voting is paused, so that the
+// pause survives restarts.  It is kept out of the votes: prefix so that
+// it is never listed or deleted along with the votes
+const VotingPausedKey = "admin:votes:paused"
+
+// VotingPausedChannel is the redis pub/sub channel that replicas of the
+// votes API use to tell each other voting was paused ("1") or resumed ("0")
+const VotingPausedChannel = "admin:votes:paused"
+
+// ErrVotingPaused is returned by AddVote while voting is paused
+var ErrVotingPaused = errors.New("voting paused")
+
+// VotingPaused reports whether new votes are currently being turned away
+func (v *VoteList) VotingPaused() bool {
+	return v.paused.Load()
+}
+
+// PauseVoting stops AddVote from accepting new votes on every replica
+// until ResumeVoting is called.  Reads and other writes carry on as usual.
+// The pause is shared through redis, so without it ErrNeedsRedis is
+// returned
+func (v *VoteList) PauseVoting() error {
+	if err := v.needsRedis(); err != nil {
+		return err
+	}
+	if err := v.cacheClient.Set(v.context, VotingPausedKey, "1", 0).Err(); err != nil {
+		return err
+	}
+	v.paused.Store(true)
+	v.publishVotingPaused("1")
+	return nil
+}
+
+// ResumeVoting lets AddVote accept new votes again on every replica
+func (v *VoteList) ResumeVoting() error {
+	if err := v.needsRedis(); err != nil {
+		return err
+	}
+	if err := v.cacheClient.Del(v.context, VotingPausedKey).Err(); err != nil {
+		return err
+	}
+	v.paused.Store(false)
+	v.publishVotingPaused("0")
+	return nil
+}
+
+func (v *VoteList) publishVotingPaused(msg string) {
+	if err := v.cacheClient.Publish(v.context, VotingPausedChannel, msg).Err(); err != nil {
+		//The flag is already in redis, so all we can do is log that the
+		//other replicas won't see the change until they restart
+		log.Println("Error publishing voting pause: ", err)
+	}
+}
+
+// watchVotingPaused loads the pause flag from redis and then listens for
+// the other replicas pausing or resuming voting.  It returns once the
+// subscription is in place, the listening carries on in the background
+func (v *VoteList) watchVotingPaused() error {
+	//Subscribe before reading the flag so that a change made in between
+	//is not missed
+	pubsub := v.cacheClient.Subscribe(v.context, VotingPausedChannel)
+	if _, err := pubsub.Receive(v.context); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	n, err := v.cacheClient.Exists(v.context, VotingPausedKey).Result()
+	if err != nil {
+		pubsub.Close()
+		return err
+	}
+	v.paused.Store(n > 0)
+
+	go func(ch <-chan *redis.Message) {
+		for msg := range ch {
+			v.paused.Store(msg.Payload == "1")
+		}
+	}(pubsub.Channel())
+
+	return nil
+}
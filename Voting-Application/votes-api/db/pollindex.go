@@ -0,0 +1,80 @@
+package db
+
+import (
+	"fmt"
+	"log"
+)
+
+// PollVotesKeyPattern matches the vote index of every poll
+const PollVotesKeyPattern = "poll-votes:*"
+
+// Each poll has a set of the ids of its votes, it looks like
+// poll-votes:<number>.  See index.go
+func pollVotesKeyFromPollId(id uint) string {
+	return fmt.Sprintf("poll-votes:%d", id)
+}
+
+// indexVote adds a vote to the vote index of its poll
+func (c *cache) indexVote(pollId uint, voteId uint) {
+	if err := c.cacheClient.SAdd(c.context, pollVotesKeyFromPollId(pollId), voteId).Err(); err != nil {
+		log.Println("Error indexing vote ", voteId, " of poll ", pollId, ": ", err)
+	}
+}
+
+// unindexVote removes a vote from the vote index of its poll
+func (c *cache) unindexVote(pollId uint, voteId uint) {
+	if err := c.cacheClient.SRem(c.context, pollVotesKeyFromPollId(pollId), voteId).Err(); err != nil {
+		log.Println("Error unindexing vote ", voteId, " of poll ", pollId, ": ", err)
+	}
+}
+
+// GetVotesByPoll accepts a poll id and returns the votes cast in that
+// poll, sorted by id.  With redis it uses the poll's vote index, and
+// polls whose votes were cast before there was an index fall back to
+// scanning every vote until RecountVotes has indexed them.  A poll with
+// neither an index nor a vote count has no votes, it is never scanned for
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The votes of the poll will be returned, an empty slice
+//			if it has none
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (v *VoteList) GetVotesByPoll(pollId uint) ([]Vote, error) {
+	return v.store.byPoll(pollId)
+}
+
+// GetPollVotes accepts a poll id and returns the votes cast in that poll,
+// like GetVotesByPoll, but tells a poll that doesn't exist apart from one
+// nobody has voted in
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The poll must exist, or have votes
+//
+// Postconditions:
+//
+//	    (1) The votes of the poll will be returned, an empty slice
+//			if it has none
+//		(2) If the poll does not exist and has no votes, ErrNotFound
+//			is returned, and if the polls lookup times out
+//			ErrDependencyUnavailable
+//		(3) If there is an error, it will be returned
+//		(4) The database file will not be modified
+func (v *VoteList) GetPollVotes(pollId uint) ([]Vote, error) {
+
+	voteList, err := v.GetVotesByPoll(pollId)
+	if err != nil {
+		return nil, err
+	}
+	//Without strict refs votes can outlive their poll, so only look the
+	//poll up when there is nothing to show
+	if len(voteList) == 0 {
+		var checkPoll pollRef
+		if err := v.getDependency(v.polls, pollId, &checkPoll); err != nil {
+			return nil, notFoundOr(err, "poll does not exist")
+		}
+	}
+
+	return voteList, nil
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresDefaultLocation is the database used with -db postgres when
+// DATABASE_URL is not set, the same database the voters and polls APIs
+// default to
+const PostgresDefaultLocation = "postgres://postgres@localhost:5432/voting?sslmode=disable"
+
+// postgresSchema creates the votes table and its indexes if they are not
+// there yet.  Each vote is kept whole as a JSONB document, the same shape
+// it has in redis, keyed by its id.  The version is kept beside it so a
+// write can check it, and the poll and voter ids so the votes of one can
+// be found through an index, in place of the redis indexes
+const postgresSchema = `CREATE TABLE IF NOT EXISTS votes (
+	vote_id  BIGINT PRIMARY KEY,
+	poll_id  BIGINT NOT NULL,
+	voter_id BIGINT NOT NULL,
+	version  BIGINT NOT NULL,
+	doc      JSONB  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS votes_poll_id ON votes (poll_id);
+CREATE INDEX IF NOT EXISTS votes_voter_id ON votes (voter_id)`
+
+// postgresStore keeps votes in a postgres table, for deployments that
+// need the votes to be durable
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresVoteList connects to the postgres database in DATABASE_URL,
+// creates the votes table if it is not there and returns a VoteList that
+// keeps its votes there instead of redis.  The trash, the vote counters,
+// pausing, rate limits, anonymous polls, idempotency keys and the audit
+// log need redis, so they are not available
+func NewPostgresVoteList() (*VoteList, error) {
+	databaseUrl := os.Getenv("DATABASE_URL")
+	if databaseUrl == "" {
+		databaseUrl = PostgresDefaultLocation
+	}
+
+	db, err := sql.Open("postgres", databaseUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	voteList := newVoteList(&postgresStore{db: db}, withoutRedis())
+	voteList.startFromEnv()
+
+	return voteList, nil
+}
+
+// scanVotes reads the doc column of each of rows as a vote
+func scanVotes(rows *sql.Rows) ([]Vote, error) {
+	defer rows.Close()
+
+	var voteList []Vote
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var vote Vote
+		if err := json.Unmarshal(doc, &vote); err != nil {
+			return nil, err
+		}
+		voteList = append(voteList, vote)
+	}
+	return voteList, rows.Err()
+}
+
+func (p *postgresStore) get(id uint) (Vote, error) {
+	var doc []byte
+	err := p.db.QueryRow(`SELECT doc FROM votes WHERE vote_id = $1`, id).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Vote{}, ErrNotFound
+	}
+	if err != nil {
+		return Vote{}, err
+	}
+
+	var vote Vote
+	if err := json.Unmarshal(doc, &vote); err != nil {
+		return Vote{}, err
+	}
+	return vote, nil
+}
+
+// create leaves a vote that is already there alone, so of two votes
+// added with the same id at once only one goes in
+func (p *postgresStore) create(vote Vote) error {
+	doc, err := json.Marshal(vote)
+	if err != nil {
+		return err
+	}
+	result, err := p.db.Exec(`INSERT INTO votes (vote_id, poll_id, voter_id, version, doc) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (vote_id) DO NOTHING`, vote.VoteID, vote.PollID, vote.VoterID, vote.Version, string(doc))
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// putVersion checks the version in the same UPDATE that writes the vote.
+// The poll and voter ids are written too, a vote can be moved to another
+// poll and anonymizing a vote clears its voter
+func (p *postgresStore) putVersion(vote Vote, version uint) error {
+	vote.Version = version + 1
+	doc, err := json.Marshal(vote)
+	if err != nil {
+		return err
+	}
+	result, err := p.db.Exec(`UPDATE votes SET poll_id = $1, voter_id = $2, version = $3, doc = $4
+		WHERE vote_id = $5 AND version = $6`,
+		vote.PollID, vote.VoterID, vote.Version, string(doc), vote.VoteID, version)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return missedVersion(p, vote.VoteID, version)
+	}
+	return nil
+}
+
+func (p *postgresStore) delete(vote Vote) error {
+	result, err := p.db.Exec(`DELETE FROM votes WHERE vote_id = $1`, vote.VoteID)
+	if err != nil {
+		return err
+	}
+	numDeleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *postgresStore) deleteAll() error {
+	_, err := p.db.Exec(`DELETE FROM votes`)
+	return err
+}
+
+func (p *postgresStore) list() ([]Vote, error) {
+	rows, err := p.db.Query(`SELECT doc FROM votes ORDER BY vote_id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanVotes(rows)
+}
+
+// listPage uses the last vote id of the previous page as the cursor,
+// vote ids start at 1 so 0 is the start
+func (p *postgresStore) listPage(cursor uint64, count int) ([]Vote, uint64, error) {
+	count = pageCount(count)
+	rows, err := p.db.Query(`SELECT doc FROM votes WHERE vote_id > $1 ORDER BY vote_id LIMIT $2`, cursor, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	voteList, err := scanVotes(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return voteList, lastVoteID(voteList, count), nil
+}
+
+func (p *postgresStore) byPoll(pollId uint) ([]Vote, error) {
+	rows, err := p.db.Query(`SELECT doc FROM votes WHERE poll_id = $1 ORDER BY vote_id`, pollId)
+	if err != nil {
+		return nil, err
+	}
+	return scanVotes(rows)
+}
+
+func (p *postgresStore) byVoter(voterId uint) ([]Vote, error) {
+	rows, err := p.db.Query(`SELECT doc FROM votes WHERE voter_id = $1 ORDER BY vote_id`, voterId)
+	if err != nil {
+		return nil, err
+	}
+	return scanVotes(rows)
+}
+
+func (p *postgresStore) ping() error {
+	return p.db.Ping()
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRankings is returned when a vote ranks the options in a way
+// that can't be counted
+var ErrInvalidRankings = errors.New("invalid rankings")
+
+// validateRankings makes sure the Rankings of a vote, if it has any, start
+// with its VoteValue and name each option once.  VoteValue stays the
+// voter's first choice, so polls that count only first choices can ignore
+// Rankings, and a poll counted by instant runoff, in the polls API, uses
+// the rest.  An empty list is the same as none
+func validateRankings(vote *Vote) error {
+	if len(vote.Rankings) == 0 {
+		vote.Rankings = nil
+		return nil
+	}
+	if vote.Rankings[0] != vote.VoteValue {
+		return fmt.Errorf("%w: the first choice must be the VoteValue %d", ErrInvalidRankings, vote.VoteValue)
+	}
+	seen := make(map[uint]bool, len(vote.Rankings))
+	for _, option := range vote.Rankings {
+		if option == 0 {
+			return fmt.Errorf("%w: options are numbered from 1", ErrInvalidRankings)
+		}
+		if seen[option] {
+			return fmt.Errorf("%w: option %d is ranked more than once", ErrInvalidRankings, option)
+		}
+		seen[option] = true
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrReceiptsOff is returned when a vote receipt is asked for but
+// VOTE_RECEIPT_SECRET is not set, so it can't be signed
+var ErrReceiptsOff = errors.New("vote receipts are not turned on, VOTE_RECEIPT_SECRET is not set")
+
+// ErrInvalidReceipt is returned for a receipt whose signature is not
+// right, it was not issued by the votes API or has been changed since
+var ErrInvalidReceipt = errors.New("invalid vote receipt")
+
+// The Status of a ReceiptCheck
+const (
+	//ReceiptRecorded is a vote that is stored as it was when the
+	//receipt was issued
+	ReceiptRecorded = "recorded"
+	//ReceiptModified is a vote that is stored, but whose choices or
+	//poll have changed since the receipt was issued, such as by a recast
+	ReceiptModified = "modified"
+	//ReceiptDeleted is a vote that is in the trash
+	ReceiptDeleted = "deleted"
+	//ReceiptMissing is a vote that is not stored at all, it was purged
+	//from the trash or expired with its poll
+	ReceiptMissing = "missing"
+)
+
+// VoteReceipt is what a voter keeps to show that their vote was recorded.
+// Signature is the HMAC-SHA256 of VoteID, PollID and IssuedAt.  Seal is
+// an HMAC-SHA256 of the vote's choices, so that a receipt can tell
+// whether they changed, but being keyed it can't be used to work out what
+// they were.  Both are hex
+type VoteReceipt struct {
+	VoteID    uint
+	PollID    uint
+	IssuedAt  time.Time
+	Signature string
+	Seal      string
+}
+
+// ReceiptCheck is what VerifyReceipt found out about the vote a genuine
+// receipt is for
+type ReceiptCheck struct {
+	VoteID   uint
+	PollID   uint
+	IssuedAt time.Time
+	Status   string
+}
+
+// ReceiptedVote is a vote along with the receipt for it, what POST /votes
+// sends back when receipts are on.  Receipt is left out when they are not
+type ReceiptedVote struct {
+	Vote
+	Receipt *VoteReceipt
+}
+
+// MarshalJSON writes the vote as Vote.MarshalJSON does, with the Receipt
+// before the Links, which stay last
+func (v ReceiptedVote) MarshalJSON() ([]byte, error) {
+	type plainVote Vote
+	return marshalOrdered(struct {
+		plainVote
+		Receipt *VoteReceipt `json:",omitempty"`
+	}{plainVote(v.Vote), v.Receipt}, "VoteID", "Links")
+}
+
+// receiptSecretFromEnv returns VOTE_RECEIPT_SECRET, the key receipts are
+// signed with, or nil if it is not set, which turns them off.  Every
+// replica has to have the same secret, and receipts issued before it
+// changes can no longer be verified
+func receiptSecretFromEnv() []byte {
+	secret := os.Getenv("VOTE_RECEIPT_SECRET")
+	if secret == "" {
+		return nil
+	}
+	if len(secret) < 32 {
+		log.Println("VOTE_RECEIPT_SECRET is shorter than 32 characters, use a longer one")
+	}
+	return []byte(secret)
+}
+
+// signReceipt returns the signature of a receipt for the vote with voteId
+// in the poll with pollId issued at issuedAt
+func (v *VoteList) signReceipt(voteId uint, pollId uint, issuedAt time.Time) string {
+	mac := hmac.New(sha256.New, v.receiptSecret)
+	fmt.Fprintf(mac, "receipt:%d:%d:%d", voteId, pollId, issuedAt.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sealVote returns the seal of vote's choices for a receipt issued at
+// issuedAt.  The voter and source are left out, so anonymizing the vote
+// or moving it between channels does not change it
+func (v *VoteList) sealVote(vote Vote, issuedAt time.Time) (string, error) {
+	choices, err := json.Marshal(struct {
+		VoteValue   uint
+		VoteValues  []uint
+		Rankings    []uint
+		WriteInText string
+	}{vote.VoteValue, vote.VoteValues, vote.Rankings, vote.WriteInText})
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, v.receiptSecret)
+	fmt.Fprintf(mac, "seal:%d:%d:", vote.VoteID, issuedAt.Unix())
+	mac.Write(choices)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// IssueReceipt accepts a vote as it was stored and returns a receipt for
+// it issued at now, to the second.  Nothing is stored for a receipt.
+// Preconditions:   (1) VOTE_RECEIPT_SECRET must be set, if it is not
+//						ErrReceiptsOff is returned
+//
+// Postconditions:
+//
+//	    (1) The signed receipt will be returned
+//		(2) If there is an error, it will be returned
+func (v *VoteList) IssueReceipt(vote Vote, now time.Time) (VoteReceipt, error) {
+	if v.receiptSecret == nil {
+		return VoteReceipt{}, ErrReceiptsOff
+	}
+
+	issuedAt := now.UTC().Truncate(time.Second)
+	seal, err := v.sealVote(vote, issuedAt)
+	if err != nil {
+		return VoteReceipt{}, err
+	}
+	return VoteReceipt{
+		VoteID:    vote.VoteID,
+		PollID:    vote.PollID,
+		IssuedAt:  issuedAt,
+		Signature: v.signReceipt(vote.VoteID, vote.PollID, issuedAt),
+		Seal:      seal,
+	}, nil
+}
+
+// VerifyReceipt accepts a receipt a voter was given for their vote and
+// checks that it is genuine, and whether the vote is still stored as it
+// was when the receipt was issued.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) VOTE_RECEIPT_SECRET must be the one the receipt
+//						was issued with
+//
+// Postconditions:
+//
+//	    (1) What was found will be returned, see the statuses of
+//			ReceiptCheck
+//		(2) If the receipt's signature is not right an error wrapping
+//			ErrInvalidReceipt will be returned, if receipts are off
+//			ErrReceiptsOff
+//		(3) If there is an error, it will be returned
+func (v *VoteList) VerifyReceipt(receipt VoteReceipt) (ReceiptCheck, error) {
+	if v.receiptSecret == nil {
+		return ReceiptCheck{}, ErrReceiptsOff
+	}
+	expected := v.signReceipt(receipt.VoteID, receipt.PollID, receipt.IssuedAt)
+	if !hmac.Equal([]byte(receipt.Signature), []byte(expected)) {
+		return ReceiptCheck{}, fmt.Errorf("%w: bad signature", ErrInvalidReceipt)
+	}
+
+	check := ReceiptCheck{VoteID: receipt.VoteID, PollID: receipt.PollID, IssuedAt: receipt.IssuedAt.UTC()}
+	vote, err := v.GetVote(receipt.VoteID)
+	if errors.Is(err, ErrNotFound) {
+		//A store with no trash can't tell a deleted vote from one that
+		//was never there
+		err := error(ErrNotFound)
+		if trash, ok := v.store.(voteTrash); ok {
+			_, err = trash.trashed(receipt.VoteID)
+		}
+		switch {
+		case err == nil:
+			check.Status = ReceiptDeleted
+		case errors.Is(err, ErrNotFound):
+			check.Status = ReceiptMissing
+		default:
+			return ReceiptCheck{}, err
+		}
+		return check, nil
+	}
+	if err != nil {
+		return ReceiptCheck{}, err
+	}
+
+	seal, err := v.sealVote(vote, receipt.IssuedAt)
+	if err != nil {
+		return ReceiptCheck{}, err
+	}
+	check.Status = ReceiptModified
+	if vote.PollID == receipt.PollID && hmac.Equal([]byte(receipt.Seal), []byte(seal)) {
+		check.Status = ReceiptRecorded
+	}
+	return check, nil
+}
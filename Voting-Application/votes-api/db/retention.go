@@ -0,0 +1,204 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultRetentionInterval is how often expiries are set on the votes of
+// closed polls when POLL_RETENTION is set and POLL_RETENTION_INTERVAL is
+// not
+const DefaultRetentionInterval = time.Hour
+
+// The votes of closed polls are kept forever unless POLL_RETENTION is
+// set, to a go duration string such as "2160h" for 90 days, the same as
+// the polls API's.  Then every POLL_RETENTION_INTERVAL the votes API looks
+// up each poll that has votes and gives its votes, vote index, counter
+// and anonymous voter keys an EXPIREAT of the poll's Settings.ClosesAt
+// plus the retention, the time the polls API purges the poll, and takes
+// it off again if the poll is no longer closed.  The voter vote indexes
+// are left alone, the ids of purged votes in them are skipped
+
+// retentionFromEnv returns POLL_RETENTION, and whether it is set.  If it
+// is not the votes of closed polls are kept forever and nothing is run.
+// "0" keeps them forever too, but takes off any expiries set while it
+// was longer
+func retentionFromEnv() (time.Duration, bool) {
+	retention := os.Getenv("POLL_RETENTION")
+	if retention == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(retention)
+	if err != nil || d < 0 {
+		log.Println("Invalid POLL_RETENTION, keeping the votes of closed polls: ", retention)
+		return 0, false
+	}
+	return d, true
+}
+
+// retentionIntervalFromEnv returns POLL_RETENTION_INTERVAL, or the
+// default if it is not set or not a valid duration
+func retentionIntervalFromEnv() time.Duration {
+	interval := DefaultRetentionInterval
+	if s := os.Getenv("POLL_RETENTION_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			log.Println("Invalid POLL_RETENTION_INTERVAL, using default: ", s)
+		} else {
+			interval = d
+		}
+	}
+	return interval
+}
+
+// purgeAt returns when the poll's votes are purged with a retention of
+// retention, at now, or nil if they are kept.  It is when the polls API
+// purges the poll, its closing time plus the retention once it has
+// closed
+func (p pollRef) purgeAt(retention time.Duration, now time.Time) *time.Time {
+	closesAt := p.Settings.ClosesAt
+	if retention <= 0 || closesAt == nil || now.Before(*closesAt) || p.Status == PollStatusDraft {
+		return nil
+	}
+	purgeAt := closesAt.Add(retention)
+	return &purgeAt
+}
+
+// pollRetentionKeys are the keys of the poll with id that are purged with
+// its votes, other than the votes themselves
+func pollRetentionKeys(id uint) []string {
+	return []string{
+		pollVotesKeyFromPollId(id),
+		countKeyFromPollId(id),
+		rateKeyFromPollId(id),
+		anonymousSaltKey(id),
+		anonymousVotersKey(id),
+	}
+}
+
+// applyRetention gives the votes of every closed poll, at now, the expiry
+// they are due and takes it off the votes of every other poll, and
+// returns how many polls' votes are expiring.  Polls are found by their
+// vote index, polls that no longer exist are left to the orphan sweep.
+// If the polls API can't be reached nothing more is changed, the next run
+// carries on.  Other stores have no expiries, so the votes whose time has
+// come are deleted by each run instead
+func (v *VoteList) applyRetention(now time.Time) (int, error) {
+	if v.needsRedis() != nil {
+		return v.purgeDue(now)
+	}
+
+	indexKeys, err := v.scanKeys(PollVotesKeyPattern)
+	if err != nil {
+		return 0, err
+	}
+
+	expiring := 0
+	for _, indexKey := range indexKeys {
+		var pollId uint
+		if _, err := fmt.Sscanf(indexKey, "poll-votes:%d", &pollId); err != nil {
+			continue
+		}
+		var poll pollRef
+		err := v.getDependency(v.polls, pollId, &poll)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return expiring, err
+		}
+
+		voteIds, err := v.cacheClient.SMembers(v.context, indexKey).Result()
+		if err != nil {
+			return expiring, err
+		}
+		keys := pollRetentionKeys(pollId)
+		for _, voteId := range voteIds {
+			keys = append(keys, RedisKeyPrefix+voteId)
+		}
+
+		purgeAt := poll.purgeAt(v.retention, now)
+		_, err = v.cacheClient.Pipelined(v.context, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				if purgeAt != nil {
+					pipe.ExpireAt(v.context, key, *purgeAt)
+				} else {
+					pipe.Persist(v.context, key)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return expiring, err
+		}
+		if purgeAt != nil {
+			expiring++
+		}
+	}
+	return expiring, nil
+}
+
+// purgeDue deletes the votes of the polls whose time has come at now, for
+// the stores that have no expiries, and returns how many of the other
+// polls' votes are expiring.  The votes are deleted for good, as redis
+// would purge them
+func (v *VoteList) purgeDue(now time.Time) (int, error) {
+	all, err := v.store.list()
+	if err != nil {
+		return 0, err
+	}
+	byPoll := make(map[uint][]Vote)
+	for _, vote := range all {
+		byPoll[vote.PollID] = append(byPoll[vote.PollID], vote)
+	}
+
+	expiring := 0
+	for pollId, votes := range byPoll {
+		var poll pollRef
+		err := v.getDependency(v.polls, pollId, &poll)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return expiring, err
+		}
+
+		purgeAt := poll.purgeAt(v.retention, now)
+		if purgeAt == nil {
+			continue
+		}
+		if now.Before(*purgeAt) {
+			expiring++
+			continue
+		}
+		for _, vote := range votes {
+			if err := v.store.delete(vote); err != nil && !errors.Is(err, ErrNotFound) {
+				return expiring, err
+			}
+			v.publishVoteEvent(VoteDeleted, vote)
+		}
+	}
+	return expiring, nil
+}
+
+// watchRetention sets the expiries of the votes of closed polls every
+// interval in the background
+func (v *VoteList) watchRetention(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			expiring, err := v.applyRetention(now)
+			if err != nil {
+				log.Println("Error setting the expiries of the votes of closed polls: ", err)
+				continue
+			}
+			log.Println("Closed polls whose votes are expiring: ", expiring)
+		}
+	}()
+}
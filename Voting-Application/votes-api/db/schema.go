@@ -0,0 +1,95 @@
+package db
+
+import (
+	"reflect"
+	"time"
+)
+
+// VoteModelVersion is the version of the Vote model returned by this
+// service.  Bump it whenever a field is added, removed or changed so that
+// clients can tell they are talking to a model they don't understand.
+//
+//	1: VoteID, VoterID, PollID, VoteValue, Links
+//	2: added Source
+//	3: added Version
+//	4: added Rankings
+//	5: added VoteValues
+//	6: added WriteInText, VoteValue can be left out of a write-in
+//	7: added VoterHash, VoterID is 0 in anonymous polls
+const VoteModelVersion = 7
+
+// ModelSchema describes a model for the GET /schema endpoint
+type ModelSchema struct {
+	Model   string
+	Version uint
+	Fields  []SchemaField
+}
+
+// SchemaField describes a single field of a model.  Type is the JSON
+// type of the field, arrays and objects also describe what they contain
+type SchemaField struct {
+	Name   string
+	Type   string
+	Items  string        `json:",omitempty"`
+	Fields []SchemaField `json:",omitempty"`
+}
+
+// VoteSchema returns the schema of the Vote model
+func VoteSchema() ModelSchema {
+	return ModelSchema{
+		Model:   "Vote",
+		Version: VoteModelVersion,
+		Fields:  schemaFields(reflect.TypeOf(Vote{})),
+	}
+}
+
+// schemaFields walks the exported fields of a struct type and describes
+// each of them
+func schemaFields(t reflect.Type) []SchemaField {
+	fields := make([]SchemaField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		field := SchemaField{Name: f.Name, Type: jsonType(f.Type)}
+		switch f.Type.Kind() {
+		case reflect.Slice:
+			field.Items = jsonType(f.Type.Elem())
+			if field.Items == "object" {
+				field.Fields = schemaFields(f.Type.Elem())
+			}
+		case reflect.Struct:
+			if field.Type == "object" {
+				field.Fields = schemaFields(f.Type)
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// jsonType maps a go type to the JSON type encoding/json produces for it
+func jsonType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Ptr:
+		return jsonType(t.Elem())
+	}
+	return "unknown"
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSelections is returned when a vote selects options in a way
+// its poll doesn't allow
+var ErrInvalidSelections = errors.New("invalid selections")
+
+// validateSelections makes sure the VoteValues of a vote, if it has any,
+// start with its VoteValue and name each option once.  VoteValue stays
+// the first of the options selected, so a vote is still counted by
+// clients that only know about VoteValue.  An empty list is the same as
+// none
+func validateSelections(vote *Vote) error {
+	if len(vote.VoteValues) == 0 {
+		vote.VoteValues = nil
+		return nil
+	}
+	if len(vote.Rankings) > 0 {
+		return fmt.Errorf("%w: a vote can rank options or select several, not both", ErrInvalidSelections)
+	}
+	if vote.VoteValues[0] != vote.VoteValue {
+		return fmt.Errorf("%w: the first selection must be the VoteValue %d", ErrInvalidSelections, vote.VoteValue)
+	}
+	seen := make(map[uint]bool, len(vote.VoteValues))
+	for _, option := range vote.VoteValues {
+		if seen[option] {
+			return fmt.Errorf("%w: option %d is selected more than once", ErrInvalidSelections, option)
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+// checkSelections makes sure a vote selects no more options than the
+// poll's MaxSelections, and only options the poll has.  A poll that was
+// not found is the zero pollRef, whose options are not known, so only
+// single votes are checked against it by the polls API when counting
+func (p pollRef) checkSelections(vote Vote) error {
+	if p.PollID == 0 || len(vote.VoteValues) == 0 {
+		return nil
+	}
+	max := p.Settings.MaxSelections
+	if max == 0 {
+		max = 1
+	}
+	if uint(len(vote.VoteValues)) > max {
+		return fmt.Errorf("%w: poll allows %d selections, the vote has %d", ErrInvalidSelections, max, len(vote.VoteValues))
+	}
+	options := make(map[uint]bool, len(p.PollOptions))
+	for _, option := range p.PollOptions {
+		options[option.PollOptionID] = true
+	}
+	for _, value := range vote.VoteValues {
+		if !options[value] {
+			return fmt.Errorf("%w: option %d is not an option of poll %d", ErrInvalidSelections, value, p.PollID)
+		}
+	}
+	return nil
+}
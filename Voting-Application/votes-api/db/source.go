@@ -0,0 +1,29 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The channels a vote can come in through.  Votes stored before votes had
+// a source, or added without one, are SourceUnknown
+const (
+	SourceWeb     = "web"
+	SourceSMS     = "sms"
+	SourceKiosk   = "kiosk"
+	SourceUnknown = "unknown"
+)
+
+// ErrInvalidSource is returned when a vote has a source that is not one
+// of the known channels
+var ErrInvalidSource = errors.New("invalid vote source")
+
+// ValidateSource makes sure that source is one of the known channels
+func ValidateSource(source string) error {
+	switch source {
+	case SourceWeb, SourceSMS, SourceKiosk, SourceUnknown:
+		return nil
+	}
+	return fmt.Errorf("%w %q, must be one of %s, %s, %s or %s", ErrInvalidSource, source,
+		SourceWeb, SourceSMS, SourceKiosk, SourceUnknown)
+}
@@ -0,0 +1,378 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// voteStore is where the votes are kept.  VoteList does all of its reads
+// and writes of votes through a voteStore, so the backend can be swapped
+// without touching the rules in VoteList.  create only adds a vote whose
+// id is free, or returns ErrAlreadyExists, so that "vote already exists"
+// holds however many replicas add the same vote at once.  putVersion
+// only writes a vote over the one stored if its Version is still version,
+// the vote having version+1, or returns ErrVersionMismatch.  delete takes
+// the vote as it was read, so that stores with indexes can update them.
+// byPoll and byVoter return the votes sorted by id.  listPage returns
+// about count votes from cursor on, 0 for the start, and the cursor to
+// carry on from, 0 after the last page.  What a cursor means is up to
+// each store
+type voteStore interface {
+	get(id uint) (Vote, error)
+	create(vote Vote) error
+	putVersion(vote Vote, version uint) error
+	delete(vote Vote) error
+	deleteAll() error
+	list() ([]Vote, error)
+	listPage(cursor uint64, count int) ([]Vote, uint64, error)
+	byPoll(pollId uint) ([]Vote, error)
+	byVoter(voterId uint) ([]Vote, error)
+	ping() error
+}
+
+// ErrNeedsRedis is returned by the features of the votes API that are
+// built on redis whatever store the votes are in, such as the trash, the
+// vote counters and pausing voting, when the votes are kept somewhere else
+var ErrNeedsRedis = errors.New("only available when the votes are kept in redis")
+
+// withoutRedis is the cache of a VoteList that keeps its votes in
+// another store, it has no client, see needsRedis
+func withoutRedis() cache {
+	return cache{
+		context:   context.Background(),
+		scanBatch: scanBatchSizeFromEnv(),
+	}
+}
+
+// needsRedis returns ErrNeedsRedis if there is no redis, the VoteList
+// keeps its votes in another store
+func (c *cache) needsRedis() error {
+	if c.cacheClient == nil {
+		return ErrNeedsRedis
+	}
+	return nil
+}
+
+// redisStore keeps votes in redis as ReJSON documents under votes:<id>.
+// Along with the votes it keeps the vote count of each poll, which the
+// polls API reads, the indexes of each poll's and voter's votes, see
+// index.go, and the trash that deleted votes are moved to
+type redisStore struct {
+	cache
+}
+
+func (r *redisStore) get(id uint) (Vote, error) {
+	var vote Vote
+	if err := r.getItemFromRedis(redisKeyFromId(id), &vote); err != nil {
+		return Vote{}, err
+	}
+	return vote, nil
+}
+
+// create uses JSON.SET's NX option, so of two votes added with the same
+// id at once only one goes in
+func (r *redisStore) create(vote Vote) error {
+	if err := r.setNew(redisKeyFromId(vote.VoteID), vote); err != nil {
+		return err
+	}
+	r.adjustPollCount(vote.PollID, 1)
+	r.indexVote(vote.PollID, vote.VoteID)
+	r.indexVoterVote(vote.VoterID, vote.VoteID)
+	return nil
+}
+
+func (r *redisStore) putVersion(vote Vote, version uint) error {
+	//The vote may be moving to another poll or voter, the indexes are
+	//moved from the ones it had.  Versions only go up, so if the write
+	//goes through this is the vote it replaced
+	old, err := r.get(vote.VoteID)
+	if err != nil {
+		return err
+	}
+	if old.Version != version {
+		return versionMismatch(version, old.Version)
+	}
+
+	if err := r.setVersioned(redisKeyFromId(vote.VoteID), ".", vote, version); err != nil {
+		return err
+	}
+	if vote.PollID != old.PollID {
+		r.adjustPollCount(old.PollID, -1)
+		r.adjustPollCount(vote.PollID, 1)
+		r.unindexVote(old.PollID, vote.VoteID)
+		r.indexVote(vote.PollID, vote.VoteID)
+	}
+	if vote.VoterID != old.VoterID {
+		r.unindexVoterVote(old.VoterID, vote.VoteID)
+		r.indexVoterVote(vote.VoterID, vote.VoteID)
+	}
+	return nil
+}
+
+// delete deletes the vote for good, see trash for moving it to the trash
+func (r *redisStore) delete(vote Vote) error {
+	numDeleted, err := r.cacheClient.Del(r.context, redisKeyFromId(vote.VoteID)).Result()
+	if err != nil {
+		return err
+	}
+	if numDeleted == 0 {
+		return ErrNotFound
+	}
+	r.unindexed(vote)
+	return nil
+}
+
+// unindexed takes a vote that is no longer under votes: out of the count
+// and indexes
+func (r *redisStore) unindexed(vote Vote) {
+	r.adjustPollCount(vote.PollID, -1)
+	r.unindexVote(vote.PollID, vote.VoteID)
+	r.unindexVoterVote(vote.VoterID, vote.VoteID)
+}
+
+// deleteAll deletes every vote, and with them the counts and indexes of
+// every poll and voter and the voters of the anonymous polls.  The trash
+// is left, the votes in it can still be restored
+func (r *redisStore) deleteAll() error {
+	ks, err := r.scanKeys(RedisKeyPrefix + "*")
+	if err != nil {
+		return err
+	}
+	//Note delete can take a collection of keys.  In go we can
+	//expand a slice into individual arguments by using the ...
+	//operator
+	if len(ks) > 0 {
+		numDeleted, err := r.cacheClient.Del(r.context, ks...).Result()
+		if err != nil {
+			return err
+		}
+		if numDeleted != int64(len(ks)) {
+			return errors.New("one or more votes could not be deleted")
+		}
+	}
+
+	//With no votes left, no poll has any
+	var countKeys []string
+	for _, pattern := range []string{CountKeyPattern, PollVotesKeyPattern, VoterVotesKeyPattern, AnonymousVotersKeyPattern} {
+		keys, err := r.scanKeys(pattern)
+		if err != nil {
+			return err
+		}
+		countKeys = append(countKeys, keys...)
+	}
+	if len(countKeys) > 0 {
+		return r.cacheClient.Del(r.context, countKeys...).Err()
+	}
+	return nil
+}
+
+func (r *redisStore) list() ([]Vote, error) {
+	ks, err := r.scanKeys(RedisKeyPrefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	voteList, err := r.getItemsFromRedis(ks)
+	if err != nil {
+		return nil, err
+	}
+	sortVotes(voteList)
+	return voteList, nil
+}
+
+// listPage uses the SCAN cursor.  SCAN is repeated until there are at
+// least count votes, so only the last page is short, but a page can have
+// a few more than count as SCAN can't stop part way through a batch
+func (r *redisStore) listPage(cursor uint64, count int) ([]Vote, uint64, error) {
+	if count <= 0 {
+		count = int(r.scanBatch)
+	}
+
+	var ks []string
+	next := cursor
+	for {
+		batch, n, err := r.scanPage(RedisKeyPrefix+"*", next, int64(count-len(ks)))
+		if err != nil {
+			return nil, 0, err
+		}
+		ks = append(ks, batch...)
+		next = n
+		if next == 0 || len(ks) >= count {
+			break
+		}
+	}
+
+	voteList, err := r.getItemsFromRedis(ks)
+	if err != nil {
+		return nil, 0, err
+	}
+	sortVotes(voteList)
+	return voteList, next, nil
+}
+
+// byPoll uses the poll's vote index.  An empty index is either a poll
+// nobody has voted in, or one whose votes were never indexed, the vote
+// count tells them apart.  No count at all is a poll nobody has voted in,
+// or one that doesn't exist, and scanning every vote for it would let
+// anyone make us do that at will
+func (r *redisStore) byPoll(pollId uint) ([]Vote, error) {
+	unindexed := func() (bool, error) {
+		count, err := r.cacheClient.Get(r.context, countKeyFromPollId(pollId)).Int64()
+		if err == nil {
+			return count > 0, nil
+		}
+		if err.Error() != RedisNilError {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return r.votesInIndex(pollVotesKeyFromPollId(pollId), unindexed, func(vote Vote) bool {
+		return vote.PollID == pollId
+	})
+}
+
+// byVoter uses the voter's vote index.  Until RecountVotes has indexed
+// the votes cast before there was an index, a voter with no indexed votes
+// falls back to scanning every vote
+func (r *redisStore) byVoter(voterId uint) ([]Vote, error) {
+	unindexed := func() (bool, error) {
+		built, err := r.cacheClient.Exists(r.context, VoterIndexBuiltKey).Result()
+		if err != nil {
+			return false, err
+		}
+		return built == 0, nil
+	}
+
+	return r.votesInIndex(voterVotesKeyFromVoterId(voterId), unindexed, func(vote Vote) bool {
+		return vote.VoterID == voterId
+	})
+}
+
+func (r *redisStore) ping() error {
+	return r.cacheClient.Ping(r.context).Err()
+}
+
+// voteTrash is a voteStore that keeps deleted votes for retention, so
+// that they can be restored.  trashed returns a vote that is in the trash
+// and restore takes it out again, ErrAlreadyExists if a vote has been
+// added with its id since.  Other stores delete votes for good
+type voteTrash interface {
+	trash(vote Vote, retention time.Duration) error
+	restore(id uint) (Vote, error)
+	trashed(id uint) (Vote, error)
+}
+
+// trash moves the vote to trash:votes:<id>, where redis purges it once
+// retention has passed
+func (r *redisStore) trash(vote Vote, retention time.Duration) error {
+	//Rename and expire inside of a transaction so that a vote can never
+	//end up in the trash without a TTL on it
+	trashKey := trashKeyFromId(vote.VoteID)
+	_, err := r.cacheClient.TxPipelined(r.context, func(pipe redis.Pipeliner) error {
+		pipe.Rename(r.context, redisKeyFromId(vote.VoteID), trashKey)
+		pipe.Expire(r.context, trashKey, retention)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.unindexed(vote)
+	return nil
+}
+
+func (r *redisStore) restore(id uint) (Vote, error) {
+	trashKey := trashKeyFromId(id)
+	exists, err := r.cacheClient.Exists(r.context, trashKey).Result()
+	if err != nil {
+		return Vote{}, err
+	}
+	if exists == 0 {
+		return Vote{}, fmt.Errorf("%w: vote is not in the trash", ErrNotFound)
+	}
+
+	//RenameNX will not overwrite a vote that was added with the same id
+	//after this one was deleted
+	redisKey := redisKeyFromId(id)
+	renamed, err := r.cacheClient.RenameNX(r.context, trashKey, redisKey).Result()
+	if err != nil {
+		return Vote{}, err
+	}
+	if !renamed {
+		return Vote{}, fmt.Errorf("%w: vote already exists", ErrAlreadyExists)
+	}
+	if err := r.cacheClient.Persist(r.context, redisKey).Err(); err != nil {
+		return Vote{}, err
+	}
+
+	vote, err := r.get(id)
+	if err != nil {
+		return Vote{}, err
+	}
+	r.adjustPollCount(vote.PollID, 1)
+	r.indexVote(vote.PollID, vote.VoteID)
+	r.indexVoterVote(vote.VoterID, vote.VoteID)
+	return vote, nil
+}
+
+func (r *redisStore) trashed(id uint) (Vote, error) {
+	var vote Vote
+	if err := r.getItemFromRedis(trashKeyFromId(id), &vote); err != nil {
+		return Vote{}, err
+	}
+	return vote, nil
+}
+
+// idAllocator is a voteStore that can give out new vote ids itself, so
+// that two replicas never hand out the same one.  Other stores are given
+// one more than the highest id they have, and a clash is caught by create
+type idAllocator interface {
+	newID() (uint, error)
+}
+
+// newID starts the counter after the votes in the trash too, so they can
+// still be restored
+func (r *redisStore) newID() (uint, error) {
+	return r.nextID(VoteIDCounterKey, RedisKeyPrefix, TrashKeyPrefix)
+}
+
+// sortVotes sorts votes by id, so that lists and pages of them are stable
+func sortVotes(voteList []Vote) {
+	sort.Slice(voteList, func(i, j int) bool {
+		return voteList[i].VoteID < voteList[j].VoteID
+	})
+}
+
+// lastVoteID returns the VoteID of the last vote of a full page, as the
+// cursor of the next page, or 0 if the page was short so it was the last
+// one.  It is the cursor of the stores that page by id
+func lastVoteID(voteList []Vote, count int) uint64 {
+	if len(voteList) < count || len(voteList) == 0 {
+		return 0
+	}
+	return uint64(voteList[len(voteList)-1].VoteID)
+}
+
+// pageCount is the number of votes a store that pages by id puts on a
+// page, count or the scan batch size if it is not positive
+func pageCount(count int) int {
+	if count <= 0 {
+		return DefaultScanBatchSize
+	}
+	return count
+}
+
+// missedVersion is the error of a putVersion that wrote nothing, either
+// the vote has gone or its version has moved on, which it reads the vote
+// again to tell apart
+func missedVersion(s voteStore, id uint, version uint) error {
+	vote, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	return versionMismatch(version, vote.Version)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+	"log"
+)
+
+// VoterVotesKeyPattern matches the vote index of every voter
+const VoterVotesKeyPattern = "voter-votes:*"
+
+// VoterIndexBuiltKey is set once RecountVotes has indexed every vote by
+// voter.  Until then a voter with an empty index may have voted before
+// the index existed.  Like the pause flag it is kept out of the votes:
+// prefix
+const VoterIndexBuiltKey = "admin:votes:voter-index-built"
+
+// Each voter has a set of the ids of their votes, it looks like
+// voter-votes:<number>.  See index.go
+func voterVotesKeyFromVoterId(id uint) string {
+	return fmt.Sprintf("voter-votes:%d", id)
+}
+
+// indexVoterVote adds a vote to the vote index of its voter.  The votes
+// of anonymous polls have no voter and are left out
+func (c *cache) indexVoterVote(voterId uint, voteId uint) {
+	if voterId == 0 {
+		return
+	}
+	if err := c.cacheClient.SAdd(c.context, voterVotesKeyFromVoterId(voterId), voteId).Err(); err != nil {
+		log.Println("Error indexing vote ", voteId, " of voter ", voterId, ": ", err)
+	}
+}
+
+// unindexVoterVote removes a vote from the vote index of its voter
+func (c *cache) unindexVoterVote(voterId uint, voteId uint) {
+	if voterId == 0 {
+		return
+	}
+	if err := c.cacheClient.SRem(c.context, voterVotesKeyFromVoterId(voterId), voteId).Err(); err != nil {
+		log.Println("Error unindexing vote ", voteId, " of voter ", voterId, ": ", err)
+	}
+}
+
+// GetVotesByVoter accepts a voter id and returns every vote they have
+// cast, sorted by id.  With redis it uses the voter's vote index, and
+// until RecountVotes has indexed the votes cast before there was an
+// index, a voter with no indexed votes falls back to scanning every vote
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) The votes of the voter will be returned, an empty slice
+//			if they have none
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (v *VoteList) GetVotesByVoter(voterId uint) ([]Vote, error) {
+	return v.store.byVoter(voterId)
+}
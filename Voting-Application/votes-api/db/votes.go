@@ -1,369 +1,1106 @@
-package db
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"time"
-	"log"
-	"os"
-
-	"github.com/go-redis/redis/v8"
-	"github.com/nitishm/go-rejson/v4"
-)
-  
-type Vote struct {
-	VoteID		uint
-	VoterID		uint
-	PollID		uint
-	VoteValue	uint
-	Links		[]string
-}
-
-const (
-	RedisNilError        = "redis: nil"
-	RedisDefaultLocation = "0.0.0.0:6379"
-	RedisKeyPrefix       = "votes:"
-)
-
-type cache struct {
-	cacheClient *redis.Client
-	jsonHelper  *rejson.Handler
-	context     context.Context
-}
-
-type healthData struct{
-	Uptime time.Duration
-	APIcalls uint
-}
-
-type VoteList struct {
-	healthInfo healthData
-	cache
-}
-
-//constructor for VoteList struct
-func NewVoteList() (*VoteList, error) {
-	//We will use an override if the REDIS_URL is provided as an environment
-	//variable, which is the preferred way to wire up a docker container
-	redisUrl := os.Getenv("REDIS_URL")
-	//This handles the default condition
-	if redisUrl == "" {
-		redisUrl = RedisDefaultLocation
-	}
-	return NewWithCacheInstance(redisUrl)
-}
-
-// NewWithCacheInstance is a constructor function that returns a pointer to a new
-// Vote struct.  It accepts a string that represents the location of the redis
-// cache.
-func NewWithCacheInstance(location string) (*VoteList, error) {
-
-	//Connect to redis.  Other options can be provided, but the
-	//defaults are OK
-	client := redis.NewClient(&redis.Options{
-		Addr: location,
-	})
-
-	//We use this context to coordinate betwen our go code and
-	//the redis operaitons
-	ctx := context.Background()
-
-	//This is the reccomended way to ensure that our redis connection
-	//is working
-	err := client.Ping(ctx).Err()
-	if err != nil {
-		log.Println("Error connecting to redis" + err.Error())
-		return nil, err
-	}
-
-	//By default, redis manages keys and values, where the values
-	//are either strings, sets, maps, etc.  Redis has an extension
-	//module called ReJSON that allows us to store JSON objects
-	//however, we need a companion library in order to work with it
-	//Below we create an instance of the JSON helper and associate
-	//it with our redis connnection
-	jsonHelper := rejson.NewReJSONHandler()
-	jsonHelper.SetGoRedisClientWithContext(ctx, client)
-
-	//Return a pointer to a new voteList struct
-	voteList := &VoteList{
-		healthInfo: healthData{},
-		cache: cache{
-			cacheClient: client,
-			jsonHelper:  jsonHelper,
-			context:     ctx,
-		},
-	}
-	return voteList, nil
-}
-
-//------------------------------------------------------------
-// REDIS HELPERS
-//------------------------------------------------------------
-
-// In redis, our keys will be strings, they will look like
-// votes:<number>.  This function will take an integer and
-// return a string that can be used as a key in redis
-func redisKeyFromId(id uint) string {
-	return fmt.Sprintf("%s%d", RedisKeyPrefix, id)
-}
-
-// Helper to return a VoteList from redis provided a key
-func (v *VoteList) getItemFromRedis(key string, vote *Vote) error {
-
-	//Lets query redis for the vote, note we can return parts of the
-	//json structure, the second parameter "." means return the entire
-	//json structure
-	voteObject, err := v.jsonHelper.JSONGet(key, ".")
-	if err != nil {
-		return err
-	}
-
-	//JSONGet returns an "any" object, or empty interface,
-	//we need to convert it to a byte array, which is the
-	//underlying type of the object, then we can unmarshal
-	//it into our voter struct
-	err = json.Unmarshal(voteObject.([]byte), vote)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-//------------------------------------------------------------
-// THESE ARE THE PUBLIC FUNCTIONS THAT SUPPORT OUR VOTE APP
-//------------------------------------------------------------
-
-// AddVote accepts a Vote and adds it to the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The vote must not already exist in the DB
-//	    				because we use the vote.VoteID as the key, this
-//						function must check if the vote already
-//	    				exists in the DB, if so, return an error
-//
-// Postconditions:
-//
-//	    (1) The vote will be added to the DB
-//		(2) The DB file will be saved with the vote added
-//		(3) If there is an error, it will be returned
-func (v *VoteList) AddVote(vote Vote) error {
-
-	//Before we add an vote to the DB, lets make sure
-	//it does not exist, if it does, return an error
-	redisKey := redisKeyFromId(vote.VoteID)
-	var existingVote Vote
-	if err := v.getItemFromRedis(redisKey, &existingVote); err == nil {
-		return errors.New("vote already exists")
-	}
-	var checkVoter Vote
-	if err := v.getItemFromRedis(fmt.Sprintf("%s%d", "voters:", vote.VoterID), &checkVoter); err != nil {
-		return errors.New("voter does not exists")
-	}
-	var checkPoll Vote
-	if err := v.getItemFromRedis(fmt.Sprintf("%s%d", "polls:", vote.PollID), &checkPoll); err != nil {
-		return errors.New("poll does not exists")
-	}
-
-	//Add vote to database with JSON Set
-	vote.Links = []string{"GET All Votes: 1100/votes/", "POST Vote: 1100/votes/:id", "DELETE All Votes: 1100/votes", "DELETE Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id","GET All Polls: 1090/Polls/","POST Poll: 1090/polls/:id"}
-	if _, err := v.jsonHelper.JSONSet(redisKey, ".", vote); err != nil {
-		return err
-	}
-
-	//If everything is ok, return nil for the error
-	return nil
-}
-
-// DeleteVote accepts a vote id and removes it from the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The vote must exist in the DB
-//	    				because we use the vote.VoteID as the key, this
-//						function must check if the vote already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The vote will be removed from the DB
-//		(2) The DB file will be saved with the vote removed
-//		(3) If there is an error, it will be returned
-func (v *VoteList) DeleteVote(id uint) error {
-
-	pattern := redisKeyFromId(id)
-	numDeleted, err := v.cacheClient.Del(v.context, pattern).Result()
-	if err != nil {
-		return err
-	}
-	if numDeleted == 0 {
-		return errors.New("vote does not exist")
-	}
-
-	return nil
-}
-
-// DeleteAllVotes removes all votes from the DB.
-// It will be exposed via a DELETE /votes endpoint
-func (v *VoteList) DeleteAllVotes() error {
-
-	pattern := RedisKeyPrefix + "*"
-	ks, _ := v.cacheClient.Keys(v.context, pattern).Result()
-	//Note delete can take a collection of keys.  In go we can
-	//expand a slice into individual arguments by using the ...
-	//operator
-	numDeleted, err := v.cacheClient.Del(v.context, ks...).Result()
-	if err != nil {
-		return err
-	}
-
-	if numDeleted != int64(len(ks)) {
-		return errors.New("one or more votes could not be deleted")
-	}
-
-	return nil
-}
-
-// UpdateVote accepts a Vote and updates it in the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The vote must exist in the DB
-//	    				because we use the vote.VoteID as the key, this
-//						function must check if the vote already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The vote will be updated in the DB
-//		(2) The DB file will be saved with the vote updated
-//		(3) If there is an error, it will be returned
-func (v *VoteList) UpdateVote(vote Vote) error {
-
-	// Check if vote exists before trying to update it
-	// this is a good practice, return an error if the
-	// vote does not exist
-	redisKey := redisKeyFromId(vote.VoteID)
-	var existingVote Vote
-	if err := v.getItemFromRedis(redisKey, &existingVote); err != nil {
-		return errors.New("vote does not exist")
-	}
-
-	//Add vote to database with JSON Set.  Note there is no update
-	//functionality, so we just overwrite the existing vote
-	vote.Links = []string{"GET All Votes: 1100/votes/", "POST Vote: 1100/votes/:id", "DELETE All Votes: 1100/votes", "DELETE Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id","GET All Polls: 1090/Polls/","POST Poll: 1090/polls/:id"}
-	if _, err := v.jsonHelper.JSONSet(redisKey, ".", vote); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// GetVote accepts a Vote id and returns the vote from the DB.
-// Preconditions:   (1) The database file must exist and be a valid
-//
-//					(2) The vote must exist in the DB
-//	    				because we use the vote.VoteID as the key, this
-//						function must check if the vote already
-//	    				exists in the DB, if not, return an error
-//
-// Postconditions:
-//
-//	    (1) The vote will be returned, if it exists
-//		(2) If there is an error, it will be returned
-//			along with an empty vote
-//		(3) The database file will not be modified
-func (v *VoteList) GetVote(id uint) (Vote, error) {
-
-	// Check if vote exists before trying to get it
-	// this is a good practice, return an error if the
-	// vote does not exist
-	var vote Vote
-	pattern := redisKeyFromId(id)
-	err := v.getItemFromRedis(pattern, &vote)
-	if err != nil {
-		return Vote{}, errors.New("vote does not exist")
-	}
-
-	return vote, nil
-}
-
-// GetAllVotes returns all votes from the DB.  If successful it
-// returns a slice of all of the votes to the caller
-// Preconditions:   (1) The database file must exist and be a valid
-//
-// Postconditions:
-//
-//	    (1) All votes will be returned, if any exist
-//		(2) If there is an error, it will be returned
-//			along with an empty slice
-//		(3) The database file will not be modified
-func (v *VoteList) GetAllVotes() ([]Vote, error) {
-
-	//Now that we have the DB loaded, lets crate a slice
-	var voteList []Vote
-	var vote Vote
-
-	//Lets query redis for all of the items
-	pattern := RedisKeyPrefix + "*"
-	ks, _ := v.cacheClient.Keys(v.context, pattern).Result()
-	for _, key := range ks {
-		err := v.getItemFromRedis(key, &vote)
-		if err != nil {
-			return nil, err
-		}
-		voteList = append(voteList, vote)
-	}
-
-	if len(voteList) < 1 {
-		voteList = append(voteList, Vote{
-			VoteID: 0,
-			VoterID: 0,
-			PollID: 0,
-			VoteValue: 0,
-			Links: []string{"GET All Votes: 1100/votes/", "POST Vote: 1100/votes/:id", "DELETE All Votes: 1100/votes", "DELETE Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id","GET All Polls: 1090/Polls/","POST Poll: 1090/polls/:id"},
-		})
-	}
-
-	//Now that we have all of our votes in a slice, return it
-	return voteList, nil
-}
-
-// PrintVote accepts a Vote and prints it to the console
-// in a JSON pretty format. As some help, look at the
-// json.MarshalIndent() function from our in class go tutorial.
-func (v *VoteList) PrintVote(vote Vote) {
-	jsonBytes, _ := json.MarshalIndent(vote, "", "  ")
-	fmt.Println(string(jsonBytes))
-}
-
-// PrintAllVotes accepts a slice of Votes and prints them to the console
-// in a JSON pretty format.  It should call PrintVote() to print each vote
-// versus repeating the code.
-func (v *VoteList) PrintAllVotes(voteList []Vote) {
-	for _, vote := range voteList {
-		v.PrintVote(vote)
-	}
-}
-
-// JsonToVote accepts a json string and returns a Vote
-// This is helpful because the CLI accepts votes for insertion
-// and updates in JSON format.  We need to convert it to a Vote
-// struct to perform any operations on it.
-func (v *VoteList) JsonToVote(jsonString string) (Vote, error) {
-	var vote Vote
-	err := json.Unmarshal([]byte(jsonString), &vote)
-	if err != nil {
-		return Vote{}, err
-	}
-
-	return vote, nil
-}
-
-func (v *VoteList) GetHealthData(bootTime time.Time, calls uint) (healthData, error){
-
-	v.healthInfo = healthData{Uptime: time.Now().Sub(bootTime), APIcalls: calls}
-
-	return v.healthInfo, nil
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"log"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nitishm/go-rejson/v4"
+)
+  
+type Vote struct {
+	VoteID		uint
+	VoterID		uint
+	PollID		uint
+	//VoteValue is the option voted for, it is left out of a write-in
+	VoteValue	uint `binding:"required_without=WriteInText"`
+	//VoteValues are every option selected, VoteValue first, for polls
+	//that allow more than one, see selections.go
+	VoteValues	[]uint `json:",omitempty"`
+	//Rankings optionally ranks the poll's options, VoteValue first,
+	//for polls counted by instant runoff, see rankings.go
+	Rankings	[]uint `json:",omitempty"`
+	//WriteInText is the answer of a vote for none of the options, in
+	//a poll that takes write-ins, see writeins.go
+	WriteInText	string `json:",omitempty"`
+	//VoterHash is set in place of VoterID on a vote in an anonymous
+	//poll, a salted hash of the voter, see anonymous.go.  It is never
+	//taken from a client
+	VoterHash	string `json:",omitempty"`
+	//Source is the channel the vote came in through, see source.go
+	Source		string
+	//Version goes up by one with every change, see versions.go
+	Version		uint
+	Links		[]string
+}
+
+// MarshalJSON writes a vote with VoteID first and Links last, whatever
+// order the fields end up declared in, so that clients can rely on that
+// order.  Unmarshalling is the default and takes the fields in any order
+func (v Vote) MarshalJSON() ([]byte, error) {
+	type plainVote Vote
+	return marshalOrdered(plainVote(v), "VoteID", "Links")
+}
+
+// pollRef holds the parts of a poll, owned by the polls API, that the
+// votes API needs to check before accepting a vote
+type pollRef struct {
+	PollID uint
+	//Status is draft, scheduled, open or closed, polls from before the
+	//polls API sent it have none
+	Status      string
+	PollOptions []struct {
+		PollOptionID uint
+	}
+	Settings struct {
+		Status        string
+		VoteRateLimit uint
+		OpensAt       *time.Time
+		ClosesAt      *time.Time
+		Archived      bool
+		MaxSelections uint
+		AllowWriteIns bool
+		HasPasscode   bool
+		InviteOnly    bool
+		Anonymous     bool
+	}
+}
+
+// PollStatusDraft is the status the polls API gives a poll that has not
+// been published yet, votes are not accepted for draft polls
+const PollStatusDraft = "draft"
+
+// PollStateOpen is the Status the polls API gives a poll that is taking
+// votes, anything else is turned away
+const PollStateOpen = "open"
+
+// PollStateScheduled is the Status the polls API gives a poll that opens
+// by itself at Settings.OpensAt.  The poll may be cached from before then,
+// so OpensAt is checked against the time of the vote instead
+const PollStateScheduled = "scheduled"
+
+// checkOpen returns ErrConflict saying why if the poll is not taking
+// votes at now.  A poll that was not found is the zero pollRef, which is
+// open
+func (p pollRef) checkOpen(now time.Time) error {
+	//A draft with an opening time opens by itself once it has passed
+	opensAt := p.Settings.OpensAt
+	if p.Settings.Status == PollStatusDraft && opensAt == nil {
+		return fmt.Errorf("%w: poll is a draft and not accepting votes", ErrConflict)
+	}
+	if opensAt != nil && now.Before(*opensAt) {
+		return fmt.Errorf("%w: poll is not open yet", ErrConflict)
+	}
+	if p.Settings.Archived {
+		return fmt.Errorf("%w: poll is archived and not accepting votes", ErrConflict)
+	}
+	//The polls API says when a poll is open, the checks above only give
+	//a clearer reason
+	status := p.Status
+	if status != "" && status != PollStateOpen && status != PollStateScheduled {
+		return fmt.Errorf("%w: poll is %s and not accepting votes", ErrConflict, status)
+	}
+	if closesAt := p.Settings.ClosesAt; closesAt != nil && !now.Before(*closesAt) {
+		return fmt.Errorf("%w: poll is closed and not accepting votes", ErrConflict)
+	}
+	return nil
+}
+
+const (
+	RedisNilError            = "redis: nil"
+	RedisDefaultLocation     = "0.0.0.0:6379"
+	RedisKeyPrefix           = "votes:"
+	TrashKeyPrefix           = "trash:" + RedisKeyPrefix
+	DefaultTrashRetention    = 24 * time.Hour
+	DefaultVoteRateWindow    = time.Minute
+	DefaultDependencyTimeout = 2 * time.Second
+)
+
+// ErrVoteRateExceeded is returned by AddVote when a poll has already
+// received its maximum number of votes for the current rate window
+var ErrVoteRateExceeded = errors.New("poll vote rate exceeded")
+
+// ErrDependencyUnavailable is returned by AddVote when the voter or poll
+// of a vote could not be looked up within the dependency timeout
+var ErrDependencyUnavailable = errors.New("dependency unavailable")
+
+// ErrNotFound is returned when a vote, or the voter or poll it refers to,
+// is not in redis.  Any other error means redis could not be reached or
+// sent back something we can't read, so callers should not report those
+// as a missing vote
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyExists is returned when a vote is added, or restored from
+// the trash, but a vote with its id is already there
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrConflict is returned when a vote can't be cast in the state its
+// poll is in, a draft or a closed poll
+var ErrConflict = errors.New("conflict")
+
+type cache struct {
+	cacheClient *redis.Client
+	jsonHelper  *rejson.Handler
+	context     context.Context
+	//scanBatch is the COUNT hint each SCAN sends, see scan.go
+	scanBatch int64
+}
+
+// HealthData is the health record returned by the health endpoint
+type HealthData struct{
+	Uptime time.Duration
+	APIcalls uint
+	//RedisLatency is how long a PING to the store took, the name is kept
+	//from when redis was the only store
+	RedisLatency time.Duration
+}
+
+type VoteList struct {
+	healthInfo HealthData
+	//store is where the votes are kept, see store.go
+	store voteStore
+	//trashRetention is how long a deleted vote is kept in the trash
+	//before redis purges it for good
+	trashRetention time.Duration
+	//voteRateLimit is the default maximum number of votes a poll will
+	//accept in any voteRateWindow, 0 means no limit.  A poll can set
+	//its own VoteRateLimit to override it
+	voteRateLimit  uint
+	voteRateWindow time.Duration
+	//strictRefs makes AddVote reject votes whose voter or poll does
+	//not exist.  Turning it off lets votes be seeded on their own
+	strictRefs bool
+	//dependencyTimeout is how long AddVote waits when looking up the
+	//voter and poll of a vote
+	dependencyTimeout time.Duration
+	//voters and polls are the services votes' voters and polls are
+	//looked up in, see deps.go
+	voters *dependency
+	polls  *dependency
+	//paused is true while voting is paused, see pause.go
+	paused atomic.Bool
+	//lastDrift is the result of the last drift check, see drift.go
+	lastDrift atomic.Pointer[DataDrift]
+	//orphanAction is what the orphan sweep does with the orphaned votes
+	//it finds, and lastOrphans its last result, see orphans.go
+	orphanAction string
+	lastOrphans  atomic.Pointer[OrphanSweep]
+	//eventWatchers are sent every vote event, see events.go
+	eventWatchers *voteEventWatchers
+	//idScheme is how AddVoteNewID picks ids, see ids.go
+	idScheme string
+	//idempotencyTTL is how long the response to a request with an
+	//Idempotency-Key is kept, see idempotency.go
+	idempotencyTTL time.Duration
+	//anonymousPepper goes into the voter hashes of anonymous polls,
+	//see anonymous.go
+	anonymousPepper []byte
+	//receiptSecret signs the receipts of votes, nil if they are off,
+	//see receipts.go
+	receiptSecret []byte
+	//retention is how long the votes of closed polls are kept, 0 for
+	//forever, see retention.go
+	retention time.Duration
+	cache
+}
+
+//constructor for VoteList struct
+func NewVoteList() (*VoteList, error) {
+	//We will use an override if the REDIS_URL is provided as an environment
+	//variable, which is the preferred way to wire up a docker container
+	redisUrl := os.Getenv("REDIS_URL")
+	//This handles the default condition
+	if redisUrl == "" {
+		redisUrl = RedisDefaultLocation
+	}
+	voteList, err := NewWithCacheInstance(redisUrl)
+	if err != nil {
+		return nil, err
+	}
+	voteList.startFromEnv()
+
+	return voteList, nil
+}
+
+// startFromEnv applies the settings in the environment to a new VoteList,
+// whatever store it keeps its votes in, and starts the background runs
+// they ask for
+func (voteList *VoteList) startFromEnv() {
+	//The trash retention can also be overridden with an environment
+	//variable, it takes a go duration string such as "72h"
+	if retention := os.Getenv("VOTE_TRASH_RETENTION"); retention != "" {
+		d, err := time.ParseDuration(retention)
+		if err != nil || d <= 0 {
+			log.Println("Invalid VOTE_TRASH_RETENTION, using default: ", retention)
+		} else {
+			voteList.trashRetention = d
+		}
+	}
+
+	//The global vote rate limit and its window come from the
+	//environment too, by default there is no limit
+	if limit := os.Getenv("VOTE_RATE_LIMIT"); limit != "" {
+		l, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil {
+			log.Println("Invalid VOTE_RATE_LIMIT, using default: ", limit)
+		} else {
+			voteList.voteRateLimit = uint(l)
+		}
+	}
+	if window := os.Getenv("VOTE_RATE_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			log.Println("Invalid VOTE_RATE_WINDOW, using default: ", window)
+		} else {
+			voteList.voteRateWindow = d
+		}
+	}
+
+	//How long to wait on the voter and poll lookups, as a go duration
+	//string such as "500ms"
+	if timeout := os.Getenv("VOTE_DEPENDENCY_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			log.Println("Invalid VOTE_DEPENDENCY_TIMEOUT, using default: ", timeout)
+		} else {
+			voteList.dependencyTimeout = d
+		}
+	}
+
+	//How long a retry with the same Idempotency-Key gets the response
+	//of the first request, as a go duration string such as "1h"
+	if ttl := os.Getenv("VOTE_IDEMPOTENCY_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			log.Println("Invalid VOTE_IDEMPOTENCY_TTL, using default: ", ttl)
+		} else {
+			voteList.idempotencyTTL = d
+		}
+	}
+
+	//Checking that the voter and poll of a vote exist can be turned
+	//off for seeding and testing the votes API on its own
+	if strict := os.Getenv("VOTE_STRICT_REFS"); strict != "" {
+		b, err := strconv.ParseBool(strict)
+		if err != nil {
+			log.Println("Invalid VOTE_STRICT_REFS, using default: ", strict)
+		} else {
+			voteList.strictRefs = b
+		}
+	}
+
+	//Votes and voter histories can be checked against each other in the
+	//background, as a go duration string such as "5m".  It is off by
+	//default, GET /admin/drift checks on demand
+	if interval := os.Getenv("DRIFT_CHECK_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			log.Println("Invalid DRIFT_CHECK_INTERVAL, not checking in the background: ", interval)
+		} else {
+			voteList.watchDrift(d)
+		}
+	}
+
+	//Votes whose voter or poll no longer exists can be swept for in the
+	//background, as a go duration string such as "10m".  It is off by
+	//default, GET /votes/orphans looks on demand
+	if interval := os.Getenv("ORPHAN_SWEEP_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			log.Println("Invalid ORPHAN_SWEEP_INTERVAL, not sweeping in the background: ", interval)
+		} else {
+			voteList.watchOrphans(d)
+		}
+	}
+
+	//The votes of closed polls are kept forever unless POLL_RETENTION is
+	//set, see retention.go
+	if retention, ok := retentionFromEnv(); ok {
+		voteList.retention = retention
+		voteList.watchRetention(retentionIntervalFromEnv())
+	}
+
+	//Seed last, so that the settings above are in place
+	voteList.seedFromEnv()
+}
+
+// NewWithCacheInstance is a constructor function that returns a pointer to a new
+// Vote struct.  It accepts a string that represents the location of the redis
+// cache.
+func NewWithCacheInstance(location string) (*VoteList, error) {
+
+	//Connect to redis.  Other options can be provided, but the
+	//defaults are OK
+	client := redis.NewClient(&redis.Options{
+		Addr: location,
+	})
+
+	//We use this context to coordinate betwen our go code and
+	//the redis operaitons
+	ctx := context.Background()
+
+	//This is the reccomended way to ensure that our redis connection
+	//is working
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		log.Println("Error connecting to redis" + err.Error())
+		return nil, err
+	}
+
+	//By default, redis manages keys and values, where the values
+	//are either strings, sets, maps, etc.  Redis has an extension
+	//module called ReJSON that allows us to store JSON objects
+	//however, we need a companion library in order to work with it
+	//Below we create an instance of the JSON helper and associate
+	//it with our redis connnection
+	jsonHelper := rejson.NewReJSONHandler()
+	jsonHelper.SetGoRedisClientWithContext(ctx, client)
+
+	c := cache{
+		cacheClient: client,
+		jsonHelper:  jsonHelper,
+		context:     ctx,
+		scanBatch:   scanBatchSizeFromEnv(),
+	}
+	voteList := newVoteList(&redisStore{cache: c}, c)
+
+	//Pick up whether voting was left paused, and keep up with the other
+	//replicas pausing and resuming it
+	if err := voteList.watchVotingPaused(); err != nil {
+		log.Println("Error loading voting pause" + err.Error())
+		return nil, err
+	}
+
+	//Pass on the vote events of every replica to our watchers
+	if err := voteList.subscribeVoteEvents(); err != nil {
+		log.Println("Error subscribing to vote events" + err.Error())
+		return nil, err
+	}
+	return voteList, nil
+}
+
+// newVoteList returns a VoteList that keeps its votes in store, with the
+// default settings.  c is the redis the features that need it use, see
+// withoutRedis for a store that isn't redis
+func newVoteList(store voteStore, c cache) *VoteList {
+	//Return a pointer to a new voteList struct
+	return &VoteList{
+		healthInfo:        HealthData{},
+		store:             store,
+		trashRetention:    DefaultTrashRetention,
+		voteRateWindow:    DefaultVoteRateWindow,
+		strictRefs:        true,
+		dependencyTimeout: DefaultDependencyTimeout,
+		voters:            newDependency("voters", "VOTERS_API_URL", DefaultVotersAPIURL, "/voters"),
+		polls:             newDependency("polls", "POLLS_API_URL", DefaultPollsAPIURL, "/polls"),
+		eventWatchers:     newVoteEventWatchers(),
+		idScheme:          idSchemeFromEnv(),
+		idempotencyTTL:    DefaultIdempotencyTTL,
+		orphanAction:      orphanActionFromEnv(),
+		anonymousPepper:   anonymousPepperFromEnv(),
+		receiptSecret:     receiptSecretFromEnv(),
+		cache:             c,
+	}
+}
+
+//------------------------------------------------------------
+// REDIS HELPERS
+//------------------------------------------------------------
+
+// notFoundOr returns a not found error saying msg if err is ErrNotFound,
+// otherwise err is a problem with redis and is passed back as it is
+func notFoundOr(err error, msg string) error {
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, msg)
+	}
+	return err
+}
+
+// In redis, our keys will be strings, they will look like
+// votes:<number>.  This function will take an integer and
+// return a string that can be used as a key in redis
+func redisKeyFromId(id uint) string {
+	return fmt.Sprintf("%s%d", RedisKeyPrefix, id)
+}
+
+// Deleted votes are kept under trash:votes:<number> until they
+// expire, this returns that key for a vote id
+func trashKeyFromId(id uint) string {
+	return fmt.Sprintf("%s%d", TrashKeyPrefix, id)
+}
+
+// Helper to return a VoteList from redis provided a key
+func (c *cache) getItemFromRedis(key string, vote *Vote) error {
+
+	//Lets query redis for the vote, note we can return parts of the
+	//json structure, the second parameter "." means return the entire
+	//json structure
+	//ReJSON reports a key that does not exist as a redis nil, anything
+	//else is a real error talking to redis
+	voteObject, err := c.jsonHelper.JSONGet(key, ".")
+	if err != nil {
+		if err.Error() == RedisNilError {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	//JSONGet returns an "any" object, or empty interface,
+	//we need to convert it to a byte array, which is the
+	//underlying type of the object, then we can unmarshal
+	//it into our voter struct
+	stored, err := decodeVote(voteObject.([]byte))
+	if err != nil {
+		return err
+	}
+	*vote = stored
+
+	return nil
+}
+
+// Helper to return the votes at keys from redis, reading them in batches
+// rather than one at a time.  Keys that have been deleted since they were
+// listed are skipped
+func (c *cache) getItemsFromRedis(keys []string) ([]Vote, error) {
+	docs, err := c.getDocsFromRedis(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var voteList []Vote
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		vote, err := decodeVote(doc)
+		if err != nil {
+			return nil, err
+		}
+		voteList = append(voteList, vote)
+	}
+	return voteList, nil
+}
+
+// decodeVote unmarshals a stored vote.  It unmarshals into a new vote so
+// that nothing is left over from another vote, and fills in what votes
+// stored by older versions didn't record
+func decodeVote(doc []byte) (Vote, error) {
+	var vote Vote
+	if err := json.Unmarshal(doc, &vote); err != nil {
+		return Vote{}, err
+	}
+	//Votes stored before there were sources didn't record one
+	if vote.Source == "" {
+		vote.Source = SourceUnknown
+	}
+	return vote, nil
+}
+
+// Each poll has a sorted set of the times of its recent votes, it
+// looks like poll:<number>:rate
+func rateKeyFromPollId(id uint) string {
+	return fmt.Sprintf("poll:%d:rate", id)
+}
+
+// checkVoteRate records a vote against the poll's rate window and
+// returns ErrVoteRateExceeded if that takes the poll over limit votes
+// in the window ending at now.  A limit of 0 means the poll is not limited.
+// A vote that is then not stored is taken back out with uncountVoteRate.
+// The windows are kept in redis, so that every replica counts the same
+// votes, without it a limited poll takes no votes at all rather than
+// letting through more than its limit
+func (v *VoteList) checkVoteRate(pollId uint, voteId uint, limit uint, now time.Time) error {
+	if limit == 0 {
+		return nil
+	}
+	if err := v.needsRedis(); err != nil {
+		return err
+	}
+
+	//The vote is added to the set first and then counted, all inside of
+	//one transaction, so concurrent votes can't both see room for one
+	//more.  Entries older than the window are trimmed as we go
+	key := rateKeyFromPollId(pollId)
+	member := voteRateMember(voteId, now)
+	windowStart := strconv.FormatInt(now.Add(-v.voteRateWindow).UnixNano(), 10)
+
+	var count *redis.IntCmd
+	_, err := v.cacheClient.TxPipelined(v.context, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(v.context, key, &redis.Z{Score: float64(now.UnixNano()), Member: member})
+		pipe.ZRemRangeByScore(v.context, key, "-inf", "("+windowStart)
+		count = pipe.ZCard(v.context, key)
+		pipe.Expire(v.context, key, v.voteRateWindow)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if uint(count.Val()) > limit {
+		//This vote is rejected so it should not count against the window
+		v.cacheClient.ZRem(v.context, key, member)
+		return ErrVoteRateExceeded
+	}
+
+	return nil
+}
+
+// voteRateMember is the entry checkVoteRate adds to a poll's rate window
+// for the vote with voteId cast at now
+func voteRateMember(voteId uint, now time.Time) string {
+	return fmt.Sprintf("%d-%d", now.UnixNano(), voteId)
+}
+
+// uncountVoteRate takes a vote that checkVoteRate counted back out of the
+// poll's rate window, for a vote that was not stored after all, so that
+// it doesn't use up the poll's room.  Like the indexes a failure is only
+// logged
+func (v *VoteList) uncountVoteRate(pollId uint, voteId uint, limit uint, now time.Time) {
+	if limit == 0 || v.needsRedis() != nil {
+		return
+	}
+	if err := v.cacheClient.ZRem(v.context, rateKeyFromPollId(pollId), voteRateMember(voteId, now)).Err(); err != nil {
+		log.Println("Error taking vote ", voteId, " out of the rate window: ", err)
+	}
+}
+
+//------------------------------------------------------------
+// THESE ARE THE PUBLIC FUNCTIONS THAT SUPPORT OUR VOTE APP
+//------------------------------------------------------------
+
+// AddVote accepts a Vote and adds it to the DB.  passcode and invitation
+// are the ones the vote was sent with, for polls that have a passcode or
+// are invite only.  now is the time the vote is cast at, it is checked
+// against the poll's closing time and rate window.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The vote must not already exist in the DB
+//	    				because we use the vote.VoteID as the key, this
+//						function must check if the vote already
+//	    				exists in the DB, if so, return an error
+//
+//					(3) Voting must not be paused, if it is
+//						ErrVotingPaused is returned
+//
+//					(4) The VoteValue must be one of the poll's
+//						options, if it is not an
+//						*InvalidVoteValueError is returned
+//
+//					(5) If the poll has a passcode, passcode must be
+//						it, if it is not ErrInvalidPasscode is
+//						returned
+//
+//					(6) If the poll is invite only, invitation must
+//						be the token of an unused invitation of the
+//						voter, if it is not ErrInvalidInvitation is
+//						returned
+//
+//					(7) If the poll is anonymous, the voter must not
+//						have voted in it, if they have ErrAlreadyVoted
+//						is returned
+//
+// Postconditions:
+//
+//	    (1) The vote will be added to the DB, with a hash of the voter
+//			in VoterHash in place of VoterID if the poll is anonymous
+//		(2) The DB file will be saved with the vote added, and the
+//			voter's invitation used up if the poll is invite only
+//		(3) If there is an error, it will be returned
+func (v *VoteList) AddVote(vote Vote, passcode string, invitation string, now time.Time) error {
+
+	if v.VotingPaused() {
+		return ErrVotingPaused
+	}
+	if vote.Source == "" {
+		vote.Source = SourceUnknown
+	}
+	vote.VoterHash = ""
+	if err := ValidateSource(vote.Source); err != nil {
+		return err
+	}
+	if err := validateRankings(&vote); err != nil {
+		return err
+	}
+	if err := validateSelections(&vote); err != nil {
+		return err
+	}
+	if err := validateWriteIn(&vote); err != nil {
+		return err
+	}
+
+	//Before we add an vote to the DB, lets make sure
+	//it does not exist, if it does, return an error
+	if _, err := v.store.get(vote.VoteID); err == nil {
+		return fmt.Errorf("%w: vote already exists", ErrAlreadyExists)
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	//Without strict refs a vote may point at a voter or poll that does
+	//not exist (yet).  A poll that does exist still has its status and
+	//rate limit enforced
+	if v.strictRefs {
+		var checkVoter struct{ VoterID uint }
+		if err := v.getDependency(v.voters, vote.VoterID, &checkVoter); err != nil {
+			return notFoundOr(err, "voter does not exists")
+		}
+	}
+	var checkPoll pollRef
+	if err := v.getDependency(v.polls, vote.PollID, &checkPoll); err != nil {
+		if v.strictRefs || !errors.Is(err, ErrNotFound) {
+			return notFoundOr(err, "poll does not exists")
+		}
+	}
+	if err := checkPoll.checkOpen(now); err != nil {
+		return err
+	}
+	if err := v.checkPasscode(checkPoll, passcode); err != nil {
+		return err
+	}
+	if err := checkPoll.checkVoteValue(vote); err != nil {
+		return err
+	}
+	if err := checkPoll.checkSelections(vote); err != nil {
+		return err
+	}
+	if err := checkPoll.checkWriteIn(vote); err != nil {
+		return err
+	}
+
+	//Polls can set their own rate limit, otherwise the global one is used
+	rateLimit := v.voteRateLimit
+	if checkPoll.Settings.VoteRateLimit > 0 {
+		rateLimit = checkPoll.Settings.VoteRateLimit
+	}
+	if err := v.checkVoteRate(vote.PollID, vote.VoteID, rateLimit, now); err != nil {
+		return err
+	}
+	//From here on a vote that is not stored is taken back out of the
+	//rate window, only votes that were stored count against it
+
+	//Add vote to database with JSON Set
+	vote.Links = []string{"GET All Votes: 1100/votes/", "POST Vote: 1100/votes/:id", "DELETE All Votes: 1100/votes", "DELETE Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id","GET All Polls: 1090/Polls/","POST Poll: 1090/polls/:id"}
+	vote.Version = 1
+	if err := validateVoteStorable(vote); err != nil {
+		v.uncountVoteRate(vote.PollID, vote.VoteID, rateLimit, now)
+		return err
+	}
+	voterId := vote.VoterID
+	if checkPoll.Settings.Anonymous {
+		if err := v.anonymize(&vote); err != nil {
+			v.uncountVoteRate(vote.PollID, vote.VoteID, rateLimit, now)
+			return err
+		}
+	}
+	//The check above turns most duplicates away before the voter and
+	//poll are looked up, create catches one added since
+	if err := v.store.create(vote); err != nil {
+		v.unmarkVoted(vote)
+		v.uncountVoteRate(vote.PollID, vote.VoteID, rateLimit, now)
+		if errors.Is(err, ErrAlreadyExists) {
+			return fmt.Errorf("%w: vote already exists", err)
+		}
+		return err
+	}
+	//The invitation is only used up once the vote is stored, so a vote
+	//that fails to store, or an AddVoteNewID retry under another id,
+	//keeps it.  If it can't be used up the vote is taken back out
+	if err := v.redeemInvitation(checkPoll, voterId, invitation); err != nil {
+		if delErr := v.store.delete(vote); delErr != nil {
+			log.Println("Error removing vote ", vote.VoteID, " after its invitation was turned away: ", delErr)
+		}
+		v.unmarkVoted(vote)
+		v.uncountVoteRate(vote.PollID, vote.VoteID, rateLimit, now)
+		return err
+	}
+	v.publishVoteEvent(VoteCreated, vote)
+
+	//If everything is ok, return nil for the error
+	return nil
+}
+
+// DeleteVote accepts a vote id and moves it to the trash.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The vote must exist in the DB
+//	    				because we use the vote.VoteID as the key, this
+//						function must check if the vote already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The vote will be moved to trash:votes:<id> where it can be
+//			restored with RestoreVote until the trash retention expires,
+//			at which point redis purges it.  Stores with no trash delete
+//			it for good
+//		(2) The vote will no longer be returned by GetVote or GetAllVotes
+//		(3) If there is an error, it will be returned
+func (v *VoteList) DeleteVote(id uint) error {
+
+	//We need the poll of the vote to keep its vote count right
+	vote, err := v.store.get(id)
+	if err != nil {
+		return notFoundOr(err, "vote does not exist")
+	}
+
+	if trash, ok := v.store.(voteTrash); ok {
+		err = trash.trash(vote, v.trashRetention)
+	} else {
+		err = v.store.delete(vote)
+	}
+	if err != nil {
+		return notFoundOr(err, "vote does not exist")
+	}
+	v.unmarkVoted(vote)
+	v.publishVoteEvent(VoteDeleted, vote)
+
+	return nil
+}
+
+// RestoreVote accepts a vote id and moves it from the trash back
+// into the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The vote must be in the trash, i.e. deleted
+//						less than the trash retention ago
+//
+//					(3) No other vote may have been added with the same
+//						id since it was deleted
+//
+// Postconditions:
+//
+//	    (1) The vote will be back in the DB with no expiry and returned
+//		(2) If the store has no trash ErrNeedsRedis will be returned
+//		(3) If there is an error, it will be returned
+func (v *VoteList) RestoreVote(id uint) (Vote, error) {
+
+	trash, ok := v.store.(voteTrash)
+	if !ok {
+		return Vote{}, ErrNeedsRedis
+	}
+	vote, err := trash.restore(id)
+	if err != nil {
+		return Vote{}, err
+	}
+	v.markVoted(vote)
+	v.publishVoteEvent(VoteCreated, vote)
+
+	return vote, nil
+}
+
+// DeleteAllVotes removes all votes from the DB.
+// It will be exposed via a DELETE /votes endpoint
+func (v *VoteList) DeleteAllVotes() error {
+
+	if err := v.store.deleteAll(); err != nil {
+		return err
+	}
+	v.publishVotesCleared()
+
+	return nil
+}
+
+// RemovePlaceholderVotes deletes the all zero vote that GetAllVotes used
+// to hand back when there were no votes.  Anything that saved that list
+// and wrote it back stored it as votes:0, where it is counted as a real
+// vote.  A vote 0 that has a voter, poll or value set is a real vote and
+// is left alone
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) Any placeholder vote will be removed from the DB
+//		(2) The removed votes will be returned, an empty slice
+//			if there were none
+//		(3) If there is an error, it will be returned
+func (v *VoteList) RemovePlaceholderVotes() ([]Vote, error) {
+
+	removed := []Vote{}
+
+	vote, err := v.store.get(0)
+	if errors.Is(err, ErrNotFound) {
+		return removed, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if vote.VoterID != 0 || vote.PollID != 0 || vote.VoteValue != 0 {
+		return removed, nil
+	}
+
+	if err := v.store.delete(vote); err != nil {
+		return nil, err
+	}
+	v.publishVoteEvent(VoteDeleted, vote)
+	removed = append(removed, vote)
+
+	return removed, nil
+}
+
+// UpdateVote accepts a Vote and updates it in the DB.  passcode,
+// invitation and now are as for AddVote, the vote is checked against its
+// poll the same way.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The vote must exist in the DB
+//	    				because we use the vote.VoteID as the key, this
+//						function must check if the vote already
+//	    				exists in the DB, if not, return an error
+//
+//					(3) The poll must be open at now, passcode must be
+//						its passcode if it has one, and the vote must
+//						fit its options, selections and write-ins, as
+//						for AddVote
+//
+//					(4) If the poll is invite only and the vote is
+//						given another voter or moved into it,
+//						invitation must be the token of an unused
+//						invitation of the voter.  It is used up once
+//						the vote is stored
+//
+// Postconditions:
+//
+//	    (1) The vote will be updated in the DB
+//		(2) The DB file will be saved with the vote updated
+//		(3) A vote in an anonymous poll keeps its VoterHash and
+//			gets no VoterID, and if it is moved to another poll an
+//			error wrapping ErrConflict will be returned
+//		(4) If there is an error, it will be returned
+func (v *VoteList) UpdateVote(vote Vote, passcode string, invitation string, now time.Time) error {
+
+	if v.VotingPaused() {
+		return ErrVotingPaused
+	}
+
+	// Check if vote exists before trying to update it
+	// this is a good practice, return an error if the
+	// vote does not exist
+	existingVote, err := v.store.get(vote.VoteID)
+	if err != nil {
+		return notFoundOr(err, "vote does not exist")
+	}
+	//A vote sent with no Version overwrites whatever is stored
+	if vote.Version != 0 && vote.Version != existingVote.Version {
+		return versionMismatch(vote.Version, existingVote.Version)
+	}
+
+	//An update that leaves out the source keeps the current one
+	if vote.Source == "" {
+		vote.Source = existingVote.Source
+	}
+	//The voter of an anonymous vote can't be put back, or its hash
+	//moved to a poll it was not made for
+	vote.VoterHash = existingVote.VoterHash
+	if vote.VoterHash != "" {
+		if vote.PollID != existingVote.PollID {
+			return fmt.Errorf("%w: an anonymous vote can't be moved to another poll", ErrConflict)
+		}
+		vote.VoterID = 0
+	}
+	if err := ValidateSource(vote.Source); err != nil {
+		return err
+	}
+	if err := validateRankings(&vote); err != nil {
+		return err
+	}
+	if err := validateSelections(&vote); err != nil {
+		return err
+	}
+	if err := validateWriteIn(&vote); err != nil {
+		return err
+	}
+
+	//The vote is held to its poll as a new one is, a voter it is given
+	//has to exist with strict refs like the one of a new vote
+	if v.strictRefs && vote.VoterHash == "" && vote.VoterID != existingVote.VoterID {
+		var checkVoter struct{ VoterID uint }
+		if err := v.getDependency(v.voters, vote.VoterID, &checkVoter); err != nil {
+			return notFoundOr(err, "voter does not exists")
+		}
+	}
+	checkPoll, err := v.openPoll(vote.PollID, now)
+	if err != nil {
+		return err
+	}
+	if err := v.checkPasscode(checkPoll, passcode); err != nil {
+		return err
+	}
+	if err := checkPoll.checkVoteValue(vote); err != nil {
+		return err
+	}
+	if err := checkPoll.checkSelections(vote); err != nil {
+		return err
+	}
+	if err := checkPoll.checkWriteIn(vote); err != nil {
+		return err
+	}
+
+	//Overwrite the existing vote, as long as it has not changed since
+	//it was read above, so the events below are sent for the right poll
+	vote.Links = []string{"GET All Votes: 1100/votes/", "POST Vote: 1100/votes/:id", "DELETE All Votes: 1100/votes", "DELETE Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id","GET All Polls: 1090/Polls/","POST Poll: 1090/polls/:id"}
+	vote.Version = existingVote.Version + 1
+	if err := validateVoteStorable(vote); err != nil {
+		return err
+	}
+	if err := v.store.putVersion(vote, existingVote.Version); err != nil {
+		return notFoundOr(err, "vote does not exist")
+	}
+	//The voter already used their invitation on the vote unless it is
+	//now someone else's or in another poll.  As with AddVote it is only
+	//used up once the vote is stored, and the old vote is put back if it
+	//can't be
+	if vote.VoterID != existingVote.VoterID || vote.PollID != existingVote.PollID {
+		if err := v.redeemInvitation(checkPoll, vote.VoterID, invitation); err != nil {
+			if putErr := v.store.putVersion(existingVote, vote.Version); putErr != nil {
+				log.Println("Error putting back vote ", vote.VoteID, " after its invitation was turned away: ", putErr)
+			}
+			return err
+		}
+	}
+	if vote.PollID != existingVote.PollID {
+		v.publishVoteEvent(VoteDeleted, existingVote)
+		v.publishVoteEvent(VoteCreated, vote)
+	} else {
+		v.publishVoteEvent(VoteUpdated, vote)
+	}
+
+	return nil
+}
+
+// GetVote accepts a Vote id and returns the vote from the DB.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) The vote must exist in the DB
+//	    				because we use the vote.VoteID as the key, this
+//						function must check if the vote already
+//	    				exists in the DB, if not, return an error
+//
+// Postconditions:
+//
+//	    (1) The vote will be returned, if it exists
+//		(2) If there is an error, it will be returned
+//			along with an empty vote
+//		(3) The database file will not be modified
+func (v *VoteList) GetVote(id uint) (Vote, error) {
+
+	// Check if vote exists before trying to get it
+	// this is a good practice, return an error if the
+	// vote does not exist
+	vote, err := v.store.get(id)
+	if err != nil {
+		return Vote{}, notFoundOr(err, "vote does not exist")
+	}
+
+	return vote, nil
+}
+
+// GetAllVotes returns all votes from the DB.  If successful it
+// returns a slice of all of the votes to the caller
+// Preconditions:   (1) The database file must exist and be a valid
+//
+// Postconditions:
+//
+//	    (1) All votes will be returned, if any exist
+//		(2) If there is an error, it will be returned
+//			along with an empty slice
+//		(3) The database file will not be modified
+func (v *VoteList) GetAllVotes() ([]Vote, error) {
+
+	//Every store lists the votes sorted by id, so that pages of the
+	//list are stable
+	voteList, err := v.store.list()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(voteList) < 1 {
+		voteList = append(voteList, Vote{
+			VoteID: 0,
+			VoterID: 0,
+			PollID: 0,
+			VoteValue: 0,
+			Links: []string{"GET All Votes: 1100/votes/", "POST Vote: 1100/votes/:id", "DELETE All Votes: 1100/votes", "DELETE Vote: 1100/votes/:id","GET All Voters: 1080/voters/","POST Voter: 1080/voters/:id","GET All Polls: 1090/Polls/","POST Poll: 1090/polls/:id"},
+		})
+	}
+
+	//Now that we have all of our votes in a slice, return it
+	return voteList, nil
+}
+
+// GetVotesPage returns a page of the votes in the DB, starting at cursor,
+// 0 for the first page, for clients that can't wait for every vote at
+// once.  count is how many votes to return, 0 for SCAN_BATCH_SIZE.  With
+// redis the cursor is the redis SCAN cursor, and SCAN is repeated until
+// there are at least count votes, so only the last page is short, but a
+// page can have a few more than count as SCAN can't stop part way through
+// a batch.  Other stores page by vote id.  Unlike GetAllVotes the votes
+// are only sorted within the page, and an empty DB gives an empty page.
+// Preconditions:   (1) The database file must exist and be a valid
+//
+//					(2) cursor must be 0 or a cursor returned by an
+//						earlier call
+//
+// Postconditions:
+//
+//	    (1) The page of votes will be returned, along with the
+//			cursor of the next page, 0 if this was the last page
+//		(2) If there is an error, it will be returned
+//		(3) The database file will not be modified
+func (v *VoteList) GetVotesPage(cursor uint64, count int) ([]Vote, uint64, error) {
+	if count <= 0 {
+		count = int(v.scanBatch)
+	}
+	return v.store.listPage(cursor, count)
+}
+
+// PrintVote accepts a Vote and prints it to the console
+// in a JSON pretty format. As some help, look at the
+// json.MarshalIndent() function from our in class go tutorial.
+func (v *VoteList) PrintVote(vote Vote) {
+	jsonBytes, _ := json.MarshalIndent(vote, "", "  ")
+	fmt.Println(string(jsonBytes))
+}
+
+// PrintAllVotes accepts a slice of Votes and prints them to the console
+// in a JSON pretty format.  It should call PrintVote() to print each vote
+// versus repeating the code.
+func (v *VoteList) PrintAllVotes(voteList []Vote) {
+	for _, vote := range voteList {
+		v.PrintVote(vote)
+	}
+}
+
+// JsonToVote accepts a json string and returns a Vote
+// This is helpful because the CLI accepts votes for insertion
+// and updates in JSON format.  We need to convert it to a Vote
+// struct to perform any operations on it.
+func (v *VoteList) JsonToVote(jsonString string) (Vote, error) {
+	var vote Vote
+	err := json.Unmarshal([]byte(jsonString), &vote)
+	if err != nil {
+		return Vote{}, err
+	}
+
+	return vote, nil
+}
+
+func (v *VoteList) GetHealthData(bootTime time.Time, calls uint) (HealthData, error){
+
+	//Time a PING so that a slow or unreachable store shows up in the
+	//health record
+	start := time.Now()
+	if err := v.store.ping(); err != nil {
+		return HealthData{}, err
+	}
+	latency := time.Since(start)
+
+	v.healthInfo = HealthData{Uptime: time.Now().Sub(bootTime), APIcalls: calls, RedisLatency: latency}
+
+	return v.healthInfo, nil
 }
\ No newline at end of file
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidVoteValue is returned when a vote's VoteValue is not one of the
+// options of its poll, the error is an *InvalidVoteValueError
+var ErrInvalidVoteValue = errors.New("invalid vote value")
+
+// InvalidVoteValueError is the error returned for a vote whose VoteValue
+// is not one of the options of its poll.  ValidOptions are the poll's
+// option ids, in the poll's order, so the caller can say what would have
+// been accepted
+type InvalidVoteValueError struct {
+	PollID       uint
+	VoteValue    uint
+	ValidOptions []uint
+}
+
+func (e *InvalidVoteValueError) Error() string {
+	return fmt.Sprintf("%s: %d is not an option of poll %d", ErrInvalidVoteValue, e.VoteValue, e.PollID)
+}
+
+func (e *InvalidVoteValueError) Unwrap() error {
+	return ErrInvalidVoteValue
+}
+
+// checkVoteValue makes sure the VoteValue of a vote is one of the poll's
+// options.  A write-in has no VoteValue, and a poll that was not found is
+// the zero pollRef whose options are not known, neither is checked
+func (p pollRef) checkVoteValue(vote Vote) error {
+	if p.PollID == 0 || vote.WriteInText != "" {
+		return nil
+	}
+	valid := make([]uint, 0, len(p.PollOptions))
+	for _, option := range p.PollOptions {
+		if option.PollOptionID == vote.VoteValue {
+			return nil
+		}
+		valid = append(valid, option.PollOptionID)
+	}
+	return &InvalidVoteValueError{PollID: p.PollID, VoteValue: vote.VoteValue, ValidOptions: valid}
+}
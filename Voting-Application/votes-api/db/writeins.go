@@ -0,0 +1,44 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxWriteInLength is the most characters a write-in answer can have
+const MaxWriteInLength = 200
+
+// ErrInvalidWriteIn is returned when a vote has a write-in answer that
+// can't be stored, or its poll doesn't take write-ins
+var ErrInvalidWriteIn = errors.New("invalid write-in")
+
+// validateWriteIn trims the WriteInText of a vote and makes sure a vote
+// with one is only a write-in.  A write-in vote has no VoteValue, since it
+// is for none of the poll's options, and can't rank or select options
+func validateWriteIn(vote *Vote) error {
+	vote.WriteInText = strings.TrimSpace(vote.WriteInText)
+	if vote.WriteInText == "" {
+		if vote.VoteValue == 0 {
+			return fmt.Errorf("%w: a vote needs a VoteValue or a WriteInText", ErrInvalidWriteIn)
+		}
+		return nil
+	}
+	if vote.VoteValue != 0 || len(vote.VoteValues) > 0 || len(vote.Rankings) > 0 {
+		return fmt.Errorf("%w: a write-in vote can't also choose options", ErrInvalidWriteIn)
+	}
+	if utf8.RuneCountInString(vote.WriteInText) > MaxWriteInLength {
+		return fmt.Errorf("%w: WriteInText can be at most %d characters", ErrInvalidWriteIn, MaxWriteInLength)
+	}
+	return nil
+}
+
+// checkWriteIn makes sure the poll takes write-ins if the vote is one.
+// A poll that was not found is the zero pollRef, which is not checked
+func (p pollRef) checkWriteIn(vote Vote) error {
+	if p.PollID == 0 || vote.WriteInText == "" || p.Settings.AllowWriteIns {
+		return nil
+	}
+	return fmt.Errorf("%w: poll %d does not take write-ins", ErrInvalidWriteIn, p.PollID)
+}
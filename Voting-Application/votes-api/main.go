@@ -1,61 +1,235 @@
-package main
-
-import (
-	"flag"
-	"fmt"
-	"os"
-
-	"drexel.edu/votes/api"
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-)
-
-// Global variables to hold the command line flags to drive the voters CLI
-// application
-var (
-	hostFlag string
-	portFlag uint
-)
-
-func processCmdLineFlags() {
-
-	//Note some networking lingo, some frameworks start the server on localhost
-	//this is a local-only interface and is fine for testing but its not accessible
-	//from other machines.  To make the server accessible from other machines, we
-	//need to listen on an interface, that could be an IP address, but modern
-	//cloud servers may have multiple network interfaces for scale.  With TCP/IP
-	//the address 0.0.0.0 instructs the network stack to listen on all interfaces
-	//We set this up as a flag so that we can overwrite it on the command line if
-	//needed
-	flag.StringVar(&hostFlag, "h", "0.0.0.0", "Listen on all interfaces")
-	flag.UintVar(&portFlag, "p", 1100, "Default Port")
-
-	flag.Parse()
-}
-
-// main is the entry point for our vote API application.  It processes
-// the command line flags and then uses the db package to perform the
-// requested operation
-func main() {
-	processCmdLineFlags()
-	r := gin.Default()
-	r.Use(cors.Default())
-
-	apiHandler, err := api.New()
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-
-	r.GET("/votes", apiHandler.ListAllVotes)
-	r.POST("/votes", apiHandler.AddVote)
-	r.PUT("/votes", apiHandler.UpdateVote)
-	r.DELETE("/votes", apiHandler.DeleteAllVotes)
-	r.DELETE("/votes/:id", apiHandler.DeleteVote)
-	r.GET("/votes/:id", apiHandler.GetVote)
-	r.GET("/votes/health", apiHandler.GetHealthData)
-	r.GET("/crash", apiHandler.CrashSim)
-
-	serverPath := fmt.Sprintf("%s:%d", hostFlag, portFlag)
-	r.Run(serverPath)
-}
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"drexel.edu/votes/api"
+	"drexel.edu/votes/db"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// Global variables to hold the command line flags to drive the voters CLI
+// application
+var (
+	hostFlag string
+	portFlag uint
+	grpcFlag uint
+	dbFlag   string
+)
+
+func processCmdLineFlags() {
+
+	//Note some networking lingo, some frameworks start the server on localhost
+	//this is a local-only interface and is fine for testing but its not accessible
+	//from other machines.  To make the server accessible from other machines, we
+	//need to listen on an interface, that could be an IP address, but modern
+	//cloud servers may have multiple network interfaces for scale.  With TCP/IP
+	//the address 0.0.0.0 instructs the network stack to listen on all interfaces
+	//We set this up as a flag so that we can overwrite it on the command line if
+	//needed
+	flag.StringVar(&hostFlag, "h", "0.0.0.0", "Listen on all interfaces")
+	flag.UintVar(&portFlag, "p", 1100, "Default Port")
+	//The gRPC server runs beside the REST one, on a port of its own.  0
+	//turns it off
+	flag.UintVar(&grpcFlag, "gp", 1101, "gRPC Port, 0 for no gRPC server")
+	flag.StringVar(&dbFlag, "db", defaultDB(), "Where to keep votes, redis, mongo, postgres or dynamodb")
+
+	flag.Parse()
+}
+
+// processFeatureFlags reads the FEATURE_* environment variables once at
+// startup.  Each flag switches an optional capability on or off for this
+// deployment, if a flag is not set (or cannot be parsed) its default is used
+func processFeatureFlags() api.Features {
+	features := api.Features{
+		SSE:        envFlag("FEATURE_SSE", false),
+		Auth:       envFlag("FEATURE_AUTH", true),
+		Metrics:    envFlag("FEATURE_METRICS", false),
+		Crash:      envFlag("FEATURE_CRASH", true),
+		StrictIDs:  envFlag("FEATURE_STRICT_IDS", true),
+		StrictJSON: envFlag("FEATURE_STRICT_JSON", false),
+		AutoIDs:    envFlag("FEATURE_AUTO_IDS", true),
+		TestClock:  envFlag("FEATURE_TEST_CLOCK", false),
+		Gateway:    envFlag("FEATURE_GATEWAY", true),
+	}
+	warnAuth(features.Auth)
+	return features
+}
+
+// warnAuth logs loudly at startup when the administrative endpoints are
+// open to anyone, FEATURE_AUTH is off, or closed to everyone, there is no
+// ADMIN_TOKEN to send
+func warnAuth(auth bool) {
+	if !auth {
+		log.Println("WARNING: FEATURE_AUTH is off, every administrative endpoint is open to anyone.  Only turn it off in dev/test deployments")
+		return
+	}
+	if os.Getenv("ADMIN_TOKEN") == "" {
+		log.Println("WARNING: ADMIN_TOKEN is not set, every administrative request will be turned away")
+	}
+}
+
+// processLimits reads MAX_BODY_BYTES and REQUEST_TIMEOUT once at startup
+// for the routes that take a body, see api.Limits.  If either is not set
+// (or cannot be parsed) its default is used
+func processLimits() (int64, time.Duration) {
+	maxBody := int64(api.DefaultMaxBodyBytes)
+	if v := os.Getenv("MAX_BODY_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Println("Invalid MAX_BODY_BYTES, using default: ", v)
+		} else {
+			maxBody = n
+		}
+	}
+
+	timeout := api.DefaultRequestTimeout
+	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Println("Invalid REQUEST_TIMEOUT, using default: ", v)
+		} else {
+			timeout = d
+		}
+	}
+	return maxBody, timeout
+}
+
+// envFlag returns the boolean value of the environment variable name, or
+// def if it is not set
+func envFlag(name string, def bool) bool {
+	val, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// defaultDB is the store -db defaults to, DB_DRIVER if it is set so that
+// a container can pick its store from the environment, otherwise redis
+func defaultDB() string {
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		return driver
+	}
+	return "redis"
+}
+
+// newAPI creates the api backed by the store picked with -db
+func newAPI(features api.Features) (*api.VotesAPI, error) {
+	//An ID_SCHEME there is no such scheme as stops the service starting
+	if err := db.CheckIDScheme(); err != nil {
+		return nil, err
+	}
+
+	switch dbFlag {
+	case "redis":
+		return api.New(features)
+	case "mongo":
+		voteList, err := db.NewMongoVoteList()
+		if err != nil {
+			return nil, err
+		}
+		return api.NewWithStore(voteList, features), nil
+	case "postgres":
+		voteList, err := db.NewPostgresVoteList()
+		if err != nil {
+			return nil, err
+		}
+		return api.NewWithStore(voteList, features), nil
+	case "dynamodb":
+		voteList, err := db.NewDynamoVoteList()
+		if err != nil {
+			return nil, err
+		}
+		return api.NewWithStore(voteList, features), nil
+	default:
+		return nil, fmt.Errorf("unknown -db %q, use redis, mongo, postgres or dynamodb", dbFlag)
+	}
+}
+
+// serveGRPC serves the VotesAPI gRPC service on -gp.  The REST server
+// keeps running if it stops, so it is only logged
+func serveGRPC(apiHandler *api.VotesAPI) {
+	grpcPath := fmt.Sprintf("%s:%d", hostFlag, grpcFlag)
+	listener, err := net.Listen("tcp", grpcPath)
+	if err != nil {
+		log.Println("Error listening for gRPC: ", err)
+		return
+	}
+	log.Println("Serving gRPC on ", grpcPath)
+	if err := apiHandler.GRPCServer().Serve(listener); err != nil {
+		log.Println("Error serving gRPC: ", err)
+	}
+}
+
+// main is the entry point for our vote API application.  It processes
+// the command line flags and then uses the db package to perform the
+// requested operation
+func main() {
+	processCmdLineFlags()
+	features := processFeatureFlags()
+	r := gin.Default()
+	r.Use(cors.Default())
+	r.Use(api.Problems())
+	r.Use(api.Limits(processLimits()))
+
+	apiHandler, err := newAPI(features)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	r.GET("/votes", apiHandler.ListAllVotes)
+	r.POST("/votes", apiHandler.Idempotent, apiHandler.Audited, apiHandler.AddVote)
+	r.PUT("/votes", apiHandler.Audited, apiHandler.UpdateVote)
+	r.DELETE("/votes", apiHandler.AuditedAll, apiHandler.DeleteAllVotes)
+	r.PUT("/votes/byballot", apiHandler.RecastVote)
+	r.POST("/votes/verify-receipt", apiHandler.VerifyReceipt)
+	r.DELETE("/votes/byballot", apiHandler.RetractVote)
+	r.PATCH("/votes/:id", apiHandler.Audited, apiHandler.PatchVote)
+	r.DELETE("/votes/:id", apiHandler.Audited, apiHandler.DeleteVote)
+	r.GET("/votes/:id", apiHandler.GetVote)
+	r.GET("/votes/byvoter/:voterId", apiHandler.GetVotesByVoter)
+	r.POST("/votes/byvoter/:voterId/anonymize", apiHandler.RequireAdmin, apiHandler.AnonymizeVoterVotes)
+	r.GET("/votes/orphans", apiHandler.RequireAdmin, apiHandler.GetOrphanVotes)
+	r.GET("/polls/:id/votes", apiHandler.GetPollVotes)
+	r.POST("/votes/:id/restore", apiHandler.Audited, apiHandler.RestoreVote)
+	r.GET("/votes/health", apiHandler.GetHealthData)
+	r.GET("/audit", apiHandler.RequireAdmin, apiHandler.GetAudit)
+	r.GET("/schema", apiHandler.GetSchema)
+	r.GET("/swagger/*any", apiHandler.Swagger)
+
+	admin := r.Group("/admin", apiHandler.RequireAdmin)
+	admin.POST("/votes/pause", apiHandler.PauseVoting)
+	admin.POST("/votes/resume", apiHandler.ResumeVoting)
+	admin.POST("/votes/cleanup-placeholders", apiHandler.CleanupPlaceholderVotes)
+	admin.POST("/votes/recount", apiHandler.RecountVotes)
+	admin.GET("/drift", apiHandler.GetDrift)
+
+	if features.SSE {
+		r.GET("/ws", apiHandler.VoteEventsSocket)
+	}
+	if features.Metrics {
+		r.GET("/metrics", apiHandler.GetMetrics)
+	}
+	if features.Gateway {
+		r.Any("/v1/*path", apiHandler.Gateway)
+	}
+	if features.Crash {
+		r.GET("/crash", apiHandler.CrashSim)
+	}
+
+	if grpcFlag != 0 {
+		go serveGRPC(apiHandler)
+	}
+
+	serverPath := fmt.Sprintf("%s:%d", hostFlag, portFlag)
+	r.Run(serverPath)
+}
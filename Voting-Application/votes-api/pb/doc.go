@@ -0,0 +1,8 @@
+// Package pb is the Go code generated from proto/votes.proto, the messages
+// and the VotesAPI gRPC service, and the grpc-gateway REST routes for it
+// from proto/votes_gateway.yaml.  Regenerate it after changing either with
+// go generate, which needs protoc, protoc-gen-go, protoc-gen-go-grpc and
+// protoc-gen-grpc-gateway on the PATH
+package pb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative --grpc-gateway_out=. --grpc-gateway_opt=paths=source_relative,grpc_api_configuration=../../proto/votes_gateway.yaml,generate_unbound_methods=false votes.proto
@@ -0,0 +1,407 @@
+// The Votes API over gRPC.  The messages mirror the JSON the REST API
+// sends, field for field apart from Links, so that a vote read over either
+// is the same vote
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: votes.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VotesAPI_ListVotes_FullMethodName       = "/voting.votes.v1.VotesAPI/ListVotes"
+	VotesAPI_GetVote_FullMethodName         = "/voting.votes.v1.VotesAPI/GetVote"
+	VotesAPI_AddVote_FullMethodName         = "/voting.votes.v1.VotesAPI/AddVote"
+	VotesAPI_UpdateVote_FullMethodName      = "/voting.votes.v1.VotesAPI/UpdateVote"
+	VotesAPI_DeleteVote_FullMethodName      = "/voting.votes.v1.VotesAPI/DeleteVote"
+	VotesAPI_GetVotesByPoll_FullMethodName  = "/voting.votes.v1.VotesAPI/GetVotesByPoll"
+	VotesAPI_GetVotesByVoter_FullMethodName = "/voting.votes.v1.VotesAPI/GetVotesByVoter"
+	VotesAPI_WatchVoteEvents_FullMethodName = "/voting.votes.v1.VotesAPI/WatchVoteEvents"
+)
+
+// VotesAPIClient is the client API for VotesAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VotesAPIClient interface {
+	ListVotes(ctx context.Context, in *ListVotesRequest, opts ...grpc.CallOption) (*ListVotesResponse, error)
+	GetVote(ctx context.Context, in *GetVoteRequest, opts ...grpc.CallOption) (*Vote, error)
+	// A vote for a poll with a passcode sends it in the x-poll-passcode
+	// metadata, as the X-Poll-Passcode header over HTTP, and one for an
+	// invite only poll its voter's token in x-poll-invitation.  A call with
+	// an idempotency-key, as the Idempotency-Key header, that is retried is
+	// sent the vote it added the first time rather than adding it again
+	AddVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*Vote, error)
+	UpdateVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*Vote, error)
+	DeleteVote(ctx context.Context, in *DeleteVoteRequest, opts ...grpc.CallOption) (*DeleteVoteResponse, error)
+	GetVotesByPoll(ctx context.Context, in *GetVotesByPollRequest, opts ...grpc.CallOption) (*ListVotesResponse, error)
+	GetVotesByVoter(ctx context.Context, in *GetVotesByVoterRequest, opts ...grpc.CallOption) (*ListVotesResponse, error)
+	// WatchVoteEvents is the /ws feed, filtered to poll_ids if any are given
+	WatchVoteEvents(ctx context.Context, in *WatchVoteEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VoteEvent], error)
+}
+
+type votesAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVotesAPIClient(cc grpc.ClientConnInterface) VotesAPIClient {
+	return &votesAPIClient{cc}
+}
+
+func (c *votesAPIClient) ListVotes(ctx context.Context, in *ListVotesRequest, opts ...grpc.CallOption) (*ListVotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVotesResponse)
+	err := c.cc.Invoke(ctx, VotesAPI_ListVotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votesAPIClient) GetVote(ctx context.Context, in *GetVoteRequest, opts ...grpc.CallOption) (*Vote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Vote)
+	err := c.cc.Invoke(ctx, VotesAPI_GetVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votesAPIClient) AddVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*Vote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Vote)
+	err := c.cc.Invoke(ctx, VotesAPI_AddVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votesAPIClient) UpdateVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*Vote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Vote)
+	err := c.cc.Invoke(ctx, VotesAPI_UpdateVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votesAPIClient) DeleteVote(ctx context.Context, in *DeleteVoteRequest, opts ...grpc.CallOption) (*DeleteVoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteVoteResponse)
+	err := c.cc.Invoke(ctx, VotesAPI_DeleteVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votesAPIClient) GetVotesByPoll(ctx context.Context, in *GetVotesByPollRequest, opts ...grpc.CallOption) (*ListVotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVotesResponse)
+	err := c.cc.Invoke(ctx, VotesAPI_GetVotesByPoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votesAPIClient) GetVotesByVoter(ctx context.Context, in *GetVotesByVoterRequest, opts ...grpc.CallOption) (*ListVotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVotesResponse)
+	err := c.cc.Invoke(ctx, VotesAPI_GetVotesByVoter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *votesAPIClient) WatchVoteEvents(ctx context.Context, in *WatchVoteEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VoteEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VotesAPI_ServiceDesc.Streams[0], VotesAPI_WatchVoteEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchVoteEventsRequest, VoteEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VotesAPI_WatchVoteEventsClient = grpc.ServerStreamingClient[VoteEvent]
+
+// VotesAPIServer is the server API for VotesAPI service.
+// All implementations must embed UnimplementedVotesAPIServer
+// for forward compatibility.
+type VotesAPIServer interface {
+	ListVotes(context.Context, *ListVotesRequest) (*ListVotesResponse, error)
+	GetVote(context.Context, *GetVoteRequest) (*Vote, error)
+	// A vote for a poll with a passcode sends it in the x-poll-passcode
+	// metadata, as the X-Poll-Passcode header over HTTP, and one for an
+	// invite only poll its voter's token in x-poll-invitation.  A call with
+	// an idempotency-key, as the Idempotency-Key header, that is retried is
+	// sent the vote it added the first time rather than adding it again
+	AddVote(context.Context, *Vote) (*Vote, error)
+	UpdateVote(context.Context, *Vote) (*Vote, error)
+	DeleteVote(context.Context, *DeleteVoteRequest) (*DeleteVoteResponse, error)
+	GetVotesByPoll(context.Context, *GetVotesByPollRequest) (*ListVotesResponse, error)
+	GetVotesByVoter(context.Context, *GetVotesByVoterRequest) (*ListVotesResponse, error)
+	// WatchVoteEvents is the /ws feed, filtered to poll_ids if any are given
+	WatchVoteEvents(*WatchVoteEventsRequest, grpc.ServerStreamingServer[VoteEvent]) error
+	mustEmbedUnimplementedVotesAPIServer()
+}
+
+// UnimplementedVotesAPIServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVotesAPIServer struct{}
+
+func (UnimplementedVotesAPIServer) ListVotes(context.Context, *ListVotesRequest) (*ListVotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVotes not implemented")
+}
+func (UnimplementedVotesAPIServer) GetVote(context.Context, *GetVoteRequest) (*Vote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVote not implemented")
+}
+func (UnimplementedVotesAPIServer) AddVote(context.Context, *Vote) (*Vote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddVote not implemented")
+}
+func (UnimplementedVotesAPIServer) UpdateVote(context.Context, *Vote) (*Vote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateVote not implemented")
+}
+func (UnimplementedVotesAPIServer) DeleteVote(context.Context, *DeleteVoteRequest) (*DeleteVoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVote not implemented")
+}
+func (UnimplementedVotesAPIServer) GetVotesByPoll(context.Context, *GetVotesByPollRequest) (*ListVotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVotesByPoll not implemented")
+}
+func (UnimplementedVotesAPIServer) GetVotesByVoter(context.Context, *GetVotesByVoterRequest) (*ListVotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVotesByVoter not implemented")
+}
+func (UnimplementedVotesAPIServer) WatchVoteEvents(*WatchVoteEventsRequest, grpc.ServerStreamingServer[VoteEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchVoteEvents not implemented")
+}
+func (UnimplementedVotesAPIServer) mustEmbedUnimplementedVotesAPIServer() {}
+func (UnimplementedVotesAPIServer) testEmbeddedByValue()                  {}
+
+// UnsafeVotesAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VotesAPIServer will
+// result in compilation errors.
+type UnsafeVotesAPIServer interface {
+	mustEmbedUnimplementedVotesAPIServer()
+}
+
+func RegisterVotesAPIServer(s grpc.ServiceRegistrar, srv VotesAPIServer) {
+	// If the following call pancis, it indicates UnimplementedVotesAPIServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VotesAPI_ServiceDesc, srv)
+}
+
+func _VotesAPI_ListVotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotesAPIServer).ListVotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotesAPI_ListVotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotesAPIServer).ListVotes(ctx, req.(*ListVotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotesAPI_GetVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotesAPIServer).GetVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotesAPI_GetVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotesAPIServer).GetVote(ctx, req.(*GetVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotesAPI_AddVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vote)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotesAPIServer).AddVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotesAPI_AddVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotesAPIServer).AddVote(ctx, req.(*Vote))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotesAPI_UpdateVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vote)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotesAPIServer).UpdateVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotesAPI_UpdateVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotesAPIServer).UpdateVote(ctx, req.(*Vote))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotesAPI_DeleteVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotesAPIServer).DeleteVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotesAPI_DeleteVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotesAPIServer).DeleteVote(ctx, req.(*DeleteVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotesAPI_GetVotesByPoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVotesByPollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotesAPIServer).GetVotesByPoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotesAPI_GetVotesByPoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotesAPIServer).GetVotesByPoll(ctx, req.(*GetVotesByPollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotesAPI_GetVotesByVoter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVotesByVoterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VotesAPIServer).GetVotesByVoter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VotesAPI_GetVotesByVoter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VotesAPIServer).GetVotesByVoter(ctx, req.(*GetVotesByVoterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VotesAPI_WatchVoteEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchVoteEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VotesAPIServer).WatchVoteEvents(m, &grpc.GenericServerStream[WatchVoteEventsRequest, VoteEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VotesAPI_WatchVoteEventsServer = grpc.ServerStreamingServer[VoteEvent]
+
+// VotesAPI_ServiceDesc is the grpc.ServiceDesc for VotesAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VotesAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "voting.votes.v1.VotesAPI",
+	HandlerType: (*VotesAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListVotes",
+			Handler:    _VotesAPI_ListVotes_Handler,
+		},
+		{
+			MethodName: "GetVote",
+			Handler:    _VotesAPI_GetVote_Handler,
+		},
+		{
+			MethodName: "AddVote",
+			Handler:    _VotesAPI_AddVote_Handler,
+		},
+		{
+			MethodName: "UpdateVote",
+			Handler:    _VotesAPI_UpdateVote_Handler,
+		},
+		{
+			MethodName: "DeleteVote",
+			Handler:    _VotesAPI_DeleteVote_Handler,
+		},
+		{
+			MethodName: "GetVotesByPoll",
+			Handler:    _VotesAPI_GetVotesByPoll_Handler,
+		},
+		{
+			MethodName: "GetVotesByVoter",
+			Handler:    _VotesAPI_GetVotesByVoter_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchVoteEvents",
+			Handler:       _VotesAPI_WatchVoteEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "votes.proto",
+}
@@ -1,2 +1,2 @@
-#!/bin/bash
+#!/bin/bash
 docker run -it --rm --name better-votes -p 1100:1100 votes-api-better:v1
\ No newline at end of file
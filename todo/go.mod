@@ -1,3 +1,3 @@
-module drexel.edu/todo
-
-go 1.20
+module drexel.edu/todo
+
+go 1.20